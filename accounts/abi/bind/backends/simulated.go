@@ -53,8 +53,9 @@ var (
 // SimulatedBackend implements bind.ContractBackend, simulating a blockchain in
 // the background. Its main purpose is to allow easily testing contract bindings.
 type SimulatedBackend struct {
-	// database   ethdb.Database   // In memory database to store our testing data
-	// blockchain *core.BlockChain // Ethereum blockchain to handle the consensus
+	database   ethdb.Database      // In memory database to store our testing data
+	blockchain *core.BlockChain    // Ethereum blockchain to handle the consensus
+	config     *params.ChainConfig // Chain configuration of the blockchain
 
 	mu           sync.Mutex
 	pendingBlock *types.Block   // Currently pending block that will be imported on request
@@ -63,7 +64,6 @@ type SimulatedBackend struct {
 	env *core.ChainEnv
 
 	BlockEnv *core.ChainEnv
-	// config *params.ChainConfig
 }
 
 // NewSimulatedBackend creates a new binding backend using a simulated blockchain
@@ -77,7 +77,7 @@ func NewSimulatedBackend() *SimulatedBackend {
 	bc, _ := core.NewBlockChain(db, gspec.Config, ce, vm.Config{}, nil)
 	env := core.NewChainEnv(gspec.Config, gspec, ce, bc, db)
 
-	backend := &SimulatedBackend{env: env}
+	backend := &SimulatedBackend{database: db, blockchain: bc, config: gspec.Config, env: env}
 	backend.BlockEnv = env
 	backend.rollback()
 	return backend
@@ -95,7 +95,7 @@ func NewSimulatedBackendEx(alloc core.GenesisAlloc) *SimulatedBackend {
 	bc, _ := core.NewBlockChain(db, gspec.Config, ce, vm.Config{}, nil)
 	env := core.NewChainEnv(gspec.Config, gspec, ce, bc, db)
 
-	backend := &SimulatedBackend{env: env}
+	backend := &SimulatedBackend{database: db, blockchain: bc, config: gspec.Config, env: env}
 	backend.rollback()
 	return backend
 }
@@ -131,10 +131,10 @@ func (b *SimulatedBackend) CodeAt(ctx context.Context, contract common.Address,
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if blockNumber != nil && blockNumber.Cmp(b.env.Blockchain().CurrentBlock().Number()) != 0 {
+	if blockNumber != nil && blockNumber.Cmp(b.blockchain.CurrentBlock().Number()) != 0 {
 		return nil, errBlockNumberUnsupported
 	}
-	statedb, _ := b.env.Blockchain().State()
+	statedb, _ := b.blockchain.State()
 	return statedb.GetCode(contract), nil
 }
 
@@ -143,10 +143,10 @@ func (b *SimulatedBackend) BalanceAt(ctx context.Context, contract common.Addres
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if blockNumber != nil && blockNumber.Cmp(b.env.Blockchain().CurrentBlock().Number()) != 0 {
+	if blockNumber != nil && blockNumber.Cmp(b.blockchain.CurrentBlock().Number()) != 0 {
 		return nil, errBlockNumberUnsupported
 	}
-	statedb, _ := b.env.Blockchain().State()
+	statedb, _ := b.blockchain.State()
 	return statedb.GetBalance(contract), nil
 }
 
@@ -155,10 +155,10 @@ func (b *SimulatedBackend) NonceAt(ctx context.Context, contract common.Address,
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if blockNumber != nil && blockNumber.Cmp(b.env.Blockchain().CurrentBlock().Number()) != 0 {
+	if blockNumber != nil && blockNumber.Cmp(b.blockchain.CurrentBlock().Number()) != 0 {
 		return 0, errBlockNumberUnsupported
 	}
-	statedb, _ := b.env.Blockchain().State()
+	statedb, _ := b.blockchain.State()
 	return statedb.GetNonce(contract), nil
 }
 
@@ -167,17 +167,17 @@ func (b *SimulatedBackend) StorageAt(ctx context.Context, contract common.Addres
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if blockNumber != nil && blockNumber.Cmp(b.env.Blockchain().CurrentBlock().Number()) != 0 {
+	if blockNumber != nil && blockNumber.Cmp(b.blockchain.CurrentBlock().Number()) != 0 {
 		return nil, errBlockNumberUnsupported
 	}
-	statedb, _ := b.env.Blockchain().State()
+	statedb, _ := b.blockchain.State()
 	val := statedb.GetState(contract, key)
 	return val[:], nil
 }
 
 // TransactionReceipt returns the receipt of a transaction.
 func (b *SimulatedBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
-	receipt, _, _, _ := core.GetReceipt(b.env.Database(), txHash)
+	receipt, _, _, _ := core.GetReceipt(b.database, txHash)
 	return receipt, nil
 }
 
@@ -194,14 +194,14 @@ func (b *SimulatedBackend) CallContract(ctx context.Context, call ethereum.CallM
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if blockNumber != nil && blockNumber.Cmp(b.env.Blockchain().CurrentBlock().Number()) != 0 {
+	if blockNumber != nil && blockNumber.Cmp(b.blockchain.CurrentBlock().Number()) != 0 {
 		return nil, errBlockNumberUnsupported
 	}
-	state, err := b.env.Blockchain().State()
+	state, err := b.blockchain.State()
 	if err != nil {
 		return nil, err
 	}
-	rval, _, _, err := b.callContract(ctx, call, b.env.Blockchain().CurrentBlock(), state)
+	rval, _, _, err := b.callContract(ctx, call, b.blockchain.CurrentBlock(), state)
 	return rval, err
 }
 
@@ -285,10 +285,10 @@ func (b *SimulatedBackend) callContract(ctx context.Context, call ethereum.CallM
 	// Execute the call.
 	msg := callmsg{call}
 
-	evmContext := core.NewEVMContext(msg, block.Header(), b.env.Blockchain(), nil)
+	evmContext := core.NewEVMContext(msg, block.Header(), b.blockchain, nil)
 	// Create a new environment which holds all relevant information
 	// about the transaction and calling mechanisms.
-	vmenv := vm.NewEVM(evmContext, statedb, b.env.Config(), vm.Config{})
+	vmenv := vm.NewEVM(evmContext, statedb, b.config, vm.Config{})
 	gaspool := new(core.GasPool).AddGas(math.MaxBig256)
 	ret, gasUsed, _, failed, err := core.NewStateTransition(vmenv, msg, gaspool).TransitionDb()
 	return ret, gasUsed, failed, err
@@ -0,0 +1,519 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package backends
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/wanchain/go-wanchain/accounts/abi/bind"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/common/math"
+	"github.com/wanchain/go-wanchain/consensus"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/epochLeader"
+	"github.com/wanchain/go-wanchain/pos/incentive"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/posconfig/posconfigtest"
+	"github.com/wanchain/go-wanchain/pos/posdb"
+	posUtil "github.com/wanchain/go-wanchain/pos/util"
+	"github.com/wanchain/go-wanchain/rlp"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+// This nil assignment ensures compile time that SimulatedPosBackend implements bind.ContractBackend.
+var _ bind.ContractBackend = (*SimulatedPosBackend)(nil)
+
+var (
+	errPosBackendActive = errors.New("SimulatedPosBackend runs a single backend per process")
+	errUnclesNotAllowed = errors.New("uncles not allowed")
+
+	posBackendActive int32 // Set while a SimulatedPosBackend is open in the process
+
+	// PosGenesisTime is the time the clock of a SimulatedPosBackend starts in,
+	// at the first slot of its epoch.
+	PosGenesisTime = uint64(1561976845)
+)
+
+// SimulatedPosBackend is a SimulatedBackend running the pos stage of a pluto
+// chain. It executes the system precompiles, pays the incentives at the epoch
+// boundaries and takes pos and privacy transactions, so dApps and tools built
+// on the system contracts can be tested locally.
+//
+// The epochs and slots follow a simulated clock which only the backend moves,
+// every committed block takes the next slot of the clock. The epoch leaders,
+// random proposers and random numbers are injected instead of being elected
+// by a network. The pos engine keeps its state per process, so a process runs
+// a single backend at a time, see Close.
+type SimulatedPosBackend struct {
+	*SimulatedBackend
+
+	clock    *posUtil.SimulatedClock
+	engine   *posEngine
+	epocher  *epochLeader.Epocher
+	saved    *posconfigtest.Snapshot // Pos configuration of the process to restore on Close
+	dir      string                  // Directory of the pos local databases
+	coinbase common.Address          // Coinbase of the blocks to commit
+
+	pendingHeader   *types.Header
+	pendingTxs      []*types.Transaction
+	pendingReceipts []*types.Receipt
+	gasPool         *core.GasPool
+}
+
+// NewSimulatedPosBackend creates a pos binding backend whose genesis funds
+// and stakes the accounts of alloc. It replaces the pos clock and
+// configuration of the process, which Close restores.
+func NewSimulatedPosBackend(alloc core.GenesisAlloc) (*SimulatedPosBackend, error) {
+	if !atomic.CompareAndSwapInt32(&posBackendActive, 0, 1) {
+		return nil, errPosBackendActive
+	}
+	dir, err := ioutil.TempDir("", "simulated-pos")
+	if err != nil {
+		atomic.StoreInt32(&posBackendActive, 0)
+		return nil, err
+	}
+	b := &SimulatedPosBackend{
+		dir:      dir,
+		engine:   &posEngine{randoms: make(map[uint64]*big.Int)},
+		saved:    posconfigtest.Save(),
+		coinbase: coinbase,
+	}
+	if err := b.init(alloc); err != nil {
+		b.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *SimulatedPosBackend) init(alloc core.GenesisAlloc) error {
+	// The blockchain sees the chain short of its pos first block, like the one
+	// of SimulatedBackend, so the chain quality, restart and leader election
+	// of a live pos chain stay off. The engine plays the pos stage instead.
+	config := *params.PlutoChainConfig
+	config.Pluto = &params.PlutoConfig{
+		Period:   params.PlutoChainConfig.Pluto.Period,
+		Epoch:    params.PlutoChainConfig.Pluto.Epoch,
+		SlotTime: 1,
+		K:        10,
+	}
+	config.PosFirstBlock = new(big.Int).Set(math.MaxBig63)
+	config.IsPosActive = false
+
+	posdb.DbInitAll(b.dir)
+	if err := posconfig.Init(nil, config.ChainId.Uint64(), config.Pluto); err != nil {
+		return err
+	}
+	posconfig.SetWhiteList([]string{hexutil.Encode(crypto.FromECDSAPub(&key.PublicKey))})
+	posconfig.Pow2PosUpgradeBlockNumber = 1
+	params.SetPosActive(true)
+
+	// The genesis closes the epoch before the first one of the pos stage, the
+	// incentives look the stakers up in the epochs before the paid ones
+	epochTime := posconfig.SlotTime * posconfig.SlotCount
+	firstEpochTime := PosGenesisTime - PosGenesisTime%epochTime
+	genesisTime := firstEpochTime - posconfig.SlotTime
+	b.clock = posUtil.NewSimulatedClock(time.Unix(int64(firstEpochTime), 0))
+	posUtil.SetClock(b.clock)
+	posconfig.FirstEpochId, _ = posUtil.CalEpochSlotID(firstEpochTime)
+
+	db, _ := ethdb.NewMemDatabase()
+	gspec := &core.Genesis{
+		Config:     &config,
+		Timestamp:  genesisTime,
+		ExtraData:  coinbase.Bytes(),
+		GasLimit:   0x47b760, // 4700000
+		Difficulty: posDifficulty(genesisTime),
+		Alloc:      alloc,
+	}
+	if _, err := gspec.Commit(db); err != nil {
+		return err
+	}
+	bc, err := core.NewBlockChain(db, &config, b.engine, vm.Config{})
+	if err != nil {
+		return err
+	}
+	b.SimulatedBackend = &SimulatedBackend{database: db, blockchain: bc, config: &config}
+
+	b.epocher = epochLeader.NewEpocherWithLBN(bc, posconfig.RbLocalDB, posconfig.EpLocalDB)
+	incentive.Init(b.epocher.GetEpochProbability, b.epocher.SetEpochIncentive, b.epocher.GetRBProposerGroup)
+
+	return b.rebuild()
+}
+
+// Close stops the chain, removes the pos databases and restores the pos
+// clock and configuration, so the process may open another backend.
+func (b *SimulatedPosBackend) Close() error {
+	if b.SimulatedBackend != nil {
+		b.blockchain.Stop()
+	}
+	posdb.DbCloseAll()
+	posUtil.SetClock(nil)
+	b.saved.Restore()
+	params.SetPosActive(false)
+	err := os.RemoveAll(b.dir)
+	atomic.StoreInt32(&posBackendActive, 0)
+	return err
+}
+
+// Clock returns the simulated clock of the backend.
+func (b *SimulatedPosBackend) Clock() *posUtil.SimulatedClock {
+	return b.clock
+}
+
+// Commit imports all the pending transactions as a single block in the next
+// slot of the clock, and starts a fresh new state.
+func (b *SimulatedPosBackend) Commit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.commit(); err != nil {
+		panic(err) // This cannot happen unless the simulator is wrong, fail in that case
+	}
+}
+
+func (b *SimulatedPosBackend) commit() error {
+	header := b.pendingHeader
+	if d := time.Unix(header.Time.Int64(), 0).Sub(b.clock.Now()); d > 0 {
+		b.clock.Run(d)
+	}
+	epochID, _ := posUtil.CalEpochSlotID(header.Time.Uint64())
+	if header.Number.Uint64() == posconfig.Pow2PosUpgradeBlockNumber {
+		posconfig.FirstEpochId = epochID
+	}
+	// The epochs passed without a block end at the head
+	parent := b.blockchain.CurrentBlock()
+	parentEpochID, _ := posUtil.GetEpochSlotIDFromDifficulty(parent.Difficulty())
+	for skipped := parentEpochID + 1; skipped < epochID; skipped++ {
+		posUtil.SetEpochBlock(skipped, parent.NumberU64(), parent.Hash())
+	}
+
+	block, err := b.engine.Finalize(b.blockchain, header, b.pendingState, b.pendingTxs, nil, b.pendingReceipts)
+	if err != nil {
+		return err
+	}
+	if _, err := b.blockchain.InsertChain(types.Blocks{block}); err != nil {
+		return err
+	}
+	posUtil.SetEpochBlock(epochID, block.NumberU64(), block.Hash())
+	b.engine.randoms = make(map[uint64]*big.Int)
+
+	b.pendingTxs, b.pendingReceipts = nil, nil
+	return b.rebuild()
+}
+
+// Rollback aborts all pending transactions, reverting to the last committed state.
+func (b *SimulatedPosBackend) Rollback() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pendingTxs, b.pendingReceipts = nil, nil
+	if err := b.rebuild(); err != nil {
+		panic(err) // An empty block always builds on the head
+	}
+}
+
+// rebuild starts a new pending block on the head in the current slot of the
+// clock, and replays the pending transactions into it. The transactions which
+// no longer apply are dropped, the error of the first one is returned.
+func (b *SimulatedPosBackend) rebuild() error {
+	parent := b.blockchain.CurrentBlock()
+	statedb, err := b.blockchain.StateAt(parent.Root())
+	if err != nil {
+		return err
+	}
+	// A block takes the slot of the clock, and at most one block fits a slot
+	blockTime := uint64(b.clock.Now().Unix())
+	blockTime -= blockTime % posconfig.SlotTime
+	if next := parent.Time().Uint64() + posconfig.SlotTime; blockTime < next {
+		blockTime = next
+	}
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Coinbase:   b.coinbase,
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   core.CalcGasLimit(parent),
+		GasUsed:    new(big.Int),
+		Time:       new(big.Int).SetUint64(blockTime),
+	}
+	if err := b.engine.Prepare(b.blockchain, header, false); err != nil {
+		return err
+	}
+	b.pendingHeader, b.pendingState = header, statedb
+	b.gasPool = new(core.GasPool).AddGas(header.GasLimit)
+
+	txs := b.pendingTxs
+	b.pendingTxs, b.pendingReceipts = nil, nil
+	b.pendingBlock = types.NewBlock(header, nil, nil, nil)
+
+	var replayErr error
+	for _, tx := range txs {
+		if err := b.applyTransaction(tx); err != nil && replayErr == nil {
+			replayErr = err
+		}
+	}
+	return replayErr
+}
+
+// applyTransaction executes tx on the pending state and adds it to the
+// pending block. A transaction which fails leaves the pending block as is.
+func (b *SimulatedPosBackend) applyTransaction(tx *types.Transaction) error {
+	if !types.IsValidTransactionType(tx.Txtype()) {
+		return core.ErrInvalidTxType
+	}
+	snap := b.pendingState.Snapshot()
+	gas := new(big.Int).Set(b.pendingHeader.GasUsed)
+	b.pendingState.Prepare(tx.Hash(), common.Hash{}, len(b.pendingTxs))
+
+	receipt, _, err := core.ApplyTransaction(b.config, b.blockchain, nil, b.gasPool, b.pendingState, b.pendingHeader, tx, gas, vm.Config{})
+	if err != nil {
+		b.pendingState.RevertToSnapshot(snap)
+		return err
+	}
+	b.pendingHeader.GasUsed = gas
+	b.pendingTxs = append(b.pendingTxs, tx)
+	b.pendingReceipts = append(b.pendingReceipts, receipt)
+	b.pendingBlock = types.NewBlock(b.pendingHeader, b.pendingTxs, nil, b.pendingReceipts)
+	return nil
+}
+
+// SendTransaction executes a normal, pos or privacy transaction on the
+// pending state, and adds it to the block of the next Commit.
+func (b *SimulatedPosBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.applyTransaction(tx)
+}
+
+// AdjustTime moves the clock forward by adjustment, and rebuilds the pending
+// block in the slot it reaches.
+func (b *SimulatedPosBackend) AdjustTime(adjustment time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.clock.Run(adjustment)
+	return b.rebuild()
+}
+
+// AdvanceSlots moves the clock forward by n slots.
+func (b *SimulatedPosBackend) AdvanceSlots(n uint64) error {
+	return b.AdjustTime(time.Duration(n*posconfig.SlotTime) * time.Second)
+}
+
+// AdvanceEpochs moves the clock to the first slot of the n-th epoch after the
+// current one. The block committed next pays the incentives of the epochs
+// which ended.
+func (b *SimulatedPosBackend) AdvanceEpochs(n uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	epochID, _ := posUtil.CalEpochSlotID(uint64(b.clock.Now().Unix()))
+	target := time.Unix(int64((epochID+n)*posconfig.SlotTime*posconfig.SlotCount), 0)
+	b.clock.Run(target.Sub(b.clock.Now()))
+	return b.rebuild()
+}
+
+// EpochSlotID returns the epoch and the slot of the pending block.
+func (b *SimulatedPosBackend) EpochSlotID() (epochID, slotID uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return posUtil.GetEpochSlotIDFromDifficulty(b.pendingHeader.Difficulty)
+}
+
+// SetCoinbase sets the slot leader the next blocks are credited to. The
+// blocks of the white listed leader, the default one, earn no incentive.
+func (b *SimulatedPosBackend) SetCoinbase(addr common.Address) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.coinbase = addr
+	return b.rebuild()
+}
+
+// SetRandom sets the random number of an epoch, in place of the one the
+// random beacon would agree on. It is written by the next committed block.
+func (b *SimulatedPosBackend) SetRandom(epochID uint64, r *big.Int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.engine.randoms[epochID] = new(big.Int).Set(r)
+}
+
+// SetEpochLeaders makes the stakers at addrs the epoch leaders of an epoch,
+// in place of the leaders an election would draw.
+func (b *SimulatedPosBackend) SetEpochLeaders(epochID uint64, addrs []common.Address) error {
+	return b.setProposers(posconfig.EpLocalDB, epochID, addrs)
+}
+
+// SetRandomProposers makes the stakers at addrs the random proposers of an
+// epoch, in place of the proposers an election would draw.
+func (b *SimulatedPosBackend) SetRandomProposers(epochID uint64, addrs []common.Address) error {
+	return b.setProposers(posconfig.RbLocalDB, epochID, addrs)
+}
+
+func (b *SimulatedPosBackend) setProposers(dbName string, epochID uint64, addrs []common.Address) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	statedb, err := b.blockchain.State()
+	if err != nil {
+		return err
+	}
+	proposers := make([][]byte, len(addrs))
+	for i, addr := range addrs {
+		stakerBytes, err := vm.GetInfo(statedb, vm.StakersInfoAddr, vm.GetStakeInKeyHash(addr))
+		if err != nil || len(stakerBytes) == 0 {
+			return fmt.Errorf("%x is not a staker", addr)
+		}
+		var staker vm.StakerInfo
+		if err := rlp.DecodeBytes(stakerBytes, &staker); err != nil {
+			return err
+		}
+		proposers[i], err = rlp.EncodeToBytes(&posdb.Proposer{
+			PubSec256:     staker.PubSec256,
+			PubBn256:      staker.PubBn256,
+			Probabilities: staker.StakeAmount,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	db := posdb.GetDbByName(dbName)
+	for i, proposer := range proposers {
+		if _, err := db.PutWithIndex(epochID, uint64(i), "", proposer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ElectLeaders elects the epoch leaders and random proposers of an epoch from
+// the stakers, the way a pos node does two epochs ahead.
+func (b *SimulatedPosBackend) ElectLeaders(epochID uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.epocher.SelectLeadersLoop(epochID)
+}
+
+// posDifficulty encodes the epoch and the slot of a block time into its
+// difficulty, as the pluto engine does.
+func posDifficulty(blockTime uint64) *big.Int {
+	epochID, slotID := posUtil.CalEpochSlotID(blockTime)
+	return new(big.Int).SetUint64(1 + slotID<<8 + epochID<<32)
+}
+
+// posEngine is the consensus engine of a SimulatedPosBackend. It accepts every
+// block it is given, and plays the epoch boundaries of the pluto engine.
+type posEngine struct {
+	randoms map[uint64]*big.Int // Random numbers for the next block to write
+}
+
+// Author returns the coinbase of the header, the slot leader of its slot.
+func (e *posEngine) Author(header *types.Header) (common.Address, error) {
+	return header.Coinbase, nil
+}
+
+func (e *posEngine) VerifyHeader(chain consensus.ChainReader, header *types.Header, seal bool) error {
+	return nil
+}
+
+func (e *posEngine) VerifyHeaders(chain consensus.ChainReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	abort, results := make(chan struct{}), make(chan error, len(headers))
+	for range headers {
+		results <- nil
+	}
+	return abort, results
+}
+
+func (e *posEngine) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	if len(block.Uncles()) > 0 {
+		return errUnclesNotAllowed
+	}
+	return nil
+}
+
+func (e *posEngine) VerifyGenesisBlocks(chain consensus.ChainReader, block *types.Block) error {
+	return nil
+}
+
+func (e *posEngine) VerifySeal(chain consensus.ChainReader, header *types.Header) error {
+	return nil
+}
+
+// Prepare sets the difficulty of the header to its epoch and slot.
+func (e *posEngine) Prepare(chain consensus.ChainReader, header *types.Header, mining bool) error {
+	header.Difficulty = e.CalcDifficulty(chain, header.Time.Uint64(), nil)
+	return nil
+}
+
+// Finalize writes the injected random numbers, and at the first block of an
+// epoch pays the incentives of the epochs which ended and runs the stake out.
+func (e *posEngine) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	for epochID, r := range e.randoms {
+		state.SetStateByteArray(vm.GetRBAddress(), *vm.GetRBRKeyHash(epochID), r.Bytes())
+	}
+
+	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	parentEpochID, _ := posUtil.GetEpochSlotIDFromDifficulty(parent.Difficulty)
+	epochID, _ := posUtil.GetEpochSlotIDFromDifficulty(header.Difficulty)
+
+	// Like pluto, pay from the second epoch after the first one of the chain
+	ended := parentEpochID
+	if first := posconfig.FirstEpochId + 2; ended < first {
+		ended = first
+	}
+	for ; ended < epochID; ended++ {
+		snap := state.Snapshot()
+		if !incentive.Run(chain, state, ended) {
+			log.Warn("Simulated incentive failed", "number", header.Number, "epochID", ended)
+			state.RevertToSnapshot(snap)
+		}
+	}
+	if epochID > parentEpochID && epochID > posconfig.FirstEpochId+2 {
+		snap := state.Snapshot()
+		if !epochLeader.StakeOutRun(state, epochID) {
+			log.Warn("Simulated stake out failed", "number", header.Number, "epochID", epochID)
+			state.RevertToSnapshot(snap)
+		}
+	}
+
+	state.Finalise(true)
+	header.Root = state.IntermediateRoot(true)
+	header.UncleHash = types.CalcUncleHash(nil)
+
+	return types.NewBlock(header, txs, nil, receipts), nil
+}
+
+// Seal returns the block as is, the simulated chain has no sealers.
+func (e *posEngine) Seal(chain consensus.ChainReader, block *types.Block, stop <-chan struct{}) (*types.Block, error) {
+	return block, nil
+}
+
+// CalcDifficulty encodes the epoch and the slot of time.
+func (e *posEngine) CalcDifficulty(chain consensus.ChainReader, time uint64, parent *types.Header) *big.Int {
+	return posDifficulty(time)
+}
+
+func (e *posEngine) APIs(chain consensus.ChainReader) []rpc.API {
+	return nil
+}
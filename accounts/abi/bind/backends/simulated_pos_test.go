@@ -0,0 +1,282 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package backends
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/accounts/abi"
+	"github.com/wanchain/go-wanchain/accounts/abi/bind"
+	"github.com/wanchain/go-wanchain/accounts/keystore"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/contracts/pos"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
+	bn256 "github.com/wanchain/go-wanchain/crypto/bn256/cloudflare"
+	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/incentive"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/posdb"
+)
+
+var posTestBalance = new(big.Int).Mul(big.NewInt(1000000), big.NewInt(params.Wan))
+
+func wan(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.Wan))
+}
+
+func checkReceipt(t *testing.T, b *SimulatedPosBackend, tx *types.Transaction) {
+	receipt, err := b.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil || receipt == nil {
+		t.Fatalf("no receipt of %x: %v", tx.Hash(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("transaction %x failed", tx.Hash())
+	}
+}
+
+func TestSimulatedPosBackendStaking(t *testing.T) {
+	validatorKey, _ := crypto.GenerateKey()
+	delegatorKey, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(validatorKey.PublicKey)
+	delegator := crypto.PubkeyToAddress(delegatorKey.PublicKey)
+
+	b, err := NewSimulatedPosBackend(core.GenesisAlloc{
+		validator: {Balance: posTestBalance},
+		delegator: {Balance: posTestBalance},
+	})
+	if err != nil {
+		t.Fatalf("failed to create backend: %v", err)
+	}
+	defer b.Close()
+	if _, err := NewSimulatedPosBackend(nil); err != errPosBackendActive {
+		t.Fatalf("second backend error mismatch: have %v, want %v", err, errPosBackendActive)
+	}
+	staking, err := pos.BindStaking(b)
+	if err != nil {
+		t.Fatalf("failed to bind staking: %v", err)
+	}
+
+	// Stake in a validator taking delegations
+	opts := pos.TransactOpts(bind.NewKeyedTransactor(validatorKey))
+	opts.Value = wan(60000)
+	bn256Pk := new(bn256.G1).ScalarBaseMult(big.NewInt(7)).Marshal()
+	tx, err := staking.StakeIn(opts, crypto.FromECDSAPub(&validatorKey.PublicKey), bn256Pk, big.NewInt(10), big.NewInt(1000))
+	if err != nil {
+		t.Fatalf("failed to stake in: %v", err)
+	}
+	b.Commit()
+	checkReceipt(t, b, tx)
+
+	// Delegate to it
+	opts = pos.TransactOpts(bind.NewKeyedTransactor(delegatorKey))
+	opts.Value = wan(1000)
+	if tx, err = staking.DelegateIn(opts, validator); err != nil {
+		t.Fatalf("failed to delegate: %v", err)
+	}
+	b.Commit()
+	checkReceipt(t, b, tx)
+
+	statedb, _ := b.blockchain.State()
+	stakers := vm.GetStakersSnap(statedb)
+	if len(stakers) != 1 || stakers[0].Address != validator {
+		t.Fatalf("stakers mismatch: have %+v", stakers)
+	}
+	if clients := stakers[0].Clients; len(clients) != 1 || clients[0].Address != delegator || clients[0].Amount.Cmp(opts.Value) != 0 {
+		t.Errorf("clients mismatch: have %+v", clients)
+	}
+
+	// Pending transactions are dropped by a rollback
+	if _, err := staking.DelegateIn(opts, validator); err != nil {
+		t.Fatalf("failed to delegate: %v", err)
+	}
+	b.Rollback()
+	if nonce, _ := b.PendingNonceAt(context.Background(), delegator); nonce != 1 {
+		t.Errorf("pending nonce mismatch after rollback: have %d, want 1", nonce)
+	}
+	untyped := types.NewTransaction(1, delegator, new(big.Int), big.NewInt(21000), big.NewInt(1), nil)
+	untyped.SetTxtype(0)
+	if err := b.SendTransaction(context.Background(), untyped); err != core.ErrInvalidTxType {
+		t.Errorf("untyped transaction error mismatch: have %v, want %v", err, core.ErrInvalidTxType)
+	}
+}
+
+func TestSimulatedPosBackendEpochs(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(key.PublicKey)
+	bn256Pk := new(bn256.G1).ScalarBaseMult(new(big.Int).SetBytes(crypto.FromECDSA(key2)))
+
+	b, err := NewSimulatedPosBackend(core.GenesisAlloc{
+		validator: {
+			Balance: posTestBalance,
+			Staking: core.GenesisAccountStaking{
+				Amount:  wan(100000),
+				S256pk:  crypto.FromECDSAPub(&key.PublicKey),
+				Bn256pk: bn256Pk.Marshal(),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create backend: %v", err)
+	}
+	defer b.Close()
+	first, slot := b.EpochSlotID()
+	if slot != 0 {
+		t.Fatalf("first block slot mismatch: have %d, want 0", slot)
+	}
+	b.Commit()
+
+	// An injected random number is read back by the random beacon
+	r := big.NewInt(0x5eed)
+	b.SetRandom(first+1, r)
+	if err := b.AdvanceEpochs(1); err != nil {
+		t.Fatalf("failed to advance: %v", err)
+	}
+	if epochID, slotID := b.EpochSlotID(); epochID != first+1 || slotID != 0 {
+		t.Fatalf("advanced to epoch %d slot %d, want epoch %d slot 0", epochID, slotID, first+1)
+	}
+	b.Commit()
+	beacon, _ := pos.BindRandomBeacon(b)
+	if have, err := beacon.GetRandomNumberByEpochId(nil, new(big.Int).SetUint64(first+1)); err != nil || have.Cmp(r) != 0 {
+		t.Errorf("random number mismatch: have %v, %v, want %v", have, err, r)
+	}
+
+	// Injected epoch leaders are the stakers
+	if err := b.SetEpochLeaders(first+3, []common.Address{validator}); err != nil {
+		t.Fatalf("failed to set epoch leaders: %v", err)
+	}
+	if leaders := posdb.GetEpochLeaderGroup(first + 3); len(leaders) != 1 || !bytes.Equal(leaders[0], crypto.FromECDSAPub(&key.PublicKey)) {
+		t.Errorf("epoch leaders mismatch: have %x", leaders)
+	}
+	if err := b.SetRandomProposers(first+3, []common.Address{common.HexToAddress("0x01")}); err == nil {
+		t.Errorf("set a non staker as random proposer")
+	}
+
+	// The slot leader of an epoch is paid at the first block after it
+	if err := b.SetCoinbase(validator); err != nil {
+		t.Fatalf("failed to set coinbase: %v", err)
+	}
+	if err := b.AdvanceEpochs(1); err != nil {
+		t.Fatalf("failed to advance: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		b.Commit()
+	}
+	before, _ := b.BalanceAt(context.Background(), validator, nil)
+	if err := b.AdvanceEpochs(1); err != nil {
+		t.Fatalf("failed to advance: %v", err)
+	}
+	b.Commit()
+	after, _ := b.BalanceAt(context.Background(), validator, nil)
+	if after.Cmp(before) <= 0 {
+		t.Errorf("slot leader not paid: balance %v before, %v after", before, after)
+	}
+	if payments, err := incentive.GetEpochPayDetail(first + 2); err != nil || len(payments) == 0 {
+		t.Errorf("no payments recorded for epoch %d: %v", first+2, err)
+	}
+
+	// The stakers are elected two epochs ahead
+	if err := b.ElectLeaders(first + 5); err != nil {
+		t.Fatalf("failed to elect leaders: %v", err)
+	}
+	if leaders := posdb.GetEpochLeaderGroup(first + 5); len(leaders) == 0 {
+		t.Errorf("no epoch leaders elected")
+	}
+}
+
+func TestSimulatedPosBackendPrivacyTx(t *testing.T) {
+	buyerKey, _ := crypto.GenerateKey()
+	senderKey, _ := crypto.GenerateKey()
+	buyer := crypto.PubkeyToAddress(buyerKey.PublicKey)
+	sender := crypto.PubkeyToAddress(senderKey.PublicKey)
+
+	b, err := NewSimulatedPosBackend(core.GenesisAlloc{buyer: {Balance: posTestBalance}})
+	if err != nil {
+		t.Fatalf("failed to create backend: %v", err)
+	}
+	defer b.Close()
+	signer := types.NewEIP155Signer(b.config.ChainId)
+
+	// Buy a stamp paying the privacy transaction to the OTA of a wan address
+	otaKey, _ := crypto.GenerateKey()
+	viewKey, _ := crypto.GenerateKey()
+	wanAddr := keystore.GenerateWaddressFromPK(&otaKey.PublicKey, &viewKey.PublicKey)
+	stampAbi, _ := abi.JSON(strings.NewReader(`[{"type":"function","name":"buyStamp","inputs":[{"name":"OtaAddr","type":"string"},{"name":"Value","type":"uint256"}]}]`))
+	stampValue, _ := new(big.Int).SetString(vm.WanStampdot2, 10)
+	input, err := stampAbi.Pack("buyStamp", hexutil.Encode(wanAddr[:]), stampValue)
+	if err != nil {
+		t.Fatalf("failed to pack stamp purchase: %v", err)
+	}
+	tx, _ := types.SignTx(types.NewTransaction(0, common.BytesToAddress([]byte{200}), stampValue, big.NewInt(100000), big.NewInt(params.Shannon), input), signer, buyerKey)
+	if err := b.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("failed to buy stamp: %v", err)
+	}
+	b.Commit()
+	checkReceipt(t, b, tx)
+
+	// Spend it with a privacy transaction ring signed over its sender
+	sig, err := crypto.DefaultRingSigner.Sign(sender.Bytes(), otaKey.D, []*ecdsa.PublicKey{&otaKey.PublicKey})
+	if err != nil {
+		t.Fatalf("failed to ring sign: %v", err)
+	}
+	pks := make([]string, 0, len(sig.PublicKeys))
+	for _, pk := range sig.PublicKeys {
+		pks = append(pks, common.ToHex(crypto.FromECDSAPub(pk)))
+	}
+	ws := make([]string, 0, len(sig.C))
+	for _, w := range sig.C {
+		ws = append(ws, hexutil.EncodeBig(w))
+	}
+	qs := make([]string, 0, len(sig.R))
+	for _, q := range sig.R {
+		qs = append(qs, hexutil.EncodeBig(q))
+	}
+	ringSigned := strings.Join([]string{
+		strings.Join(pks, "&"),
+		common.ToHex(crypto.FromECDSAPub(sig.KeyImage)),
+		strings.Join(ws, "&"),
+		strings.Join(qs, "&"),
+	}, "+")
+	data, err := core.TokenAbi.Pack("combine", ringSigned, []byte{})
+	if err != nil {
+		t.Fatalf("failed to pack privacy call: %v", err)
+	}
+	// At this price the stamp buys the 200000 gas the transaction uses
+	gasPrice := big.NewInt(1000000000000)
+	tx, _ = types.SignTx(types.NewOTATransaction(0, buyer, new(big.Int), big.NewInt(200000), gasPrice, data), signer, senderKey)
+	if err := b.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("failed to send privacy transaction: %v", err)
+	}
+	b.Commit()
+	checkReceipt(t, b, tx)
+
+	// The stamp is spent
+	tx, _ = types.SignTx(types.NewOTATransaction(1, buyer, new(big.Int), big.NewInt(200000), gasPrice, data), signer, senderKey)
+	if err := b.SendTransaction(context.Background(), tx); err == nil {
+		t.Errorf("spent stamp accepted again")
+	}
+}
+
+func TestSimulatedPosBackendCloseRestores(t *testing.T) {
+	k, firstEpochID, whiteList := posconfig.K, posconfig.FirstEpochId, posconfig.WhiteList
+	b, err := NewSimulatedPosBackend(nil)
+	if err != nil {
+		t.Fatalf("failed to create backend: %v", err)
+	}
+	if posconfig.K == k {
+		t.Fatalf("backend kept K %d", k)
+	}
+	b.Close()
+	if posconfig.K != k || posconfig.SlotCount != 12*k || posconfig.FirstEpochId != firstEpochID || posconfig.WhiteList != whiteList {
+		t.Errorf("pos configuration not restored: K %d, FirstEpochId %d", posconfig.K, posconfig.FirstEpochId)
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wanchain/go-wanchain/awskms"
+	"github.com/wanchain/go-wanchain/crypto/randentropy"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Key encryption backends, as named in KeyEncryptorConfig.Backend.
+const (
+	KeyEncryptorAWS    = "aws"
+	KeyEncryptorVault  = "vault"
+	KeyEncryptorScrypt = "scrypt"
+)
+
+const (
+	envelopeVersion = 1
+	envelopeCipher  = "aes-256-gcm"
+
+	defaultVaultMount   = "transit"
+	vaultRequestTimeout = 30 * time.Second
+)
+
+var (
+	ErrUnknownKeyEncryptor = errors.New("unknown key encryption backend")
+	ErrInvalidVaultInfo    = errors.New("invalid vault transit info")
+	ErrInvalidEnvelope     = errors.New("invalid scrypt envelope")
+)
+
+// KeyEncryptor encrypts keystore files as a whole, on top of their own
+// passphrase encryption, so a stolen keystore file is useless without access
+// to the backend.
+type KeyEncryptor interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// VaultTransitInfo is the HashiCorp Vault transit engine used to encrypt the
+// keystore files.
+type VaultTransitInfo struct {
+	Addr  string // Address of the vault server, such as https://127.0.0.1:8200
+	Mount string `toml:",omitempty"` // Mount path of the transit engine, "transit" if empty
+	Key   string // Name of the transit key
+	Token string `toml:"-"`
+}
+
+// ScryptEnvelopeInfo is the local passphrase used to encrypt the keystore
+// files when no key management service is available.
+type ScryptEnvelopeInfo struct {
+	Passphrase string `toml:"-"`
+	ScryptN    int    `toml:",omitempty"` // StandardScryptN if zero
+	ScryptP    int    `toml:",omitempty"` // StandardScryptP if zero
+}
+
+// KeyEncryptorConfig selects and configures the key encryption backend. The
+// secrets are never read from or written to the config file, they have to be
+// filled in before calling NewKeyEncryptor.
+type KeyEncryptorConfig struct {
+	Backend string `toml:",omitempty"` // One of aws, vault and scrypt, aws if empty
+	AWS     AwsKmsInfo
+	Vault   VaultTransitInfo
+	Scrypt  ScryptEnvelopeInfo
+}
+
+// BackendName returns the configured backend, defaulting to AWS KMS.
+func (c *KeyEncryptorConfig) BackendName() string {
+	if c.Backend == "" {
+		return KeyEncryptorAWS
+	}
+	return c.Backend
+}
+
+// NewKeyEncryptor creates the key encryptor of the configured backend.
+func NewKeyEncryptor(c *KeyEncryptorConfig) (KeyEncryptor, error) {
+	switch c.BackendName() {
+	case KeyEncryptorAWS:
+		if c.AWS.AKID == "" || c.AWS.SecretKey == "" || c.AWS.Region == "" {
+			return nil, ErrInvalidKmsInfo
+		}
+		return &awsKeyEncryptor{info: c.AWS}, nil
+
+	case KeyEncryptorVault:
+		if c.Vault.Addr == "" || c.Vault.Key == "" || c.Vault.Token == "" {
+			return nil, ErrInvalidVaultInfo
+		}
+		mount := c.Vault.Mount
+		if mount == "" {
+			mount = defaultVaultMount
+		}
+		return &vaultKeyEncryptor{
+			url:    strings.TrimRight(c.Vault.Addr, "/") + "/v1/" + strings.Trim(mount, "/"),
+			key:    c.Vault.Key,
+			token:  c.Vault.Token,
+			client: &http.Client{Timeout: vaultRequestTimeout},
+		}, nil
+
+	case KeyEncryptorScrypt:
+		if c.Scrypt.Passphrase == "" {
+			return nil, errors.New("empty scrypt envelope passphrase")
+		}
+		n, p := c.Scrypt.ScryptN, c.Scrypt.ScryptP
+		if n == 0 {
+			n = StandardScryptN
+		}
+		if p == 0 {
+			p = StandardScryptP
+		}
+		return &scryptKeyEncryptor{passphrase: []byte(c.Scrypt.Passphrase), scryptN: n, scryptP: p}, nil
+	}
+	return nil, ErrUnknownKeyEncryptor
+}
+
+// EncryptKeyFile encrypts the keystore file src into dst.
+func EncryptKeyFile(enc KeyEncryptor, src, dst string) error {
+	plaintext, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	ciphertext, err := enc.Encrypt(plaintext)
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(dst, ciphertext)
+}
+
+// DecryptKeyFile returns the keystore json of the encrypted file src.
+func DecryptKeyFile(enc KeyEncryptor, src string) ([]byte, error) {
+	ciphertext, err := ioutil.ReadFile(src)
+	if err != nil {
+		return nil, err
+	}
+	return enc.Decrypt(ciphertext)
+}
+
+// awsKeyEncryptor encrypts with an AWS KMS key.
+type awsKeyEncryptor struct {
+	info AwsKmsInfo
+}
+
+func (e *awsKeyEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	if e.info.KeyId == "" {
+		return nil, ErrInvalidKmsInfo
+	}
+	return awskms.Encrypt(string(plaintext), e.info.AKID, e.info.SecretKey, e.info.Region, e.info.KeyId)
+}
+
+func (e *awsKeyEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	return awskms.Decrypt(ciphertext, e.info.AKID, e.info.SecretKey, e.info.Region)
+}
+
+// vaultKeyEncryptor encrypts with a key of the Vault transit engine. The
+// ciphertext is the one returned by vault, such as "vault:v1:...".
+type vaultKeyEncryptor struct {
+	url    string
+	key    string
+	token  string
+	client *http.Client
+}
+
+func (e *vaultKeyEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	var result struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	req := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(plaintext)}
+	if err := e.call("encrypt", req, &result); err != nil {
+		return nil, err
+	}
+	if result.Ciphertext == "" {
+		return nil, errors.New("vault transit returned no ciphertext")
+	}
+	return []byte(result.Ciphertext), nil
+}
+
+func (e *vaultKeyEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	var result struct {
+		Plaintext string `json:"plaintext"`
+	}
+	req := map[string]string{"ciphertext": string(bytes.TrimSpace(ciphertext))}
+	if err := e.call("decrypt", req, &result); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(result.Plaintext)
+}
+
+// call posts a request to the transit endpoint of op and decodes the data of
+// the response into result.
+func (e *vaultKeyEncryptor) call(op string, req interface{}, result interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequest("POST", e.url+"/"+op+"/"+e.key, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("X-Vault-Token", e.token)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var reply struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []string        `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return fmt.Errorf("vault transit %s: %s: %v", op, resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault transit %s: %s: %s", op, resp.Status, strings.Join(reply.Errors, ", "))
+	}
+	return json.Unmarshal(reply.Data, result)
+}
+
+// scryptEnvelope is the json format of a keystore file encrypted with a key
+// derived from a local passphrase.
+type scryptEnvelope struct {
+	Version    int                    `json:"version"`
+	KDF        string                 `json:"kdf"`
+	KDFParams  map[string]interface{} `json:"kdfparams"`
+	Cipher     string                 `json:"cipher"`
+	Nonce      string                 `json:"nonce"`
+	Ciphertext string                 `json:"ciphertext"`
+}
+
+// scryptKeyEncryptor encrypts with AES-GCM under a key derived by scrypt from
+// a local passphrase.
+type scryptKeyEncryptor struct {
+	passphrase []byte
+	scryptN    int
+	scryptP    int
+}
+
+func (e *scryptKeyEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	salt := randentropy.GetEntropyCSPRNG(32)
+	derivedKey, err := scrypt.Key(e.passphrase, salt, e.scryptN, scryptR, e.scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newEnvelopeAEAD(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := randentropy.GetEntropyCSPRNG(aead.NonceSize())
+
+	return json.Marshal(&scryptEnvelope{
+		Version: envelopeVersion,
+		KDF:     keyHeaderKDF,
+		KDFParams: map[string]interface{}{
+			"n":     e.scryptN,
+			"r":     scryptR,
+			"p":     e.scryptP,
+			"dklen": scryptDKLen,
+			"salt":  hex.EncodeToString(salt),
+		},
+		Cipher:     envelopeCipher,
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(aead.Seal(nil, nonce, plaintext, nil)),
+	})
+}
+
+func (e *scryptKeyEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	var env scryptEnvelope
+	if err := json.Unmarshal(ciphertext, &env); err != nil {
+		return nil, err
+	}
+	if env.Version != envelopeVersion || env.KDF != keyHeaderKDF || env.Cipher != envelopeCipher {
+		return nil, ErrInvalidEnvelope
+	}
+	for _, param := range []string{"n", "r", "p", "dklen"} {
+		if _, ok := env.KDFParams[param].(float64); !ok {
+			return nil, ErrInvalidEnvelope
+		}
+	}
+	salt, err := hex.DecodeString(ensureString(env.KDFParams["salt"]))
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(env.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := hex.DecodeString(env.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	n, r, p, dkLen := ensureInt(env.KDFParams["n"]), ensureInt(env.KDFParams["r"]), ensureInt(env.KDFParams["p"]), ensureInt(env.KDFParams["dklen"])
+	if dkLen != scryptDKLen {
+		return nil, ErrInvalidEnvelope
+	}
+	derivedKey, err := scrypt.Key(e.passphrase, salt, n, r, p, dkLen)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newEnvelopeAEAD(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrInvalidEnvelope
+	}
+	plaintext, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func newEnvelopeAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func ensureString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
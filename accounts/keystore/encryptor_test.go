@@ -0,0 +1,142 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package keystore
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newVaultStub starts a stub of the vault transit engine, mounted at "transit"
+// with a single key "miner", which "encrypts" by prefixing the plaintext.
+func newVaultStub(token string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reply := func(code int, v interface{}) {
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(v)
+		}
+		if r.Header.Get("X-Vault-Token") != token {
+			reply(http.StatusForbidden, map[string][]string{"errors": {"permission denied"}})
+			return
+		}
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			reply(http.StatusBadRequest, map[string][]string{"errors": {err.Error()}})
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/miner":
+			reply(http.StatusOK, map[string]interface{}{"data": map[string]string{"ciphertext": "vault:v1:" + req["plaintext"]}})
+		case "/v1/transit/decrypt/miner":
+			if !strings.HasPrefix(req["ciphertext"], "vault:v1:") {
+				reply(http.StatusBadRequest, map[string][]string{"errors": {"invalid ciphertext"}})
+				return
+			}
+			reply(http.StatusOK, map[string]interface{}{"data": map[string]string{"plaintext": strings.TrimPrefix(req["ciphertext"], "vault:v1:")}})
+		default:
+			reply(http.StatusNotFound, map[string][]string{"errors": {"no handler for route"}})
+		}
+	}))
+}
+
+func TestVaultKeyEncryptor(t *testing.T) {
+	server := newVaultStub("s.token")
+	defer server.Close()
+
+	enc, err := NewKeyEncryptor(&KeyEncryptorConfig{
+		Backend: KeyEncryptorVault,
+		Vault:   VaultTransitInfo{Addr: server.URL + "/", Key: "miner", Token: "s.token"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create vault encryptor: %v", err)
+	}
+	plaintext := []byte(`{"address":"0x01"}`)
+	ciphertext, err := enc.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("failed to encrypt: %v", err)
+	}
+	if want := "vault:v1:" + base64.StdEncoding.EncodeToString(plaintext); string(ciphertext) != want {
+		t.Fatalf("ciphertext mismatch: have %s, want %s", ciphertext, want)
+	}
+	decrypted, err := enc.Decrypt(append(ciphertext, '\n'))
+	if err != nil {
+		t.Fatalf("failed to decrypt: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("plaintext mismatch: have %s, want %s", decrypted, plaintext)
+	}
+
+	// Errors of vault are reported
+	denied, _ := NewKeyEncryptor(&KeyEncryptorConfig{
+		Backend: KeyEncryptorVault,
+		Vault:   VaultTransitInfo{Addr: server.URL, Key: "miner", Token: "wrong"},
+	})
+	if _, err := denied.Decrypt(ciphertext); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("denied decryption error mismatch: %v", err)
+	}
+	if _, err := NewKeyEncryptor(&KeyEncryptorConfig{Backend: KeyEncryptorVault, Vault: VaultTransitInfo{Addr: server.URL}}); err != ErrInvalidVaultInfo {
+		t.Fatalf("incomplete vault info error mismatch: have %v, want %v", err, ErrInvalidVaultInfo)
+	}
+}
+
+func TestScryptKeyEncryptor(t *testing.T) {
+	config := &KeyEncryptorConfig{
+		Backend: KeyEncryptorScrypt,
+		Scrypt:  ScryptEnvelopeInfo{Passphrase: "envelope", ScryptN: LightScryptN, ScryptP: LightScryptP},
+	}
+	enc, err := NewKeyEncryptor(config)
+	if err != nil {
+		t.Fatalf("failed to create scrypt encryptor: %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "keystore-encryptor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	plaintext := []byte(`{"address":"0x01"}`)
+	src, dst := filepath.Join(dir, "key"), filepath.Join(dir, "key"+AwsKMSCiphertextFileExt)
+	if err := ioutil.WriteFile(src, plaintext, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := EncryptKeyFile(enc, src, dst); err != nil {
+		t.Fatalf("failed to encrypt key file: %v", err)
+	}
+	if ciphertext, _ := ioutil.ReadFile(dst); bytes.Contains(ciphertext, plaintext) {
+		t.Fatal("plaintext found in the encrypted key file")
+	}
+	decrypted, err := DecryptKeyFile(enc, dst)
+	if err != nil {
+		t.Fatalf("failed to decrypt key file: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("plaintext mismatch: have %s, want %s", decrypted, plaintext)
+	}
+
+	config.Scrypt.Passphrase = "wrong"
+	wrong, _ := NewKeyEncryptor(config)
+	if _, err := DecryptKeyFile(wrong, dst); err != ErrDecrypt {
+		t.Fatalf("wrong passphrase error mismatch: have %v, want %v", err, ErrDecrypt)
+	}
+	if _, err := enc.Decrypt([]byte(`{"version":1,"kdf":"scrypt","cipher":"aes-256-gcm"}`)); err != ErrInvalidEnvelope {
+		t.Fatalf("incomplete envelope error mismatch: have %v, want %v", err, ErrInvalidEnvelope)
+	}
+}
+
+func TestNewKeyEncryptor(t *testing.T) {
+	if _, err := NewKeyEncryptor(&KeyEncryptorConfig{}); err != ErrInvalidKmsInfo {
+		t.Fatalf("default backend error mismatch: have %v, want %v", err, ErrInvalidKmsInfo)
+	}
+	if _, err := NewKeyEncryptor(&KeyEncryptorConfig{Backend: "gcp"}); err != ErrUnknownKeyEncryptor {
+		t.Fatalf("unknown backend error mismatch: have %v, want %v", err, ErrUnknownKeyEncryptor)
+	}
+}
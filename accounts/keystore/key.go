@@ -62,10 +62,12 @@ type keyPair struct {
 	D1 string `json:"privateKey1"`
 }
 
+// AwsKmsInfo is the AWS KMS key used to encrypt the keystore files.
 type AwsKmsInfo struct {
-	AKID      string
-	SecretKey string
+	AKID      string `toml:"-"`
+	SecretKey string `toml:"-"`
 	Region    string
+	KeyId     string `toml:",omitempty"` // Only needed to encrypt
 }
 
 type keyStore interface {
@@ -307,6 +307,38 @@ func (ks *KeyStore) ComputeOTAPPKeys(a accounts.Account, AX, AY, BX, BY string)
 	return []string{pub1X, pub1Y, priv1D, priv2D}, err
 }
 
+// CheckOTAOwner reports whether the OTA wanaddr was generated for the unlocked account.
+// For an owned OTA it also returns the key image its spending transaction records on chain.
+func (ks *KeyStore) CheckOTAOwner(a accounts.Account, otaWAddr []byte) (bool, []byte, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	unlockedKey, found := ks.unlocked[a.Address]
+	if !found {
+		return false, nil, ErrLocked
+	}
+	if unlockedKey.PrivateKey == nil || unlockedKey.PrivateKey2 == nil {
+		return false, nil, ErrWAddressInvalid
+	}
+
+	A1, S1, err := GeneratePKPairFromWAddress(otaWAddr)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if !crypto.CompareA1(unlockedKey.PrivateKey2.D.Bytes(), &unlockedKey.PrivateKey.PublicKey, S1, A1) {
+		return false, nil, nil
+	}
+
+	priv1, _, err := crypto.GenerateOneTimePrivateKey2528(unlockedKey.PrivateKey, unlockedKey.PrivateKey2, A1, S1)
+	if err != nil {
+		return true, nil, err
+	}
+
+	image := crypto.ComputeKeyImage(priv1.D, A1)
+	return true, crypto.FromECDSAPub(image), nil
+}
+
 // SignHashWithPassphrase signs hash if the private key matching the given address
 // can be decrypted with the given passphrase. The produced signature is in the
 // [R || S || V] format where V is 0 or 1.
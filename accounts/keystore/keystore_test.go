@@ -18,7 +18,10 @@
 package keystore
 
 import (
+	"bytes"
+	"crypto/ecdsa"
 	"io/ioutil"
+	"math/big"
 	"math/rand"
 	"os"
 	"runtime"
@@ -534,3 +537,69 @@ func TestComputeOTAPPKeys(t *testing.T) {
 		t.Errorf("invalid ota pk. pk lenght:%d", len(pk))
 	}
 }
+
+func TestCheckOTAOwner(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	auth := "wanchain_test"
+	a, err := ks.NewAccount(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ks.NewAccount(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wAddr, err := ks.GetWanAddress(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ota, err := genOTA(hexutil.Encode(wAddr[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otaRaw := common.FromHex(ota)
+
+	if _, _, err := ks.CheckOTAOwner(a, otaRaw); err != ErrLocked {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+
+	if err := ks.Unlock(a, auth); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Unlock(other, auth); err != nil {
+		t.Fatal(err)
+	}
+
+	mine, image, err := ks.CheckOTAOwner(a, otaRaw)
+	if err != nil || !mine {
+		t.Fatalf("ota should belong to the account, mine:%v err:%v", mine, err)
+	}
+
+	mine, _, err = ks.CheckOTAOwner(other, otaRaw)
+	if err != nil || mine {
+		t.Fatalf("ota should not belong to other account, mine:%v err:%v", mine, err)
+	}
+
+	// the key image must match the one produced when the ota is spent
+	A1, _, err := GeneratePKPairFromWAddress(otaRaw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otaRawUncompressed, _ := WaddrToUncompressedRawBytes(otaRaw)
+	otaHex := hexutil.Encode(otaRawUncompressed)[2:]
+	keys, err := ks.ComputeOTAPPKeys(a, "0x"+otaHex[0:64], "0x"+otaHex[64:128], "0x"+otaHex[128:192], "0x"+otaHex[192:256])
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := new(big.Int).SetBytes(common.FromHex(keys[2]))
+	_, I, _, _, err := crypto.RingSign([]byte("hello"), x, []*ecdsa.PublicKey{A1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(crypto.FromECDSAPub(I), image) {
+		t.Fatal("key image mismatch")
+	}
+}
@@ -19,6 +19,7 @@ package main
 import (
 	"encoding/json"
 	"io"
+	"math/big"
 	"time"
 
 	"github.com/wanchain/go-wanchain/common"
@@ -56,6 +57,16 @@ func (l *JSONLogger) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cos
 	return l.encoder.Encode(log)
 }
 
+// CaptureEnter is triggered when the EVM enters a call frame.
+func (l *JSONLogger) CaptureEnter(env *vm.EVM, typ vm.OpCode, from, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureExit is triggered when the EVM leaves a call frame.
+func (l *JSONLogger) CaptureExit(env *vm.EVM, output []byte, gasUsed uint64, err error) error {
+	return nil
+}
+
 // CaptureEnd is triggered at end of execution.
 func (l *JSONLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
 	type endLog struct {
@@ -114,6 +114,10 @@ var (
 		Name:  "nostack",
 		Usage: "disable stack output",
 	}
+	IstanbulFlag = cli.BoolFlag{
+		Name:  "istanbul",
+		Usage: "enable the constantinople and istanbul EVM changes",
+	}
 )
 
 func init() {
@@ -138,6 +142,7 @@ func init() {
 		ReceiverFlag,
 		DisableMemoryFlag,
 		DisableStackFlag,
+		IstanbulFlag,
 	}
 	app.Commands = []cli.Command{
 		compileCommand,
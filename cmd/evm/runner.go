@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"os"
 	"runtime/pprof"
 	"time"
@@ -178,6 +179,12 @@ func runCmd(ctx *cli.Context) error {
 		defer pprof.StopCPUProfile()
 	}
 
+	if ctx.GlobalBool(IstanbulFlag.Name) {
+		if chainConfig == nil {
+			chainConfig = &params.ChainConfig{ChainId: big.NewInt(1)}
+		}
+		chainConfig.IstanbulBlock = new(big.Int)
+	}
 	if chainConfig != nil {
 		runtimeConfig.ChainConfig = chainConfig
 	}
@@ -17,12 +17,13 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"github.com/wanchain/go-wanchain/awskms"
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/crypto"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
 	"io/ioutil"
+	"os"
 	"strings"
 
 	"github.com/wanchain/go-wanchain/accounts"
@@ -31,6 +32,7 @@ import (
 	"github.com/wanchain/go-wanchain/console"
 	"github.com/wanchain/go-wanchain/crypto/bn256/cloudflare"
 	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/node"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -206,36 +208,50 @@ Print public key of an address`,
 			},
 			{
 				Name:      "encrypt",
-				Usage:     "Encrypt an existing account with AWS KMS",
+				Usage:     "Encrypt an existing account with the key encryption backend",
 				Action:    utils.MigrateFlags(accountEncrypt),
 				ArgsUsage: "<address>",
 				Flags: []cli.Flag{
 					utils.DataDirFlag,
 					utils.KeyStoreDirFlag,
+					utils.KeyEncryptorFlag,
+					utils.KeyEncryptorRegionFlag,
+					utils.KeyEncryptorKeyIdFlag,
+					utils.KeyEncryptorVaultAddrFlag,
+					utils.KeyEncryptorVaultMountFlag,
+					utils.KeyEncryptorVaultKeyFlag,
 				},
 				Description: `
     gwan account encrypt <address>
 
 Encrypt an existing account.
 
-The account will be encrypted by AWS KMS, and ciphertext will be saved into new file named as "<original-name>-cipher"
+The account will be encrypted by the key encryption backend selected with --kms.backend
+(aws, vault or scrypt), and ciphertext will be saved into new file named as "<original-name>-cipher"
 `,
 			},
 			{
 				Name:      "decrypt",
-				Usage:     "Decrypt an existing AWS KMS encrypted account",
+				Usage:     "Decrypt an existing envelope encrypted account",
 				Action:    utils.MigrateFlags(accountDecrypt),
 				ArgsUsage: "<address>",
 				Flags: []cli.Flag{
 					utils.DataDirFlag,
 					utils.KeyStoreDirFlag,
+					utils.KeyEncryptorFlag,
+					utils.KeyEncryptorRegionFlag,
+					utils.KeyEncryptorKeyIdFlag,
+					utils.KeyEncryptorVaultAddrFlag,
+					utils.KeyEncryptorVaultMountFlag,
+					utils.KeyEncryptorVaultKeyFlag,
 				},
 				Description: `
     gwan account decrypt <address>
 
 Decrypt an existing account.
 
-The account will be decrypted by AWS KMS, and plaintext will be saved into new file named as "<original-name>"
+The account will be decrypted by the key encryption backend selected with --kms.backend
+(aws, vault or scrypt), and plaintext will be saved into new file named as "<original-name>"
 `,
 			},
 		},
@@ -283,7 +299,7 @@ func unlockAccount(ctx *cli.Context, ks *keystore.KeyStore, address string, i in
 	return accounts.Account{}, ""
 }
 
-func unlockAccountFromAwsKmsFile(ctx *cli.Context, ks *keystore.KeyStore, address string, i int, passwords []string) (accounts.Account, string) {
+func unlockAccountFromKmsFile(ctx *cli.Context, stack *node.Node, ks *keystore.KeyStore, address string, i int, passwords []string) (accounts.Account, string) {
 	account, err := utils.MakeAddress(ks, address)
 	if err != nil {
 		utils.Fatalf("Could not list accounts: %v", err)
@@ -297,16 +313,17 @@ func unlockAccountFromAwsKmsFile(ctx *cli.Context, ks *keystore.KeyStore, addres
 	var trials int
 	var keyjson []byte
 	for ; trials < 3; trials++ {
-		prompt := fmt.Sprintf("AWS KMS decrypting account %s | Attempt %d/%d", address, trials+1, 3)
-		keyNames := [3]string{"aKID", "secretKey", "region"}
-		kmsInfo, err := getAwsKmsSecretInfo(prompt, keyNames[:])
+		cfg := stack.KeyEncryptorConfig()
+		prompt := fmt.Sprintf("%s decrypting account %s | Attempt %d/%d", cfg.BackendName(), address, trials+1, 3)
+		enc, err := makeKeyEncryptor(&cfg, prompt, false)
 		if err != nil {
-			utils.Fatalf("Failed to read input: %v", err)
+			fmt.Println("invalid key encryptor info: ", err)
+			continue
 		}
 
-		keyjson, err = awskms.DecryptFileToBuffer(a.URL.Path, kmsInfo[0], kmsInfo[1], kmsInfo[2])
+		keyjson, err = keystore.DecryptKeyFile(enc, a.URL.Path)
 		if err != nil {
-			fmt.Println("invalid AWS KMS info, decrypt keystore file fail: ", err)
+			fmt.Println("decrypt keystore file fail: ", err)
 			continue
 		}
 
@@ -314,10 +331,10 @@ func unlockAccountFromAwsKmsFile(ctx *cli.Context, ks *keystore.KeyStore, addres
 	}
 
 	if trials == 3 || len(keyjson) == 0 {
-		utils.Fatalf("AWS KMS decrypt failed")
+		utils.Fatalf("Keystore file decrypt failed")
 	}
 
-	fmt.Println("AWS KMS decrypt successful")
+	fmt.Println("Keystore file decrypt successful")
 	for trials := 0; trials < 3; trials++ {
 		prompt := fmt.Sprintf("Unlocking account %s | Attempt %d/%d", address, trials+1, 3)
 		password := getPassPhrase(prompt, false, i, passwords)
@@ -510,21 +527,21 @@ func showPublicKey(ctx *cli.Context) error {
 	return nil
 }
 
-// accountEncrypt encrypt an account using AWS KMS,
+// accountEncrypt encrypt an account using the key encryption backend,
 // and save ciphertext into new file named as "<original-name>-cipher"
 func accountEncrypt(ctx *cli.Context) error {
 	if len(ctx.Args()) == 0 {
 		utils.Fatalf("No accounts specified to encrypt")
 	}
 
-	keyNames := [4]string{"aKID", "secretKey", "region", "keyId"}
-	keyVals, err := getAwsKmsSecretInfo("", keyNames[:])
+	stack, _ := makeConfigNode(ctx)
+	cfg := stack.KeyEncryptorConfig()
+	enc, err := makeKeyEncryptor(&cfg, "", true)
 	if err != nil {
 		return err
 	}
 
 	fmt.Println("begin encrypting...")
-	stack, _ := makeConfigNode(ctx)
 	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
 	for _, addr := range ctx.Args() {
 		exceptAddr := common.HexToAddress(addr)
@@ -535,7 +552,7 @@ func accountEncrypt(ctx *cli.Context) error {
 		}
 
 		desFile := fa.URL.Path + keystore.AwsKMSCiphertextFileExt
-		err = awskms.EncryptFile(fa.URL.Path, desFile, keyVals[0], keyVals[1], keyVals[2], keyVals[3])
+		err = keystore.EncryptKeyFile(enc, fa.URL.Path, desFile)
 		if err != nil {
 			return err
 		}
@@ -546,21 +563,21 @@ func accountEncrypt(ctx *cli.Context) error {
 	return nil
 }
 
-// accountDecrypt decrypt an account using AWS KMS,
+// accountDecrypt decrypt an account using the key encryption backend,
 // and save ciphertext into new file named as "<original-name>-plain"
 func accountDecrypt(ctx *cli.Context) error {
 	if len(ctx.Args()) == 0 {
 		utils.Fatalf("No accounts specified to decrypt")
 	}
 
-	keyNames := [3]string{"aKID", "secretKey", "region"}
-	keyVals, err := getAwsKmsSecretInfo("", keyNames[:])
+	stack, _ := makeConfigNode(ctx)
+	cfg := stack.KeyEncryptorConfig()
+	enc, err := makeKeyEncryptor(&cfg, "", false)
 	if err != nil {
 		return err
 	}
 
 	fmt.Println("begin decrypting...")
-	stack, _ := makeConfigNode(ctx)
 	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
 	for _, addr := range ctx.Args() {
 		exceptAddr := common.HexToAddress(addr)
@@ -578,10 +595,13 @@ func accountDecrypt(ctx *cli.Context) error {
 			desFile = fa.URL.Path + "-plain"
 		}
 
-		err = awskms.DecryptFile(fa.URL.Path, desFile, keyVals[0], keyVals[1], keyVals[2])
+		keyjson, err := keystore.DecryptKeyFile(enc, fa.URL.Path)
 		if err != nil {
 			return err
 		}
+		if err := ioutil.WriteFile(desFile, keyjson, 0600); err != nil {
+			return err
+		}
 
 		fmt.Println("decrypt account(",  addr, ") successfully into new keystore file : ", desFile)
 	}
@@ -589,18 +609,48 @@ func accountDecrypt(ctx *cli.Context) error {
 	return nil
 }
 
-func getAwsKmsSecretInfo(notice string, items []string) ([]string, error) {
-	inputs := make([]string, len(items))
-	fmt.Println(notice)
-	for i, name := range items {
-		input, err := console.Stdin.PromptPassword(name + ": ")
+// makeKeyEncryptor creates the key encryptor of cfg, prompting for the secrets
+// which are never kept in the config, and for the missing AWS region and key.
+func makeKeyEncryptor(cfg *keystore.KeyEncryptorConfig, notice string, encrypt bool) (keystore.KeyEncryptor, error) {
+	var items []*string
+	var names []string
+	switch cfg.BackendName() {
+	case keystore.KeyEncryptorAWS:
+		items, names = append(items, &cfg.AWS.AKID, &cfg.AWS.SecretKey), append(names, "aKID", "secretKey")
+		if cfg.AWS.Region == "" {
+			items, names = append(items, &cfg.AWS.Region), append(names, "region")
+		}
+		if encrypt && cfg.AWS.KeyId == "" {
+			items, names = append(items, &cfg.AWS.KeyId), append(names, "keyId")
+		}
+	case keystore.KeyEncryptorVault:
+		cfg.Vault.Token = os.Getenv("VAULT_TOKEN")
+		if cfg.Vault.Token == "" {
+			items, names = append(items, &cfg.Vault.Token), append(names, "vault token")
+		}
+	case keystore.KeyEncryptorScrypt:
+		items, names = append(items, &cfg.Scrypt.Passphrase), append(names, "envelope passphrase")
+	}
+
+	if notice != "" {
+		fmt.Println(notice)
+	}
+	for i, item := range items {
+		input, err := console.Stdin.PromptPassword(names[i] + ": ")
 		if err != nil {
 			return nil, err
 		}
-
-		inputs[i] = input
+		*item = input
 	}
-
-	return inputs, nil
+	if encrypt && cfg.BackendName() == keystore.KeyEncryptorScrypt {
+		confirm, err := console.Stdin.PromptPassword("Repeat envelope passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if confirm != cfg.Scrypt.Passphrase {
+			return nil, errors.New("envelope passphrases do not match")
+		}
+	}
+	return keystore.NewKeyEncryptor(cfg)
 }
 
@@ -31,7 +31,9 @@ import (
 	"github.com/naoina/toml"
 	"github.com/wanchain/go-wanchain/cmd/utils"
 	"github.com/wanchain/go-wanchain/contracts/release"
+	"github.com/wanchain/go-wanchain/core"
 	"github.com/wanchain/go-wanchain/eth"
+	"github.com/wanchain/go-wanchain/eth/downloader"
 	"github.com/wanchain/go-wanchain/node"
 	"github.com/wanchain/go-wanchain/params"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
@@ -144,11 +146,49 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 
 	//Init wanpos private db
 	posdb.DbInitAll(cfg.Node.DataDir)
-	posconfig.Init(&cfg.Node, cfg.Eth.NetworkId)
+	pluto, err := plutoConfig(stack, &cfg.Eth)
+	if err != nil {
+		utils.Fatalf("Failed to read the pos config of the chain: %v", err)
+	}
+	if err := posconfig.Init(&cfg.Node, cfg.Eth.NetworkId, pluto); err != nil {
+		utils.Fatalf("Invalid pos config: %v", err)
+	}
 
 	return stack, cfg
 }
 
+// plutoConfig returns the PoS config of the chain the node runs, the one
+// stored by `gwan init` in an initialized datadir taking precedence over the
+// genesis the flags select. A chain database that cannot be read, e.g.
+// because another gwan holds it, is an error rather than a reason to run
+// the PoS schedule of the flags.
+func plutoConfig(stack *node.Node, cfg *eth.Config) (*params.PlutoConfig, error) {
+	name := "chaindata"
+	if cfg.SyncMode == downloader.LightSync {
+		name = "lightchaindata"
+	}
+	if stack.DataDir() != "" {
+		if _, err := os.Stat(stack.ResolvePath(name)); err == nil {
+			db, err := stack.OpenDatabase(name, 0, 0)
+			if err != nil {
+				return nil, err
+			}
+			config, err := core.GetChainConfig(db, core.GetCanonicalHash(db, 0))
+			db.Close()
+			switch {
+			case err == nil:
+				return config.Pluto, nil
+			case err != core.ErrChainConfigNotFound:
+				return nil, err
+			}
+		}
+	}
+	if cfg.Genesis != nil && cfg.Genesis.Config != nil {
+		return cfg.Genesis.Config.Pluto, nil
+	}
+	return nil, nil
+}
+
 // enableWhisper returns true in case one of the whisper flags is set.
 func enableWhisper(ctx *cli.Context) bool {
 	for _, flag := range whisperFlags {
@@ -177,6 +217,11 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 		utils.RegisterShhService(stack, &cfg.Shh)
 	}
 
+	// Add the GraphQL service if requested.
+	if ctx.GlobalBool(utils.GraphQLEnabledFlag.Name) {
+		utils.RegisterGraphQLService(ctx, stack)
+	}
+
 	// Add the Ethereum Stats daemon if requested.
 	if cfg.Ethstats.URL != "" {
 		utils.RegisterEthStatsService(stack, cfg.Ethstats.URL)
@@ -40,6 +40,7 @@ import (
 	"github.com/wanchain/go-wanchain/internal/debug"
 	"github.com/wanchain/go-wanchain/log"
 	"github.com/wanchain/go-wanchain/metrics"
+	"github.com/wanchain/go-wanchain/metrics/prometheus"
 	"github.com/wanchain/go-wanchain/node"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
 	"gopkg.in/urfave/cli.v1"
@@ -61,6 +62,7 @@ var (
 		utils.IdentityFlag,
 		utils.UnlockedAccountFlag,
 		utils.PasswordFileFlag,
+		utils.OTAScanFlag,
 		utils.BootnodesFlag,
 		utils.BootnodesV4Flag,
 		utils.BootnodesV5Flag,
@@ -83,15 +85,22 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolProtocolJournalFlag,
+		utils.TxPoolProtocolSlotsFlag,
 		utils.FastSyncFlag,
 		utils.LightModeFlag,
 		utils.SyncModeFlag,
+		utils.CheckpointFlag,
 		utils.NoStakingFlag,
+		utils.EquivocationGossipFlag,
+		utils.ValidatorHistoryBackfillFlag,
 		utils.LightServFlag,
 		utils.LightPeersFlag,
 		utils.LightKDFFlag,
 		utils.CacheFlag,
 		utils.TrieCacheGenFlag,
+		utils.GCModeFlag,
+		utils.StateHistoryFlag,
 		utils.ListenPortFlag,
 		utils.MaxPeersFlag,
 		utils.MaxPendingPeersFlag,
@@ -122,6 +131,7 @@ var (
 		utils.RPCCORSDomainFlag,
 		utils.EthStatsURLFlag,
 		utils.MetricsEnabledFlag,
+		utils.MetricsPrometheusFlag,
 		utils.FakePoWFlag,
 		utils.NoCompactionFlag,
 		utils.GpoBlocksFlag,
@@ -130,6 +140,12 @@ var (
 		configFileFlag,
 
 		utils.AwsKmsFlag,
+		utils.KeyEncryptorFlag,
+		utils.KeyEncryptorRegionFlag,
+		utils.KeyEncryptorKeyIdFlag,
+		utils.KeyEncryptorVaultAddrFlag,
+		utils.KeyEncryptorVaultMountFlag,
+		utils.KeyEncryptorVaultKeyFlag,
 	}
 
 	rpcFlags = []cli.Flag{
@@ -144,6 +160,10 @@ var (
 		utils.WSAllowedOriginsFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
+		utils.GraphQLEnabledFlag,
+		utils.GraphQLListenAddrFlag,
+		utils.GraphQLPortFlag,
+		utils.GraphQLCORSDomainFlag,
 	}
 
 	whisperFlags = []cli.Flag{
@@ -181,6 +201,10 @@ func init() {
 		accountCommand,
 		walletCommand,
 		transactionCommand,
+		// See poscmd.go:
+		posCommand,
+		// See rbcmd.go:
+		rbCommand,
 		// See consolecmd.go:
 		consoleCommand,
 		attachCommand,
@@ -210,6 +234,9 @@ func init() {
 		}
 		// Start system runtime metrics collection
 		go metrics.CollectProcessMetrics(3 * time.Second)
+		if addr := ctx.GlobalString(utils.MetricsPrometheusFlag.Name); addr != "" {
+			go prometheus.ListenAndServe(addr)
+		}
 
 		utils.SetupNetwork(ctx)
 		return nil
@@ -273,7 +300,7 @@ func startNode(ctx *cli.Context, stack *node.Node) {
 	for i, account := range unlocks {
 		if trimmed := strings.TrimSpace(account); trimmed != "" {
 			if ctx.IsSet(utils.AwsKmsFlag.Name) {
-				unlockAccountFromAwsKmsFile(ctx, ks, trimmed, i, passwords)
+				unlockAccountFromKmsFile(ctx, stack, ks, trimmed, i, passwords)
 			} else {
 				unlockAccount(ctx, ks, trimmed, i, passwords)
 			}
@@ -0,0 +1,236 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"text/tabwriter"
+
+	"github.com/wanchain/go-wanchain/cmd/utils"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/simulator"
+	posutil "github.com/wanchain/go-wanchain/pos/util"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	posPlanFlag = cli.StringFlag{
+		Name:  "plan",
+		Usage: "JSON file of the staking plan",
+	}
+	posBlockFlag = cli.Int64Flag{
+		Name:  "block",
+		Value: -1,
+		Usage: "Block whose state the plan is replayed on (default: latest)",
+	}
+	posJSONFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the projection as JSON instead of a table",
+	}
+
+	posCommand = cli.Command{
+		Name:     "pos",
+		Usage:    "Proof of stake tools",
+		Category: "POS COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:   "simulate",
+				Usage:  "Project the probability, incentive and refunds of a staking plan",
+				Action: utils.MigrateFlags(posSimulate),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.CacheFlag,
+					posPlanFlag,
+					posBlockFlag,
+					posJSONFlag,
+				},
+				Description: `
+    gwan pos simulate --plan plan.json [--block <number>] [--json]
+
+replays the staking plan on the state of the given block with the pos staking
+contract and projects every following epoch. The plan file looks like:
+
+    {
+      "epochs": 30,
+      "validator": {"from": "0x...", "amount": 50000, "lockEpochs": 30, "feeRate": 1000},
+      "partners": [{"from": "0x...", "validator": "0x...", "amount": 10000, "renewal": true}],
+      "delegations": [{"from": "0x...", "validator": "0x...", "amount": 1000, "outEpoch": 18000}]
+    }
+
+Amounts are in wan. A zero or missing validator address refers to the validator
+of the plan, whose secPk and bn256Pk may be left out. The plan accounts are
+credited with the staked amounts. Expected incentives assume a fully active
+validator and leave the future gas fees out.`,
+			},
+			{
+				Name:   "build-tx",
+				Usage:  "Build an unsigned staking transaction",
+				Action: utils.MigrateFlags(posBuildTx),
+				Flags: []cli.Flag{
+					posTxMethodFlag,
+					posTxFromFlag,
+					posTxValidatorFlag,
+					posTxSecPkFlag,
+					posTxBn256PkFlag,
+					posTxLockEpochsFlag,
+					posTxFeeRateFlag,
+					posTxAmountFlag,
+					posTxNonceFlag,
+					posTxGasFlag,
+					posTxGasPriceFlag,
+					posTxChainIdFlag,
+					posTxRPCFlag,
+					posTxOutFlag,
+				},
+				Description: `
+    gwan pos build-tx --method <method> [arguments] [--out unsigned.json]
+
+builds an unsigned pos transaction for the staking contract and checks its
+payload the way the transaction pool does. The methods and their arguments are:
+
+    stakeIn        --secpk --bn256pk --lockepochs --feerate --amount
+    stakeAppend    --validator --amount
+    delegateIn     --validator --amount
+    delegateOut    --validator
+    updateFeeRate  --validator --feerate
+
+Amounts are in wan. When --nonce or --gasprice are left out they are fetched
+from the node at --rpc, the nonce as the pending nonce of --from. The result is
+a JSON file holding the raw transaction next to a readable copy of it.`,
+			},
+			{
+				Name:      "sign",
+				Usage:     "Sign a staking transaction built by build-tx",
+				ArgsUsage: "<unsigned.json>",
+				Action:    utils.MigrateFlags(posSignTx),
+				Flags: []cli.Flag{
+					posTxKeyFileFlag,
+					utils.PasswordFileFlag,
+					posTxOutFlag,
+				},
+				Description: `
+    gwan pos sign --keyfile <keystore file> [--password <file>] [--out signed.json] unsigned.json
+
+validates the transaction again and signs it with the key of the keystore file
+for the chain id recorded in the file. No node is needed, so this can run on an
+air-gapped machine. The key must match --from when the transaction was built
+with it.`,
+			},
+			{
+				Name:      "broadcast",
+				Usage:     "Send a signed staking transaction to a node",
+				ArgsUsage: "<signed.json>",
+				Action:    utils.MigrateFlags(posBroadcastTx),
+				Flags: []cli.Flag{
+					posTxRPCFlag,
+				},
+				Description: `
+    gwan pos broadcast [--rpc <endpoint>] signed.json
+
+recovers the sender, validates the transaction and sends the raw transaction to
+the node with eth_sendRawTransaction. The transaction hash is printed.`,
+			},
+		},
+	}
+)
+
+func posSimulate(ctx *cli.Context) error {
+	if !ctx.IsSet(posPlanFlag.Name) {
+		utils.Fatalf("The staking plan must be given with --%s", posPlanFlag.Name)
+	}
+	data, err := ioutil.ReadFile(ctx.String(posPlanFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to read the staking plan: %v", err)
+	}
+	plan := new(simulator.Plan)
+	if err := json.Unmarshal(data, plan); err != nil {
+		utils.Fatalf("Invalid staking plan: %v", err)
+	}
+
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+
+	if err := setPosUpgradePoint(chain); err != nil {
+		utils.Fatalf("Could not simulate: %v", err)
+	}
+
+	var header *types.Header
+	if number := ctx.Int64(posBlockFlag.Name); number < 0 {
+		header = chain.CurrentHeader()
+	} else {
+		header = chain.GetHeaderByNumber(uint64(number))
+	}
+	if header == nil {
+		utils.Fatalf("Block not found")
+	}
+	statedb, err := chain.StateAt(header.Root)
+	if err != nil {
+		utils.Fatalf("Could not open the state of block %d: %v", header.Number, err)
+	}
+
+	result, err := simulator.Simulate(chain.Config(), statedb, header, plan)
+	if err != nil {
+		utils.Fatalf("Simulation failed: %v", err)
+	}
+
+	if ctx.Bool(posJSONFlag.Name) {
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	printProjection(result)
+	return nil
+}
+
+// setPosUpgradePoint sets the first pos block and epoch from the chain, the
+// node does it when it starts.
+func setPosUpgradePoint(chain *core.BlockChain) error {
+	if chain.Config().PosFirstBlock == nil {
+		return simulator.ErrNoPosStage
+	}
+	posconfig.Pow2PosUpgradeBlockNumber = chain.Config().PosFirstBlock.Uint64()
+	if h := chain.GetHeaderByNumber(posconfig.Pow2PosUpgradeBlockNumber); h != nil {
+		posconfig.FirstEpochId, _ = posutil.CalEpSlbyTd(h.Difficulty.Uint64())
+	}
+	return nil
+}
+
+// printProjection prints one row per epoch, validator and rewarded account,
+// followed by the refunds of the epoch.
+func printProjection(result *simulator.Result) {
+	fmt.Printf("Replayed at block %d, epoch %d\n\n", result.BlockNumber, result.EpochID)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "EPOCH\tVALIDATOR\tSHARE\tEXP. EL\tEXP. RNP\tACCOUNT\tREWARD (WAN)\tREFUND (WAN)")
+	for _, ep := range result.Epochs {
+		for _, vp := range ep.Validators {
+			prefix := fmt.Sprintf("%d\t%s\t%.4f%%\t%.2f\t%.2f", ep.EpochID, vp.Address.Hex(),
+				vp.Share*100, vp.ExpectedEpochLeaders, vp.ExpectedRandomProposers)
+			if len(vp.Rewards) == 0 {
+				fmt.Fprintf(w, "%s\t-\t-\t\n", prefix)
+			}
+			for _, r := range vp.Rewards {
+				fmt.Fprintf(w, "%s\t%s\t%s\t\n", prefix, r.Address.Hex(), toWan(r.Incentive.ToInt()))
+			}
+		}
+		for _, r := range ep.Refunds {
+			fmt.Fprintf(w, "%d\t\t\t\t\t%s\t\t%s\n", ep.EpochID, r.Address.Hex(), toWan(r.Amount.ToInt()))
+		}
+	}
+	w.Flush()
+}
+
+func toWan(wei *big.Int) string {
+	return new(big.Rat).SetFrac(wei, big.NewInt(params.Wan)).FloatString(6)
+}
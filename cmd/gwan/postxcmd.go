@@ -0,0 +1,426 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/wanchain/go-wanchain/accounts/keystore"
+	"github.com/wanchain/go-wanchain/cmd/utils"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/rlp"
+	"github.com/wanchain/go-wanchain/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const defaultPosTxGas = 200000
+
+var (
+	posTxMethodFlag = cli.StringFlag{
+		Name:  "method",
+		Usage: "Staking method: stakeIn, stakeAppend, delegateIn, delegateOut or updateFeeRate",
+	}
+	posTxFromFlag = cli.StringFlag{
+		Name:  "from",
+		Usage: "Sender of the transaction, used to fetch the nonce and checked when signing",
+	}
+	posTxValidatorFlag = cli.StringFlag{
+		Name:  "validator",
+		Usage: "Validator address for stakeAppend, delegateIn, delegateOut and updateFeeRate",
+	}
+	posTxSecPkFlag = cli.StringFlag{
+		Name:  "secpk",
+		Usage: "Hex secp256k1 public key of the validator for stakeIn",
+	}
+	posTxBn256PkFlag = cli.StringFlag{
+		Name:  "bn256pk",
+		Usage: "Hex bn256 public key of the validator for stakeIn",
+	}
+	posTxLockEpochsFlag = cli.Uint64Flag{
+		Name:  "lockepochs",
+		Usage: "Lock epochs for stakeIn",
+	}
+	posTxFeeRateFlag = cli.Uint64Flag{
+		Name:  "feerate",
+		Usage: "Fee rate for stakeIn and updateFeeRate, 10000 is 100%",
+	}
+	posTxAmountFlag = cli.StringFlag{
+		Name:  "amount",
+		Value: "0",
+		Usage: "Amount in wan sent with the transaction",
+	}
+	posTxNonceFlag = cli.Int64Flag{
+		Name:  "nonce",
+		Value: -1,
+		Usage: "Nonce of the transaction (default: pending nonce from --rpc)",
+	}
+	posTxGasFlag = cli.Uint64Flag{
+		Name:  "gas",
+		Value: defaultPosTxGas,
+		Usage: "Gas limit of the transaction",
+	}
+	posTxGasPriceFlag = cli.StringFlag{
+		Name:  "gasprice",
+		Usage: "Gas price in wei (default: suggested price from --rpc)",
+	}
+	posTxChainIdFlag = cli.Uint64Flag{
+		Name:  "chainid",
+		Value: params.MainnetChainConfig.ChainId.Uint64(),
+		Usage: "Chain id the transaction is signed for",
+	}
+	posTxRPCFlag = cli.StringFlag{
+		Name:  "rpc",
+		Usage: "Endpoint of a node, IPC path or HTTP/WS URL (default: IPC endpoint of the default datadir)",
+	}
+	posTxOutFlag = cli.StringFlag{
+		Name:  "out",
+		Usage: "File the transaction is written to",
+	}
+	posTxKeyFileFlag = cli.StringFlag{
+		Name:  "keyfile",
+		Usage: "Keystore file of the sender",
+	}
+
+	// staking methods which can be built offline, by their abi name
+	posTxMethods = map[string]string{
+		"stakeIn":       "stakeIn",
+		"stakeAppend":   "stakeAppend",
+		"delegateIn":    "delegateIn",
+		"delegateOut":   "delegateOut",
+		"updateFeeRate": "stakeUpdateFeeRate",
+	}
+)
+
+// posTxFile is exchanged between build-tx, sign and broadcast. Raw is the rlp
+// encoding of the transaction and is authoritative, Tx is there for review.
+type posTxFile struct {
+	ChainId *hexutil.Big    `json:"chainId"`
+	From    *common.Address `json:"from,omitempty"`
+	Method  string          `json:"method"`
+	Tx      *posTxReview    `json:"tx"`
+	Raw     hexutil.Bytes   `json:"raw"`
+	Signed  bool            `json:"signed"`
+	Hash    *common.Hash    `json:"hash,omitempty"`
+}
+
+// posTxReview is the readable copy of a transaction, which unlike
+// types.Transaction can be decoded without a signature.
+type posTxReview struct {
+	Txtype   hexutil.Uint64  `json:"txType"`
+	Nonce    hexutil.Uint64  `json:"nonce"`
+	To       *common.Address `json:"to"`
+	Value    *hexutil.Big    `json:"value"`
+	Gas      *hexutil.Big    `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Input    hexutil.Bytes   `json:"input"`
+}
+
+func newPosTxReview(tx *types.Transaction) *posTxReview {
+	return &posTxReview{
+		Txtype:   hexutil.Uint64(tx.Txtype()),
+		Nonce:    hexutil.Uint64(tx.Nonce()),
+		To:       tx.To(),
+		Value:    (*hexutil.Big)(tx.Value()),
+		Gas:      (*hexutil.Big)(tx.Gas()),
+		GasPrice: (*hexutil.Big)(tx.GasPrice()),
+		Input:    tx.Data(),
+	}
+}
+
+// transaction decodes the raw transaction and checks it against the
+// reviewable copy.
+func (f *posTxFile) transaction() (*types.Transaction, error) {
+	if f.ChainId == nil {
+		return nil, errors.New("missing chain id")
+	}
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(f.Raw, tx); err != nil {
+		return nil, err
+	}
+	if f.Tx != nil {
+		want, err := json.Marshal(newPosTxReview(tx))
+		if err != nil {
+			return nil, err
+		}
+		have, err := json.Marshal(f.Tx)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(have, want) {
+			return nil, errors.New("raw transaction differs from the reviewed one")
+		}
+	}
+	if !types.IsPosTransaction(tx.Txtype()) {
+		return nil, errors.New("not a pos transaction")
+	}
+	return tx, nil
+}
+
+func newPosTxFile(chainId *big.Int, from *common.Address, method string, tx *types.Transaction, signed bool) (*posTxFile, error) {
+	raw, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return nil, err
+	}
+	file := &posTxFile{
+		ChainId: (*hexutil.Big)(chainId),
+		From:    from,
+		Method:  method,
+		Tx:      newPosTxReview(tx),
+		Raw:     raw,
+		Signed:  signed,
+	}
+	if signed {
+		hash := tx.Hash()
+		file.Hash = &hash
+	}
+	return file, nil
+}
+
+// buildPosTx creates the unsigned staking transaction and validates its
+// payload the way the transaction pool does.
+func buildPosTx(chainId *big.Int, nonce uint64, gas uint64, gasPrice, amount *big.Int, method string, args ...interface{}) (*types.Transaction, error) {
+	name, ok := posTxMethods[method]
+	if !ok {
+		return nil, fmt.Errorf("unknown staking method %q", method)
+	}
+	input, err := vm.PackStakingInput(name, args...)
+	if err != nil {
+		return nil, err
+	}
+	tx := types.NewTransaction(nonce, vm.WanCscPrecompileAddr, amount, new(big.Int).SetUint64(gas), gasPrice, input)
+	tx.SetTxtype(types.POS_TX)
+	if err := validatePosTx(chainId, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// validatePosTx runs the validation of the pos staking contract.
+func validatePosTx(chainId *big.Int, tx *types.Transaction) error {
+	if tx.To() == nil || *tx.To() != vm.WanCscPrecompileAddr {
+		return errors.New("not a pos staking transaction")
+	}
+	return vm.PrecompiledContractsByzantium[vm.WanCscPrecompileAddr].ValidTx(nil, types.NewEIP155Signer(chainId), tx)
+}
+
+func posBuildTx(ctx *cli.Context) error {
+	method := ctx.String(posTxMethodFlag.Name)
+	amount, err := parseWan(ctx.String(posTxAmountFlag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid amount: %v", err)
+	}
+
+	var from *common.Address
+	if ctx.IsSet(posTxFromFlag.Name) {
+		addr := parseAddress(ctx.String(posTxFromFlag.Name))
+		from = &addr
+	}
+
+	var args []interface{}
+	switch method {
+	case "stakeIn":
+		secPk, err := hexutil.Decode(ctx.String(posTxSecPkFlag.Name))
+		if err != nil {
+			utils.Fatalf("Invalid secpk: %v", err)
+		}
+		bn256Pk, err := hexutil.Decode(ctx.String(posTxBn256PkFlag.Name))
+		if err != nil {
+			utils.Fatalf("Invalid bn256pk: %v", err)
+		}
+		args = []interface{}{secPk, bn256Pk,
+			new(big.Int).SetUint64(ctx.Uint64(posTxLockEpochsFlag.Name)),
+			new(big.Int).SetUint64(ctx.Uint64(posTxFeeRateFlag.Name))}
+	case "stakeAppend", "delegateIn", "delegateOut":
+		args = []interface{}{parseAddress(ctx.String(posTxValidatorFlag.Name))}
+	case "updateFeeRate":
+		args = []interface{}{parseAddress(ctx.String(posTxValidatorFlag.Name)),
+			new(big.Int).SetUint64(ctx.Uint64(posTxFeeRateFlag.Name))}
+	default:
+		utils.Fatalf("Unknown staking method %q", method)
+	}
+
+	// the nonce and gas price may be fetched from a node
+	var client *rpc.Client
+	dial := func() *rpc.Client {
+		if client == nil {
+			if client, err = dialRPC(ctx.String(posTxRPCFlag.Name)); err != nil {
+				utils.Fatalf("Unable to connect to the node: %v", err)
+			}
+		}
+		return client
+	}
+
+	nonce := uint64(ctx.Int64(posTxNonceFlag.Name))
+	if ctx.Int64(posTxNonceFlag.Name) < 0 {
+		if from == nil {
+			utils.Fatalf("Either --%s or --%s must be given", posTxNonceFlag.Name, posTxFromFlag.Name)
+		}
+		var result hexutil.Uint64
+		if err := dial().CallContext(context.Background(), &result, "eth_getTransactionCount", *from, "pending"); err != nil {
+			utils.Fatalf("Failed to get the nonce: %v", err)
+		}
+		nonce = uint64(result)
+	}
+
+	gasPrice := new(big.Int)
+	if ctx.IsSet(posTxGasPriceFlag.Name) {
+		if _, ok := gasPrice.SetString(ctx.String(posTxGasPriceFlag.Name), 10); !ok {
+			utils.Fatalf("Invalid gas price %q", ctx.String(posTxGasPriceFlag.Name))
+		}
+	} else {
+		var result hexutil.Big
+		if err := dial().CallContext(context.Background(), &result, "eth_gasPrice"); err != nil {
+			utils.Fatalf("Failed to get the gas price: %v", err)
+		}
+		gasPrice = (*big.Int)(&result)
+	}
+	if client != nil {
+		client.Close()
+	}
+
+	chainId := new(big.Int).SetUint64(ctx.Uint64(posTxChainIdFlag.Name))
+	tx, err := buildPosTx(chainId, nonce, ctx.Uint64(posTxGasFlag.Name), gasPrice, amount, method, args...)
+	if err != nil {
+		utils.Fatalf("Invalid %s transaction: %v", method, err)
+	}
+	file, err := newPosTxFile(chainId, from, method, tx, false)
+	if err != nil {
+		return err
+	}
+	return writePosTxFile(ctx, file)
+}
+
+func posSignTx(ctx *cli.Context) error {
+	file := readPosTxFile(ctx)
+	if file.Signed {
+		utils.Fatalf("The transaction is signed already")
+	}
+	tx, err := file.transaction()
+	if err != nil {
+		utils.Fatalf("Invalid transaction file: %v", err)
+	}
+	chainId := file.ChainId.ToInt()
+	if err := validatePosTx(chainId, tx); err != nil {
+		utils.Fatalf("Invalid %s transaction: %v", file.Method, err)
+	}
+
+	keyFile := ctx.String(posTxKeyFileFlag.Name)
+	if keyFile == "" {
+		utils.Fatalf("The keystore file must be given with --%s", posTxKeyFileFlag.Name)
+	}
+	keyJSON, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		utils.Fatalf("Failed to read the keystore file: %v", err)
+	}
+	password := getPassPhrase("Passphrase of the keystore file", false, 0, utils.MakePasswordList(ctx))
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		utils.Fatalf("Failed to decrypt the keystore file: %v", err)
+	}
+	if file.From != nil && *file.From != key.Address {
+		utils.Fatalf("The keystore file holds %s, the transaction is built for %s", key.Address.Hex(), file.From.Hex())
+	}
+
+	signed, err := types.SignTx(tx, types.NewEIP155Signer(chainId), key.PrivateKey)
+	if err != nil {
+		utils.Fatalf("Failed to sign the transaction: %v", err)
+	}
+	signedFile, err := newPosTxFile(chainId, &key.Address, file.Method, signed, true)
+	if err != nil {
+		return err
+	}
+	return writePosTxFile(ctx, signedFile)
+}
+
+func posBroadcastTx(ctx *cli.Context) error {
+	file := readPosTxFile(ctx)
+	if !file.Signed {
+		utils.Fatalf("The transaction is not signed")
+	}
+	tx, err := file.transaction()
+	if err != nil {
+		utils.Fatalf("Invalid transaction file: %v", err)
+	}
+	chainId := file.ChainId.ToInt()
+	from, err := types.Sender(types.NewEIP155Signer(chainId), tx)
+	if err != nil {
+		utils.Fatalf("Invalid signature: %v", err)
+	}
+	if file.From != nil && *file.From != from {
+		utils.Fatalf("The transaction is signed by %s, expected %s", from.Hex(), file.From.Hex())
+	}
+	if err := validatePosTx(chainId, tx); err != nil {
+		utils.Fatalf("Invalid %s transaction: %v", file.Method, err)
+	}
+
+	client, err := dialRPC(ctx.String(posTxRPCFlag.Name))
+	if err != nil {
+		utils.Fatalf("Unable to connect to the node: %v", err)
+	}
+	defer client.Close()
+
+	var hash common.Hash
+	if err := client.CallContext(context.Background(), &hash, "eth_sendRawTransaction", file.Raw); err != nil {
+		utils.Fatalf("Failed to broadcast the transaction: %v", err)
+	}
+	fmt.Println(hash.Hex())
+	return nil
+}
+
+func readPosTxFile(ctx *cli.Context) *posTxFile {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("The transaction file must be given as argument")
+	}
+	data, err := ioutil.ReadFile(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Failed to read the transaction file: %v", err)
+	}
+	file := new(posTxFile)
+	if err := json.Unmarshal(data, file); err != nil {
+		utils.Fatalf("Invalid transaction file: %v", err)
+	}
+	return file
+}
+
+func writePosTxFile(ctx *cli.Context, file *posTxFile) error {
+	out, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if path := ctx.String(posTxOutFlag.Name); path != "" {
+		return ioutil.WriteFile(path, append(out, '\n'), 0600)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+func parseAddress(s string) common.Address {
+	if !common.IsHexAddress(s) {
+		utils.Fatalf("Invalid address %q", s)
+	}
+	return common.HexToAddress(s)
+}
+
+// parseWan converts an amount in wan to wei.
+func parseWan(s string) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || r.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(params.Wan))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("amount %q is below 1 wei", s)
+	}
+	return new(big.Int).Set(r.Num()), nil
+}
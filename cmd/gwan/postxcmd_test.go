@@ -0,0 +1,82 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+)
+
+func TestPosTxRoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	validator := common.HexToAddress("0x0000000000000000000000000000000000001234")
+	chainId := big.NewInt(3)
+
+	amount, err := parseWan("100.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, _ := new(big.Int).SetString("100500000000000000000", 10); amount.Cmp(exp) != 0 {
+		t.Fatalf("amount mismatch: have %v, want %v", amount, exp)
+	}
+
+	if _, err := buildPosTx(chainId, 0, defaultPosTxGas, big.NewInt(1), amount, "unknown"); err == nil {
+		t.Fatal("unknown method accepted")
+	}
+	tx, err := buildPosTx(chainId, 7, defaultPosTxGas, big.NewInt(180e9), amount, "delegateIn", validator)
+	if err != nil {
+		t.Fatalf("failed to build the transaction: %v", err)
+	}
+	if tx.Txtype() != types.POS_TX {
+		t.Fatalf("txtype mismatch: have %d, want %d", tx.Txtype(), types.POS_TX)
+	}
+
+	// the unsigned file survives a json round trip
+	unsigned, err := newPosTxFile(chainId, &from, "delegateIn", tx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(posTxFile)
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	tx, err = decoded.transaction()
+	if err != nil {
+		t.Fatalf("failed to decode the transaction: %v", err)
+	}
+
+	signed, err := types.SignTx(tx, types.NewEIP155Signer(decoded.ChainId.ToInt()), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedFile, err := newPosTxFile(chainId, &from, "delegateIn", signed, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err = signedFile.transaction()
+	if err != nil {
+		t.Fatalf("failed to decode the signed transaction: %v", err)
+	}
+	if sender, err := types.Sender(types.NewEIP155Signer(chainId), tx); err != nil || sender != from {
+		t.Fatalf("sender mismatch: have %x (%v), want %x", sender, err, from)
+	}
+	if err := validatePosTx(chainId, tx); err != nil {
+		t.Fatalf("signed transaction is invalid: %v", err)
+	}
+
+	// a tampered readable copy is refused
+	signedFile.Tx.Nonce++
+	if _, err := signedFile.transaction(); err == nil {
+		t.Fatal("tampered transaction file accepted")
+	}
+}
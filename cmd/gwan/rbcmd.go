@@ -0,0 +1,133 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"text/tabwriter"
+
+	"github.com/wanchain/go-wanchain/cmd/utils"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/pos/epochLeader"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	rbEpochFlag = cli.Uint64Flag{
+		Name:  "epoch",
+		Usage: "Epoch whose random beacon protocol is verified",
+	}
+
+	rbCommand = cli.Command{
+		Name:     "rb",
+		Usage:    "Random beacon tools",
+		Category: "POS COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:   "verify",
+				Usage:  "Verify the random number generated by the random beacon of an epoch",
+				Action: utils.MigrateFlags(rbVerify),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.CacheFlag,
+					rbEpochFlag,
+					posBlockFlag,
+					posJSONFlag,
+				},
+				Description: `
+    gwan rb verify --epoch <epochID> [--block <number>] [--json]
+
+reads the DKG commitments and signature shares stored by the random beacon
+contract during the epoch and verifies them offline: the group public key is
+recomputed from the DKG1 commitments, every signature share is checked against
+the public share of its proposer with a pairing, and the random number of the
+next epoch is rebuilt from the valid shares and compared with the stored one.
+Every proposer is reported as honest, missing or malicious.`,
+			},
+		},
+	}
+)
+
+func rbVerify(ctx *cli.Context) error {
+	if !ctx.IsSet(rbEpochFlag.Name) {
+		utils.Fatalf("The epoch must be given with --%s", rbEpochFlag.Name)
+	}
+	epochID := ctx.Uint64(rbEpochFlag.Name)
+
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+
+	if err := setPosUpgradePoint(chain); err != nil {
+		utils.Fatalf("Could not verify: %v", err)
+	}
+
+	var header *types.Header
+	if number := ctx.Int64(posBlockFlag.Name); number < 0 {
+		header = chain.CurrentHeader()
+	} else {
+		header = chain.GetHeaderByNumber(uint64(number))
+	}
+	if header == nil {
+		utils.Fatalf("Block not found")
+	}
+	statedb, err := chain.StateAt(header.Root)
+	if err != nil {
+		utils.Fatalf("Could not open the state of block %d: %v", header.Number, err)
+	}
+
+	// the proposer group is kept in the local pos db, select it again when
+	// the node did not.
+	epocher := epochLeader.NewEpocher(chain)
+	if !epocher.IsGenerateRBPSuc(epochID) {
+		if err := epocher.SelectLeadersLoop(epochID); err != nil {
+			utils.Fatalf("Could not select the random beacon proposers of epoch %d: %v", epochID, err)
+		}
+	}
+
+	result, err := vm.VerifyRandom(statedb, epochID, epocher.GetRBProposerG1(epochID))
+	if err != nil {
+		utils.Fatalf("Verification failed: %v", err)
+	}
+
+	if ctx.Bool(posJSONFlag.Name) {
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	printRandomVerification(result)
+	return nil
+}
+
+func printRandomVerification(result *vm.RbVerifyResult) {
+	fmt.Printf("Random of epoch %d generated during epoch %d\n", result.RandomEpochId, result.EpochId)
+	fmt.Printf("Stored:  %s\n", formatRandom(result.R))
+	fmt.Printf("Rebuilt: %s\n", formatRandom(result.ComputedR))
+	if result.Valid {
+		fmt.Printf("Result:  valid\n\n")
+	} else {
+		fmt.Printf("Result:  invalid (%s)\n\n", result.Reason)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PROPOSER\tSTATUS\tDKG1\tDKG2\tSIGSHARE\tREASON")
+	for _, p := range result.Proposers {
+		fmt.Fprintf(w, "%d\t%s\t%t\t%t\t%t\t%s\n", p.ProposerId, p.Status, p.Dkg1, p.Dkg2, p.SigShare, p.Reason)
+	}
+	w.Flush()
+}
+
+func formatRandom(r *big.Int) string {
+	if r == nil {
+		return "-"
+	}
+	return hexutil.EncodeBig(r)
+}
@@ -78,6 +78,9 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.PlutoDevFlag,
 			utils.DevModeFlag,
 			utils.SyncModeFlag,
+			utils.CheckpointFlag,
+			utils.EquivocationGossipFlag,
+			utils.ValidatorHistoryBackfillFlag,
 			utils.EthStatsURLFlag,
 			utils.IdentityFlag,
 			utils.LightServFlag,
@@ -109,6 +112,8 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolProtocolJournalFlag,
+			utils.TxPoolProtocolSlotsFlag,
 		},
 	},
 	{
@@ -116,6 +121,8 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.CacheFlag,
 			utils.TrieCacheGenFlag,
+			utils.GCModeFlag,
+			utils.StateHistoryFlag,
 		},
 	},
 	{
@@ -123,7 +130,14 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.UnlockedAccountFlag,
 			utils.PasswordFileFlag,
+			utils.OTAScanFlag,
 			utils.AwsKmsFlag,
+			utils.KeyEncryptorFlag,
+			utils.KeyEncryptorRegionFlag,
+			utils.KeyEncryptorKeyIdFlag,
+			utils.KeyEncryptorVaultAddrFlag,
+			utils.KeyEncryptorVaultMountFlag,
+			utils.KeyEncryptorVaultKeyFlag,
 		},
 	},
 	{
@@ -141,6 +155,10 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.IPCDisabledFlag,
 			utils.IPCPathFlag,
 			utils.RPCCORSDomainFlag,
+			utils.GraphQLEnabledFlag,
+			utils.GraphQLListenAddrFlag,
+			utils.GraphQLPortFlag,
+			utils.GraphQLCORSDomainFlag,
 			utils.JSpathFlag,
 			utils.ExecFlag,
 			utils.PreloadJSFlag,
@@ -191,6 +209,7 @@ var AppHelpFlagGroups = []flagGroup{
 		Name: "LOGGING AND DEBUGGING",
 		Flags: append([]cli.Flag{
 			utils.MetricsEnabledFlag,
+			utils.MetricsPrometheusFlag,
 			utils.FakePoWFlag,
 			utils.NoCompactionFlag,
 			utils.SysLogFlag,
@@ -29,6 +29,7 @@ import (
 	"github.com/wanchain/go-wanchain/core"
 	"github.com/wanchain/go-wanchain/log"
 	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
 )
 
 // makeGenesis creates a new genesis struct based on some user input.
@@ -52,6 +53,7 @@ func (w *wizard) makeGenesis() {
 	fmt.Println("Which consensus engine to use? (default = clique)")
 	fmt.Println(" 1. Ethash - proof-of-work")
 	fmt.Println(" 2. Clique - proof-of-authority")
+	fmt.Println(" 3. Pluto - proof-of-stake")
 
 	choice := w.read()
 	switch {
@@ -98,6 +100,24 @@ func (w *wizard) makeGenesis() {
 			copy(genesis.ExtraData[32+i*common.AddressLength:], signer[:])
 		}
 
+	case choice == "3":
+		// In the case of pluto, the network runs pos from its first block
+		genesis.Difficulty = big.NewInt(1)
+		genesis.Config.ByzantiumBlock = big.NewInt(0)
+		genesis.Config.PosFirstBlock = big.NewInt(1)
+		genesis.Config.IsPosActive = true
+		genesis.Config.Pluto = &params.PlutoConfig{
+			Period: 10,
+			Epoch:  100,
+		}
+		fmt.Println()
+		fmt.Printf("How many seconds should a slot take? (default = %d)\n", posconfig.DefaultSlotTime)
+		genesis.Config.Pluto.SlotTime = uint64(w.readDefaultInt(posconfig.DefaultSlotTime))
+
+		fmt.Println()
+		fmt.Printf("How many slots should a stage take? An epoch has %d stages (default = %d)\n", posconfig.KCount, posconfig.DefaultK)
+		genesis.Config.Pluto.K = uint64(w.readDefaultInt(posconfig.DefaultK))
+
 	default:
 		log.Crit("Invalid consensus engine choice", "choice", choice)
 	}
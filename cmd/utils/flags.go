@@ -44,6 +44,7 @@ import (
 	"github.com/wanchain/go-wanchain/eth/gasprice"
 	"github.com/wanchain/go-wanchain/ethdb"
 	"github.com/wanchain/go-wanchain/ethstats"
+	"github.com/wanchain/go-wanchain/graphql"
 	"github.com/wanchain/go-wanchain/les"
 	"github.com/wanchain/go-wanchain/log"
 	"github.com/wanchain/go-wanchain/metrics"
@@ -191,13 +192,25 @@ var (
 	defaultSyncMode = eth.DefaultConfig.SyncMode
 	SyncModeFlag    = TextMarshalerFlag{
 		Name:  "syncmode",
-		Usage: `Blockchain sync mode ("fast", "full", or "light")`,
+		Usage: `Blockchain sync mode ("fast", "full", "light" or "checkpoint")`,
 		Value: &defaultSyncMode,
 	}
+	CheckpointFlag = cli.StringFlag{
+		Name:  "checkpoint",
+		Usage: "Trusted stable block checkpoint sync starts from (<number>:<hash>)",
+	}
 	NoStakingFlag = cli.BoolFlag{
 		Name:  "noStaking",
 		Usage: "Disable staking",
 	}
+	EquivocationGossipFlag = cli.BoolFlag{
+		Name:  "equivocationgossip",
+		Usage: "Gossip the evidence of slot leaders sealing two blocks for the same slot (only sent to eth/64 peers)",
+	}
+	ValidatorHistoryBackfillFlag = cli.BoolFlag{
+		Name:  "validatorhistory.backfill",
+		Usage: "Index the validator histories from the genesis instead of the current head",
+	}
 
 	LightServFlag = cli.IntFlag{
 		Name:  "lightserv",
@@ -293,6 +306,16 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: eth.DefaultConfig.TxPool.Lifetime,
 	}
+	TxPoolProtocolJournalFlag = cli.StringFlag{
+		Name:  "txpool.protocoljournal",
+		Usage: "Disk journal for random beacon and slot leader selection transactions to survive node restarts",
+		Value: core.DefaultTxPoolConfig.ProtocolJournal,
+	}
+	TxPoolProtocolSlotsFlag = cli.Uint64Flag{
+		Name:  "txpool.protocolslots",
+		Usage: "Maximum number of random beacon and slot leader selection transactions, kept apart from the other limits",
+		Value: eth.DefaultConfig.TxPool.ProtocolSlots,
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -304,6 +327,15 @@ var (
 		Usage: "Number of trie node generations to keep in memory",
 		Value: int(state.MaxTrieCacheGen),
 	}
+	GCModeFlag = cli.StringFlag{
+		Name:  "gcmode",
+		Usage: `Blockchain garbage collection mode ("archive", or "full" to prune old states)`,
+		Value: "archive",
+	}
+	StateHistoryFlag = cli.Uint64Flag{
+		Name:  "statehistory",
+		Usage: "Number of recent block states kept in full garbage collection mode, above the pos reorg depth (0 = K+128 of the chain)",
+	}
 	// Miner settings
 	MiningEnabledFlag = cli.BoolFlag{
 		Name:  "mine",
@@ -344,6 +376,10 @@ var (
 		Usage: "Password file to use for non-interactive password input",
 		Value: "",
 	}
+	OTAScanFlag = cli.BoolFlag{
+		Name:  "otascan",
+		Usage: "Index the OTAs received by the keystore accounts watched through personal_listOTAs (in memory, rescanned after a restart)",
+	}
 
 	VMEnableDebugFlag = cli.BoolFlag{
 		Name:  "vmdebug",
@@ -358,6 +394,10 @@ var (
 		Name:  metrics.MetricsEnabledFlag,
 		Usage: "Enable metrics collection and reporting",
 	}
+	MetricsPrometheusFlag = cli.StringFlag{
+		Name:  metrics.MetricsPrometheusFlag,
+		Usage: "Enable metrics collection and export them in the Prometheus format on this address (e.g. 127.0.0.1:6061)",
+	}
 	FakePoWFlag = cli.BoolFlag{
 		Name:  "fakepow",
 		Usage: "Disables proof-of-work verification",
@@ -423,6 +463,25 @@ var (
 		Usage: "Origins from which to accept websockets requests",
 		Value: "",
 	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable the GraphQL server",
+	}
+	GraphQLListenAddrFlag = cli.StringFlag{
+		Name:  "graphql.addr",
+		Usage: "GraphQL server listening interface",
+		Value: node.DefaultGraphQLHost,
+	}
+	GraphQLPortFlag = cli.IntFlag{
+		Name:  "graphql.port",
+		Usage: "GraphQL server listening port",
+		Value: node.DefaultGraphQLPort,
+	}
+	GraphQLCORSDomainFlag = cli.StringFlag{
+		Name:  "graphql.corsdomain",
+		Usage: "Comma separated list of domains from which to accept cross origin GraphQL requests (browser enforced)",
+		Value: "",
+	}
 	ExecFlag = cli.StringFlag{
 		Name:  "exec",
 		Usage: "Execute JavaScript statement",
@@ -548,7 +607,33 @@ var (
 	}
 	AwsKmsFlag = cli.BoolFlag{
 		Name:  "kms",
-		Usage: "Enable AWS KMS encrypted keystore file",
+		Usage: "Enable envelope encrypted keystore file",
+	}
+	KeyEncryptorFlag = cli.StringFlag{
+		Name:  "kms.backend",
+		Usage: "Key encryption backend of the keystore files (aws, vault, scrypt)",
+		Value: keystore.KeyEncryptorAWS,
+	}
+	KeyEncryptorRegionFlag = cli.StringFlag{
+		Name:  "kms.aws.region",
+		Usage: "AWS region of the KMS key",
+	}
+	KeyEncryptorKeyIdFlag = cli.StringFlag{
+		Name:  "kms.aws.keyid",
+		Usage: "AWS KMS key id used to encrypt the keystore files",
+	}
+	KeyEncryptorVaultAddrFlag = cli.StringFlag{
+		Name:  "kms.vault.addr",
+		Usage: "Address of the Vault server, the token is read from VAULT_TOKEN or prompted",
+	}
+	KeyEncryptorVaultMountFlag = cli.StringFlag{
+		Name:  "kms.vault.mount",
+		Usage: "Mount path of the Vault transit engine",
+		Value: "transit",
+	}
+	KeyEncryptorVaultKeyFlag = cli.StringFlag{
+		Name:  "kms.vault.key",
+		Usage: "Name of the Vault transit key",
 	}
 )
 
@@ -904,6 +989,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(NoUSBFlag.Name) {
 		cfg.NoUSB = ctx.GlobalBool(NoUSBFlag.Name)
 	}
+	setKeyEncryptor(ctx, &cfg.KeyEncryptor)
 }
 
 func setGPO(ctx *cli.Context, cfg *gasprice.Config) {
@@ -946,6 +1032,34 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolProtocolJournalFlag.Name) {
+		cfg.ProtocolJournal = ctx.GlobalString(TxPoolProtocolJournalFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolProtocolSlotsFlag.Name) {
+		cfg.ProtocolSlots = ctx.GlobalUint64(TxPoolProtocolSlotsFlag.Name)
+	}
+}
+
+// setKeyEncryptor applies the key encryption backend flags to cfg.
+func setKeyEncryptor(ctx *cli.Context, cfg *keystore.KeyEncryptorConfig) {
+	if ctx.GlobalIsSet(KeyEncryptorFlag.Name) {
+		cfg.Backend = ctx.GlobalString(KeyEncryptorFlag.Name)
+	}
+	if ctx.GlobalIsSet(KeyEncryptorRegionFlag.Name) {
+		cfg.AWS.Region = ctx.GlobalString(KeyEncryptorRegionFlag.Name)
+	}
+	if ctx.GlobalIsSet(KeyEncryptorKeyIdFlag.Name) {
+		cfg.AWS.KeyId = ctx.GlobalString(KeyEncryptorKeyIdFlag.Name)
+	}
+	if ctx.GlobalIsSet(KeyEncryptorVaultAddrFlag.Name) {
+		cfg.Vault.Addr = ctx.GlobalString(KeyEncryptorVaultAddrFlag.Name)
+	}
+	if ctx.GlobalIsSet(KeyEncryptorVaultMountFlag.Name) {
+		cfg.Vault.Mount = ctx.GlobalString(KeyEncryptorVaultMountFlag.Name)
+	}
+	if ctx.GlobalIsSet(KeyEncryptorVaultKeyFlag.Name) {
+		cfg.Vault.Key = ctx.GlobalString(KeyEncryptorVaultKeyFlag.Name)
+	}
 }
 
 func setEthash(ctx *cli.Context, cfg *eth.Config) {
@@ -1011,9 +1125,25 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	case ctx.GlobalBool(LightModeFlag.Name):
 		cfg.SyncMode = downloader.LightSync
 	}
+	if ctx.GlobalIsSet(CheckpointFlag.Name) {
+		checkpoint, err := downloader.ParseCheckpoint(ctx.GlobalString(CheckpointFlag.Name))
+		if err != nil {
+			Fatalf("Option %q: %v", CheckpointFlag.Name, err)
+		}
+		cfg.Checkpoint = checkpoint
+	}
 	if ctx.GlobalIsSet(NoStakingFlag.Name) {
 		params.SetNoStaking()
 	}
+	if ctx.GlobalIsSet(EquivocationGossipFlag.Name) {
+		cfg.EquivocationGossip = ctx.GlobalBool(EquivocationGossipFlag.Name)
+	}
+	if ctx.GlobalIsSet(ValidatorHistoryBackfillFlag.Name) {
+		cfg.ValidatorHistoryBackfill = ctx.GlobalBool(ValidatorHistoryBackfillFlag.Name)
+	}
+	if ctx.GlobalIsSet(OTAScanFlag.Name) {
+		cfg.OTAScan = ctx.GlobalBool(OTAScanFlag.Name)
+	}
 	if ctx.GlobalIsSet(LightServFlag.Name) {
 		cfg.LightServ = ctx.GlobalInt(LightServFlag.Name)
 	}
@@ -1029,6 +1159,14 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	}
 	cfg.DatabaseHandles = makeDatabaseHandles()
 
+	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
+		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
+	}
+	cfg.NoPruning = ctx.GlobalString(GCModeFlag.Name) == "archive"
+	if ctx.GlobalIsSet(StateHistoryFlag.Name) {
+		cfg.TrieStateHistory = ctx.GlobalUint64(StateHistoryFlag.Name)
+	}
+
 	if ctx.GlobalIsSet(MinerThreadsFlag.Name) {
 		cfg.MinerThreads = ctx.GlobalInt(MinerThreadsFlag.Name)
 	}
@@ -1146,6 +1284,24 @@ func RegisterEthStatsService(stack *node.Node, url string) {
 	}
 }
 
+// RegisterGraphQLService adds the GraphQL service to a full node, serving the
+// chain and the PoS data on the endpoint configured by the GraphQL flags.
+func RegisterGraphQLService(ctx *cli.Context, stack *node.Node) {
+	endpoint := fmt.Sprintf("%s:%d", ctx.GlobalString(GraphQLListenAddrFlag.Name), ctx.GlobalInt(GraphQLPortFlag.Name))
+	cors := splitAndTrim(ctx.GlobalString(GraphQLCORSDomainFlag.Name))
+
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		// The PoS data is read from the chain of a full node
+		var ethServ *eth.Ethereum
+		if err := ctx.Service(&ethServ); err != nil {
+			return nil, fmt.Errorf("GraphQL requires a full node: %v", err)
+		}
+		return graphql.New(ethServ.ApiBackend, ethServ.BlockChain(), endpoint, cors)
+	}); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
 // SetupNetwork configures the system for either the main net or some test network.
 func SetupNetwork(ctx *cli.Context) {
 	// TODO(fjl): move target gas limit into config
@@ -1210,7 +1366,13 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 		}
 	}
 	vmcfg := vm.Config{EnablePreimageRecording: ctx.GlobalBool(VMEnableDebugFlag.Name)}
-	chain, err = core.NewBlockChain(chainDb, config, engine, vmcfg, nil)
+	cache := &core.CacheConfig{
+		Disabled:      ctx.GlobalString(GCModeFlag.Name) == "archive",
+		TrieNodeLimit: eth.DefaultConfig.TrieCache,
+		TrieTimeLimit: eth.DefaultConfig.TrieTimeout,
+		StateHistory:  ctx.GlobalUint64(StateHistoryFlag.Name),
+	}
+	chain, err = core.NewBlockChainWithCache(chainDb, cache, config, engine, vmcfg, nil)
 	if err != nil {
 		Fatalf("Can't create BlockChain: %v", err)
 	}
@@ -0,0 +1,119 @@
+package pluto
+
+import (
+	"errors"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/event"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/pos/posdb"
+	"github.com/wanchain/go-wanchain/pos/slotleader"
+	"github.com/wanchain/go-wanchain/pos/util"
+)
+
+const inmemorySlotHeaders = 4096 // Number of recent slots whose first sealed header is kept in memory
+
+var (
+	// errEquivocationSlot is returned if a header of an equivocation does not
+	// belong to the slot of the evidence.
+	errEquivocationSlot = errors.New("equivocation header of another slot")
+
+	// errEquivocationSigner is returned if a header of an equivocation is not
+	// sealed by the signer of the evidence.
+	errEquivocationSigner = errors.New("equivocation header of another signer")
+)
+
+// EquivocationEvent is posted when new evidence of a slot leader sealing two
+// different blocks for the same slot is stored.
+type EquivocationEvent struct{ Evidence *posdb.Equivocation }
+
+type slotKey struct {
+	epochID uint64
+	slotID  uint64
+}
+
+// observeSeal remembers the first header sealed for a slot and records the
+// evidence when its signer seals another header for the same slot.
+func (c *Pluto) observeSeal(header *types.Header, signer common.Address, epochID, slotID uint64) {
+	key := slotKey{epochID, slotID}
+	cached, ok := c.slotHeaders.Get(key)
+	if !ok {
+		c.slotHeaders.Add(key, header)
+		return
+	}
+	first := cached.(*types.Header)
+	if first.Hash() == header.Hash() {
+		return
+	}
+
+	// the first header may not be sealed by the slot leader when it was
+	// verified without the slot proof, keep the one which is.
+	firstSigner, err := ecrecover(first, c.signatures)
+	if err != nil || firstSigner != signer {
+		if slotleader.GetSlotLeaderSelection().ValidateBody(types.NewBlockWithHeader(header)) == nil {
+			c.slotHeaders.Add(key, header)
+		}
+		return
+	}
+
+	if _, err := c.AddEquivocation(posdb.NewEquivocation(epochID, slotID, signer, first, header)); err != nil {
+		log.Debug("Discarded slot leader equivocation", "epochID", epochID, "slotID", slotID, "signer", signer, "err", err)
+	}
+}
+
+// verifyEquivocation checks that both headers of the evidence are different
+// blocks of the slot, sealed by the signer with a valid slot leader proof.
+func (c *Pluto) verifyEquivocation(ev *posdb.Equivocation) error {
+	if ev.HeaderA == nil || ev.HeaderB == nil || ev.HeaderA.Hash() == ev.HeaderB.Hash() {
+		return errors.New("equivocation needs two different headers")
+	}
+	s := slotleader.GetSlotLeaderSelection()
+	for _, header := range []*types.Header{ev.HeaderA, ev.HeaderB} {
+		if header.Difficulty == nil || len(header.Extra) <= extraSeal {
+			return errUnauthorized
+		}
+		epochID, slotID := util.GetEpochSlotIDFromDifficulty(header.Difficulty)
+		if epochID != ev.EpochId || slotID != ev.SlotId {
+			return errEquivocationSlot
+		}
+		signer, err := ecrecover(header, c.signatures)
+		if err != nil {
+			return err
+		}
+		_, proofMeg, err := s.GetInfoFromHeadExtra(epochID, header.Extra[:len(header.Extra)-extraSeal])
+		if err != nil || len(proofMeg) == 0 {
+			return errUnauthorized
+		}
+		if signer != ev.Signer || crypto.PubkeyToAddress(*proofMeg[0]) != signer {
+			return errEquivocationSigner
+		}
+		if err := s.ValidateBody(types.NewBlockWithHeader(header)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddEquivocation verifies and stores evidence found locally or received from
+// a peer. It reports whether the evidence is new, in which case an
+// EquivocationEvent is posted.
+func (c *Pluto) AddEquivocation(ev *posdb.Equivocation) (bool, error) {
+	if err := c.verifyEquivocation(ev); err != nil {
+		return false, err
+	}
+	isNew, err := posdb.PutEquivocation(ev)
+	if err != nil || !isNew {
+		return false, err
+	}
+	log.Warn("Slot leader sealed two blocks for the same slot", "epochID", ev.EpochId, "slotID", ev.SlotId,
+		"signer", ev.Signer, "hashA", ev.HeaderA.Hash(), "hashB", ev.HeaderB.Hash())
+	c.equivocationFeed.Send(EquivocationEvent{Evidence: ev})
+	return true, nil
+}
+
+// SubscribeEquivocationEvent registers a subscription of EquivocationEvent.
+func (c *Pluto) SubscribeEquivocationEvent(ch chan<- EquivocationEvent) event.Subscription {
+	return c.scope.Track(c.equivocationFeed.Subscribe(ch))
+}
@@ -43,6 +43,7 @@ import (
 	"github.com/wanchain/go-wanchain/crypto"
 	"github.com/wanchain/go-wanchain/crypto/sha3"
 	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/event"
 	"github.com/wanchain/go-wanchain/log"
 	"github.com/wanchain/go-wanchain/params"
 	"github.com/wanchain/go-wanchain/pos/incentive"
@@ -229,6 +230,10 @@ type Pluto struct {
 	lock   sync.RWMutex   // Protects the signer fields
 
 	key *keystore.Key // Unlocked key
+
+	slotHeaders      *lru.ARCCache // First sealed header of recent slots to detect equivocations
+	equivocationFeed event.Feed
+	scope            event.SubscriptionScope
 }
 
 // New creates a Pluto proof-of-authority consensus engine with the initial
@@ -242,13 +247,15 @@ func New(config *params.PlutoConfig, db ethdb.Database) *Pluto {
 	// Allocate the snapshot caches and create the engine
 	recents, _ := lru.NewARC(inmemorySnapshots)
 	signatures, _ := lru.NewARC(inmemorySignatures)
+	slotHeaders, _ := lru.NewARC(inmemorySlotHeaders)
 
 	return &Pluto{
-		config:     &conf,
-		db:         db,
-		recents:    recents,
-		signatures: signatures,
-		proposals:  make(map[common.Address]bool),
+		config:      &conf,
+		db:          db,
+		recents:     recents,
+		signatures:  signatures,
+		proposals:   make(map[common.Address]bool),
+		slotHeaders: slotHeaders,
 	}
 }
 
@@ -296,7 +303,7 @@ func (c *Pluto) verifyHeader(chain consensus.ChainReader, header *types.Header,
 	//number := header.Number.Uint64()
 
 	// Don't waste time checking blocks from the future
-	if header.Time.Cmp(big.NewInt(time.Now().Unix())) > 0 {
+	if header.Time.Cmp(big.NewInt(posUtil.Now().Unix())) > 0 {
 		return consensus.ErrFutureBlock
 	}
 
@@ -634,6 +641,8 @@ func (c *Pluto) verifySeal(chain consensus.ChainReader, header *types.Header, pa
 				}
 			}
 
+			c.observeSeal(header, signer, epochID, slotID)
+
 			log.Debug("end c *Pluto ValidateBody")
 		}
 	}
@@ -781,6 +790,12 @@ func (c *Pluto) Finalize(chain consensus.ChainReader, header *types.Header, stat
 	if chain.Config().ChainId.Int64() == params.TestnetChainId && header.Number.Uint64() == posconfig.TestnetAdditionalBlock   {
 		log.Info("Finalize testnet", "blockNumber", posconfig.TestnetAdditionalBlock)
 		state.AddBalance(posconfig.PosOwnerAddrTestnet, posconfig.TestnetAdditionalValue)
+		state.AddSystemTransfer(&types.SystemTransfer{
+			Reason:    types.SystemTransferTestnetGrant,
+			Recipient: posconfig.PosOwnerAddrTestnet,
+			Epoch:     epochID,
+			Amount:    new(big.Int).Set(posconfig.TestnetAdditionalValue),
+		})
 		epochLeader.CleanInactiveValidator(state, epochID)
 		//epochLeader.ListValidator(state)
 	}
@@ -0,0 +1,62 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+// Package pos contains the Go bindings of the system precompiles of the pos
+// stage: the staking contract, the random beacon, the slot leader selection
+// and the pos control contract.
+//
+// The ABIs in the abi directory are the definitions the precompiles of core/vm
+// are built from. The precompiles have no code, so transactions sent through
+// the bindings must be pos transactions, see TransactOpts.
+package pos
+
+//go:generate abigen --abi abi/staking.abi --pkg pos --type Staking --out staking.go
+//go:generate abigen --abi abi/randombeacon.abi --pkg pos --type RandomBeacon --out randombeacon.go
+//go:generate abigen --abi abi/slotleader.abi --pkg pos --type SlotLeader --out slotleader.go
+//go:generate abigen --abi abi/poscontrol.abi --pkg pos --type PosControl --out poscontrol.go
+
+import (
+	"github.com/wanchain/go-wanchain/accounts/abi/bind"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+)
+
+// Addresses of the system precompiles.
+var (
+	StakingAddress      = vm.WanCscPrecompileAddr
+	RandomBeaconAddress = vm.RandomBeaconPrecompileAddr
+	SlotLeaderAddress   = vm.SlotLeaderPrecompileAddr
+	PosControlAddress   = vm.PosControlPrecompileAddr
+)
+
+// TransactOpts returns a copy of opts which sends pos transactions.
+func TransactOpts(opts *bind.TransactOpts) *bind.TransactOpts {
+	posOpts := *opts
+	posOpts.Txtype = types.POS_TX
+	return &posOpts
+}
+
+// BindStaking binds the staking precompile.
+func BindStaking(backend bind.ContractBackend) (*Staking, error) {
+	return NewStaking(StakingAddress, backend)
+}
+
+// BindRandomBeacon binds the random beacon precompile.
+func BindRandomBeacon(backend bind.ContractBackend) (*RandomBeacon, error) {
+	return NewRandomBeacon(RandomBeaconAddress, backend)
+}
+
+// BindSlotLeader binds the slot leader selection precompile.
+func BindSlotLeader(backend bind.ContractBackend) (*SlotLeader, error) {
+	return NewSlotLeader(SlotLeaderAddress, backend)
+}
+
+// BindPosControl binds the pos control precompile.
+func BindPosControl(backend bind.ContractBackend) (*PosControl, error) {
+	return NewPosControl(PosControlAddress, backend)
+}
+
+// IsSystemAddress tells whether addr is one of the system precompiles.
+func IsSystemAddress(addr common.Address) bool {
+	return addr == StakingAddress || addr == RandomBeaconAddress || addr == SlotLeaderAddress || addr == PosControlAddress
+}
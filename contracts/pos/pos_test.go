@@ -0,0 +1,114 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package pos
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain"
+	"github.com/wanchain/go-wanchain/accounts/abi/bind"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
+)
+
+// testBackend is a chain without any contract code, like the one the system
+// precompiles live on.
+type testBackend struct {
+	estimated []ethereum.CallMsg
+	sent      []*types.Transaction
+}
+
+func (b *testBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (b *testBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (b *testBackend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return nil, nil
+}
+
+func (b *testBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return uint64(len(b.sent)), nil
+}
+
+func (b *testBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(180e9), nil
+}
+
+func (b *testBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (*big.Int, error) {
+	b.estimated = append(b.estimated, call)
+	return big.NewInt(200000), nil
+}
+
+func (b *testBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func TestStakingTransact(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	backend := new(testBackend)
+	staking, err := BindStaking(backend)
+	if err != nil {
+		t.Fatalf("failed to bind staking: %v", err)
+	}
+	opts := bind.NewKeyedTransactor(key)
+	opts.Value = big.NewInt(1e18)
+
+	// A normal transaction needs code to estimate its gas against
+	validator := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	if _, err := staking.DelegateIn(opts, validator); err != bind.ErrNoCode {
+		t.Fatalf("normal transaction error mismatch: have %v, want %v", err, bind.ErrNoCode)
+	}
+	tx, err := staking.DelegateIn(TransactOpts(opts), validator)
+	if err != nil {
+		t.Fatalf("failed to delegate: %v", err)
+	}
+	if opts.Txtype != 0 {
+		t.Errorf("pos options modified the original ones")
+	}
+	if tx.Txtype() != types.POS_TX || *tx.To() != StakingAddress || tx.Value().Cmp(opts.Value) != 0 {
+		t.Errorf("transaction mismatch: have type %d to %x value %v", tx.Txtype(), tx.To(), tx.Value())
+	}
+	if len(backend.estimated) != 1 || backend.estimated[0].TxType != types.POS_TX {
+		t.Errorf("gas estimation mismatch: have %+v", backend.estimated)
+	}
+	from, err := types.Sender(types.HomesteadSigner{}, tx)
+	if err != nil || from != opts.From {
+		t.Errorf("sender mismatch: have %x, %v, want %x", from, err, opts.From)
+	}
+	want, _ := vm.PackStakingInput("delegateIn", validator)
+	if !bytes.Equal(tx.Data(), want) {
+		t.Errorf("input mismatch: have %x, want %x", tx.Data(), want)
+	}
+
+	// The staking precompile decodes what the binding packs
+	secPk, bn256Pk := bytes.Repeat([]byte{4}, 65), bytes.Repeat([]byte{1}, 64)
+	tx, err = staking.StakeIn(TransactOpts(opts), secPk, bn256Pk, big.NewInt(10), big.NewInt(1500))
+	if err != nil {
+		t.Fatalf("failed to stake in: %v", err)
+	}
+	want, _ = vm.PackStakingInput("stakeIn", secPk, bn256Pk, big.NewInt(10), big.NewInt(1500))
+	if !bytes.Equal(tx.Data(), want) || tx.Nonce() != 1 {
+		t.Errorf("stake in mismatch: have %x nonce %d, want %x nonce 1", tx.Data(), tx.Nonce(), want)
+	}
+}
+
+func TestSystemAddress(t *testing.T) {
+	for _, addr := range []common.Address{StakingAddress, RandomBeaconAddress, SlotLeaderAddress, PosControlAddress} {
+		if _, ok := vm.PrecompiledContractsByzantium[addr]; !ok || !IsSystemAddress(addr) {
+			t.Errorf("%x is not a system precompile", addr)
+		}
+	}
+	if IsSystemAddress(vm.IncentivePrecompileAddr) {
+		t.Errorf("incentive address is bound")
+	}
+}
@@ -84,7 +84,7 @@ func genValueTx(nbytes int) func(int, *BlockGen) {
 	return func(i int, gen *BlockGen) {
 		toaddr := common.BytesToAddress(big.NewInt(123456789099999).Bytes())
 		data := make([]byte, nbytes)
-		gas := IntrinsicGas(data, &toaddr, false)
+		gas := IntrinsicGas(data, &toaddr, false, false)
 		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(benchRootAddr), toaddr, big.NewInt(1), gas, nil, data), types.HomesteadSigner{}, benchRootKey)
 		gen.AddTx(tx)
 	}
@@ -30,6 +30,7 @@ import (
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
+	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/common/mclock"
 	"github.com/wanchain/go-wanchain/consensus"
@@ -71,6 +72,23 @@ const (
 	INITRESTARTING = 0
 )
 
+// DefaultStateHistory returns the number of recent block states a pruning node
+// of the chain keeps. Reorgs deeper than the chain's K blocks are refused, so
+// the state of any block a reorg can rewind to is kept.
+func DefaultStateHistory(config *params.ChainConfig) uint64 {
+	_, k := posconfig.PlutoSchedule(config.Pluto)
+	return k + 128
+}
+
+// CacheConfig contains the configuration values for the trie caching and
+// pruning of the block chain.
+type CacheConfig struct {
+	Disabled      bool          // Whether to disable trie write caching, keeping every state (archive node)
+	TrieNodeLimit int           // Memory limit (MB) at which to flush the oldest kept state to disk, zero for none
+	TrieTimeLimit time.Duration // Time limit after which to flush the oldest kept state to disk, zero for none
+	StateHistory  uint64        // Number of recent block states kept, must exceed the reorg depth, zero for the chain default
+}
+
 // BlockChain represents the canonical chain given a database with a genesis
 // block. The Blockchain manages chain imports, reverts, chain reorganisations.
 //
@@ -90,6 +108,10 @@ type BlockChain struct {
 
 	hc            *HeaderChain
 	chainDb       ethdb.Database
+	cacheConfig   *CacheConfig    // Trie caching and pruning configuration
+	triedb        *trie.NodeCache // Trie node cache of the recent states, nil when archiving
+	triegc        *prque.Prque    // Cached state roots by block number, dereferenced once too old
+	trieFlushed   time.Time       // Time the oldest kept state was last flushed to disk
 	rmLogsFeed    event.Feed
 	chainFeed     event.Feed
 	chainSideFeed event.Feed
@@ -148,6 +170,18 @@ type BlockChain struct {
 // Processor.
 //func NewBlockChain(chainDb ethdb.Database, config *params.ChainConfig, engine consensus.Engine, vmConfig vm.Config, posEngine consensus.Engine) (*BlockChain, error) {
 func NewBlockChain(chainDb ethdb.Database, config *params.ChainConfig, engine consensus.Engine, vmConfig vm.Config, posEngines ...consensus.Engine) (*BlockChain, error) {
+	return NewBlockChainWithCache(chainDb, nil, config, engine, vmConfig, posEngines...)
+}
+
+// NewBlockChainWithCache returns a fully initialised block chain whose states
+// are cached and pruned as configured. A nil cache config keeps every state.
+func NewBlockChainWithCache(chainDb ethdb.Database, cacheConfig *CacheConfig, config *params.ChainConfig, engine consensus.Engine, vmConfig vm.Config, posEngines ...consensus.Engine) (*BlockChain, error) {
+	if cacheConfig == nil {
+		cacheConfig = &CacheConfig{Disabled: true}
+	}
+	if cacheConfig.StateHistory == 0 {
+		cacheConfig.StateHistory = DefaultStateHistory(config)
+	}
 	bodyCache, _ := lru.New(bodyCacheLimit)
 	bodyRLPCache, _ := lru.New(bodyCacheLimit)
 	blockCache, _ := lru.New(blockCacheLimit)
@@ -157,7 +191,9 @@ func NewBlockChain(chainDb ethdb.Database, config *params.ChainConfig, engine co
 	bc := &BlockChain{
 		config:       config,
 		chainDb:      chainDb,
-		stateCache:   state.NewDatabase(chainDb),
+		cacheConfig:  cacheConfig,
+		triegc:       prque.New(),
+		trieFlushed:  time.Now(),
 		quit:         make(chan struct{}),
 		bodyCache:    bodyCache,
 		bodyRLPCache: bodyRLPCache,
@@ -173,12 +209,19 @@ func NewBlockChain(chainDb ethdb.Database, config *params.ChainConfig, engine co
 
 
 
-	c, e := lru.NewARC(posconfig.SlotSecurityParam)
+	c, e := lru.NewARC(int(posconfig.SlotSecurityParam))
 	if e != nil || c == nil {
 		panic("failed to create chain quality cache")
 	}
 	bc.cqCache = c
 
+	if cacheConfig.Disabled {
+		bc.stateCache = state.NewDatabase(chainDb)
+	} else {
+		bc.triedb = state.NewNodeCache(chainDb)
+		bc.stateCache = state.NewTrieDatabase(bc.triedb)
+	}
+
 	if len(posEngines) > 0 {
 		bc.posEngine = posEngines[0]
 	} else {
@@ -237,7 +280,7 @@ func NewBlockChain(chainDb ethdb.Database, config *params.ChainConfig, engine co
 	// Take ownership of this particular state
 
 
-	epid, slid := posUtil.CalEpochSlotID(uint64(time.Now().Unix()))
+	epid, slid := posUtil.CalEpochSlotID(uint64(posUtil.Now().Unix()))
 	//record the restarting slot point
 	bc.checkCQStartSlot = epid*posconfig.SlotCount + slid
 
@@ -283,9 +326,12 @@ func (bc *BlockChain) loadLastState() error {
 	}
 	// Make sure the state associated with the block is available
 	if _, err := state.New(currentBlock.Root(), bc.stateCache); err != nil {
-		// Dangling block without a state associated, init from scratch
-		log.Warn("Head state missing, resetting chain", "number", currentBlock.Number(), "hash", currentBlock.Hash())
-		return bc.Reset()
+		// Dangling block without a state associated, e.g. the cached states of a
+		// pruning node were lost, rewind to the last block with one
+		log.Warn("Head state missing, repairing chain", "number", currentBlock.Number(), "hash", currentBlock.Hash())
+		if err := bc.repair(&currentBlock); err != nil {
+			return err
+		}
 	}
 	// Everything seems to be fine, set as the head block
 	bc.currentBlock = currentBlock
@@ -319,6 +365,23 @@ func (bc *BlockChain) loadLastState() error {
 	return nil
 }
 
+// repair rewinds the given head block to its newest ancestor whose state is
+// available.
+func (bc *BlockChain) repair(head **types.Block) error {
+	from := (*head).NumberU64()
+	for {
+		if _, err := state.New((*head).Root(), bc.stateCache); err == nil {
+			log.Warn("Rewound blockchain to past state, the blocks above it are processed again", "from", from, "to", (*head).Number(), "blocks", from-(*head).NumberU64(), "hash", (*head).Hash())
+			return nil
+		}
+		block := bc.GetBlock((*head).ParentHash(), (*head).NumberU64()-1)
+		if block == nil {
+			return fmt.Errorf("missing block %d [%x…]", (*head).NumberU64()-1, (*head).ParentHash().Bytes()[:4])
+		}
+		*head = block
+	}
+}
+
 // SetHead rewinds the local chain to a new head. In the case of headers, everything
 // above the new head will be deleted and the new one set. In the case of blocks
 // though, the head may be further rewound if block bodies are missing (non-archive
@@ -380,7 +443,7 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	if block == nil {
 		return fmt.Errorf("non existent block [%x…]", hash[:4])
 	}
-	if _, err := trie.NewSecure(block.Root(), bc.chainDb, 0); err != nil {
+	if _, err := trie.NewSecure(block.Root(), bc.TrieDB(), 0); err != nil {
 		return err
 	}
 	// If all checks out, manually set the head block
@@ -474,6 +537,15 @@ func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
 	return state.New(root, bc.stateCache)
 }
 
+// TrieDB returns the database the state tries are read from, which holds the
+// recent states of a pruning node in memory.
+func (bc *BlockChain) TrieDB() trie.Database {
+	if bc.triedb != nil {
+		return bc.triedb
+	}
+	return bc.chainDb
+}
+
 // Reset purges the entire blockchain, restoring it to its genesis state.
 func (bc *BlockChain) Reset() error {
 	return bc.ResetWithGenesisBlock(bc.genesisBlock)
@@ -695,6 +767,22 @@ func (bc *BlockChain) Stop() {
 	atomic.StoreInt32(&bc.procInterrupt, 1)
 
 	bc.wg.Wait()
+
+	// Persist the cached head state, and the oldest one kept for reorgs
+	if bc.triedb != nil {
+		head := bc.CurrentBlock().NumberU64()
+		for _, offset := range []uint64{0, bc.cacheConfig.StateHistory - 1} {
+			if head < offset {
+				break
+			}
+			if recent := bc.GetBlockByNumber(head - offset); recent != nil {
+				log.Info("Writing cached state to database", "block", recent.Number(), "hash", recent.Hash(), "root", recent.Root())
+				if err := bc.triedb.Commit(recent.Root()); err != nil {
+					log.Error("Failed to commit recent state trie", "err", err)
+				}
+			}
+		}
+	}
 	log.Info("Blockchain manager stopped")
 }
 
@@ -934,7 +1022,7 @@ func (bc *BlockChain) isWriteBlockSecure(block *types.Block) bool {
 	endFlatSlotId := epochId*posconfig.SlotCount + slotId
 	startId := endFlatSlotId - posconfig.SlotSecurityParam -1
 
-	if  bc.cqCache.Len() > posconfig.BlockSecurityParam{
+	if  uint64(bc.cqCache.Len()) > posconfig.BlockSecurityParam{
 
 		if startId > bc.cqLastSlot {
 			bc.cqCache.Purge()
@@ -947,7 +1035,7 @@ func (bc *BlockChain) isWriteBlockSecure(block *types.Block) bool {
 
 		blocksIn2K := bc.cqCache.Len()
 
-		return  blocksIn2K > posconfig.K
+		return  uint64(blocksIn2K) > posconfig.K
 	}
 
 	return false
@@ -991,7 +1079,7 @@ func (bc *BlockChain) ChainQuality(epochid uint64, slotid uint64) (uint64, error
 		flatSlotId := epochid*posconfig.SlotCount + slotid
 
 		cacheBeginId :=  bc.cqLastSlot - posconfig.SlotSecurityParam
-		if flatSlotId <= bc.cqLastSlot && flatSlotId > cacheBeginId && bc.cqCache.Len() > posconfig.BlockSecurityParam {
+		if flatSlotId <= bc.cqLastSlot && flatSlotId > cacheBeginId && uint64(bc.cqCache.Len()) > posconfig.BlockSecurityParam {
 
 			for ;flatSlotId > cacheBeginId;flatSlotId-- {
 				blks,ok := bc.cqCache.Get(flatSlotId)
@@ -1005,7 +1093,7 @@ func (bc *BlockChain) ChainQuality(epochid uint64, slotid uint64) (uint64, error
 			blocksIn2K = bc.getBlocksCountIn2KSlots(curBlk, posconfig.SlotSecurityParam-diff)
 		}
 
-		quality := blocksIn2K * 1000 / (posconfig.SlotSecurityParam)
+		quality := blocksIn2K * 1000 / int(posconfig.SlotSecurityParam)
 
 		return uint64(quality), nil
 	}
@@ -1015,7 +1103,7 @@ func (bc *BlockChain) ChainQuality(epochid uint64, slotid uint64) (uint64, error
 func (bc *BlockChain) biggerThanCriticalBlock(block *types.Block) bool{
 
 	diff := int(posconfig.Cfg().SyncTargetBlokcNum - block.NumberU64())
-	if diff >  2*posconfig.SlotSecurityParam{
+	if diff >  2*int(posconfig.SlotSecurityParam){
 		return false
 	} else {
 		return true
@@ -1081,12 +1169,26 @@ func (bc *BlockChain) WriteBlockAndState(block *types.Block, receipts []*types.R
 		return NonStatTy, err
 	}
 
-	if _, err := state.CommitTo(batch, true /*bc.config.IsEIP158(block.Number())*/); err != nil {
-		return NonStatTy, err
+	if bc.triedb == nil {
+		if _, err := state.CommitTo(batch, true /*bc.config.IsEIP158(block.Number())*/); err != nil {
+			return NonStatTy, err
+		}
+	} else {
+		root, err := state.CommitTo(bc.triedb, true)
+		if err != nil {
+			return NonStatTy, err
+		}
+		bc.triedb.Reference(root)
+		bc.triegc.Push(root, -float32(block.NumberU64()))
+		bc.pruneTries(block)
+		bc.pinEpochState(block)
 	}
 	if err := WriteBlockReceipts(batch, block.Hash(), block.NumberU64(), receipts); err != nil {
 		return NonStatTy, err
 	}
+	if err := WriteSystemTransfers(batch, block.Hash(), block.NumberU64(), state.SystemTransfers()); err != nil {
+		return NonStatTy, err
+	}
 
 	/// If the total difficulty is higher than our known, add it to the canonical chain
 	/// Second clause in the if statement reduces the vulnerability to selfish mining.
@@ -1154,6 +1256,71 @@ func (bc *BlockChain) WriteBlockAndState(block *types.Block, receipts []*types.R
 	return status, nil
 }
 
+// pruneTries flushes a kept state to disk once the cached tries grow too big or
+// old, and drops the cached states too old to be kept. Until block fills the
+// kept history no state is dropped, so block's own state is flushed instead of
+// the oldest one, bounding the blocks a crash rewinds from the start.
+func (bc *BlockChain) pruneTries(block *types.Block) {
+	var (
+		current = block.NumberU64()
+		history = bc.cacheConfig.StateHistory
+	)
+	_, size := bc.triedb.Size()
+	var (
+		nodeLimit = bc.cacheConfig.TrieNodeLimit > 0 && size > common.StorageSize(bc.cacheConfig.TrieNodeLimit)*1024*1024
+		timeLimit = bc.cacheConfig.TrieTimeLimit > 0 && time.Since(bc.trieFlushed) > bc.cacheConfig.TrieTimeLimit
+	)
+	if nodeLimit || timeLimit {
+		header := block.Header()
+		if current > history {
+			header = bc.GetHeaderByNumber(current - history)
+		}
+		if header == nil {
+			log.Warn("Reorg in progress, trie commit postponed", "number", current-history)
+		} else {
+			if err := bc.triedb.Commit(header.Root); err != nil {
+				log.Error("Failed to commit state trie", "number", header.Number, "err", err)
+			}
+			bc.trieFlushed = time.Now()
+		}
+	}
+	if current <= history {
+		return
+	}
+	chosen := current - history
+	for !bc.triegc.Empty() {
+		root, number := bc.triegc.Pop()
+		if uint64(-number) > chosen {
+			bc.triegc.Push(root, number)
+			break
+		}
+		bc.triedb.Dereference(root.(common.Hash))
+	}
+}
+
+// pinEpochState persists the state of the last block of an epoch once block
+// starts the next one. Leader selection and the incentive read these states
+// epochs after the kept history has dropped them.
+func (bc *BlockChain) pinEpochState(block *types.Block) {
+	if !bc.config.IsPosBlockNumber(block.Number()) {
+		return
+	}
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return
+	}
+	if bc.config.IsPosBlockNumber(parent.Number) {
+		epochID, _ := posUtil.GetEpochSlotIDFromDifficulty(block.Difficulty())
+		parentEpochID, _ := posUtil.GetEpochSlotIDFromDifficulty(parent.Difficulty)
+		if epochID == parentEpochID {
+			return
+		}
+	}
+	if err := bc.triedb.Commit(parent.Root); err != nil {
+		log.Error("Failed to commit epoch state trie", "number", parent.Number, "hash", parent.Hash(), "err", err)
+	}
+}
+
 func (bc *BlockChain) isCurrentLastPPowBlock() bool {
 	num := bc.currentBlock.Number()
 	num = num.Add(num, big.NewInt(1))
@@ -1375,7 +1542,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 				// Allow up to MaxFuture second in the future blocks. If this limit
 				// is exceeded the chain is discarded and processed at a later time
 				// if given.
-				max := big.NewInt(time.Now().Unix() + maxTimeFutureBlocks)
+				max := big.NewInt(posUtil.Now().Unix() + maxTimeFutureBlocks)
 				if block.Time().Cmp(max) > 0 {
 					return i, events, coalescedLogs, fmt.Errorf("future block: %v > %v", block.Time(), max)
 				}
@@ -1921,7 +2088,7 @@ func (bc *BlockChain) SetRestartBlock(block *types.Block, preBlock *types.Block,
 
 	if useLocalTime {
 
-		epid, slid := posUtil.CalEpochSlotID(uint64(time.Now().Unix()))
+		epid, slid := posUtil.CalEpochSlotID(uint64(posUtil.Now().Unix()))
 		//record the restarting slot point
 		bc.checkCQStartSlot = epid*posconfig.SlotCount + slid
 
@@ -1071,6 +1071,172 @@ func TestCanonicalBlockRetrieval(t *testing.T) {
 	pend.Wait()
 }
 
+// Tests that a pruning chain keeps only the states of its recent blocks in
+// memory, persisting the head state on stop, and that a restart after losing
+// the cached states rewinds to the last persisted one.
+func TestTriePruning(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+	)
+	gspec := DefaultPPOWTestingGenesisBlock()
+	gspec.Alloc = GenesisAlloc{addr: {Balance: big.NewInt(1000000)}}
+	signer := types.NewEIP155Signer(gspec.Config.ChainId)
+
+	gendb, _ := ethdb.NewMemDatabase()
+	genesis := gspec.MustCommit(gendb)
+	genengine := ethash.NewFaker(gendb)
+	genchain, _ := NewBlockChain(gendb, gspec.Config, genengine, vm.Config{})
+	defer genchain.Stop()
+	blocks, _ := NewChainEnv(gspec.Config, gspec, genengine, genchain, gendb).GenerateChain(genesis, 12, func(i int, gen *BlockGen) {
+		// Change the state of every block
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), common.Address{byte(i)}, big.NewInt(1000), bigTxGas, nil, nil), signer, key)
+		gen.AddTx(tx)
+	})
+	head := blocks[len(blocks)-1]
+
+	db, _ := ethdb.NewMemDatabase()
+	gspec.MustCommit(db)
+	chain, err := NewBlockChainWithCache(db, &CacheConfig{StateHistory: 4}, gspec.Config, ethash.NewFaker(db), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create pruning chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	for i, block := range blocks {
+		_, err := chain.StateAt(block.Root())
+		if kept := i >= len(blocks)-4; kept && err != nil {
+			t.Errorf("state of block %d missing: %v", block.NumberU64(), err)
+		} else if !kept && err == nil {
+			t.Errorf("state of block %d not pruned", block.NumberU64())
+		}
+		if ok, _ := db.Has(block.Root().Bytes()); ok {
+			t.Errorf("state of block %d written to disk", block.NumberU64())
+		}
+	}
+	// A chain opened while the states are only cached rewinds to genesis
+	crashed, err := NewBlockChainWithCache(db, &CacheConfig{StateHistory: 4}, gspec.Config, ethash.NewFaker(db), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	if number := crashed.CurrentBlock().NumberU64(); number != 0 {
+		t.Errorf("crashed chain head mismatch: have %d, want 0", number)
+	}
+	crashed.Stop()
+
+	chain.Stop()
+	if ok, _ := db.Has(head.Root().Bytes()); !ok {
+		t.Fatalf("head state not written to disk on stop")
+	}
+	restarted, err := NewBlockChainWithCache(db, &CacheConfig{StateHistory: 4}, gspec.Config, ethash.NewFaker(db), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	defer restarted.Stop()
+	if restarted.CurrentBlock().Hash() != head.Hash() {
+		t.Errorf("restarted chain head mismatch: have %d, want %d", restarted.CurrentBlock().NumberU64(), head.NumberU64())
+	}
+}
+
+// Tests that a pruning chain flushes its kept states to disk on its time limit,
+// so that a restart after losing the cached states rewinds only the kept history.
+func TestTriePruningFlush(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+	)
+	gspec := DefaultPPOWTestingGenesisBlock()
+	gspec.Alloc = GenesisAlloc{addr: {Balance: big.NewInt(1000000)}}
+	signer := types.NewEIP155Signer(gspec.Config.ChainId)
+
+	gendb, _ := ethdb.NewMemDatabase()
+	genesis := gspec.MustCommit(gendb)
+	genengine := ethash.NewFaker(gendb)
+	genchain, _ := NewBlockChain(gendb, gspec.Config, genengine, vm.Config{})
+	defer genchain.Stop()
+	blocks, _ := NewChainEnv(gspec.Config, gspec, genengine, genchain, gendb).GenerateChain(genesis, 12, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), common.Address{byte(i)}, big.NewInt(1000), bigTxGas, nil, nil), signer, key)
+		gen.AddTx(tx)
+	})
+
+	db, _ := ethdb.NewMemDatabase()
+	gspec.MustCommit(db)
+	cache := &CacheConfig{StateHistory: 4, TrieTimeLimit: time.Nanosecond}
+	chain, err := NewBlockChainWithCache(db, cache, gspec.Config, ethash.NewFaker(db), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create pruning chain: %v", err)
+	}
+	defer chain.Stop()
+	// Flush the first blocks before the history fills up
+	if _, err := chain.InsertChain(blocks[:2]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if ok, _ := db.Has(blocks[1].Root().Bytes()); !ok {
+		t.Errorf("state of block %d not flushed", blocks[1].NumberU64())
+	}
+	if _, err := chain.InsertChain(blocks[2:]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	crashed, err := NewBlockChainWithCache(db, &CacheConfig{StateHistory: 4}, gspec.Config, ethash.NewFaker(db), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	defer crashed.Stop()
+	if have, want := crashed.CurrentBlock().NumberU64(), blocks[len(blocks)-5].NumberU64(); have != want {
+		t.Errorf("crashed chain head mismatch: have %d, want %d", have, want)
+	}
+}
+
+func TestEpochStatePinning(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	gspec := DefaultPPOWTestingGenesisBlock()
+	config := *gspec.Config
+	config.PosFirstBlock = big.NewInt(1)
+	gspec.Config = &config
+	genesis := gspec.MustCommit(db)
+
+	chain, err := NewBlockChainWithCache(db, &CacheConfig{StateHistory: 4}, gspec.Config, ethash.NewFaker(db), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create pruning chain: %v", err)
+	}
+	defer chain.Stop()
+
+	// Every header gets a cached state of its own, in epochs 5, 5 and 6
+	var (
+		parent  = genesis.Header()
+		headers []*types.Header
+	)
+	for i, epochID := range []uint64{5, 5, 6} {
+		statedb, _ := state.New(common.Hash{}, chain.stateCache)
+		statedb.AddBalance(common.Address{byte(i + 1)}, big.NewInt(int64(i+1)))
+		root, err := statedb.CommitTo(chain.triedb, true)
+		if err != nil {
+			t.Fatalf("failed to commit state: %v", err)
+		}
+		chain.triedb.Reference(root)
+
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			Difficulty: new(big.Int).SetUint64(epochID<<32 | uint64(i+1)<<8),
+			Root:       root,
+		}
+		WriteHeader(db, header)
+		headers, parent = append(headers, header), header
+	}
+	for _, header := range headers {
+		chain.pinEpochState(types.NewBlockWithHeader(header))
+	}
+	// Only the last state of epoch 5, which the leader selection of epoch 7
+	// reads, is written to disk
+	for i, pinned := range []bool{false, true, false} {
+		if ok, _ := db.Has(headers[i].Root.Bytes()); ok != pinned {
+			t.Errorf("state of block %d pinned mismatch: have %v, want %v", headers[i].Number, ok, pinned)
+		}
+	}
+}
+
 
 //func TestEIP155Transition(t *testing.T) {
 //	// Configure and generate a sample block chain
@@ -49,14 +49,15 @@ var (
 	headFastKey   = []byte("LastFast")
 
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`).
-	headerPrefix        = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
-	tdSuffix            = []byte("t") // headerPrefix + num (uint64 big endian) + hash + tdSuffix -> td
-	numSuffix           = []byte("n") // headerPrefix + num (uint64 big endian) + numSuffix -> hash
-	blockHashPrefix     = []byte("H") // blockHashPrefix + hash -> num (uint64 big endian)
-	bodyPrefix          = []byte("b") // bodyPrefix + num (uint64 big endian) + hash -> block body
-	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
-	lookupPrefix        = []byte("l") // lookupPrefix + hash -> transaction/receipt lookup metadata
-	bloomBitsPrefix     = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
+	headerPrefix         = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
+	tdSuffix             = []byte("t") // headerPrefix + num (uint64 big endian) + hash + tdSuffix -> td
+	numSuffix            = []byte("n") // headerPrefix + num (uint64 big endian) + numSuffix -> hash
+	blockHashPrefix      = []byte("H") // blockHashPrefix + hash -> num (uint64 big endian)
+	bodyPrefix           = []byte("b") // bodyPrefix + num (uint64 big endian) + hash -> block body
+	blockReceiptsPrefix  = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
+	systemTransferPrefix = []byte("S") // systemTransferPrefix + num (uint64 big endian) + hash -> block system transfers
+	lookupPrefix         = []byte("l") // lookupPrefix + hash -> transaction/receipt lookup metadata
+	bloomBitsPrefix      = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
 
 	preimagePrefix = "secure-key-"              // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
@@ -251,6 +252,26 @@ func GetBlockReceipts(db DatabaseReader, hash common.Hash, number uint64) types.
 	return receipts
 }
 
+// GetSystemTransfers retrieves the balance changes done by the consensus engine
+// while the block was finalized, with their derived fields filled in.
+func GetSystemTransfers(db DatabaseReader, hash common.Hash, number uint64) []*types.SystemTransfer {
+	data, _ := db.Get(append(append(systemTransferPrefix, encodeBlockNumber(number)...), hash[:]...))
+	if len(data) == 0 {
+		return nil
+	}
+	transfers := []*types.SystemTransfer{}
+	if err := rlp.DecodeBytes(data, &transfers); err != nil {
+		log.Error("Invalid system transfer array RLP", "hash", hash, "err", err)
+		return nil
+	}
+	for i, transfer := range transfers {
+		transfer.BlockNumber = number
+		transfer.BlockHash = hash
+		transfer.Index = uint(i)
+	}
+	return transfers
+}
+
 // GetTxLookupEntry retrieves the positional metadata associated with a transaction
 // hash to allow retrieving the transaction or receipt by hash.
 func GetTxLookupEntry(db DatabaseReader, hash common.Hash) (common.Hash, uint64, uint64) {
@@ -460,6 +481,23 @@ func WriteBlockReceipts(db ethdb.Putter, hash common.Hash, number uint64, receip
 	return nil
 }
 
+// WriteSystemTransfers stores the balance changes done by the consensus engine
+// while the block was finalized. Nothing is stored for a block without any.
+func WriteSystemTransfers(db ethdb.Putter, hash common.Hash, number uint64, transfers []*types.SystemTransfer) error {
+	if len(transfers) == 0 {
+		return nil
+	}
+	bytes, err := rlp.EncodeToBytes(transfers)
+	if err != nil {
+		return err
+	}
+	key := append(append(systemTransferPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+	if err := db.Put(key, bytes); err != nil {
+		log.Crit("Failed to store system transfers", "err", err)
+	}
+	return nil
+}
+
 // WriteTxLookupEntries stores a positional metadata for every transaction from
 // a block, enabling hash based transaction and receipt lookups.
 func WriteTxLookupEntries(db ethdb.Putter, block *types.Block) error {
@@ -518,6 +556,7 @@ func DeleteTd(db DatabaseDeleter, hash common.Hash, number uint64) {
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db DatabaseDeleter, hash common.Hash, number uint64) {
 	DeleteBlockReceipts(db, hash, number)
+	DeleteSystemTransfers(db, hash, number)
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
@@ -528,6 +567,11 @@ func DeleteBlockReceipts(db DatabaseDeleter, hash common.Hash, number uint64) {
 	db.Delete(append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
 }
 
+// DeleteSystemTransfers removes the system transfers associated with a block hash.
+func DeleteSystemTransfers(db DatabaseDeleter, hash common.Hash, number uint64) {
+	db.Delete(append(append(systemTransferPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
+}
+
 // DeleteTxLookupEntry removes all transaction data associated with a hash.
 func DeleteTxLookupEntry(db DatabaseDeleter, hash common.Hash) {
 	db.Delete(append(lookupPrefix, hash.Bytes()...))
@@ -20,6 +20,7 @@ package core
 import (
 	"bytes"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/wanchain/go-wanchain/common"
@@ -387,3 +388,49 @@ func TestBlockReceiptStorage(t *testing.T) {
 		t.Fatalf("deleted receipts returned: %v", rs)
 	}
 }
+
+// Tests that the system transfers of a block can be stored and retrieved.
+func TestSystemTransferStorage(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+
+	transfers := []*types.SystemTransfer{
+		{
+			Reason:    types.SystemTransferIncentive,
+			Recipient: common.BytesToAddress([]byte{0x11}),
+			Validator: common.BytesToAddress([]byte{0x01, 0x11}),
+			Epoch:     18000,
+			Amount:    big.NewInt(111),
+		},
+		{
+			Reason:    types.SystemTransferStakeOut,
+			From:      common.BytesToAddress([]byte{0xda}),
+			Recipient: common.BytesToAddress([]byte{0x22}),
+			Validator: common.BytesToAddress([]byte{0x02, 0x22}),
+			Epoch:     18001,
+			Amount:    big.NewInt(222),
+		},
+	}
+
+	hash := common.BytesToHash([]byte{0x03, 0x14})
+	if ts := GetSystemTransfers(db, hash, 7); len(ts) != 0 {
+		t.Fatalf("non existent system transfers returned: %v", ts)
+	}
+	if err := WriteSystemTransfers(db, hash, 7, transfers); err != nil {
+		t.Fatalf("failed to write system transfers: %v", err)
+	}
+	ts := GetSystemTransfers(db, hash, 7)
+	if len(ts) != len(transfers) {
+		t.Fatalf("system transfer count mismatch: have %d, want %d", len(ts), len(transfers))
+	}
+	for i, have := range ts {
+		want := *transfers[i]
+		want.BlockNumber, want.BlockHash, want.Index = 7, hash, uint(i)
+		if !reflect.DeepEqual(*have, want) {
+			t.Fatalf("system transfer #%d mismatch: have %+v, want %+v", i, *have, want)
+		}
+	}
+	DeleteSystemTransfers(db, hash, 7)
+	if ts := GetSystemTransfers(db, hash, 7); len(ts) != 0 {
+		t.Fatalf("deleted system transfers returned: %v", ts)
+	}
+}
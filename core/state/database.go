@@ -23,6 +23,7 @@ import (
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/rlp"
 	"github.com/wanchain/go-wanchain/trie"
 )
 
@@ -66,12 +67,35 @@ type Trie interface {
 // NewDatabase creates a backing store for state. The returned database is safe for
 // concurrent use and retains cached trie nodes in memory.
 func NewDatabase(db ethdb.Database) Database {
+	return NewTrieDatabase(db)
+}
+
+// NewTrieDatabase creates a backing store for state reading tries and code
+// from the given trie database, e.g. the trie node cache of a pruning node.
+func NewTrieDatabase(db trie.Database) Database {
 	csc, _ := lru.New(codeSizeCacheSize)
 	return &cachingDB{db: db, codeSizeCache: csc}
 }
 
+// NewNodeCache creates a trie node cache for state tries on top of the given
+// database, keeping the storage tries and the code of the cached accounts.
+func NewNodeCache(db ethdb.Database) *trie.NodeCache {
+	return trie.NewNodeCache(db, accountRefs)
+}
+
+// accountRefs returns the storage trie root and the code hash a state trie
+// leaf refers to. Storage trie leaves do not decode as accounts, the empty
+// root and code are never cached so they are not referenced either.
+func accountRefs(leaf []byte) []common.Hash {
+	var account Account
+	if err := rlp.DecodeBytes(leaf, &account); err != nil {
+		return nil
+	}
+	return []common.Hash{account.Root, common.BytesToHash(account.CodeHash)}
+}
+
 type cachingDB struct {
-	db            ethdb.Database
+	db            trie.Database
 	mu            sync.Mutex
 	pastTries     []*trie.SecureTrie
 	codeSizeCache *lru.Cache
@@ -83,6 +107,10 @@ func (db *cachingDB) OpenTrie(root common.Hash) (Trie, error) {
 
 	for i := len(db.pastTries) - 1; i >= 0; i-- {
 		if db.pastTries[i].Hash() == root {
+			// The nodes of a pruned trie are gone, its clean nodes must not be reused
+			if ok, _ := db.db.Has(root[:]); !ok {
+				break
+			}
 			return cachedTrie{db.pastTries[i].Copy(), db}, nil
 		}
 	}
@@ -82,6 +82,7 @@ type (
 		prev      bool
 		prevDirty bool
 	}
+	addSystemTransferChange struct{}
 )
 
 func (ch createObjectChange) undo(s *StateDB) {
@@ -136,6 +137,10 @@ func (ch refundChange) undo(s *StateDB) {
 	s.refund = ch.prev
 }
 
+func (ch addSystemTransferChange) undo(s *StateDB) {
+	s.systemTransfers = s.systemTransfers[:len(s.systemTransfers)-1]
+}
+
 func (ch addLogChange) undo(s *StateDB) {
 	logs := s.logs[ch.txhash]
 	if len(logs) == 1 {
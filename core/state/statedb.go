@@ -71,6 +71,8 @@ type StateDB struct {
 	logs         map[common.Hash][]*types.Log
 	logSize      uint
 
+	systemTransfers []*types.SystemTransfer
+
 	preimages map[common.Hash][]byte
 
 	// Journal of state modifications. This is the backbone of
@@ -125,6 +127,7 @@ func (self *StateDB) Reset(root common.Hash) error {
 	self.txIndex = 0
 	self.logs = make(map[common.Hash][]*types.Log)
 	self.logSize = 0
+	self.systemTransfers = nil
 	self.preimages = make(map[common.Hash][]byte)
 	self.clearJournalAndRefund()
 	return nil
@@ -153,6 +156,20 @@ func (self *StateDB) Logs() []*types.Log {
 	return logs
 }
 
+// AddSystemTransfer records a balance change done by the consensus engine
+// outside of any transaction. The caller moves the balance itself.
+func (self *StateDB) AddSystemTransfer(transfer *types.SystemTransfer) {
+	self.journal = append(self.journal, addSystemTransferChange{})
+
+	transfer.Index = uint(len(self.systemTransfers))
+	self.systemTransfers = append(self.systemTransfers, transfer)
+}
+
+// SystemTransfers returns the system transfers recorded since the last reset.
+func (self *StateDB) SystemTransfers() []*types.SystemTransfer {
+	return self.systemTransfers
+}
+
 // AddPreimage records a SHA3 preimage seen by the VM.
 func (self *StateDB) AddPreimage(hash common.Hash, preimage []byte) {
 	if _, ok := self.preimages[hash]; !ok {
@@ -173,6 +190,16 @@ func (self *StateDB) AddRefund(gas *big.Int) {
 	self.refund.Add(self.refund, gas)
 }
 
+// SubRefund removes gas from the refund counter.
+// This method will panic if the refund counter goes below zero
+func (self *StateDB) SubRefund(gas *big.Int) {
+	self.journal = append(self.journal, refundChange{prev: new(big.Int).Set(self.refund)})
+	if gas.Cmp(self.refund) > 0 {
+		panic("Refund counter below zero")
+	}
+	self.refund.Sub(self.refund, gas)
+}
+
 // Exist reports whether the given account address exists in the state.
 // Notably this also returns true for suicided accounts.
 func (self *StateDB) Exist(addr common.Address) bool {
@@ -243,6 +270,16 @@ func (self *StateDB) GetState(a common.Address, b common.Hash) common.Hash {
 	return common.Hash{}
 }
 
+// GetCommittedState retrieves a value from the given account's storage as of
+// the start of the current transaction.
+func (self *StateDB) GetCommittedState(a common.Address, b common.Hash) common.Hash {
+	stateObject := self.getStateObject(a)
+	if stateObject != nil {
+		return stateObject.GetCommittedState(self.db, b)
+	}
+	return common.Hash{}
+}
+
 func (self *StateDB) GetStateByteArray(a common.Address, b common.Hash) []byte {
 	stateObject := self.getStateObject(a)
 	if stateObject != nil {
@@ -543,8 +580,10 @@ func (self *StateDB) Copy() *StateDB {
 		refund:            new(big.Int).Set(self.refund),
 		logs:              make(map[common.Hash][]*types.Log, len(self.logs)),
 		logSize:           self.logSize,
+		systemTransfers:   make([]*types.SystemTransfer, len(self.systemTransfers)),
 		preimages:         make(map[common.Hash][]byte),
 	}
+	copy(state.systemTransfers, self.systemTransfers)
 	// Copy the dirty states, logs, and preimages
 	for addr := range self.stateObjectsDirty {
 		state.stateObjects[addr] = self.stateObjects[addr].deepCopy(state, state.MarkStateObjectDirty)
@@ -393,3 +393,35 @@ func (s *StateSuite) TestTouchDelete(c *check.C) {
 		c.Fatal("expected no dirty state object")
 	}
 }
+
+// Tests that system transfers are dropped when the state is reverted and kept
+// by a copy.
+func TestSystemTransferRevert(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(db))
+
+	state.AddSystemTransfer(&types.SystemTransfer{Reason: types.SystemTransferIncentive, Amount: big.NewInt(1)})
+	snap := state.Snapshot()
+	state.AddSystemTransfer(&types.SystemTransfer{Reason: types.SystemTransferStakeOut, Amount: big.NewInt(2)})
+	state.AddSystemTransfer(&types.SystemTransfer{Reason: types.SystemTransferStakeOut, Amount: big.NewInt(3)})
+	if n := len(state.SystemTransfers()); n != 3 {
+		t.Fatalf("system transfer count mismatch: have %d, want 3", n)
+	}
+	if index := state.SystemTransfers()[2].Index; index != 2 {
+		t.Fatalf("system transfer index mismatch: have %d, want 2", index)
+	}
+
+	cpy := state.Copy()
+	state.RevertToSnapshot(snap)
+	if n := len(state.SystemTransfers()); n != 1 {
+		t.Fatalf("reverted system transfer count mismatch: have %d, want 1", n)
+	}
+	if n := len(cpy.SystemTransfers()); n != 3 {
+		t.Fatalf("copied system transfer count mismatch: have %d, want 3", n)
+	}
+
+	state.Reset(common.Hash{})
+	if n := len(state.SystemTransfers()); n != 0 {
+		t.Fatalf("reset system transfer count mismatch: have %d, want 0", n)
+	}
+}
@@ -90,7 +90,7 @@ type Message interface {
 // with the given data.
 //
 // TODO convert to uint64
-func IntrinsicGas(data []byte, to *common.Address, homestead bool) *big.Int {
+func IntrinsicGas(data []byte, to *common.Address, homestead, istanbul bool) *big.Int {
 	contractCreation := to == nil
 
 	igas := new(big.Int)
@@ -107,7 +107,11 @@ func IntrinsicGas(data []byte, to *common.Address, homestead bool) *big.Int {
 			}
 		}
 		m := big.NewInt(nz)
-		m.Mul(m, new(big.Int).SetUint64(params.TxDataNonZeroGas))
+		nonZeroGas := params.TxDataNonZeroGas
+		if istanbul {
+			nonZeroGas = params.TxDataNonZeroGasEIP2028
+		}
+		m.Mul(m, new(big.Int).SetUint64(nonZeroGas))
 		igas.Add(igas, m)
 		m.SetInt64(int64(len(data)) - nz)
 		m.Mul(m, new(big.Int).SetUint64(params.TxDataZeroGas))
@@ -247,7 +251,8 @@ func (st *StateTransition) TransitionDb() (ret []byte, requiredGas, usedGas *big
 
 	// Pay intrinsic gas
 	// TODO convert to uint64
-	intrinsicGas := IntrinsicGas(st.data, msg.To(), true /*homestead*/)
+	istanbul := st.evm.ChainConfig().IsIstanbul(st.evm.BlockNumber)
+	intrinsicGas := IntrinsicGas(st.data, msg.To(), true /*homestead*/, istanbul)
 	//log.Trace("get intrinsic gas", "gas", intrinsicGas.String())
 	if intrinsicGas.BitLen() > 64 {
 		return nil, nil, nil, false, vm.ErrOutOfGas
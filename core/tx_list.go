@@ -317,7 +317,7 @@ func (l *txList) Filter(costLimit, gasLimit *big.Int) (types.Transactions, types
 }
 
 // InvalidPrivacyTx remove invalidate privacy transactions
-func (l *txList) InvalidPrivacyTx(stateDB vm.StateDB, signer types.Signer, gasLimit *big.Int) types.Transactions {
+func (l *txList) InvalidPrivacyTx(stateDB vm.StateDB, signer types.Signer, gasLimit *big.Int, istanbul bool) types.Transactions {
 	removed := l.txs.Filter(func(tx *types.Transaction) bool {
 		if !types.IsPrivacyTransaction(tx.Txtype()) {
 			return false
@@ -328,7 +328,7 @@ func (l *txList) InvalidPrivacyTx(stateDB vm.StateDB, signer types.Signer, gasLi
 			return true
 		}
 
-		intrGas := IntrinsicGas(tx.Data(), tx.To(), true)
+		intrGas := IntrinsicGas(tx.Data(), tx.To(), true, istanbul)
 		err = ValidPrivacyTx(stateDB, from.Bytes(), tx.Data(), tx.GasPrice(), intrGas, tx.Value(), gasLimit)
 
 		return err != nil
@@ -545,8 +545,8 @@ func (l *txPricedList) Cap(threshold *big.Int, local *accountSet) types.Transact
 			save = append(save, tx)
 			break
 		}
-		// Non stale transaction found, discard unless local
-		if local.containsTx(tx) {
+		// Non stale transaction found, discard unless local or protocol
+		if local.containsTx(tx) || isProtocolTx(tx) {
 			save = append(save, tx)
 		} else {
 			drop = append(drop, tx)
@@ -597,8 +597,8 @@ func (l *txPricedList) Discard(count int, local *accountSet) types.Transactions
 			l.stales--
 			continue
 		}
-		// Non stale transaction found, discard unless local
-		if local.containsTx(tx) {
+		// Non stale transaction found, discard unless local or protocol
+		if local.containsTx(tx) || isProtocolTx(tx) {
 			save = append(save, tx)
 		} else {
 			drop = append(drop, tx)
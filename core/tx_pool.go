@@ -136,6 +136,9 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	ProtocolJournal string // Journal of random beacon and slot leader selection transactions
+	ProtocolSlots   uint64 // Maximum number of random beacon and slot leader selection transactions
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -153,6 +156,9 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	GlobalQueue:  10240,
 
 	Lifetime: 3 * time.Hour,
+
+	ProtocolJournal: "protocol_transactions.rlp",
+	ProtocolSlots:   1024,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -171,6 +177,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool price bump", "provided", conf.PriceBump, "updated", DefaultTxPoolConfig.PriceBump)
 		conf.PriceBump = DefaultTxPoolConfig.PriceBump
 	}
+	if conf.ProtocolSlots < 1 {
+		log.Warn("Sanitizing invalid txpool protocol slots", "provided", conf.ProtocolSlots, "updated", DefaultTxPoolConfig.ProtocolSlots)
+		conf.ProtocolSlots = DefaultTxPoolConfig.ProtocolSlots
+	}
 	return conf
 }
 
@@ -196,6 +206,7 @@ type TxPool struct {
 	currentState  *state.StateDB      // Current state in the blockchain head
 	pendingState  *state.ManagedState // Pending state tracking virtual nonces
 	currentMaxGas *big.Int            // Current gas limit for transaction caps
+	currentNumber *big.Int            // Current head block number
 
 	locals  *accountSet // Set of local transaction to exepmt from evicion rules
 	journal *txJournal  // Journal of local transaction to back up to disk
@@ -206,9 +217,12 @@ type TxPool struct {
 	all     map[common.Hash]*types.Transaction // All transactions to allow lookups
 	priced  *txPricedList                      // All transactions sorted by price
 
+	protocol *protocolLane // Random beacon and slot leader selection transactions
+
 	wg sync.WaitGroup // for shutdown sync
 
 	homestead bool
+	istanbul  bool // Fork indicator whether the next block prices calldata by EIP-2028
 }
 
 // NewTxPool creates a new transaction pool to gather, sort and filter inbound
@@ -229,6 +243,7 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		all:         make(map[common.Hash]*types.Transaction),
 		chainHeadCh: make(chan ChainHeadEvent, chainHeadChanSize),
 		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
+		protocol:    newProtocolLane(),
 	}
 	pool.locals = newAccountSet(pool.signer)
 	pool.priced = newTxPricedList(&pool.all)
@@ -245,6 +260,16 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// Protocol transactions are journaled whatever their sender
+	if config.ProtocolJournal != "" {
+		journal := newTxJournal(config.ProtocolJournal)
+
+		if err := journal.load(pool.AddRemote); err != nil {
+			log.Warn("Failed to load protocol transaction journal", "err", err)
+		}
+		pool.protocol.journal = journal
+		pool.rotateProtocolJournal()
+	}
 	// Subscribe events from blockchain
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
 
@@ -324,6 +349,8 @@ func (pool *TxPool) loop() {
 					}
 				}
 			}
+			// Protocol transactions are dropped once their stage is over
+			pool.sweepProtocolLane()
 			pool.mu.Unlock()
 
 		// Handle local transaction journal rotation
@@ -335,6 +362,9 @@ func (pool *TxPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+			pool.mu.Lock()
+			pool.rotateProtocolJournal()
+			pool.mu.Unlock()
 		}
 	}
 }
@@ -410,6 +440,8 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.currentState = statedb
 	pool.pendingState = state.ManageState(statedb)
 	pool.currentMaxGas = newHead.GasLimit
+	pool.currentNumber = newHead.Number
+	pool.istanbul = pool.chainconfig.IsIstanbul(new(big.Int).Add(newHead.Number, big.NewInt(1)))
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
@@ -429,6 +461,9 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// Check the queue and move transactions over to the pending if possible
 	// or remove those that have become invalid
 	pool.promoteExecutables(nil)
+
+	// Account the mined protocol transactions and drop the expired ones
+	pool.sweepProtocolLane()
 }
 
 // Stop terminates the transaction pool.
@@ -443,6 +478,9 @@ func (pool *TxPool) Stop() {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.protocol.journal != nil {
+		pool.protocol.journal.close()
+	}
 	log.Info("Transaction pool stopped")
 }
 
@@ -600,7 +638,7 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) (*common.Addre
 		return nil, ErrInsufficientFunds
 	}
 
-	intrGas := IntrinsicGas(tx.Data(), tx.To(), pool.homestead)
+	intrGas := IntrinsicGas(tx.Data(), tx.To(), pool.homestead, pool.istanbul)
 	if isNormalType || isPosType {
 		if tx.Gas().Cmp(intrGas) < 0 {
 			return nil, ErrIntrinsicGas
@@ -616,7 +654,13 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) (*common.Addre
 	// Check precompile contracts transactions validation
 	if tx.To() != nil {
 		if p := vm.PrecompiledContractsByzantium[*tx.To()]; p != nil {
-			if err = p.ValidTx(pool.currentState, pool.signer, tx); err != nil {
+			if fv, ok := p.(vm.ForkValidator); ok {
+				next := new(big.Int).Add(pool.currentNumber, common.Big1)
+				err = fv.ValidTxAt(pool.currentState, pool.signer, tx, pool.chainconfig, next)
+			} else {
+				err = p.ValidTx(pool.currentState, pool.signer, tx)
+			}
+			if err != nil {
 				return nil, err
 			}
 		}
@@ -649,8 +693,15 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		invalidTxCounter.Inc(1)
 		return false, err
 	}
+	// Protocol transactions are bounded by their own lane
+	var ptx *protocolTx
+	if isProtocolTx(tx) {
+		if ptx, err = pool.admitProtocolTx(tx, *senderFrom); err != nil {
+			return false, err
+		}
+	}
 	// If the transaction pool is full, discard underpriced transactions
-	if uint64(len(pool.all)) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
+	if pooled := pool.pooledCount(); ptx == nil && uint64(pooled) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
 		if pool.priced.Underpriced(tx, pool.locals) {
 			// log.Trace("Discarding underpriced transaction", "hash", hash, "price", tx.GasPrice())
@@ -658,7 +709,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			return false, ErrUnderpriced
 		}
 		// New transaction is better than our worse ones, make room for it
-		drop := pool.priced.Discard(len(pool.all)-int(pool.config.GlobalSlots+pool.config.GlobalQueue-1), pool.locals)
+		drop := pool.priced.Discard(pooled-int(pool.config.GlobalSlots+pool.config.GlobalQueue-1), pool.locals)
 		for _, tx := range drop {
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "price", tx.GasPrice())
 			underpricedTxCounter.Inc(1)
@@ -684,6 +735,9 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		pool.all[tx.Hash()] = tx
 		pool.priced.Put(tx)
 		pool.journalTx(from, tx)
+		if ptx != nil {
+			pool.trackProtocolTx(ptx)
+		}
 
 		//log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 		return old != nil, nil
@@ -698,6 +752,9 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		pool.locals.add(from)
 	}
 	pool.journalTx(from, tx)
+	if ptx != nil {
+		pool.trackProtocolTx(ptx)
+	}
 
 	//log.Trace("Pooled new future transaction", "hash", hash, "from", from, "to", tx.To())
 	return replace, nil
@@ -925,6 +982,9 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			accounts = append(accounts, addr)
 		}
 	}
+	// The transactions needed to mine the protocol ones are kept out of the rate limits
+	protocolNonces := pool.protocol.lastNonces()
+
 	// Iterate over all accounts and promote any executable transactions
 	for _, addr := range accounts {
 		list := pool.queue[addr]
@@ -951,7 +1011,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 		}
 
 		// Remove all invalid privacy transactions
-		invalidPrivacy := list.InvalidPrivacyTx(pool.currentState, pool.signer, pool.currentMaxGas)
+		invalidPrivacy := list.InvalidPrivacyTx(pool.currentState, pool.signer, pool.currentMaxGas, pool.istanbul)
 		for _, tx := range invalidPrivacy {
 			hash := tx.Hash()
 			log.Trace("Removed invalid privacy transaction", "hash", hash)
@@ -988,7 +1048,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 		}
 		// Drop all transactions over the allowed limit
 		if !pool.locals.contains(addr) {
-			for _, tx := range list.Cap(int(pool.config.AccountQueue)) {
+			for _, tx := range list.Cap(int(pool.config.AccountQueue) + protocolProtected(list, protocolNonces, addr)) {
 				hash := tx.Hash()
 				delete(pool.all, hash)
 				pool.priced.Removed()
@@ -1010,12 +1070,17 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 		pendingBeforeCap := pending
 		// Assemble a spam order to penalize large transactors first
 		spammers := prque.New()
+		protected := make(map[common.Address]int)
 		for addr, list := range pool.pending {
+			protected[addr] = protocolProtected(list, protocolNonces, addr)
 			// Only evict transactions from high rollers
-			if !pool.locals.contains(addr) && uint64(list.Len()) > pool.config.AccountSlots {
-				spammers.Push(addr, float32(list.Len()))
+			if slots := list.Len() - protected[addr]; !pool.locals.contains(addr) && uint64(slots) > pool.config.AccountSlots {
+				spammers.Push(addr, float32(slots))
 			}
 		}
+		slots := func(addr common.Address) int {
+			return pool.pending[addr].Len() - protected[addr]
+		}
 		// Gradually drop transactions from offenders
 		offenders := []common.Address{}
 		for pending > pool.config.GlobalSlots && !spammers.Empty() {
@@ -1026,10 +1091,10 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			// Equalize balances until all the same or below threshold
 			if len(offenders) > 1 {
 				// Calculate the equalization threshold for all current offenders
-				threshold := pool.pending[offender.(common.Address)].Len()
+				threshold := slots(offender.(common.Address))
 
 				// Iteratively reduce all offenders until below limit or threshold reached
-				for pending > pool.config.GlobalSlots && pool.pending[offenders[len(offenders)-2]].Len() > threshold {
+				for pending > pool.config.GlobalSlots && slots(offenders[len(offenders)-2]) > threshold {
 					for i := 0; i < len(offenders)-1; i++ {
 						list := pool.pending[offenders[i]]
 						for _, tx := range list.Cap(list.Len() - 1) {
@@ -1051,7 +1116,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 		}
 		// If still above threshold, reduce to limit or min allowance
 		if pending > pool.config.GlobalSlots && len(offenders) > 0 {
-			for pending > pool.config.GlobalSlots && uint64(pool.pending[offenders[len(offenders)-1]].Len()) > pool.config.AccountSlots {
+			for pending > pool.config.GlobalSlots && uint64(slots(offenders[len(offenders)-1])) > pool.config.AccountSlots {
 				for _, addr := range offenders {
 					list := pool.pending[addr]
 					for _, tx := range list.Cap(list.Len() - 1) {
@@ -1096,9 +1161,11 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 
 			addresses = addresses[:len(addresses)-1]
 
-			// Drop all transactions if they are less than the overflow
-			if size := uint64(list.Len()); size <= drop {
-				for _, tx := range list.Flatten() {
+			// Drop all transactions if they are less than the overflow, sparing
+			// the ones needed to mine the protocol transactions
+			txs := list.Flatten()[protocolProtected(list, protocolNonces, addr.address):]
+			if size := uint64(len(txs)); size <= drop {
+				for _, tx := range txs {
 					pool.removeTx(tx.Hash())
 				}
 				drop -= size
@@ -1106,7 +1173,6 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 				continue
 			}
 			// Otherwise drop only last few transactions
-			txs := list.Flatten()
 			for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
 				pool.removeTx(txs[i].Hash())
 				drop--
@@ -1149,7 +1215,7 @@ func (pool *TxPool) demoteUnexecutables() {
 		}
 
 		// Remove all invalid privacy transactions
-		invalidPrivacy := list.InvalidPrivacyTx(pool.currentState, pool.signer, pool.currentMaxGas)
+		invalidPrivacy := list.InvalidPrivacyTx(pool.currentState, pool.signer, pool.currentMaxGas, pool.istanbul)
 		for _, tx := range invalidPrivacy {
 			hash := tx.Hash()
 			log.Trace("Removed invalid privacy transaction", "hash", hash)
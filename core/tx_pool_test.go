@@ -46,6 +46,7 @@ var testTxPoolConfig TxPoolConfig
 func init() {
 	testTxPoolConfig = DefaultTxPoolConfig
 	testTxPoolConfig.Journal = ""
+	testTxPoolConfig.ProtocolJournal = ""
 }
 
 type testBlockChain struct {
@@ -1521,7 +1522,9 @@ func (CTStateDB) GetCode(common.Address) []byte
 func (CTStateDB) SetCode(common.Address, []byte)                                         {}
 func (CTStateDB) GetCodeSize(common.Address) int                                         { return 0 }
 func (CTStateDB) AddRefund(*big.Int)                                                     {}
+func (CTStateDB) SubRefund(*big.Int)                                                     {}
 func (CTStateDB) GetRefund() *big.Int                                                    { return nil }
+func (CTStateDB) GetCommittedState(common.Address, common.Hash) common.Hash              { return common.Hash{} }
 func (CTStateDB) GetState(common.Address, common.Hash) common.Hash                       { return common.Hash{} }
 func (CTStateDB) SetState(common.Address, common.Hash, common.Hash)                      {}
 func (CTStateDB) Suicide(common.Address) bool                                            { return false }
@@ -0,0 +1,230 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package core
+
+import (
+	"errors"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/metrics"
+	posutil "github.com/wanchain/go-wanchain/pos/util"
+)
+
+var (
+	// ErrProtocolLaneFull is returned if a random beacon or slot leader
+	// selection transaction arrives while the protocol lane is full.
+	ErrProtocolLaneFull = errors.New("protocol transaction lane is full")
+
+	// ErrProtocolTxExpired is returned if a random beacon or slot leader
+	// selection transaction arrives after its stage is over.
+	ErrProtocolTxExpired = errors.New("protocol transaction stage is over")
+)
+
+var (
+	// Metrics for the protocol lane
+	protocolIncludedCounter = metrics.NewCounter("txpool/protocol/included") // Mined within their stage
+	protocolExpiredCounter  = metrics.NewCounter("txpool/protocol/expired")  // Dropped as their stage ended unmined
+	protocolDroppedCounter  = metrics.NewCounter("txpool/protocol/dropped")  // Dropped as invalid or over the lane limit
+
+	// Mined protocol transactions sent by the local node, per protocol
+	rbIncludedCounter = metrics.NewCounter("pos/rb/tx/included")
+	slIncludedCounter = metrics.NewCounter("pos/sl/tx/included")
+)
+
+// maxProtocolProtected is the maximum number of transactions of an account kept
+// out of the account limits for its protocol transactions. A validator sends
+// a handful of them per epoch, so a sender needing more is flooding the pool.
+const maxProtocolProtected = 16
+
+// protocolNow returns the current time, replaced by the tests.
+var protocolNow = func() uint64 { return uint64(posutil.Now().Unix()) }
+
+// isProtocolTx tells whether tx is a random beacon or slot leader selection
+// transaction sent by the pos protocol.
+func isProtocolTx(tx *types.Transaction) bool {
+	return types.IsPosTransaction(tx.Txtype()) && vm.IsPosProtocolAddr(tx.To())
+}
+
+// protocolTx is a protocol transaction along with the stage it must be mined in.
+type protocolTx struct {
+	tx      *types.Transaction
+	from    common.Address
+	rb      bool // random beacon transaction, slot leader selection otherwise
+	epochID uint64
+	stage   int
+}
+
+// expired tells whether the stage of the transaction is over at the given slot.
+func (p *protocolTx) expired(epochID, slotID uint64) bool {
+	if epochID != p.epochID {
+		return epochID > p.epochID
+	}
+	if p.rb {
+		stage, _, _ := vm.GetRBStage(slotID)
+		return stage > p.stage
+	}
+	return int(vm.GetSlStage(slotID)) > p.stage
+}
+
+// protocolLane tracks the random beacon and slot leader selection
+// transactions of the pool. They are kept out of the price based eviction
+// and the global limits, bounded by their own limit instead, journaled on
+// their own and dropped once their stage is over.
+type protocolLane struct {
+	txs     map[common.Hash]*protocolTx
+	journal *txJournal // Journal of protocol transactions to back up to disk
+}
+
+func newProtocolLane() *protocolLane {
+	return &protocolLane{txs: make(map[common.Hash]*protocolTx)}
+}
+
+// lastNonces returns the highest nonce of the protocol transactions of each
+// sender with some in the pool.
+func (lane *protocolLane) lastNonces() map[common.Address]uint64 {
+	nonces := make(map[common.Address]uint64)
+	for _, ptx := range lane.txs {
+		if nonce, ok := nonces[ptx.from]; !ok || ptx.tx.Nonce() > nonce {
+			nonces[ptx.from] = ptx.tx.Nonce()
+		}
+	}
+	return nonces
+}
+
+// protocolProtected returns how many transactions of an account list are
+// needed to mine the protocol transactions of the account, that is the ones
+// up to the last protocol transaction nonce, at most maxProtocolProtected.
+// These are kept out of the account limits, the other transactions of the
+// account are capped as usual.
+func protocolProtected(list *txList, nonces map[common.Address]uint64, addr common.Address) int {
+	last, ok := nonces[addr]
+	if !ok {
+		return 0
+	}
+	protected := 0
+	for _, tx := range list.Flatten() {
+		if tx.Nonce() > last || protected == maxProtocolProtected {
+			break
+		}
+		protected++
+	}
+	return protected
+}
+
+// grouped returns the protocol transactions grouped by sender.
+func (lane *protocolLane) grouped() map[common.Address]types.Transactions {
+	txs := make(map[common.Address]types.Transactions)
+	for _, ptx := range lane.txs {
+		txs[ptx.from] = append(txs[ptx.from], ptx.tx)
+	}
+	return txs
+}
+
+// admitProtocolTx checks that a validated protocol transaction fits in the
+// lane and is still in its stage.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) admitProtocolTx(tx *types.Transaction, from common.Address) (*protocolTx, error) {
+	// replacements of a pooled transaction do not grow the lane
+	if _, known := pool.protocol.txs[tx.Hash()]; !known && uint64(len(pool.protocol.txs)) >= pool.config.ProtocolSlots {
+		protocolDroppedCounter.Inc(1)
+		return nil, ErrProtocolLaneFull
+	}
+	epochID, stage, err := vm.PosProtocolTxStage(*tx.To(), tx.Data())
+	if err != nil {
+		protocolDroppedCounter.Inc(1)
+		return nil, err
+	}
+	ptx := &protocolTx{
+		tx:      tx,
+		from:    from,
+		rb:      *tx.To() == vm.GetRBAddress(),
+		epochID: epochID,
+		stage:   stage,
+	}
+	if ptx.expired(posutil.CalEpochSlotID(protocolNow())) {
+		protocolExpiredCounter.Inc(1)
+		return nil, ErrProtocolTxExpired
+	}
+	return ptx, nil
+}
+
+// trackProtocolTx adds an accepted protocol transaction to the lane.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) trackProtocolTx(ptx *protocolTx) {
+	pool.protocol.txs[ptx.tx.Hash()] = ptx
+	if pool.protocol.journal != nil {
+		if err := pool.protocol.journal.insert(ptx.tx); err != nil {
+			log.Warn("Failed to journal protocol transaction", "err", err)
+		}
+	}
+}
+
+// sweepProtocolLane accounts the protocol transactions which left the pool,
+// either mined or dropped, and removes the ones whose stage is over.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) sweepProtocolLane() {
+	epochID, slotID := posutil.CalEpochSlotID(protocolNow())
+
+	changed := false
+	for hash, ptx := range pool.protocol.txs {
+		switch {
+		case pool.all[hash] == nil && pool.currentState.GetNonce(ptx.from) > ptx.tx.Nonce():
+			// the nonce was used, by this transaction or by a replacement
+			protocolIncludedCounter.Inc(1)
+			if pool.locals.contains(ptx.from) {
+				if ptx.rb {
+					rbIncludedCounter.Inc(1)
+				} else {
+					slIncludedCounter.Inc(1)
+				}
+			}
+		case pool.all[hash] == nil && ptx.expired(epochID, slotID):
+			protocolExpiredCounter.Inc(1)
+		case pool.all[hash] == nil:
+			protocolDroppedCounter.Inc(1)
+		case ptx.expired(epochID, slotID):
+			log.Debug("Removed expired protocol transaction", "hash", hash, "epochID", ptx.epochID, "stage", ptx.stage)
+			pool.removeTx(hash)
+			protocolExpiredCounter.Inc(1)
+		default:
+			continue
+		}
+		delete(pool.protocol.txs, hash)
+		changed = true
+	}
+	if changed {
+		pool.rotateProtocolJournal()
+	}
+}
+
+// rotateProtocolJournal regenerates the protocol journal from the lane.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) rotateProtocolJournal() {
+	if pool.protocol.journal == nil {
+		return
+	}
+	if err := pool.protocol.journal.rotate(pool.protocol.grouped()); err != nil {
+		log.Warn("Failed to rotate protocol tx journal", "err", err)
+	}
+}
+
+// pooledCount returns the number of pooled transactions subject to the global
+// limits, the protocol transactions being bounded by their lane.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) pooledCount() int {
+	count := len(pool.all)
+	for hash := range pool.protocol.txs {
+		if pool.all[hash] != nil {
+			count--
+		}
+	}
+	return count
+}
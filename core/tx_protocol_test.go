@@ -0,0 +1,193 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+// slotTime returns the time of a slot, as used by the protocol lane clock.
+func slotTime(epochID, slotID uint64) uint64 {
+	return (epochID*posconfig.SlotCount + slotID) * posconfig.SlotTime
+}
+
+func TestProtocolTxLane(t *testing.T) {
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	defer func(now func() uint64) { protocolNow = now }(protocolNow)
+
+	// a dkg2 transaction of epoch 10
+	payload, _ := rlp.EncodeToBytes(&struct {
+		EpochId    uint64
+		ProposerId uint32
+	}{10, 3})
+	tx, _ := types.SignTx(types.NewTransaction(0, vm.GetRBAddress(), big.NewInt(0), big.NewInt(100000), big.NewInt(1),
+		append(vm.GetDkg2Id(), payload...)), types.HomesteadSigner{}, key)
+	tx.SetTxtype(types.POS_TX)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	if !isProtocolTx(tx) {
+		t.Fatal("dkg2 transaction not recognised as protocol transaction")
+	}
+	if isProtocolTx(transaction(0, big.NewInt(100000), key)) {
+		t.Fatal("normal transaction recognised as protocol transaction")
+	}
+
+	protocolNow = func() uint64 { return slotTime(10, posconfig.Cfg().Dkg2End+1) }
+	if _, err := pool.admitProtocolTx(tx, from); err != ErrProtocolTxExpired {
+		t.Fatalf("late dkg2 transaction error mismatch: have %v, want %v", err, ErrProtocolTxExpired)
+	}
+	protocolNow = func() uint64 { return slotTime(11, 0) }
+	if _, err := pool.admitProtocolTx(tx, from); err != ErrProtocolTxExpired {
+		t.Fatalf("dkg2 transaction of a past epoch error mismatch: have %v, want %v", err, ErrProtocolTxExpired)
+	}
+
+	protocolNow = func() uint64 { return slotTime(10, posconfig.Cfg().Dkg2Begin) }
+	ptx, err := pool.admitProtocolTx(tx, from)
+	if err != nil {
+		t.Fatalf("dkg2 transaction rejected in its stage: %v", err)
+	}
+	if ptx.epochID != 10 || ptx.stage != vm.RbDkg2Stage {
+		t.Fatalf("stage mismatch: have epoch %d stage %d, want epoch 10 stage %d", ptx.epochID, ptx.stage, vm.RbDkg2Stage)
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.all[tx.Hash()] = tx
+	pool.priced.Put(tx)
+	pool.trackProtocolTx(ptx)
+
+	// the lane is exempt from the global limits and the price based eviction
+	if count := pool.pooledCount(); count != 0 {
+		t.Fatalf("pooled count mismatch: have %d, want 0", count)
+	}
+	if drop := pool.priced.Discard(1, pool.locals); len(drop) != 0 {
+		t.Fatalf("protocol transaction discarded as underpriced")
+	}
+	pool.config.ProtocolSlots = 1
+	other := pricedTransaction(1, big.NewInt(100000), big.NewInt(1), key)
+	other.SetTxtype(types.POS_TX)
+	if _, err := pool.admitProtocolTx(other, from); err != ErrProtocolLaneFull {
+		t.Fatalf("full lane error mismatch: have %v, want %v", err, ErrProtocolLaneFull)
+	}
+
+	// the transaction stays in its stage and is dropped after it
+	pool.sweepProtocolLane()
+	if pool.all[tx.Hash()] == nil || len(pool.protocol.txs) != 1 {
+		t.Fatal("protocol transaction dropped within its stage")
+	}
+	protocolNow = func() uint64 { return slotTime(10, posconfig.Cfg().SignBegin) }
+	pool.sweepProtocolLane()
+	if pool.all[tx.Hash()] != nil || len(pool.protocol.txs) != 0 {
+		t.Fatal("protocol transaction kept after its stage")
+	}
+}
+
+func TestProtocolSenderLimits(t *testing.T) {
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(from, big.NewInt(1000000000))
+
+	// a dkg2 transaction at nonce 5, after a nonce gap keeping it queued
+	payload, _ := rlp.EncodeToBytes(&struct {
+		EpochId    uint64
+		ProposerId uint32
+	}{10, 3})
+	tx, _ := types.SignTx(types.NewTransaction(5, vm.GetRBAddress(), big.NewInt(0), big.NewInt(100000), big.NewInt(1),
+		append(vm.GetDkg2Id(), payload...)), types.HomesteadSigner{}, key)
+	tx.SetTxtype(types.POS_TX)
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.enqueueTx(tx.Hash(), tx)
+	pool.trackProtocolTx(&protocolTx{tx: tx, from: from, rb: true, epochID: 10, stage: vm.RbDkg2Stage})
+
+	// the transactions it depends on are kept, the later ones are capped
+	for nonce := uint64(1); nonce < 5; nonce++ {
+		queued := transaction(nonce, big.NewInt(100000), key)
+		pool.enqueueTx(queued.Hash(), queued)
+	}
+	for nonce := uint64(6); nonce < 6+pool.config.AccountQueue+10; nonce++ {
+		queued := transaction(nonce, big.NewInt(100000), key)
+		pool.enqueueTx(queued.Hash(), queued)
+	}
+	pool.promoteExecutables([]common.Address{from})
+
+	before, after := 0, 0
+	for _, queued := range pool.queue[from].Flatten() {
+		switch {
+		case queued.Nonce() < 5:
+			before++
+		case queued.Nonce() > 5:
+			after++
+		}
+	}
+	if before != 4 {
+		t.Errorf("transactions before the protocol one mismatch: have %d, want 4", before)
+	}
+	if uint64(after) != pool.config.AccountQueue {
+		t.Errorf("transactions after the protocol one mismatch: have %d, want %d", after, pool.config.AccountQueue)
+	}
+}
+
+func TestProtocolSenderFlood(t *testing.T) {
+	pool, _ := setupTxPool()
+	defer pool.Stop()
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.config.AccountQueue = 8
+	pool.config.AccountSlots = 4
+	pool.config.GlobalSlots = 8
+
+	// a protocol transaction far ahead of the nonce does not protect the
+	// whole range of transactions before it
+	payload, _ := rlp.EncodeToBytes(&struct {
+		EpochId    uint64
+		ProposerId uint32
+	}{10, 3})
+	flood := func(first uint64) common.Address {
+		key, _ := crypto.GenerateKey()
+		from := crypto.PubkeyToAddress(key.PublicKey)
+		pool.currentState.AddBalance(from, big.NewInt(1000000000))
+
+		tx, _ := types.SignTx(types.NewTransaction(first+100, vm.GetRBAddress(), big.NewInt(0), big.NewInt(100000), big.NewInt(1),
+			append(vm.GetDkg2Id(), payload...)), types.HomesteadSigner{}, key)
+		tx.SetTxtype(types.POS_TX)
+		pool.enqueueTx(tx.Hash(), tx)
+		pool.trackProtocolTx(&protocolTx{tx: tx, from: from, rb: true, epochID: 10, stage: vm.RbDkg2Stage})
+		for nonce := first; nonce < first+100; nonce++ {
+			queued := transaction(nonce, big.NewInt(100000), key)
+			pool.enqueueTx(queued.Hash(), queued)
+		}
+		pool.promoteExecutables([]common.Address{from})
+		return from
+	}
+
+	// queued behind a nonce gap
+	from := flood(1)
+	if queued := pool.queue[from].Len(); queued != int(pool.config.AccountQueue)+maxProtocolProtected {
+		t.Fatalf("queued transactions mismatch: have %d, want %d", queued, int(pool.config.AccountQueue)+maxProtocolProtected)
+	}
+
+	// executable, over the pending limits
+	pool.config.AccountQueue = 1024
+	from = flood(0)
+	if pending := pool.pending[from].Len(); pending != int(pool.config.AccountSlots)+maxProtocolProtected {
+		t.Fatalf("pending transactions mismatch: have %d, want %d", pending, int(pool.config.AccountSlots)+maxProtocolProtected)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+// SystemTransferReason tells why the consensus engine moved a balance.
+type SystemTransferReason uint8
+
+const (
+	// SystemTransferIncentive is an epoch incentive paid to a validator,
+	// delegator or partner wallet.
+	SystemTransferIncentive SystemTransferReason = iota + 1
+	// SystemTransferStakeOut is a stake refunded by the staking contract when
+	// the lock time of a validator, delegation or partnership expires.
+	SystemTransferStakeOut
+	// SystemTransferInactiveValidator is a stake refunded when an inactive
+	// validator is removed.
+	SystemTransferInactiveValidator
+	// SystemTransferTestnetGrant is the one-off grant of the testnet.
+	SystemTransferTestnetGrant
+)
+
+var systemTransferReasons = map[SystemTransferReason]string{
+	SystemTransferIncentive:         "incentive",
+	SystemTransferStakeOut:          "stakeOut",
+	SystemTransferInactiveValidator: "inactiveValidator",
+	SystemTransferTestnetGrant:      "testnetGrant",
+}
+
+func (r SystemTransferReason) String() string {
+	if s, ok := systemTransferReasons[r]; ok {
+		return s
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(r))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r SystemTransferReason) MarshalText() ([]byte, error) {
+	if _, ok := systemTransferReasons[r]; !ok {
+		return nil, fmt.Errorf("unknown system transfer reason %d", uint8(r))
+	}
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *SystemTransferReason) UnmarshalText(input []byte) error {
+	for reason, s := range systemTransferReasons {
+		if s == string(input) {
+			*r = reason
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown system transfer reason %q", input)
+}
+
+// SystemTransfer is a balance change done by the consensus engine outside of
+// any transaction while a block is finalized. A zero From means the amount is
+// minted.
+type SystemTransfer struct {
+	Reason    SystemTransferReason
+	From      common.Address
+	Recipient common.Address
+	Validator common.Address
+	Epoch     uint64
+	Amount    *big.Int
+
+	// Derived fields, filled in by the node when the transfers are read.
+	BlockNumber uint64
+	BlockHash   common.Hash
+	Index       uint
+}
+
+type rlpSystemTransfer struct {
+	Reason    SystemTransferReason
+	From      common.Address
+	Recipient common.Address
+	Validator common.Address
+	Epoch     uint64
+	Amount    *big.Int
+}
+
+// EncodeRLP implements rlp.Encoder.
+func (t *SystemTransfer) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, rlpSystemTransfer{
+		Reason:    t.Reason,
+		From:      t.From,
+		Recipient: t.Recipient,
+		Validator: t.Validator,
+		Epoch:     t.Epoch,
+		Amount:    t.Amount,
+	})
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (t *SystemTransfer) DecodeRLP(s *rlp.Stream) error {
+	var dec rlpSystemTransfer
+	if err := s.Decode(&dec); err != nil {
+		return err
+	}
+	*t = SystemTransfer{
+		Reason:    dec.Reason,
+		From:      dec.From,
+		Recipient: dec.Recipient,
+		Validator: dec.Validator,
+		Epoch:     dec.Epoch,
+		Amount:    dec.Amount,
+	}
+	return nil
+}
+
+type systemTransferJSON struct {
+	Reason      SystemTransferReason `json:"reason"`
+	From        common.Address       `json:"from"`
+	Recipient   common.Address       `json:"recipient"`
+	Validator   common.Address       `json:"validator"`
+	Epoch       hexutil.Uint64       `json:"epoch"`
+	Amount      *hexutil.Big         `json:"amount"`
+	BlockNumber hexutil.Uint64       `json:"blockNumber"`
+	BlockHash   common.Hash          `json:"blockHash"`
+	Index       hexutil.Uint         `json:"index"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t *SystemTransfer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&systemTransferJSON{
+		Reason:      t.Reason,
+		From:        t.From,
+		Recipient:   t.Recipient,
+		Validator:   t.Validator,
+		Epoch:       hexutil.Uint64(t.Epoch),
+		Amount:      (*hexutil.Big)(t.Amount),
+		BlockNumber: hexutil.Uint64(t.BlockNumber),
+		BlockHash:   t.BlockHash,
+		Index:       hexutil.Uint(t.Index),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *SystemTransfer) UnmarshalJSON(input []byte) error {
+	var dec systemTransferJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*t = SystemTransfer{
+		Reason:      dec.Reason,
+		From:        dec.From,
+		Recipient:   dec.Recipient,
+		Validator:   dec.Validator,
+		Epoch:       uint64(dec.Epoch),
+		Amount:      (*big.Int)(dec.Amount),
+		BlockNumber: uint64(dec.BlockNumber),
+		BlockHash:   dec.BlockHash,
+		Index:       uint(dec.Index),
+	}
+	return nil
+}
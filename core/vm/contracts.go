@@ -657,12 +657,12 @@ func (c *wanCoinSC) RequiredGas(input []byte) uint64 {
 			return params.RequiredGasPerMixPub
 		}
 
-		err, publickeys, _, _, _ := DecodeRingSignOut(RefundStruct.RingSignedData)
+		_, sig, err := DecodeRingSignature(RefundStruct.RingSignedData)
 		if err != nil {
 			return params.RequiredGasPerMixPub
 		}
 
-		mixLen := len(publickeys)
+		mixLen := len(sig.PublicKeys)
 		ringSigDiffRequiredGas := params.RequiredGasPerMixPub * (uint64(mixLen))
 
 		// ringsign compute gas + ota image key store setting gas
@@ -693,6 +693,13 @@ func (c *wanCoinSC) Run(in []byte, contract *Contract, evm *EVM) ([]byte, error)
 }
 
 func (c *wanCoinSC) ValidTx(stateDB StateDB, signer types.Signer, tx *types.Transaction) error {
+	return c.ValidTxAt(stateDB, signer, tx, nil, nil)
+}
+
+// ValidTxAt validates a transaction going into block number. Compact ring
+// signature refunds are only valid from the fork on, without a chain config
+// they are rejected.
+func (c *wanCoinSC) ValidTxAt(stateDB StateDB, signer types.Signer, tx *types.Transaction, config *params.ChainConfig, number *big.Int) error {
 	if stateDB == nil || signer == nil || tx == nil {
 		return errParameters
 	}
@@ -715,7 +722,8 @@ func (c *wanCoinSC) ValidTx(stateDB StateDB, signer types.Signer, tx *types.Tran
 			return err
 		}
 
-		_, _, err = c.ValidRefundReq(stateDB, payload[4:], from.Bytes())
+		allowCompact := config != nil && number != nil && config.IsCompactRingSig(number)
+		_, _, err = c.ValidRefundReq(stateDB, payload[4:], from.Bytes(), allowCompact)
 		return err
 	}
 
@@ -795,7 +803,8 @@ func (c *wanCoinSC) buyCoin(in []byte, contract *Contract, evm *EVM) ([]byte, er
 	}
 }
 
-func (c *wanCoinSC) ValidRefundReq(stateDB StateDB, payload []byte, from []byte) (image []byte, value *big.Int, err error) {
+// ValidRefundReq checks a refundCoin request. Compact ring signatures are only accepted if allowCompact is set.
+func (c *wanCoinSC) ValidRefundReq(stateDB StateDB, payload []byte, from []byte, allowCompact bool) (image []byte, value *big.Int, err error) {
 	if stateDB == nil || len(payload) == 0 || len(from) == 0 {
 		return nil, nil, errors.New("unknown error")
 	}
@@ -810,7 +819,7 @@ func (c *wanCoinSC) ValidRefundReq(stateDB StateDB, payload []byte, from []byte)
 		return nil, nil, errRefundCoin
 	}
 
-	ringSignInfo, err := FetchRingSignInfo(stateDB, from, RefundStruct.RingSignedData)
+	ringSignInfo, err := fetchRingSignInfo(stateDB, from, RefundStruct.RingSignedData, allowCompact)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -834,7 +843,8 @@ func (c *wanCoinSC) ValidRefundReq(stateDB StateDB, payload []byte, from []byte)
 }
 
 func (c *wanCoinSC) refund(all []byte, contract *Contract, evm *EVM) ([]byte, error) {
-	kix, value, err := c.ValidRefundReq(evm.StateDB, all, contract.CallerAddress.Bytes())
+	allowCompact := evm.ChainConfig().IsCompactRingSig(evm.BlockNumber)
+	kix, value, err := c.ValidRefundReq(evm.StateDB, all, contract.CallerAddress.Bytes(), allowCompact)
 	if err != nil {
 		fmt.Println("failed refund")
 		fmt.Println(evm.BlockNumber)
@@ -910,6 +920,43 @@ func DecodeRingSignOut(s string) (error, []*ecdsa.PublicKey, *ecdsa.PublicKey, [
 	return nil, publickeys, keyimgae, w, q
 }
 
+// CompactRingSignTag is appended to a ring signed string produced by crypto.CompactRingSigner.
+const CompactRingSignTag = "lsag"
+
+// DecodeRingSignature decodes a ring signed string and returns the scheme it was signed with.
+// The default format is "pubs+I+w+q", a compact signature is "pubs+I+c+r+lsag" with a single c.
+func DecodeRingSignature(s string) (crypto.RingSigner, *crypto.RingSignature, error) {
+	ss := strings.Split(s, "+")
+	if len(ss) == 5 && ss[4] == CompactRingSignTag {
+		// reuse the default decoder by expanding the single challenge
+		ps, k, cs, rs := ss[0], ss[1], ss[2], ss[3]
+		c, err := hexutil.DecodeBig(cs)
+		if c == nil || err != nil {
+			return nil, nil, ErrInvalidRingSigned
+		}
+
+		n := len(strings.Split(ps, "&"))
+		expanded := make([]string, n)
+		for i := range expanded {
+			expanded[i] = cs
+		}
+
+		err, publickeys, keyimage, _, r := DecodeRingSignOut(strings.Join([]string{ps, k, strings.Join(expanded, "&"), rs}, "+"))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return crypto.CompactRingSigner, &crypto.RingSignature{PublicKeys: publickeys, KeyImage: keyimage, C: []*big.Int{c}, R: r}, nil
+	}
+
+	err, publickeys, keyimage, w, q := DecodeRingSignOut(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return crypto.DefaultRingSigner, &crypto.RingSignature{PublicKeys: publickeys, KeyImage: keyimage, C: w, R: q}, nil
+}
+
 type RingSignInfo struct {
 	PublicKeys []*ecdsa.PublicKey
 	KeyImage   *ecdsa.PublicKey
@@ -918,18 +965,32 @@ type RingSignInfo struct {
 	OTABalance *big.Int
 }
 
+// FetchRingSignInfo decodes and verifies a ring signed string of the default scheme.
 func FetchRingSignInfo(stateDB StateDB, hashInput []byte, ringSignedStr string) (info *RingSignInfo, err error) {
+	return fetchRingSignInfo(stateDB, hashInput, ringSignedStr, false)
+}
+
+func fetchRingSignInfo(stateDB StateDB, hashInput []byte, ringSignedStr string, allowCompact bool) (info *RingSignInfo, err error) {
 	if stateDB == nil || hashInput == nil {
 		return nil, errParameters
 	}
 
-	infoTmp := new(RingSignInfo)
-
-	err, infoTmp.PublicKeys, infoTmp.KeyImage, infoTmp.W_Random, infoTmp.Q_Random = DecodeRingSignOut(ringSignedStr)
+	signer, sig, err := DecodeRingSignature(ringSignedStr)
 	if err != nil {
 		return nil, err
 	}
 
+	if signer == crypto.CompactRingSigner && !allowCompact {
+		return nil, ErrInvalidRingSigned
+	}
+
+	infoTmp := &RingSignInfo{
+		PublicKeys: sig.PublicKeys,
+		KeyImage:   sig.KeyImage,
+		W_Random:   sig.C,
+		Q_Random:   sig.R,
+	}
+
 	otaLongs := make([][]byte, 0, len(infoTmp.PublicKeys))
 	for i := 0; i < len(infoTmp.PublicKeys); i++ {
 		otaLongs = append(otaLongs, keystore.ECDSAPKCompression(infoTmp.PublicKeys[i]))
@@ -948,7 +1009,7 @@ func FetchRingSignInfo(stateDB StateDB, hashInput []byte, ringSignedStr string)
 
 	infoTmp.OTABalance = balanceGet
 
-	valid := crypto.VerifyRingSign(hashInput, infoTmp.PublicKeys, infoTmp.KeyImage, infoTmp.W_Random, infoTmp.Q_Random)
+	valid := signer.Verify(hashInput, sig)
 	if !valid {
 		return nil, ErrInvalidRingSigned
 	}
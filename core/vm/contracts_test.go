@@ -0,0 +1,145 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package vm
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/accounts/keystore"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/params"
+)
+
+func encodeTestRingSignature(sig *crypto.RingSignature, compact bool) string {
+	pks := make([]string, 0, len(sig.PublicKeys))
+	for _, pk := range sig.PublicKeys {
+		pks = append(pks, common.ToHex(crypto.FromECDSAPub(pk)))
+	}
+	cs := make([]string, 0, len(sig.C))
+	for _, c := range sig.C {
+		cs = append(cs, hexutil.EncodeBig(c))
+	}
+	rs := make([]string, 0, len(sig.R))
+	for _, r := range sig.R {
+		rs = append(rs, hexutil.EncodeBig(r))
+	}
+
+	parts := []string{strings.Join(pks, "&"), common.ToHex(crypto.FromECDSAPub(sig.KeyImage)), strings.Join(cs, "&"), strings.Join(rs, "&")}
+	if compact {
+		parts = append(parts, CompactRingSignTag)
+	}
+	return strings.Join(parts, "+")
+}
+
+func TestFetchRingSignInfoSchemes(t *testing.T) {
+	var (
+		db, _      = ethdb.NewMemDatabase()
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(db))
+		balance, _ = new(big.Int).SetString(Wancoin10, 10)
+		hashInput  = common.HexToAddress("0x0102030405").Bytes()
+	)
+
+	var (
+		key  *ecdsa.PrivateKey
+		ring []*ecdsa.PublicKey
+	)
+	for i := 0; i < 4; i++ {
+		otaKey, _ := crypto.GenerateKey()
+		s1, _ := crypto.GenerateKey()
+		wanAddr := keystore.GenerateWaddressFromPK(&otaKey.PublicKey, &s1.PublicKey)
+		if err := setOTA(statedb, balance, wanAddr[:]); err != nil {
+			t.Fatal(err)
+		}
+		if key == nil {
+			key = otaKey
+		}
+		ring = append(ring, &otaKey.PublicKey)
+	}
+
+	for _, compact := range []bool{false, true} {
+		signer := crypto.DefaultRingSigner
+		if compact {
+			signer = crypto.CompactRingSigner
+		}
+		sig, err := signer.Sign(hashInput, key.D, append([]*ecdsa.PublicKey{}, ring...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded := encodeTestRingSignature(sig, compact)
+
+		decodedSigner, decoded, err := DecodeRingSignature(encoded)
+		if err != nil || decodedSigner != signer || len(decoded.PublicKeys) != len(ring) {
+			t.Fatalf("compact:%v decode failed, err:%v", compact, err)
+		}
+
+		_, err = fetchRingSignInfo(statedb, hashInput, encoded, false)
+		if compact && err != ErrInvalidRingSigned {
+			t.Fatalf("compact signature accepted before the fork, err:%v", err)
+		} else if !compact && err != nil {
+			t.Fatalf("default signature rejected: %v", err)
+		}
+
+		info, err := fetchRingSignInfo(statedb, hashInput, encoded, true)
+		if err != nil {
+			t.Fatalf("compact:%v signature rejected after the fork: %v", compact, err)
+		}
+		if info.OTABalance.Cmp(balance) != 0 {
+			t.Fatalf("compact:%v wrong ota balance %v", compact, info.OTABalance)
+		}
+		image := crypto.ComputeKeyImage(key.D, &key.PublicKey)
+		if info.KeyImage.X.Cmp(image.X) != 0 || info.KeyImage.Y.Cmp(image.Y) != 0 {
+			t.Fatalf("compact:%v key image mismatch", compact)
+		}
+	}
+}
+
+func TestRefundValidTxFork(t *testing.T) {
+	var (
+		db, _      = ethdb.NewMemDatabase()
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(db))
+		balance, _ = new(big.Int).SetString(Wancoin10, 10)
+		sender, _  = crypto.GenerateKey()
+		from       = crypto.PubkeyToAddress(sender.PublicKey)
+		signer     = types.HomesteadSigner{}
+	)
+
+	otaKey, _ := crypto.GenerateKey()
+	s1, _ := crypto.GenerateKey()
+	wanAddr := keystore.GenerateWaddressFromPK(&otaKey.PublicKey, &s1.PublicKey)
+	if err := setOTA(statedb, balance, wanAddr[:]); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.CompactRingSigner.Sign(from.Bytes(), otaKey.D, []*ecdsa.PublicKey{&otaKey.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := coinAbi.Pack("refundCoin", encodeTestRingSignature(sig, true), balance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := types.SignTx(types.NewTransaction(0, wanCoinPrecompileAddr, new(big.Int), big.NewInt(300000), big.NewInt(1), payload), signer, sender)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A compact refund is only valid in the blocks of the fork
+	contract := PrecompiledContractsByzantium[wanCoinPrecompileAddr].(ForkValidator)
+	config := &params.ChainConfig{CompactRingSigBlock: big.NewInt(10)}
+	if err := contract.ValidTxAt(statedb, signer, tx, config, big.NewInt(9)); err != ErrInvalidRingSigned {
+		t.Errorf("refund before the fork error mismatch: have %v, want %v", err, ErrInvalidRingSigned)
+	}
+	if err := contract.ValidTxAt(statedb, signer, tx, config, big.NewInt(10)); err != nil {
+		t.Errorf("refund at the fork rejected: %v", err)
+	}
+	if err := PrecompiledContractsByzantium[wanCoinPrecompileAddr].ValidTx(statedb, signer, tx); err != ErrInvalidRingSigned {
+		t.Errorf("refund without a chain config error mismatch: have %v, want %v", err, ErrInvalidRingSigned)
+	}
+}
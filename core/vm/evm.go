@@ -148,6 +148,10 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	if !evm.Context.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, gas, ErrInsufficientBalance
 	}
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(evm, CALL, caller.Address(), addr, input, gas, value)
+		defer func() { evm.vmConfig.Tracer.CaptureExit(evm, ret, gas-leftOverGas, err) }()
+	}
 
 	var (
 		to       = AccountRef(addr)
@@ -214,6 +218,10 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	if !evm.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, gas, ErrInsufficientBalance
 	}
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(evm, CALLCODE, caller.Address(), addr, input, gas, value)
+		defer func() { evm.vmConfig.Tracer.CaptureExit(evm, ret, gas-leftOverGas, err) }()
+	}
 
 	var (
 		snapshot = evm.StateDB.Snapshot()
@@ -248,6 +256,10 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
 	}
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(evm, DELEGATECALL, caller.Address(), addr, input, gas, nil)
+		defer func() { evm.vmConfig.Tracer.CaptureExit(evm, ret, gas-leftOverGas, err) }()
+	}
 
 	var (
 		snapshot = evm.StateDB.Snapshot()
@@ -280,6 +292,10 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
 	}
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(evm, STATICCALL, caller.Address(), addr, input, gas, nil)
+		defer func() { evm.vmConfig.Tracer.CaptureExit(evm, ret, gas-leftOverGas, err) }()
+	}
 	// Make sure the readonly is only set if we aren't in readonly yet
 	// this makes also sure that the readonly flag isn't removed for
 	// child calls.
@@ -311,8 +327,8 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	return ret, contract.Gas, err
 }
 
-// Create creates a new contract using code as deployment code.
-func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
+// create creates a new contract at the given address using code as deployment code.
+func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, value *big.Int, contractAddr common.Address, typ OpCode) (ret []byte, createdAddr common.Address, leftOverGas uint64, err error) {
 
 	// Depth check execution. Fail if we're trying to execute above the
 	// limit.
@@ -326,7 +342,10 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 	nonce := evm.StateDB.GetNonce(caller.Address())
 	evm.StateDB.SetNonce(caller.Address(), nonce+1)
 
-	contractAddr = crypto.CreateAddress(caller.Address(), nonce)
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(evm, typ, caller.Address(), contractAddr, code, gas, value)
+		defer func() { evm.vmConfig.Tracer.CaptureExit(evm, ret, gas-leftOverGas, err) }()
+	}
 	if !evm.StateDB.Empty(contractAddr) {
 		return nil, common.Address{}, 0, ErrContractAddressCollision
 	}
@@ -381,6 +400,19 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 	return ret, contractAddr, contract.Gas, err
 }
 
+// Create creates a new contract using code as deployment code.
+func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
+	contractAddr = crypto.CreateAddress(caller.Address(), evm.StateDB.GetNonce(caller.Address()))
+	return evm.create(caller, code, gas, value, contractAddr, CREATE)
+}
+
+// Create2 creates a new contract using code as deployment code, at an address
+// derived from the salt and the code instead of the nonce of the caller.
+func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, value *big.Int, salt *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
+	contractAddr = crypto.CreateAddress2(caller.Address(), common.BigToHash(salt), crypto.Keccak256(code))
+	return evm.create(caller, code, gas, value, contractAddr, CREATE2)
+}
+
 // ChainConfig returns the evmironment's chain configuration
 func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
 
@@ -139,6 +139,50 @@ func gasSStore(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, m
 	}
 }
 
+// gasSStoreEIP2200 calculates the gas of an SSTORE after the istanbul fork
+// from the original value of the slot at the start of the transaction, so
+// that rewriting a slot already written by the transaction is cheap (EIP-2200).
+func gasSStoreEIP2200(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	// If we fail the minimum gas availability invariant, fail (0)
+	if contract.Gas <= params.SstoreSentryGasEIP2200 {
+		return 0, errSstoreSentry
+	}
+	// Gas sentry honoured, do the actual gas calculation based on the stored value
+	var (
+		y, x    = stack.Back(1), stack.Back(0)
+		current = evm.StateDB.GetState(contract.Address(), common.BigToHash(x))
+		value   = common.BigToHash(y)
+	)
+	if current == value { // noop (1)
+		return params.SstoreNoopGasEIP2200, nil
+	}
+	original := evm.StateDB.GetCommittedState(contract.Address(), common.BigToHash(x))
+	if original == current {
+		if common.EmptyHash(original) { // create slot (2.1.1)
+			return params.SstoreInitGasEIP2200, nil
+		}
+		if common.EmptyHash(value) { // delete slot (2.1.2b)
+			evm.StateDB.AddRefund(new(big.Int).SetUint64(params.SstoreClearRefundEIP2200))
+		}
+		return params.SstoreCleanGasEIP2200, nil // write existing slot (2.1.2)
+	}
+	if !common.EmptyHash(original) {
+		if common.EmptyHash(current) { // recreate slot (2.2.1.1)
+			evm.StateDB.SubRefund(new(big.Int).SetUint64(params.SstoreClearRefundEIP2200))
+		} else if common.EmptyHash(value) { // delete slot (2.2.1.2)
+			evm.StateDB.AddRefund(new(big.Int).SetUint64(params.SstoreClearRefundEIP2200))
+		}
+	}
+	if original == value {
+		if common.EmptyHash(original) { // reset to original inexistent slot (2.2.2.1)
+			evm.StateDB.AddRefund(new(big.Int).SetUint64(params.SstoreInitRefundEIP2200))
+		} else { // reset to original existing slot (2.2.2.2)
+			evm.StateDB.AddRefund(new(big.Int).SetUint64(params.SstoreCleanRefundEIP2200))
+		}
+	}
+	return params.SstoreDirtyGasEIP2200, nil // dirty update (2.2)
+}
+
 func makeGasLog(n uint64) gasFunc {
 	return func(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
 		requestedSize, overflow := bigUint64(stack.Back(1))
@@ -291,6 +335,25 @@ func gasCreate(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, m
 	return gas, nil
 }
 
+func gasCreate2(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	gas, err := gasCreate(gt, evm, contract, stack, mem, memorySize)
+	if err != nil {
+		return 0, err
+	}
+	// The init code is hashed to derive the contract address
+	wordGas, overflow := bigUint64(stack.Back(2))
+	if overflow {
+		return 0, errGasUintOverflow
+	}
+	if wordGas, overflow = math.SafeMul(toWordSize(wordGas), params.Sha3WordGas); overflow {
+		return 0, errGasUintOverflow
+	}
+	if gas, overflow = math.SafeAdd(gas, wordGas); overflow {
+		return 0, errGasUintOverflow
+	}
+	return gas, nil
+}
+
 func gasBalance(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
 	return gt.Balance, nil
 }
@@ -299,6 +362,10 @@ func gasExtCodeSize(gt params.GasTable, evm *EVM, contract *Contract, stack *Sta
 	return gt.ExtcodeSize, nil
 }
 
+func gasExtCodeHash(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	return gt.ExtcodeHash, nil
+}
+
 func gasSLoad(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
 	return gt.SLoad, nil
 }
@@ -30,7 +30,10 @@ type (
 	memorySizeFunc      func(*Stack) *big.Int
 )
 
-var errGasUintOverflow = errors.New("gas uint64 overflow")
+var (
+	errGasUintOverflow = errors.New("gas uint64 overflow")
+	errSstoreSentry    = errors.New("not enough gas for reentrancy sentry")
+)
 
 type operation struct {
 	// op is the operation function
@@ -54,8 +57,71 @@ var (
 	frontierInstructionSet  = NewFrontierInstructionSet()
 	homesteadInstructionSet = NewHomesteadInstructionSet()
 	byzantiumInstructionSet = NewByzantiumInstructionSet()
+	istanbulInstructionSet  = NewIstanbulInstructionSet()
 )
 
+// NewIstanbulInstructionSet returns the frontier, homestead, byzantium,
+// constantinople and istanbul instructions.
+func NewIstanbulInstructionSet() [256]operation {
+	// instructions that can be executed during the istanbul phase.
+	instructionSet := NewConstantinopleInstructionSet()
+	instructionSet[SSTORE].gasCost = gasSStoreEIP2200
+	instructionSet[CHAINID] = operation{
+		execute:       opChainID,
+		gasCost:       constGasFunc(GasQuickStep),
+		validateStack: makeStackFunc(0, 1),
+		valid:         true,
+	}
+	instructionSet[SELFBALANCE] = operation{
+		execute:       opSelfBalance,
+		gasCost:       constGasFunc(GasFastStep),
+		validateStack: makeStackFunc(0, 1),
+		valid:         true,
+	}
+	return instructionSet
+}
+
+// NewConstantinopleInstructionSet returns the frontier, homestead,
+// byzantium and constantinople instructions.
+func NewConstantinopleInstructionSet() [256]operation {
+	// instructions that can be executed during the constantinople phase.
+	instructionSet := NewByzantiumInstructionSet()
+	instructionSet[SHL] = operation{
+		execute:       opSHL,
+		gasCost:       constGasFunc(GasFastestStep),
+		validateStack: makeStackFunc(2, 1),
+		valid:         true,
+	}
+	instructionSet[SHR] = operation{
+		execute:       opSHR,
+		gasCost:       constGasFunc(GasFastestStep),
+		validateStack: makeStackFunc(2, 1),
+		valid:         true,
+	}
+	instructionSet[SAR] = operation{
+		execute:       opSAR,
+		gasCost:       constGasFunc(GasFastestStep),
+		validateStack: makeStackFunc(2, 1),
+		valid:         true,
+	}
+	instructionSet[EXTCODEHASH] = operation{
+		execute:       opExtCodeHash,
+		gasCost:       gasExtCodeHash,
+		validateStack: makeStackFunc(1, 1),
+		valid:         true,
+	}
+	instructionSet[CREATE2] = operation{
+		execute:       opCreate2,
+		gasCost:       gasCreate2,
+		validateStack: makeStackFunc(4, 1),
+		memorySize:    memoryCreate2,
+		valid:         true,
+		writes:        true,
+		returns:       true,
+	}
+	return instructionSet
+}
+
 // NewByzantiumInstructionSet returns the frontier, homestead and
 // byzantium instructions.
 func NewByzantiumInstructionSet() [256]operation {
@@ -81,11 +81,15 @@ func (s *StructLog) OpName() string {
 
 // Tracer is used to collect execution traces from an EVM transaction
 // execution. CaptureState is called for each step of the VM with the
-// current VM state.
+// current VM state. CaptureEnter and CaptureExit are called when a call
+// frame is entered and left, including the frames of calls into the
+// precompiled contracts, which run no steps.
 // Note that reference types are actual VM data structures; make copies
 // if you need to retain them beyond the current call.
 type Tracer interface {
 	CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error
+	CaptureEnter(env *EVM, typ OpCode, from, to common.Address, input []byte, gas uint64, value *big.Int) error
+	CaptureExit(env *EVM, output []byte, gasUsed uint64, err error) error
 	CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error
 }
 
@@ -179,6 +183,16 @@ func (l *StructLogger) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost ui
 	return nil
 }
 
+// CaptureEnter is called when the EVM enters a call frame.
+func (l *StructLogger) CaptureEnter(env *EVM, typ OpCode, from, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureExit is called when the EVM leaves a call frame.
+func (l *StructLogger) CaptureExit(env *EVM, output []byte, gasUsed uint64, err error) error {
+	return nil
+}
+
 func (l *StructLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
 	fmt.Printf("0x%x", output)
 	if err != nil {
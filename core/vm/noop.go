@@ -57,7 +57,9 @@ func (NoopStateDB) GetCode(common.Address) []byte
 func (NoopStateDB) SetCode(common.Address, []byte)                                     {}
 func (NoopStateDB) GetCodeSize(common.Address) int                                     { return 0 }
 func (NoopStateDB) AddRefund(*big.Int)                                                 {}
+func (NoopStateDB) SubRefund(*big.Int)                                                 {}
 func (NoopStateDB) GetRefund() *big.Int                                                { return nil }
+func (NoopStateDB) GetCommittedState(common.Address, common.Hash) common.Hash          { return common.Hash{} }
 func (NoopStateDB) GetState(common.Address, common.Hash) common.Hash                   { return common.Hash{} }
 func (NoopStateDB) SetState(common.Address, common.Hash, common.Hash)                  {}
 func (NoopStateDB) Suicide(common.Address) bool                                        { return false }
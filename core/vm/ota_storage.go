@@ -434,6 +434,23 @@ func GetOTASet(statedb StateDB, otaAX []byte, setNum int) (otaWanAddrs [][]byte,
 	}
 }
 
+// ForEachOTA travels all OTA wanaddr stored with the balance, until cb returns false.
+func ForEachOTA(statedb StateDB, balance *big.Int, cb func(otaWanAddr []byte) bool) error {
+	if statedb == nil || balance == nil || cb == nil {
+		return ErrUnknown
+	}
+
+	mptAddr := OTABalance2ContractAddr(balance)
+	statedb.ForEachStorageByteArray(mptAddr, func(key common.Hash, value []byte) bool {
+		if len(value) != common.WAddressLength {
+			return true
+		}
+		return cb(value)
+	})
+
+	return nil
+}
+
 // CheckOTAImageExist checks ota image key exist already or not
 func CheckOTAImageExist(statedb StateDB, otaImage []byte) (bool, []byte, error) {
 	if statedb == nil || len(otaImage) == 0 {
@@ -5,7 +5,6 @@ import (
 	"math/big"
 	"sort"
 	"strings"
-	"time"
 
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
@@ -127,7 +126,7 @@ func (p *PosControl) ValidTx(stateDB StateDB, signer types.Signer, tx *types.Tra
 	copy(methodId[:], input[:4])
 
 	if methodId == upgradeWhiteEpochLeaderId {
-		_, err := p.upgradeWhiteEpochLeaderParseAndValid(input[4:], uint64(time.Now().Unix()))
+		_, err := p.upgradeWhiteEpochLeaderParseAndValid(input[4:], uint64(util.Now().Unix()))
 		if err != nil {
 			return errors.New("upgradeWhiteEpochLeaderParseAndValid verify failed")
 		}
@@ -0,0 +1,79 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package vm
+
+import (
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/pos/util/convert"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+// slot leader selection stages, as returned by GetSlStage, in which the
+// stage one and stage two transactions are accepted.
+const (
+	SlStage1TxStage = 1
+	SlStage2TxStage = 3
+)
+
+// rbTxHeader is the part shared by the payloads of the random beacon methods.
+type rbTxHeader struct {
+	EpochId    uint64
+	ProposerId uint32
+	Rest       []rlp.RawValue `rlp:"tail"`
+}
+
+// IsPosProtocolAddr tells whether addr is the random beacon or the slot
+// leader selection contract, whose transactions are sent by the protocol.
+func IsPosProtocolAddr(addr *common.Address) bool {
+	return addr != nil && (*addr == randomBeaconPrecompileAddr || *addr == slotLeaderPrecompileAddr)
+}
+
+// PosProtocolTxStage returns the epoch and the stage in which a random beacon
+// or slot leader selection transaction must be mined. The stage is one of
+// the RbDkg1Stage, RbDkg2Stage and RbSignStage for the random beacon and a
+// GetSlStage value for the slot leader selection.
+func PosProtocolTxStage(to common.Address, payload []byte) (uint64, int, error) {
+	if len(payload) < 4 {
+		return 0, 0, errParameters
+	}
+	var methodId [4]byte
+	copy(methodId[:], payload[:4])
+
+	switch to {
+	case randomBeaconPrecompileAddr:
+		var stage int
+		switch methodId {
+		case dkg1Id:
+			stage = RbDkg1Stage
+		case dkg2Id:
+			stage = RbDkg2Stage
+		case sigShareId:
+			stage = RbSignStage
+		default:
+			return 0, 0, errParameters
+		}
+		var header rbTxHeader
+		if err := rlp.DecodeBytes(payload[4:], &header); err != nil {
+			return 0, 0, err
+		}
+		return header.EpochId, stage, nil
+
+	case slotLeaderPrecompileAddr:
+		switch methodId {
+		case stgOneIdArr:
+			epochIDBuf, _, err := RlpGetStage1IDFromTx(payload)
+			if err != nil {
+				return 0, 0, err
+			}
+			return convert.BytesToUint64(epochIDBuf), SlStage1TxStage, nil
+		case stgTwoIdArr:
+			epochIDBuf, _, err := RlpGetStage2IDFromTx(payload)
+			if err != nil {
+				return 0, 0, err
+			}
+			return convert.BytesToUint64(epochIDBuf), SlStage2TxStage, nil
+		}
+		return 0, 0, errMethodId
+	}
+	return 0, 0, errParameters
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/wanchain/go-wanchain/params"
 	"math/big"
 	"strings"
-	"time"
 
 	"github.com/wanchain/go-wanchain/pos/posconfig"
 
@@ -626,7 +625,7 @@ func (p *PosStaking) ValidTx(stateDB StateDB, signer types.Signer, tx *types.Tra
 	copy(methodId[:], input[:4])
 
 	if methodId == stakeRegisterId {
-		eidNow, _ := util.CalEpochSlotID(uint64(time.Now().Unix()))
+		eidNow, _ := util.CalEpochSlotID(uint64(util.Now().Unix()))
 		if eidNow < posconfig.ApolloEpochID {
 			return  errors.New("stakeRegister haven't enabled.")
 		}
@@ -672,7 +671,7 @@ func (p *PosStaking) ValidTx(stateDB StateDB, signer types.Signer, tx *types.Tra
 		}
 		return nil
 	} else if methodId == stakeUpdateFeeRateId {
-		eidNow, _ := util.CalEpochSlotID(uint64(time.Now().Unix()))
+		eidNow, _ := util.CalEpochSlotID(uint64(util.Now().Unix()))
 		if eidNow < posconfig.ApolloEpochID {
 			return  errors.New("stakeUpdateFeeRateId haven't enabled.")
 		}
@@ -1221,6 +1220,12 @@ func GetStakersSnap(stateDb *state.StateDB) []StakerInfo {
 	return stakeHolders
 }
 
+// PackStakingInput packs the input of a pos staking contract method, it is
+// used by tools which build staking calls outside of a transaction.
+func PackStakingInput(method string, args ...interface{}) ([]byte, error) {
+	return cscAbi.Pack(method, args...)
+}
+
 func StakeoutSetEpoch(stateDb *state.StateDB, epochID uint64) {
 	b := big.NewInt(int64(epochID))
 	StoreInfo(stateDb, StakingCommonAddr, StakersInfoStakeOutKeyHash, b.Bytes())
@@ -56,7 +56,9 @@ func (StakerStateDB) GetCode(common.Address) []byte
 func (StakerStateDB) SetCode(common.Address, []byte)                                         {}
 func (StakerStateDB) GetCodeSize(common.Address) int                                         { return 0 }
 func (StakerStateDB) AddRefund(*big.Int)                                                     {}
+func (StakerStateDB) SubRefund(*big.Int)                                                     {}
 func (StakerStateDB) GetRefund() *big.Int                                                    { return nil }
+func (StakerStateDB) GetCommittedState(common.Address, common.Hash) common.Hash              { return common.Hash{} }
 func (StakerStateDB) GetState(common.Address, common.Hash) common.Hash                       { return common.Hash{} }
 func (StakerStateDB) SetState(common.Address, common.Hash, common.Hash)                      {}
 func (StakerStateDB) Suicide(common.Address) bool                                            { return false }
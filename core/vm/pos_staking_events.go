@@ -0,0 +1,173 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/accounts/abi"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+)
+
+var (
+	ErrNotStakingLog  = errors.New("not a pos staking contract log")
+	ErrNotStakingCall = errors.New("not a pos staking contract call")
+
+	// topics of the logs written before Apollo, they are keyed by the method
+	// signature and carry every argument as a topic.
+	legacyStakingLogs = map[string][]string{
+		"stakeIn":     {"sender", "v", "feeRate", "lockEpoch", "posAddress"},
+		"stakeAppend": {"sender", "v", "posAddress"},
+		"stakeUpdate": {"sender", "lockEpoch", "posAddress"},
+		"delegateIn":  {"sender", "v", "posAddress"},
+		"delegateOut": {"sender", "posAddress"},
+	}
+)
+
+// StakingEvent is an operation of the pos staking contract, decoded with the
+// contract abi from its log or from the call input. Fields which the
+// operation does not carry are nil.
+type StakingEvent struct {
+	Name       string
+	Sender     common.Address
+	Validator  common.Address
+	Amount     *big.Int
+	LockEpochs *big.Int
+	FeeRate    *big.Int
+	MaxFeeRate *big.Int
+	Renewal    *bool
+}
+
+// DecodeStakingLog decodes a log written by the pos staking contract.
+func DecodeStakingLog(log *types.Log) (*StakingEvent, error) {
+	if log == nil || log.Address != WanCscPrecompileAddr || len(log.Topics) == 0 {
+		return nil, ErrNotStakingLog
+	}
+
+	for name, event := range cscAbi.Events {
+		if log.Topics[0] != event.Id() {
+			continue
+		}
+
+		values := make(map[string]interface{})
+		topic := 1
+		for _, input := range event.Inputs {
+			if !input.Indexed {
+				continue
+			}
+			if topic >= len(log.Topics) {
+				return nil, ErrNotStakingLog
+			}
+			values[input.Name] = topicValue(input.Type, log.Topics[topic])
+			topic++
+		}
+
+		nonIndexed := event.Inputs.NonIndexed()
+		unpacked, err := nonIndexed.UnpackValues(log.Data)
+		if err != nil {
+			return nil, err
+		}
+		for i, input := range nonIndexed {
+			values[input.Name] = unpacked[i]
+		}
+		return newStakingEvent(name, values), nil
+	}
+
+	for name, layout := range legacyStakingLogs {
+		if log.Topics[0] != common.BytesToHash(crypto.Keccak256([]byte(cscAbi.Methods[name].Sig()))) {
+			continue
+		}
+		if len(log.Topics) != len(layout)+1 {
+			return nil, ErrNotStakingLog
+		}
+
+		values := make(map[string]interface{})
+		for i, arg := range layout {
+			if arg == "sender" || arg == "posAddress" {
+				values[arg] = common.BytesToAddress(log.Topics[i+1].Bytes())
+			} else {
+				values[arg] = log.Topics[i+1].Big()
+			}
+		}
+		return newStakingEvent(name, values), nil
+	}
+	return nil, ErrNotStakingLog
+}
+
+// DecodeStakingCall decodes a call to the pos staking contract, it is used for
+// the operations which did not write a log before Apollo.
+func DecodeStakingCall(from common.Address, input []byte, value *big.Int) (*StakingEvent, error) {
+	if len(input) < 4 {
+		return nil, ErrNotStakingCall
+	}
+	method, err := cscAbi.MethodById(input[:4])
+	if err != nil {
+		return nil, ErrNotStakingCall
+	}
+	unpacked, err := method.Inputs.UnpackValues(input[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{"sender": from}
+	for i, arg := range method.Inputs {
+		values[arg.Name] = unpacked[i]
+	}
+	if secPk, ok := values["secPk"].([]byte); ok {
+		if pub := crypto.ToECDSAPub(secPk); pub != nil {
+			values["posAddress"] = crypto.PubkeyToAddress(*pub)
+		}
+	}
+	if value != nil && value.Sign() > 0 {
+		values["v"] = new(big.Int).Set(value)
+	}
+	return newStakingEvent(method.Name, values), nil
+}
+
+func topicValue(typ abi.Type, topic common.Hash) interface{} {
+	switch typ.T {
+	case abi.AddressTy:
+		return common.BytesToAddress(topic.Bytes())
+	case abi.BoolTy:
+		return topic.Big().Sign() != 0
+	default:
+		return topic.Big()
+	}
+}
+
+// newStakingEvent maps the abi argument names of the methods and events to
+// the event fields.
+func newStakingEvent(name string, values map[string]interface{}) *StakingEvent {
+	event := &StakingEvent{Name: name}
+	for arg, value := range values {
+		switch v := value.(type) {
+		case common.Address:
+			switch arg {
+			case "sender":
+				event.Sender = v
+			case "posAddress", "addr", "delegateAddress":
+				event.Validator = v
+			}
+		case *big.Int:
+			switch arg {
+			case "v":
+				event.Amount = v
+			case "lockEpoch", "lockEpochs":
+				event.LockEpochs = v
+			case "feeRate":
+				event.FeeRate = v
+			case "maxFeeRate":
+				event.MaxFeeRate = v
+			}
+		case bool:
+			if arg == "renewal" {
+				renewal := v
+				event.Renewal = &renewal
+			}
+		}
+	}
+	return event
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+)
+
+func TestDecodeStakingLog(t *testing.T) {
+	sender := common.HexToAddress("0x01")
+	validator := common.HexToAddress("0x02")
+	amount := big.NewInt(5e18)
+
+	// stakeIn log written after Apollo
+	data := append(common.BigToHash(big.NewInt(1500)).Bytes(), common.BigToHash(big.NewInt(30)).Bytes()...)
+	event, err := DecodeStakingLog(&types.Log{
+		Address: WanCscPrecompileAddr,
+		Topics:  []common.Hash{cscAbi.Events["stakeIn"].Id(), sender.Hash(), validator.Hash(), common.BigToHash(amount)},
+		Data:    data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Name != "stakeIn" || event.Sender != sender || event.Validator != validator ||
+		event.Amount.Cmp(amount) != 0 || event.FeeRate.Uint64() != 1500 || event.LockEpochs.Uint64() != 30 ||
+		event.MaxFeeRate != nil || event.Renewal != nil {
+		t.Fatalf("unexpected stakeIn event %+v", event)
+	}
+
+	// delegateIn log written before Apollo
+	event, err = DecodeStakingLog(&types.Log{
+		Address: WanCscPrecompileAddr,
+		Topics: []common.Hash{common.BytesToHash(crypto.Keccak256([]byte(cscAbi.Methods["delegateIn"].Sig()))),
+			sender.Hash(), common.BigToHash(amount), validator.Hash()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Name != "delegateIn" || event.Sender != sender || event.Validator != validator || event.Amount.Cmp(amount) != 0 {
+		t.Fatalf("unexpected delegateIn event %+v", event)
+	}
+
+	if _, err := DecodeStakingLog(&types.Log{Address: WanCscPrecompileAddr, Topics: []common.Hash{{}}}); err != ErrNotStakingLog {
+		t.Fatalf("unknown topic: got %v", err)
+	}
+	if _, err := DecodeStakingLog(&types.Log{Address: sender, Topics: []common.Hash{cscAbi.Events["stakeIn"].Id()}}); err != ErrNotStakingLog {
+		t.Fatalf("other contract: got %v", err)
+	}
+}
+
+func TestDecodeStakingCall(t *testing.T) {
+	sender := common.HexToAddress("0x01")
+	validator := common.HexToAddress("0x02")
+	amount := big.NewInt(1e18)
+
+	input, err := PackStakingInput("partnerIn", validator, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := DecodeStakingCall(sender, input, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Name != "partnerIn" || event.Sender != sender || event.Validator != validator ||
+		event.Amount.Cmp(amount) != 0 || event.Renewal == nil || !*event.Renewal {
+		t.Fatalf("unexpected partnerIn event %+v", event)
+	}
+
+	key, _ := crypto.GenerateKey()
+	input, err = PackStakingInput("stakeIn", crypto.FromECDSAPub(&key.PublicKey), []byte{1}, big.NewInt(10), big.NewInt(200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err = DecodeStakingCall(sender, input, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Validator != crypto.PubkeyToAddress(key.PublicKey) || event.LockEpochs.Uint64() != 10 || event.FeeRate.Uint64() != 200 {
+		t.Fatalf("unexpected stakeIn event %+v", event)
+	}
+
+	if _, err := DecodeStakingCall(sender, []byte{1, 2, 3, 4}, nil); err != ErrNotStakingCall {
+		t.Fatalf("unknown method: got %v", err)
+	}
+}
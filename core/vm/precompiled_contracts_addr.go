@@ -7,6 +7,7 @@ import (
 
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/params"
 )
 
 // Precompiled contracts address or
@@ -81,6 +82,12 @@ type PrecompiledContract interface {
 	ValidTx(stateDB StateDB, signer types.Signer, tx *types.Transaction) error
 }
 
+// ForkValidator is implemented by the precompiled contracts whose transaction
+// validation depends on the forks active at the block the transaction goes in.
+type ForkValidator interface {
+	ValidTxAt(stateDB StateDB, signer types.Signer, tx *types.Transaction, config *params.ChainConfig, number *big.Int) error
+}
+
 // PrecompiledContractsHomestead contains the default set of pre-compiled Ethereum
 // contracts used in the Frontier and Homestead releases.
 var PrecompiledContractsHomestead = map[common.Address]PrecompiledContract{
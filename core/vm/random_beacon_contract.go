@@ -11,7 +11,6 @@ import (
 	"math/big"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/wanchain/go-wanchain/accounts/abi"
 	"github.com/wanchain/go-wanchain/common"
@@ -328,13 +327,13 @@ func ValidPosRBTx(stateDB StateDB, from common.Address, payload []byte) error {
 	copy(methodId[:], payload[:4])
 
 	if methodId == dkg1Id {
-		_, err := validDkg1(stateDB, uint64(time.Now().Unix()), from, payload[4:])
+		_, err := validDkg1(stateDB, uint64(util.Now().Unix()), from, payload[4:])
 		return err
 	} else if methodId == dkg2Id {
-		_, err := validDkg2(stateDB, uint64(time.Now().Unix()), from, payload[4:])
+		_, err := validDkg2(stateDB, uint64(util.Now().Unix()), from, payload[4:])
 		return err
 	} else if methodId == sigShareId {
-		_, _, _, err := validSigShare(stateDB, uint64(time.Now().Unix()), from, payload[4:])
+		_, _, _, err := validSigShare(stateDB, uint64(util.Now().Unix()), from, payload[4:])
 		return err
 	} else {
 		return errParameters
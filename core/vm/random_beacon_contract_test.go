@@ -40,7 +40,9 @@ func (CTStateDB) GetCode(common.Address) []byte
 func (CTStateDB) SetCode(common.Address, []byte)                                         {}
 func (CTStateDB) GetCodeSize(common.Address) int                                         { return 0 }
 func (CTStateDB) AddRefund(*big.Int)                                                     {}
+func (CTStateDB) SubRefund(*big.Int)                                                     {}
 func (CTStateDB) GetRefund() *big.Int                                                    { return nil }
+func (CTStateDB) GetCommittedState(common.Address, common.Hash) common.Hash              { return common.Hash{} }
 func (CTStateDB) GetState(common.Address, common.Hash) common.Hash                       { return common.Hash{} }
 func (CTStateDB) SetState(common.Address, common.Hash, common.Hash)                      {}
 func (CTStateDB) Suicide(common.Address) bool                                            { return false }
@@ -478,7 +480,7 @@ func TestValidPosTx(t *testing.T) {
 }
 
 func TestGetRBStage(t *testing.T) {
-	k := posconfig.K
+	k := int(posconfig.K)
 	data := [][]int{
 		{0, 		RbDkg1Stage, 			0, 		int(2*k-1)},
 		{k-1, 		RbDkg1Stage, 			k-1, 	int(k)},
@@ -508,3 +510,42 @@ func TestGetRBStage(t *testing.T) {
 		}
 	}
 }
+
+func TestVerifyRandom(t *testing.T) {
+	TestRBSig(t)
+
+	result, err := VerifyRandom(evm.StateDB, rbepochId, pubs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid || result.ComputedR.Cmp(GetStateR(evm.StateDB, rbepochId+1)) != 0 {
+		t.Fatalf("random not verified: %s", result.Reason)
+	}
+	for _, verdict := range result.Proposers {
+		if verdict.Status != RbProposerHonest {
+			t.Fatalf("proposer %d: expect honest, got %s", verdict.ProposerId, verdict.Status)
+		}
+	}
+
+	// proposer 1 stores the share of proposer 2, proposer 3 does not sign
+	forged, _ := rlp.EncodeToBytes(&RbSIGTxPayload{EpochId: rbepochId, ProposerId: 1, GSignShare: prepareSig(pris, enshareA)[2]})
+	evm.StateDB.SetStateByteArray(randomBeaconPrecompileAddr, *GetRBKeyHash(sigShareId[:], rbepochId, 1), forged)
+	evm.StateDB.SetStateByteArray(randomBeaconPrecompileAddr, *GetRBKeyHash(sigShareId[:], rbepochId, 3), nil)
+
+	result, err = VerifyRandom(evm.StateDB, rbepochId, pubs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid {
+		t.Fatalf("random not verified: %s", result.Reason)
+	}
+	if result.Proposers[1].Status != RbProposerMalicious {
+		t.Fatalf("proposer 1: expect malicious, got %s", result.Proposers[1].Status)
+	}
+	if result.Proposers[3].Status != RbProposerMissing || result.Proposers[3].SigShare {
+		t.Fatalf("proposer 3: expect missing, got %s", result.Proposers[3].Status)
+	}
+	if result.Proposers[0].Status != RbProposerHonest {
+		t.Fatalf("proposer 0: expect honest, got %s", result.Proposers[0].Status)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/crypto/bn256/cloudflare"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/rbselection"
+)
+
+// verdicts of a random beacon proposer
+const (
+	RbProposerHonest    = "honest"
+	RbProposerMissing   = "missing"
+	RbProposerMalicious = "malicious"
+)
+
+var errEmptyRBProposerGroup = errors.New("empty random beacon proposer group")
+
+// RbProposerVerdict tells how a proposer took part in the random beacon
+// protocol of an epoch.
+type RbProposerVerdict struct {
+	ProposerId uint32 `json:"proposerId"`
+	Status     string `json:"status"`
+	Dkg1       bool   `json:"dkg1"`
+	Dkg2       bool   `json:"dkg2"`
+	SigShare   bool   `json:"sigShare"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// RbVerifyResult is the independent verification of the random number
+// generated by the random beacon protocol of an epoch.
+type RbVerifyResult struct {
+	EpochId        uint64              `json:"epochId"`
+	RandomEpochId  uint64              `json:"randomEpochId"`
+	R              *big.Int            `json:"r"`
+	ComputedR      *big.Int            `json:"computedR"`
+	GroupPublicKey hexutil.Bytes       `json:"groupPublicKey,omitempty"`
+	Valid          bool                `json:"valid"`
+	Reason         string              `json:"reason,omitempty"`
+	Proposers      []RbProposerVerdict `json:"proposers"`
+}
+
+// VerifyRandom checks the random beacon protocol run by the proposers pks
+// during epochId, whose output is the random number of epochId+1. It
+// recomputes the group public key from the DKG1 commitments, checks every
+// signature share against the public share of its proposer with a pairing
+// and rebuilds the random number from the valid shares only.
+func VerifyRandom(db StateDB, epochId uint64, pks []bn256.G1) (*RbVerifyResult, error) {
+	nr := len(pks)
+	if nr == 0 {
+		return nil, errEmptyRBProposerGroup
+	}
+	degree := int(posconfig.Cfg().PolymDegree)
+
+	result := &RbVerifyResult{
+		EpochId:       epochId,
+		RandomEpochId: epochId + 1,
+		R:             GetStateR(db, epochId+1),
+		Proposers:     make([]RbProposerVerdict, nr),
+	}
+
+	xAll := make([]big.Int, nr)
+	for i := 0; i < nr; i++ {
+		xAll[i].SetBytes(GetPolynomialX(&pks[i], uint32(i)))
+		xAll[i].Mod(&xAll[i], bn256.Order)
+	}
+
+	// collect the commitments of the dealers which finished the dkg
+	dealers := make([][]*bn256.G2, 0)
+	for i := 0; i < nr; i++ {
+		verdict := &result.Proposers[i]
+		verdict.ProposerId = uint32(i)
+
+		cij, err := GetCji(db, epochId, uint32(i))
+		if err != nil {
+			verdict.Status, verdict.Reason = RbProposerMalicious, "undecodable dkg1 commitments"
+			continue
+		}
+		verdict.Dkg1 = len(cij) != 0
+		verdict.Dkg2 = IsJoinDKG2(db, epochId, uint32(i))
+		if !verdict.Dkg1 {
+			continue
+		}
+
+		if len(cij) != nr {
+			verdict.Status, verdict.Reason = RbProposerMalicious, "dkg1 commitments do not match the group size"
+			continue
+		}
+		commits := make([]bn256.G2, nr)
+		for j := range cij {
+			commits[j] = *cij[j]
+		}
+		if !rbselection.RScodeVerify(commits, xAll, degree) {
+			verdict.Status, verdict.Reason = RbProposerMalicious, "dkg1 commitments are not a polynomial of the protocol degree"
+			continue
+		}
+		if verdict.Dkg2 {
+			dealers = append(dealers, cij)
+		}
+	}
+
+	// check the signature shares against the public shares
+	m, err := getRBMVar(db, epochId)
+	if err != nil {
+		return nil, err
+	}
+	mG := new(bn256.G1).ScalarBaseMult(new(big.Int).SetBytes(m))
+
+	sigShares := make([]bn256.G1, 0)
+	xSig := make([]big.Int, 0)
+	for i := 0; i < nr; i++ {
+		verdict := &result.Proposers[i]
+		sig, err := GetSig(db, epochId, uint32(i))
+		if err != nil || (sig != nil && sig.GSignShare == nil) {
+			verdict.SigShare = true
+			verdict.Status, verdict.Reason = RbProposerMalicious, "undecodable signature share"
+			continue
+		}
+		if sig == nil {
+			continue
+		}
+		verdict.SigShare = true
+
+		var gPKShare bn256.G2
+		for _, cij := range dealers {
+			gPKShare.Add(&gPKShare, cij[i])
+		}
+		if bn256.Pair(sig.GSignShare, hBase).String() != bn256.Pair(mG, &gPKShare).String() {
+			verdict.Status, verdict.Reason = RbProposerMalicious, "signature share does not match the public share"
+			continue
+		}
+		if verdict.Status == "" {
+			sigShares = append(sigShares, *sig.GSignShare)
+			x := new(big.Int).SetBytes(GetPolynomialX(&pks[i], uint32(i)))
+			xSig = append(xSig, *x)
+		}
+	}
+
+	for i := range result.Proposers {
+		verdict := &result.Proposers[i]
+		if verdict.Status != "" {
+			continue
+		}
+		if verdict.Dkg1 && verdict.Dkg2 && verdict.SigShare {
+			verdict.Status = RbProposerHonest
+			continue
+		}
+		missed := make([]string, 0)
+		if !verdict.Dkg1 {
+			missed = append(missed, "dkg1")
+		}
+		if !verdict.Dkg2 {
+			missed = append(missed, "dkg2")
+		}
+		if !verdict.SigShare {
+			missed = append(missed, "sigShare")
+		}
+		verdict.Status, verdict.Reason = RbProposerMissing, "missed "+strings.Join(missed, ", ")
+	}
+
+	if uint(len(dealers)) < posconfig.Cfg().RBThres {
+		result.Reason = "insufficient dkg dealers"
+		return result, nil
+	}
+	if uint(len(sigShares)) < posconfig.Cfg().RBThres {
+		result.Reason = "insufficient valid signature shares"
+		return result, nil
+	}
+
+	// rebuild the group public key and the group signature
+	c := make([]bn256.G2, nr)
+	for i := 0; i < nr; i++ {
+		c[i].ScalarBaseMult(big.NewInt(0))
+		for _, cij := range dealers {
+			c[i].Add(&c[i], cij[i])
+		}
+	}
+	gPub := rbselection.LagrangePub(c, xAll, degree)
+	result.GroupPublicKey = gPub.Marshal()
+
+	gSignature := rbselection.LagrangeSig(sigShares, xSig, degree)
+	if bn256.Pair(&gSignature, rbselection.Hbase).String() != bn256.Pair(mG, &gPub).String() {
+		result.Reason = "group signature does not match the group public key"
+		return result, nil
+	}
+	result.ComputedR = new(big.Int).SetBytes(crypto.Keccak256(gSignature.Marshal()))
+
+	switch {
+	case result.R == nil:
+		result.Reason = "no random number stored"
+	case result.R.Cmp(result.ComputedR) != 0:
+		result.Reason = "stored random number differs from the rebuilt one"
+	default:
+		result.Valid = true
+	}
+	return result, nil
+}
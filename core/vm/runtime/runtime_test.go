@@ -26,6 +26,7 @@ import (
 	"github.com/wanchain/go-wanchain/core/state"
 	"github.com/wanchain/go-wanchain/core/vm"
 	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/params"
 )
 
 func TestDefaults(t *testing.T) {
@@ -93,6 +94,66 @@ func TestExecute(t *testing.T) {
 	}
 }
 
+func TestIstanbulOpcodes(t *testing.T) {
+	code := []byte{
+		byte(vm.CHAINID),
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 32,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	}
+	if _, _, err := Execute(code, nil, nil); err == nil {
+		t.Fatal("expected CHAINID to be invalid before the istanbul fork")
+	}
+	cfg := &Config{ChainConfig: &params.ChainConfig{ChainId: big.NewInt(6), IstanbulBlock: new(big.Int)}}
+	ret, _, err := Execute(code, nil, cfg)
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if num := new(big.Int).SetBytes(ret); num.Cmp(big.NewInt(6)) != 0 {
+		t.Error("Expected chain id 6, got", num)
+	}
+}
+
+func TestIstanbulSstoreGas(t *testing.T) {
+	// Writing the same slot twice only pays the full price for the first write
+	code := []byte{
+		byte(vm.PUSH1), 1,
+		byte(vm.PUSH1), 0,
+		byte(vm.SSTORE),
+		byte(vm.PUSH1), 2,
+		byte(vm.PUSH1), 0,
+		byte(vm.SSTORE),
+	}
+	tests := []struct {
+		istanbul *big.Int
+		gas      uint64
+	}{
+		{nil, 4*vm.GasFastestStep + params.SstoreSetGas + params.SstoreResetGas},
+		{new(big.Int), 4*vm.GasFastestStep + params.SstoreInitGasEIP2200 + params.SstoreDirtyGasEIP2200},
+	}
+	for i, tt := range tests {
+		db, _ := ethdb.NewMemDatabase()
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+		address := common.HexToAddress("0x0a")
+		statedb.SetCode(address, code)
+
+		cfg := &Config{
+			State:       statedb,
+			GasLimit:    100000,
+			ChainConfig: &params.ChainConfig{ChainId: big.NewInt(1), IstanbulBlock: tt.istanbul},
+		}
+		_, leftOver, err := Call(address, nil, cfg)
+		if err != nil {
+			t.Fatalf("test %d: didn't expect error: %v", i, err)
+		}
+		if used := cfg.GasLimit - leftOver; used != tt.gas {
+			t.Errorf("test %d: gas used mismatch: have %d, want %d", i, used, tt.gas)
+		}
+	}
+}
+
 func TestCall(t *testing.T) {
 	db, _ := ethdb.NewMemDatabase()
 	state, _ := state.New(common.Hash{}, state.NewDatabase(db))
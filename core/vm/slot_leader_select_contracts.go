@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
-	"time"
 
 	"github.com/wanchain/go-wanchain/pos/uleaderselection"
 	"github.com/wanchain/go-wanchain/rlp"
@@ -179,7 +178,7 @@ func (c *slotLeaderSC) ValidTx(stateDB StateDB, signer types.Signer, tx *types.T
 	copy(methodId[:], payload[:4])
 
 	if methodId == stgOneIdArr {
-		vldReset := validStg1Reset(stateDB, from, payload, uint64(time.Now().Unix()))
+		vldReset := validStg1Reset(stateDB, from, payload, uint64(util.Now().Unix()))
 		vldService := validStg1Service(from, payload)
 
 		if vldReset && vldService {
@@ -188,7 +187,7 @@ func (c *slotLeaderSC) ValidTx(stateDB StateDB, signer types.Signer, tx *types.T
 			return errors.New("ValidTx stg1")
 		}
 	} else if methodId == stgTwoIdArr {
-		vldReset := validStg2Reset(stateDB, from, payload, uint64(time.Now().Unix()))
+		vldReset := validStg2Reset(stateDB, from, payload, uint64(util.Now().Unix()))
 		vldService := validStg2Service(stateDB, from, payload)
 
 		if vldReset && vldService {
@@ -213,13 +212,13 @@ func ValidPosELTx(stateDB StateDB, from common.Address, payload []byte) error {
 	copy(methodId[:], payload[:4])
 
 	if methodId == stgOneIdArr {
-		if validStg1Reset(stateDB, from, payload, uint64(time.Now().Unix())) {
+		if validStg1Reset(stateDB, from, payload, uint64(util.Now().Unix())) {
 			return nil
 		} else {
 			return errors.New("ValidPosELTx stage1 error")
 		}
 	} else if methodId == stgTwoIdArr {
-		if validStg2Reset(stateDB, from, payload, uint64(time.Now().Unix())) {
+		if validStg2Reset(stateDB, from, payload, uint64(util.Now().Unix())) {
 			return nil
 		} else {
 			return errors.New("ValidPosELTx stage2 error")
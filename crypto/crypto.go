@@ -83,6 +83,12 @@ func CreateAddress(b common.Address, nonce uint64) common.Address {
 	return common.BytesToAddress(Keccak256(data)[12:])
 }
 
+// CreateAddress2 creates an ethereum address given the address bytes, the salt
+// and the hash of the initial contract code, the way CREATE2 does.
+func CreateAddress2(b common.Address, salt [32]byte, inithash []byte) common.Address {
+	return common.BytesToAddress(Keccak256([]byte{0xff}, b.Bytes(), salt[:], inithash)[12:])
+}
+
 // ToECDSA creates a private key with the given D value.
 func ToECDSA(d []byte) (*ecdsa.PrivateKey, error) {
 	return toECDSA(d, true)
@@ -615,6 +621,15 @@ func CompareA1(b []byte, A *ecdsa.PublicKey, S1 *ecdsa.PublicKey, A1 *ecdsa.Publ
 	return false
 }
 
+// ComputeKeyImage returns the key image [x]Hash(P) of an OTA with private key x
+// and public key P. It is the value recorded on chain when the OTA is spent.
+func ComputeKeyImage(x *big.Int, pub *ecdsa.PublicKey) *ecdsa.PublicKey {
+	if x == nil || pub == nil || pub.X == nil || pub.Y == nil {
+		return nil
+	}
+	return xScalarHashP(x.Bytes(), pub)
+}
+
 // generateOneTimeKey2528 generates an OTA account for receiver using receiver's publickey
 // Pengbo added, TeemoGuo revised
 func generateOneTimeKey2528(A *ecdsa.PublicKey, B *ecdsa.PublicKey) (A1 *ecdsa.PublicKey, R *ecdsa.PublicKey, err error) {
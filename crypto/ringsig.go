@@ -0,0 +1,205 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/crypto/sha3"
+)
+
+var (
+	ErrInvalidRingSignature = errors.New("invalid ring signature")
+)
+
+// RingSignature is the output of a linkable ring signature over secp256k1.
+// The key image I = [x]Hash(P) is the same for every scheme, so a spent OTA
+// is detected whatever scheme signed it.
+type RingSignature struct {
+	PublicKeys []*ecdsa.PublicKey
+	KeyImage   *ecdsa.PublicKey
+	C          []*big.Int // challenges, one per ring member or a single one for compact schemes
+	R          []*big.Int // responses, one per ring member
+}
+
+// RingSigner is a linkable ring signature scheme. The real signer's public key
+// must be PublicKeys[0], it is moved to a random position of the returned ring.
+type RingSigner interface {
+	Sign(M []byte, x *big.Int, PublicKeys []*ecdsa.PublicKey) (*RingSignature, error)
+	Verify(M []byte, sig *RingSignature) bool
+}
+
+var (
+	// DefaultRingSigner is the original scheme of RingSign and VerifyRingSign,
+	// it carries one challenge per ring member.
+	DefaultRingSigner RingSigner = defaultRingSigner{}
+
+	// CompactRingSigner is an LSAG style scheme with a single challenge, its
+	// signature is n+1 scalars instead of 2n.
+	CompactRingSigner RingSigner = compactRingSigner{}
+)
+
+type defaultRingSigner struct{}
+
+func (defaultRingSigner) Sign(M []byte, x *big.Int, PublicKeys []*ecdsa.PublicKey) (*RingSignature, error) {
+	pubs, I, c, r, err := RingSign(M, x, PublicKeys)
+	if err != nil {
+		return nil, err
+	}
+	return &RingSignature{PublicKeys: pubs, KeyImage: I, C: c, R: r}, nil
+}
+
+func (defaultRingSigner) Verify(M []byte, sig *RingSignature) bool {
+	if sig == nil {
+		return false
+	}
+	return VerifyRingSign(M, sig.PublicKeys, sig.KeyImage, sig.C, sig.R)
+}
+
+type compactRingSigner struct{}
+
+// ringHash binds the challenges to the ring members and the key image.
+func ringHash(PublicKeys []*ecdsa.PublicKey, I *ecdsa.PublicKey) []byte {
+	d := sha3.NewKeccak256()
+	for _, pub := range PublicKeys {
+		d.Write(FromECDSAPub(pub))
+	}
+	d.Write(FromECDSAPub(I))
+	return d.Sum(nil)
+}
+
+// challenge computes hash(M, ring, L, R) mod N
+func challenge(M []byte, ring []byte, L, R *ecdsa.PublicKey) *big.Int {
+	d := sha3.NewKeccak256()
+	d.Write(M)
+	d.Write(ring)
+	d.Write(FromECDSAPub(L))
+	d.Write(FromECDSAPub(R))
+	c := new(big.Int).SetBytes(d.Sum(nil))
+	return c.Mod(c, secp256k1_N)
+}
+
+// lsagStep computes L=[r]G+[c]P and R=[r]HashP+[c]I of ring member P.
+func lsagStep(P, I *ecdsa.PublicKey, c, r *big.Int) (L, R *ecdsa.PublicKey, ok bool) {
+	L = new(ecdsa.PublicKey)
+	L.X, L.Y = S256().ScalarBaseMult(r.Bytes()) //[r]G
+	cP := new(ecdsa.PublicKey)
+	cP.X, cP.Y = S256().ScalarMult(P.X, P.Y, c.Bytes()) //[c]P
+	if L.X == nil || cP.X == nil {
+		return nil, nil, false
+	}
+	L.X, L.Y = S256().Add(L.X, L.Y, cP.X, cP.Y)
+
+	R = xScalarHashP(r.Bytes(), P) //[r]HashP
+	cI := new(ecdsa.PublicKey)
+	cI.X, cI.Y = S256().ScalarMult(I.X, I.Y, c.Bytes()) //[c]I
+	if R == nil || R.X == nil || cI.X == nil {
+		return nil, nil, false
+	}
+	R.X, R.Y = S256().Add(R.X, R.Y, cI.X, cI.Y)
+	return L, R, true
+}
+
+func (compactRingSigner) Sign(M []byte, x *big.Int, PublicKeys []*ecdsa.PublicKey) (*RingSignature, error) {
+	if M == nil || x == nil || len(PublicKeys) == 0 {
+		return nil, ErrInvalidRingSignParams
+	}
+	for _, publicKey := range PublicKeys {
+		if publicKey == nil || publicKey.X == nil || publicKey.Y == nil {
+			return nil, ErrInvalidRingSignParams
+		}
+	}
+
+	n := len(PublicKeys)
+	I := xScalarHashP(x.Bytes(), PublicKeys[0]) //Key Image
+	if I == nil || I.X == nil || I.Y == nil {
+		return nil, ErrRingSignFail
+	}
+
+	rnd, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return nil, ErrRingSignFail
+	}
+	s := int(rnd.Int64()) //s is the random position for real key
+
+	ring := make([]*ecdsa.PublicKey, n)
+	copy(ring, PublicKeys)
+	if s > 0 {
+		ring[0], ring[s] = ring[s], ring[0]
+	}
+	rh := ringHash(ring, I)
+
+	alpha, err := randFieldElement2528(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	c := make([]*big.Int, n)
+	r := make([]*big.Int, n)
+
+	L := new(ecdsa.PublicKey)
+	L.X, L.Y = S256().ScalarBaseMult(alpha.Bytes()) //[alpha]G
+	R := xScalarHashP(alpha.Bytes(), ring[s])       //[alpha]HashPs
+	c[(s+1)%n] = challenge(M, rh, L, R)
+
+	for j := 1; j < n; j++ {
+		i := (s + j) % n
+		r[i], err = randFieldElement2528(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		L, R, ok := lsagStep(ring[i], I, c[i], r[i])
+		if !ok {
+			return nil, ErrRingSignFail
+		}
+		c[(i+1)%n] = challenge(M, rh, L, R)
+	}
+
+	// r[s] = alpha - c[s]*x
+	r[s] = new(big.Int).Mul(c[s], x)
+	r[s].Sub(alpha, r[s])
+	r[s].Mod(r[s], secp256k1_N)
+
+	return &RingSignature{PublicKeys: ring, KeyImage: I, C: []*big.Int{c[0]}, R: r}, nil
+}
+
+func (compactRingSigner) Verify(M []byte, sig *RingSignature) bool {
+	if M == nil || sig == nil || sig.KeyImage == nil || sig.KeyImage.X == nil || sig.KeyImage.Y == nil {
+		return false
+	}
+
+	n := len(sig.PublicKeys)
+	if n == 0 || len(sig.C) != 1 || len(sig.R) != n || sig.C[0] == nil {
+		return false
+	}
+	if !S256().IsOnCurve(sig.KeyImage.X, sig.KeyImage.Y) {
+		return false
+	}
+	if sig.C[0].Sign() < 0 || sig.C[0].Cmp(secp256k1_N) >= 0 {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if sig.PublicKeys[i] == nil || sig.PublicKeys[i].X == nil || sig.PublicKeys[i].Y == nil || sig.R[i] == nil {
+			return false
+		}
+		if sig.R[i].Sign() < 0 || sig.R[i].Cmp(secp256k1_N) >= 0 {
+			return false
+		}
+	}
+
+	rh := ringHash(sig.PublicKeys, sig.KeyImage)
+	c := sig.C[0]
+	for i := 0; i < n; i++ {
+		L, R, ok := lsagStep(sig.PublicKeys[i], sig.KeyImage, c, sig.R[i])
+		if !ok {
+			return false
+		}
+		c = challenge(M, rh, L, R)
+	}
+
+	return c.Cmp(sig.C[0]) == 0
+}
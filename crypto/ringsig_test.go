@@ -0,0 +1,71 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+)
+
+func genRing(t *testing.T, n int) (*ecdsa.PrivateKey, []*ecdsa.PublicKey) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring := []*ecdsa.PublicKey{&key.PublicKey}
+	for i := 1; i < n; i++ {
+		mix, err := GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ring = append(ring, &mix.PublicKey)
+	}
+	return key, ring
+}
+
+func TestRingSigners(t *testing.T) {
+	msg := Keccak256([]byte("ring signature"))
+	for _, n := range []int{1, 2, 5} {
+		for name, signer := range map[string]RingSigner{"default": DefaultRingSigner, "compact": CompactRingSigner} {
+			key, ring := genRing(t, n)
+			sig, err := signer.Sign(msg, key.D, ring)
+			if err != nil {
+				t.Fatalf("%s n=%d: sign failed: %v", name, n, err)
+			}
+			if !signer.Verify(msg, sig) {
+				t.Fatalf("%s n=%d: valid signature rejected", name, n)
+			}
+			if signer.Verify(Keccak256([]byte("other")), sig) {
+				t.Fatalf("%s n=%d: signature verified for another message", name, n)
+			}
+
+			tampered := *sig
+			tampered.R = append([]*big.Int{}, sig.R...)
+			tampered.R[0] = new(big.Int).Add(sig.R[0], big.NewInt(1))
+			if signer.Verify(msg, &tampered) {
+				t.Fatalf("%s n=%d: tampered signature verified", name, n)
+			}
+
+			if want := ComputeKeyImage(key.D, &key.PublicKey); want.X.Cmp(sig.KeyImage.X) != 0 || want.Y.Cmp(sig.KeyImage.Y) != 0 {
+				t.Fatalf("%s n=%d: key image differs from ComputeKeyImage", name, n)
+			}
+		}
+	}
+}
+
+func TestCompactRingSignerSize(t *testing.T) {
+	key, ring := genRing(t, 8)
+	sig, err := CompactRingSigner.Sign([]byte("msg"), key.D, ring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig.C) != 1 || len(sig.R) != 8 {
+		t.Fatalf("compact signature should hold 1 challenge and 8 responses, have %d and %d", len(sig.C), len(sig.R))
+	}
+
+	// a compact signature is not a valid default signature
+	if DefaultRingSigner.Verify([]byte("msg"), sig) {
+		t.Fatal("compact signature accepted by default scheme")
+	}
+}
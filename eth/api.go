@@ -313,6 +313,13 @@ func NewPublicDebugAPI(eth *Ethereum) *PublicDebugAPI {
 
 // DumpBlock retrieves the entire state of the database at a given block.
 func (api *PublicDebugAPI) DumpBlock(blockNr rpc.BlockNumber) (state.Dump, error) {
+	if blockNr == rpc.StableBlockNumber {
+		number, err := stableBlockNumber()
+		if err != nil {
+			return state.Dump{}, err
+		}
+		blockNr = number
+	}
 	if blockNr == rpc.PendingBlockNumber {
 		// If we're dumping the pending state, we need to request
 		// both the pending block as well as the pending state from
@@ -351,13 +358,25 @@ func NewPrivateDebugAPI(config *params.ChainConfig, eth *Ethereum) *PrivateDebug
 
 // BlockTraceResult is the returned value when replaying a block to check for
 // consensus results and full VM trace logs for all included transactions.
+// When a tracer is named, the block holds the result of each transaction
+// traced on its own instead of the logs.
 type BlockTraceResult struct {
 	Validated  bool                  `json:"validated"`
 	StructLogs []ethapi.StructLogRes `json:"structLogs"`
+	Results    []TxTraceResult       `json:"results,omitempty"`
 	Error      string                `json:"error"`
 }
 
-// TraceArgs holds extra parameters to trace functions
+// TxTraceResult is the result of tracing a transaction of a block.
+type TxTraceResult struct {
+	TxHash common.Hash `json:"txHash"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// TraceArgs holds extra parameters to trace functions. The Tracer is either
+// the name of a native tracer (callTracer, prestateTracer or 4byteTracer) or
+// the code of a javascript tracer.
 type TraceArgs struct {
 	*vm.LogConfig
 	Tracer  *string
@@ -366,34 +385,35 @@ type TraceArgs struct {
 
 // TraceBlock processes the given block'api RLP but does not import the block in to
 // the chain.
-func (api *PrivateDebugAPI) TraceBlock(blockRlp []byte, config *vm.LogConfig) BlockTraceResult {
+func (api *PrivateDebugAPI) TraceBlock(ctx context.Context, blockRlp []byte, config *TraceArgs) BlockTraceResult {
 	var block types.Block
 	err := rlp.Decode(bytes.NewReader(blockRlp), &block)
 	if err != nil {
 		return BlockTraceResult{Error: fmt.Sprintf("could not decode block: %v", err)}
 	}
-
-	validated, logs, err := api.traceBlock(&block, config)
-	return BlockTraceResult{
-		Validated:  validated,
-		StructLogs: ethapi.FormatLogs(logs),
-		Error:      formatError(err),
-	}
+	return api.traceBlock(ctx, &block, config)
 }
 
 // TraceBlockFromFile loads the block'api RLP from the given file name and attempts to
 // process it but does not import the block in to the chain.
-func (api *PrivateDebugAPI) TraceBlockFromFile(file string, config *vm.LogConfig) BlockTraceResult {
+func (api *PrivateDebugAPI) TraceBlockFromFile(ctx context.Context, file string, config *TraceArgs) BlockTraceResult {
 	blockRlp, err := ioutil.ReadFile(file)
 	if err != nil {
 		return BlockTraceResult{Error: fmt.Sprintf("could not read file: %v", err)}
 	}
-	return api.TraceBlock(blockRlp, config)
+	return api.TraceBlock(ctx, blockRlp, config)
 }
 
 // TraceBlockByNumber processes the block by canonical block number.
-func (api *PrivateDebugAPI) TraceBlockByNumber(blockNr rpc.BlockNumber, config *vm.LogConfig) BlockTraceResult {
+func (api *PrivateDebugAPI) TraceBlockByNumber(ctx context.Context, blockNr rpc.BlockNumber, config *TraceArgs) BlockTraceResult {
 	// Fetch the block that we aim to reprocess
+	if blockNr == rpc.StableBlockNumber {
+		number, err := stableBlockNumber()
+		if err != nil {
+			return BlockTraceResult{Error: err.Error()}
+		}
+		blockNr = number
+	}
 	var block *types.Block
 	switch blockNr {
 	case rpc.PendingBlockNumber:
@@ -408,24 +428,29 @@ func (api *PrivateDebugAPI) TraceBlockByNumber(blockNr rpc.BlockNumber, config *
 	if block == nil {
 		return BlockTraceResult{Error: fmt.Sprintf("block #%d not found", blockNr)}
 	}
-
-	validated, logs, err := api.traceBlock(block, config)
-	return BlockTraceResult{
-		Validated:  validated,
-		StructLogs: ethapi.FormatLogs(logs),
-		Error:      formatError(err),
-	}
+	return api.traceBlock(ctx, block, config)
 }
 
 // TraceBlockByHash processes the block by hash.
-func (api *PrivateDebugAPI) TraceBlockByHash(hash common.Hash, config *vm.LogConfig) BlockTraceResult {
+func (api *PrivateDebugAPI) TraceBlockByHash(ctx context.Context, hash common.Hash, config *TraceArgs) BlockTraceResult {
 	// Fetch the block that we aim to reprocess
 	block := api.eth.BlockChain().GetBlockByHash(hash)
 	if block == nil {
 		return BlockTraceResult{Error: fmt.Sprintf("block #%x not found", hash)}
 	}
+	return api.traceBlock(ctx, block, config)
+}
 
-	validated, logs, err := api.traceBlock(block, config)
+// traceBlock processes the given block but does not save the state.
+func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block, config *TraceArgs) BlockTraceResult {
+	if config != nil && config.Tracer != nil {
+		return api.traceBlockTxs(ctx, block, config)
+	}
+	var logConfig *vm.LogConfig
+	if config != nil {
+		logConfig = config.LogConfig
+	}
+	validated, logs, err := api.traceBlockLogs(block, logConfig)
 	return BlockTraceResult{
 		Validated:  validated,
 		StructLogs: ethapi.FormatLogs(logs),
@@ -433,8 +458,8 @@ func (api *PrivateDebugAPI) TraceBlockByHash(hash common.Hash, config *vm.LogCon
 	}
 }
 
-// traceBlock processes the given block but does not save the state.
-func (api *PrivateDebugAPI) traceBlock(block *types.Block, logConfig *vm.LogConfig) (bool, []vm.StructLog, error) {
+// traceBlockLogs processes the given block with a single struct logger.
+func (api *PrivateDebugAPI) traceBlockLogs(block *types.Block, logConfig *vm.LogConfig) (bool, []vm.StructLog, error) {
 	// Validate and reprocess the block
 	var (
 		blockchain = api.eth.BlockChain()
@@ -466,6 +491,63 @@ func (api *PrivateDebugAPI) traceBlock(block *types.Block, logConfig *vm.LogConf
 	return true, structLogger.StructLogs(), nil
 }
 
+// traceBlockTxs processes the given block the way the state processor does,
+// tracing each transaction with a tracer of its own.
+func (api *PrivateDebugAPI) traceBlockTxs(ctx context.Context, block *types.Block, config *TraceArgs) BlockTraceResult {
+	var (
+		blockchain = api.eth.BlockChain()
+		header     = block.Header()
+		parent     = blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+		result     BlockTraceResult
+	)
+	if err := api.eth.engine.VerifyHeader(blockchain, header, true); err != nil {
+		result.Error = formatError(err)
+		return result
+	}
+	if parent == nil {
+		result.Error = fmt.Sprintf("block parent %x not found", block.ParentHash())
+		return result
+	}
+	statedb, err := blockchain.StateAt(parent.Root())
+	if err != nil {
+		result.Error = formatError(err)
+		return result
+	}
+	var (
+		receipts types.Receipts
+		usedGas  = new(big.Int)
+		gp       = new(core.GasPool).AddGas(block.GasLimit())
+	)
+	for i, tx := range block.Transactions() {
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+
+		tracer, cancel, err := newTracer(ctx, config, statedb)
+		if err != nil {
+			result.Error = formatError(err)
+			return result
+		}
+		receipt, _, err := core.ApplyTransaction(api.config, blockchain, nil, gp, statedb, header, tx, usedGas, vm.Config{Debug: true, Tracer: tracer})
+		if err != nil {
+			cancel()
+			result.Error = fmt.Sprintf("tx %x failed: %v", tx.Hash(), err)
+			return result
+		}
+		res, err := tracer.(resultTracer).GetResult()
+		cancel()
+
+		result.Results = append(result.Results, TxTraceResult{TxHash: tx.Hash(), Result: res, Error: formatError(err)})
+		receipts = append(receipts, receipt)
+	}
+	api.eth.engine.Finalize(blockchain, header, statedb, block.Transactions(), block.Uncles(), receipts)
+
+	if err := blockchain.Validator().ValidateState(block, parent, statedb, receipts, usedGas); err != nil {
+		result.Error = formatError(err)
+		return result
+	}
+	result.Validated = true
+	return result
+}
+
 // formatError formats a Go error into either an empty string or the data content
 // of the error itself.
 func formatError(err error) string {
@@ -481,37 +563,56 @@ func (t *timeoutError) Error() string {
 	return "Execution time exceeded"
 }
 
-// TraceTransaction returns the structured logs created during the execution of EVM
-// and returns them as a JSON object.
-func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, txHash common.Hash, config *TraceArgs) (interface{}, error) {
-	var tracer vm.Tracer
-	if config != nil && config.Tracer != nil {
-		timeout := defaultTraceTimeout
-		if config.Timeout != nil {
-			var err error
-			if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
-				return nil, err
-			}
-		}
+// resultTracer is a tracer returning a result of its own, as the javascript
+// and the native tracers do.
+type resultTracer interface {
+	vm.Tracer
+	GetResult() (interface{}, error)
+	Stop(err error)
+}
 
+// newTracer creates the tracer selected by the trace arguments for a message
+// applied to the given state. A named tracer is stopped once the timeout of
+// the arguments passes or the context is done, the returned function releases
+// the timeout.
+func newTracer(ctx context.Context, config *TraceArgs, statedb *state.StateDB) (vm.Tracer, context.CancelFunc, error) {
+	if config == nil {
+		return vm.NewStructLogger(nil), func() {}, nil
+	}
+	if config.Tracer == nil {
+		return vm.NewStructLogger(config.LogConfig), func() {}, nil
+	}
+	timeout := defaultTraceTimeout
+	if config.Timeout != nil {
 		var err error
-		if tracer, err = ethapi.NewJavascriptTracer(*config.Tracer); err != nil {
-			return nil, err
+		if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
+			return nil, nil, err
 		}
+	}
 
-		// Handle timeouts and RPC cancellations
-		deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
-		go func() {
-			<-deadlineCtx.Done()
-			tracer.(*ethapi.JavascriptTracer).Stop(&timeoutError{})
-		}()
-		defer cancel()
-	} else if config == nil {
-		tracer = vm.NewStructLogger(nil)
+	var tracer resultTracer
+	if native, ok := ethapi.NewNativeTracer(*config.Tracer); ok {
+		native.CaptureTxStart(statedb)
+		tracer = native
 	} else {
-		tracer = vm.NewStructLogger(config.LogConfig)
+		var err error
+		if tracer, err = ethapi.NewJavascriptTracer(*config.Tracer); err != nil {
+			return nil, nil, err
+		}
 	}
 
+	// Handle timeouts and RPC cancellations
+	deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+	go func() {
+		<-deadlineCtx.Done()
+		tracer.Stop(&timeoutError{})
+	}()
+	return tracer, cancel, nil
+}
+
+// TraceTransaction returns the structured logs created during the execution of EVM
+// and returns them as a JSON object.
+func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, txHash common.Hash, config *TraceArgs) (interface{}, error) {
 	// Retrieve the tx from the chain and the containing block
 	tx, blockHash, _, txIndex := core.GetTransaction(api.eth.ChainDb(), txHash)
 	if tx == nil {
@@ -521,10 +622,34 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, txHash common.
 	if err != nil {
 		return nil, err
 	}
+	return api.traceMessage(ctx, msg, context, statedb, config)
+}
+
+// TraceCall returns the trace of executing the given call on top of the state
+// of the given block, the way TraceTransaction traces a transaction.
+func (api *PrivateDebugAPI) TraceCall(ctx context.Context, args ethapi.CallArgs, blockNr rpc.BlockNumber, config *TraceArgs) (interface{}, error) {
+	statedb, header, err := api.eth.ApiBackend.StateAndHeaderByNumber(ctx, blockNr)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	msg := args.ToMessage(api.eth.AccountManager())
+	context := core.NewEVMContext(msg, header, api.eth.BlockChain(), nil)
+
+	return api.traceMessage(ctx, msg, context, statedb, config)
+}
+
+// traceMessage applies the message to the state with the tracer selected by
+// the trace arguments, returning the result of the tracer.
+func (api *PrivateDebugAPI) traceMessage(ctx context.Context, msg core.Message, vmctx vm.Context, statedb *state.StateDB, config *TraceArgs) (interface{}, error) {
+	tracer, cancel, err := newTracer(ctx, config, statedb)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
 
-	// Run the transaction with tracing enabled.
-	vmenv := vm.NewEVM(context, statedb, api.config, vm.Config{Debug: true, Tracer: tracer})
-	ret, gas, failed, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(tx.Gas()))
+	// Run the message with tracing enabled.
+	vmenv := vm.NewEVM(vmctx, statedb, api.config, vm.Config{Debug: true, Tracer: tracer})
+	ret, gas, failed, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()))
 	if err != nil {
 		return nil, fmt.Errorf("tracing failed: %v", err)
 	}
@@ -536,7 +661,7 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, txHash common.
 			ReturnValue: fmt.Sprintf("%x", ret),
 			StructLogs:  ethapi.FormatLogs(tracer.StructLogs()),
 		}, nil
-	case *ethapi.JavascriptTracer:
+	case resultTracer:
 		return tracer.GetResult()
 	default:
 		panic(fmt.Sprintf("bad tracer type %T", tracer))
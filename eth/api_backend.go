@@ -18,6 +18,7 @@ package eth
 
 import (
 	"context"
+	"errors"
 	"math/big"
 
 	"github.com/wanchain/go-wanchain/accounts"
@@ -33,9 +34,22 @@ import (
 	"github.com/wanchain/go-wanchain/ethdb"
 	"github.com/wanchain/go-wanchain/event"
 	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/cfm"
 	"github.com/wanchain/go-wanchain/rpc"
 )
 
+var errNoStableBlock = errors.New("stable block unknown before the pos stage")
+
+// stableBlockNumber returns the highest block the confirmation engine deems
+// irreversible.
+func stableBlockNumber() (rpc.BlockNumber, error) {
+	confirm := cfm.GetCFM()
+	if confirm == nil {
+		return 0, errNoStableBlock
+	}
+	return rpc.BlockNumber(confirm.GetMaxStableBlkNumber()), nil
+}
+
 // EthApiBackend implements ethapi.Backend for full nodes
 type EthApiBackend struct {
 	eth *Ethereum
@@ -56,6 +70,14 @@ func (b *EthApiBackend) SetHead(number uint64) {
 }
 
 func (b *EthApiBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error) {
+	// Stable block is only known by the confirmation engine
+	if blockNr == rpc.StableBlockNumber {
+		number, err := stableBlockNumber()
+		if err != nil {
+			return nil, err
+		}
+		blockNr = number
+	}
 	// Pending block is only known by the miner
 	if blockNr == rpc.PendingBlockNumber {
 		block := b.eth.miner.PendingBlock()
@@ -69,6 +91,14 @@ func (b *EthApiBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNum
 }
 
 func (b *EthApiBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Block, error) {
+	// Stable block is only known by the confirmation engine
+	if blockNr == rpc.StableBlockNumber {
+		number, err := stableBlockNumber()
+		if err != nil {
+			return nil, err
+		}
+		blockNr = number
+	}
 	// Pending block is only known by the miner
 	if blockNr == rpc.PendingBlockNumber {
 		block := b.eth.miner.PendingBlock()
@@ -184,6 +214,10 @@ func (b *EthApiBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	return b.gpo.SuggestPrice(ctx)
 }
 
+func (b *EthApiBackend) FeeHistory(ctx context.Context, blockCount int, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []float64, error) {
+	return b.gpo.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+}
+
 func (b *EthApiBackend) ChainDb() ethdb.Database {
 	return b.eth.ChainDb()
 }
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"github.com/wanchain/go-wanchain/accounts/keystore"
 	"github.com/wanchain/go-wanchain/pos/posapi"
+	"github.com/wanchain/go-wanchain/pos/validatorhistory"
 	"math/big"
 	"runtime"
 	"sync"
@@ -41,6 +42,7 @@ import (
 	"github.com/wanchain/go-wanchain/eth/downloader"
 	"github.com/wanchain/go-wanchain/eth/filters"
 	"github.com/wanchain/go-wanchain/eth/gasprice"
+	"github.com/wanchain/go-wanchain/eth/otascan"
 	"github.com/wanchain/go-wanchain/ethdb"
 	"github.com/wanchain/go-wanchain/event"
 	"github.com/wanchain/go-wanchain/internal/ethapi"
@@ -85,6 +87,8 @@ type Ethereum struct {
 	bloomIndexer  *core.ChainIndexer             // Bloom indexer operating during block imports
 
 	ApiBackend *EthApiBackend
+	otaScanner *otascan.Scanner
+	validatorHistory *validatorhistory.Indexer
 
 	miner     *miner.Miner
 	gasPrice  *big.Int
@@ -109,6 +113,9 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
+	if config.SyncMode == downloader.CheckpointSync && config.Checkpoint == nil {
+		return nil, errors.New("can't run checkpoint sync without a checkpoint")
+	}
 	chainDb, err := CreateDB(ctx, config, "chaindata")
 	if err != nil {
 		return nil, err
@@ -156,8 +163,14 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 
 
 	vmConfig := vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}
+	cacheConfig := &core.CacheConfig{
+		Disabled:      config.NoPruning,
+		TrieNodeLimit: config.TrieCache,
+		TrieTimeLimit: config.TrieTimeout,
+		StateHistory:  config.TrieStateHistory,
+	}
 
-	eth.blockchain, err = core.NewBlockChain(chainDb, eth.chainConfig, eth.engine, vmConfig, posEngine)
+	eth.blockchain, err = core.NewBlockChainWithCache(chainDb, cacheConfig, eth.chainConfig, eth.engine, vmConfig, posEngine)
 	if err != nil {
 		return nil, err
 	}
@@ -179,11 +192,23 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.ProtocolJournal != "" {
+		config.TxPool.ProtocolJournal = ctx.ResolvePath(config.TxPool.ProtocolJournal)
+	}
 	eth.txPool = core.NewTxPool(config.TxPool, eth.chainConfig, eth.blockchain)
 
 	if eth.protocolManager, err = NewProtocolManager(eth.chainConfig, config.SyncMode, config.NetworkId, eth.eventMux, eth.txPool, eth.engine, eth.blockchain, chainDb); err != nil {
 		return nil, err
 	}
+	if config.EquivocationGossip {
+		eth.protocolManager.equivocations = posEngine
+	}
+	if config.Checkpoint != nil {
+		if config.Checkpoint.Number < eth.blockchain.GetFirstPosBlockNumber() {
+			return nil, errCheckpointNotPos
+		}
+		eth.protocolManager.downloader.SetCheckpoint(config.Checkpoint, &posCheckpointer{eth.blockchain})
+	}
 	eth.miner = miner.New(eth, eth.chainConfig, eth.EventMux(), eth.engine)
 	eth.miner.SetExtra(makeExtraData(config.ExtraData))
 
@@ -193,6 +218,10 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		gpoParams.Default = config.GasPrice
 	}
 	eth.ApiBackend.gpo = gasprice.NewOracle(eth.ApiBackend, gpoParams)
+	if config.OTAScan {
+		eth.otaScanner = otascan.New(eth.ApiBackend)
+	}
+	eth.validatorHistory = validatorhistory.New(eth.ApiBackend, config.ValidatorHistoryBackfill)
 
 
     if inPosStage{
@@ -267,6 +296,10 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 	apis = append(apis, posapi.APIs(s.BlockChain(), s.ApiBackend)...)
+	if s.otaScanner != nil {
+		apis = append(apis, otascan.APIs(s.otaScanner)...)
+	}
+	apis = append(apis, validatorhistory.APIs(s.validatorHistory)...)
 
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
@@ -430,6 +463,12 @@ func (s *Ethereum) Start(srvr *p2p.Server) error {
 	// Start the RPC service
 	s.netRPCService = ethapi.NewPublicNetAPI(srvr, s.NetVersion())
 
+	// Start the OTA scanner following the chain head
+	if s.otaScanner != nil {
+		s.otaScanner.Start()
+	}
+	s.validatorHistory.Start()
+
 	// Figure out a max peers count based on the server limits
 	maxPeers := srvr.MaxPeers
 	if s.config.LightServ > 0 {
@@ -453,6 +492,10 @@ func (s *Ethereum) Stop() error {
 		s.stopDbUpgrade()
 	}
 	s.bloomIndexer.Close()
+	if s.otaScanner != nil {
+		s.otaScanner.Stop()
+	}
+	s.validatorHistory.Stop()
 	s.blockchain.Stop()
 	s.protocolManager.Stop()
 	if s.lesServer != nil {
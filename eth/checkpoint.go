@@ -0,0 +1,134 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/pos/cfm"
+	"github.com/wanchain/go-wanchain/pos/epochLeader"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	posUtil "github.com/wanchain/go-wanchain/pos/util"
+)
+
+// checkpointEpochs is the number of epochs before the one of the checkpoint
+// whose last state is downloaded: the leaders of an epoch are selected from the
+// state at the end of the epoch two before, and the blocks of an epoch are
+// verified against the leaders of the previous one, so importing the blocks
+// after the checkpoint needs the leaders of its previous, own and next epochs.
+const checkpointEpochs = 3
+
+var (
+	errCheckpointNotPos  = errors.New("checkpoint before the first pos block")
+	errFirstEpochInvalid = errors.New("first pos block not in an epoch before the checkpoint")
+	errFirstEpochChanged = errors.New("first pos epoch differs from the known one")
+)
+
+// posCheckpointer rebuilds the epoch block index and the epoch leader and
+// random proposer selections, which a pos node stores in posdb while executing
+// blocks, for a chain synced up to a checkpoint.
+//
+// The security message an epoch leader builds from the stage two transactions
+// it received is not rebuilt, as it is only derived with the leader's key: a
+// checkpoint synced leader falls back to the genesis pieces until it builds one
+// again. The verification of the blocks reads those transactions from the state.
+type posCheckpointer struct {
+	blockchain *core.BlockChain
+}
+
+// StateHeaders returns the last blocks of the epochs before the checkpoint
+// whose state the leaders are selected from, oldest first.
+func (c *posCheckpointer) StateHeaders(checkpoint *types.Header) ([]*types.Header, error) {
+	blocks, _, err := c.epochBlocks(checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	// Epochs without blocks share the last block of an earlier one
+	unique := make(map[common.Hash]*types.Header)
+	for _, header := range blocks {
+		unique[header.Hash()] = header
+	}
+	headers := make([]*types.Header, 0, len(unique))
+	for _, header := range unique {
+		headers = append(headers, header)
+	}
+	sort.Slice(headers, func(i, j int) bool { return headers[i].Number.Cmp(headers[j].Number) < 0 })
+	return headers, nil
+}
+
+// Rebuild indexes the epoch blocks and selects the leaders of the epochs around
+// the checkpoint from the downloaded states.
+func (c *posCheckpointer) Rebuild(checkpoint *types.Header) error {
+	blocks, firstEpochID, err := c.epochBlocks(checkpoint)
+	if err != nil {
+		return err
+	}
+	posconfig.FirstEpochId = firstEpochID
+
+	epochID, _ := posUtil.GetEpochSlotIDFromDifficulty(checkpoint.Difficulty)
+	for id, header := range blocks {
+		posUtil.SetEpochBlock(id, header.Number.Uint64(), header.Hash())
+	}
+	posUtil.SetEpochBlock(epochID, checkpoint.Number.Uint64(), checkpoint.Hash())
+
+	epocher := epochLeader.NewEpocher(c.blockchain)
+	for id := epochID - 1; id <= epochID+1; id++ {
+		// The first epochs are led by the genesis leaders
+		if id < firstEpochID+2 {
+			continue
+		}
+		if err := epocher.SelectLeadersLoop(id); err != nil {
+			return fmt.Errorf("failed to select the leaders of epoch %d: %v", id, err)
+		}
+		log.Info("Rebuilt checkpoint epoch leaders", "epochID", id, "leaders", len(epocher.GetEpochLeaders(id)), "proposers", len(epocher.GetRBProposer(id)))
+	}
+	return nil
+}
+
+// Stable checks the checkpoint against the confirmation rule of the stable
+// blocks, with the remote headers after it.
+func (c *posCheckpointer) Stable(checkpoint *types.Header, suffix []*types.Header) bool {
+	return cfm.IsStableBySuffix(checkpoint, suffix, uint64(posUtil.Now().Unix()))
+}
+
+// epochBlocks returns the last blocks of the checkpointEpochs epochs before the
+// checkpoint by epoch, and the first pos epoch. The first epoch is checked to
+// be a pos one up to the checkpoint's, and to be the one already known if any.
+func (c *posCheckpointer) epochBlocks(checkpoint *types.Header) (map[uint64]*types.Header, uint64, error) {
+	first := c.blockchain.GetHeaderByNumber(c.blockchain.GetFirstPosBlockNumber())
+	if first == nil || checkpoint.Number.Cmp(first.Number) < 0 {
+		return nil, 0, errCheckpointNotPos
+	}
+	firstEpochID, _ := posUtil.GetEpochSlotIDFromDifficulty(first.Difficulty)
+	epochID, _ := posUtil.GetEpochSlotIDFromDifficulty(checkpoint.Difficulty)
+	if firstEpochID == 0 || firstEpochID > epochID {
+		return nil, 0, errFirstEpochInvalid
+	}
+	if posconfig.FirstEpochId != 0 && posconfig.FirstEpochId != firstEpochID {
+		return nil, 0, errFirstEpochChanged
+	}
+
+	blocks := make(map[uint64]*types.Header)
+	header := checkpoint
+	for target := epochID; target > firstEpochID && epochID-target < checkpointEpochs; {
+		target--
+		for {
+			if id, _ := posUtil.GetEpochSlotIDFromDifficulty(header.Difficulty); id <= target {
+				break
+			}
+			parent := c.blockchain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+			if parent == nil {
+				return nil, 0, fmt.Errorf("missing checkpoint ancestor %d", header.Number.Uint64()-1)
+			}
+			header = parent
+		}
+		blocks[target] = header
+	}
+	return blocks, firstEpochID, nil
+}
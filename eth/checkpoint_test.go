@@ -0,0 +1,101 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/consensus/ethash"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	posUtil "github.com/wanchain/go-wanchain/pos/util"
+)
+
+// newCheckpointChain creates a chain whose blocks from 3 on are pos ones of
+// the epochs 100 to 104, with no block in epoch 102.
+func newCheckpointChain(t *testing.T) (*posCheckpointer, []*types.Header) {
+	db, _ := ethdb.NewMemDatabase()
+	gspec := core.DefaultPPOWTestingGenesisBlock()
+	config := *gspec.Config
+	config.PosFirstBlock = big.NewInt(3)
+	gspec.Config = &config
+	genesis := gspec.MustCommit(db)
+	blockchain, err := core.NewBlockChain(db, gspec.Config, ethash.NewFaker(db), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+
+	headers := []*types.Header{genesis.Header()}
+	epochs := []uint64{0, 0, 100, 100, 101, 101, 103, 104}
+	for i := 1; i <= len(epochs); i++ {
+		difficulty := big.NewInt(1)
+		if epoch := epochs[i-1]; epoch != 0 {
+			difficulty = new(big.Int).SetUint64(epoch<<32 | uint64(i)<<8)
+		}
+		header := &types.Header{Number: big.NewInt(int64(i)), ParentHash: headers[i-1].Hash(), Difficulty: difficulty}
+		core.WriteHeader(db, header)
+		core.WriteCanonicalHash(db, header.Hash(), uint64(i))
+		headers = append(headers, header)
+	}
+	return &posCheckpointer{blockchain}, headers
+}
+
+func TestCheckpointEpochBlocks(t *testing.T) {
+	defer func(id uint64) { posconfig.FirstEpochId = id }(posconfig.FirstEpochId)
+	posconfig.FirstEpochId = 0
+
+	checkpointer, headers := newCheckpointChain(t)
+
+	// The state of the last blocks of the epochs 101 to 103 is needed, the
+	// empty epoch 102 sharing the one of epoch 101
+	states, err := checkpointer.StateHeaders(headers[8])
+	if err != nil {
+		t.Fatalf("failed to get the checkpoint states: %v", err)
+	}
+	if len(states) != 2 || states[0].Hash() != headers[6].Hash() || states[1].Hash() != headers[7].Hash() {
+		t.Fatalf("checkpoint states mismatch: have %d headers", len(states))
+	}
+
+	// A checkpoint in the first epochs is rebuilt without selecting leaders
+	if err := checkpointer.Rebuild(headers[4]); err != nil {
+		t.Fatalf("failed to rebuild the checkpoint: %v", err)
+	}
+	if posconfig.FirstEpochId != 100 || posUtil.GetEpochBlockHash(100) != headers[4].Hash() {
+		t.Errorf("rebuilt epoch data mismatch: first epoch %d", posconfig.FirstEpochId)
+	}
+}
+
+func TestCheckpointMismatch(t *testing.T) {
+	defer func(id uint64) { posconfig.FirstEpochId = id }(posconfig.FirstEpochId)
+	posconfig.FirstEpochId = 0
+
+	checkpointer, headers := newCheckpointChain(t)
+
+	// A pow block is no checkpoint
+	if err := checkpointer.Rebuild(headers[2]); err != errCheckpointNotPos {
+		t.Errorf("pow checkpoint error mismatch: have %v, want %v", err, errCheckpointNotPos)
+	}
+	// Nor is a block of an epoch before the first pos one
+	early := &types.Header{Number: big.NewInt(9), ParentHash: headers[8].Hash(), Difficulty: big.NewInt(99 << 32)}
+	if err := checkpointer.Rebuild(early); err != errFirstEpochInvalid {
+		t.Errorf("early checkpoint error mismatch: have %v, want %v", err, errFirstEpochInvalid)
+	}
+	// The first epoch known already isn't overwritten
+	posconfig.FirstEpochId = 90
+	if err := checkpointer.Rebuild(headers[4]); err != errFirstEpochChanged {
+		t.Errorf("conflicting first epoch error mismatch: have %v, want %v", err, errFirstEpochChanged)
+	}
+	if posconfig.FirstEpochId != 90 {
+		t.Errorf("first epoch overwritten: have %d, want 90", posconfig.FirstEpochId)
+	}
+	// A checkpoint off the local chain is missing its ancestors
+	posconfig.FirstEpochId = 0
+	orphan := &types.Header{Number: big.NewInt(9), Difficulty: big.NewInt(104 << 32)}
+	if _, err := checkpointer.StateHeaders(orphan); err == nil {
+		t.Errorf("checkpoint without ancestors accepted")
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/common/hexutil"
@@ -42,6 +43,9 @@ var DefaultConfig = Config{
 	NetworkId:            1,
 	LightPeers:           20,
 	DatabaseCache:        128,
+	NoPruning:            true,
+	TrieCache:            256,
+	TrieTimeout:          5 * time.Minute,
 	//GasPrice:             big.NewInt(0).Mul(big.NewInt(18 * params.Shannon),params.WanGasTimesFactor),
 	GasPrice:             big.NewInt(1 * params.Shannon),
 	TxPool: core.DefaultTxPoolConfig,
@@ -76,6 +80,15 @@ type Config struct {
 	NetworkId uint64 // Network ID to use for selecting peers to connect to
 	SyncMode  downloader.SyncMode
 
+	// Trusted stable block a checkpoint sync starts from
+	Checkpoint *downloader.Checkpoint `toml:",omitempty"`
+
+	// Gossip the evidence of slot leaders sealing two blocks for the same slot
+	EquivocationGossip bool
+
+	// Index the validator histories from the genesis rather than the current head
+	ValidatorHistoryBackfill bool
+
 	// Light client options
 	LightServ  int `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
 	LightPeers int `toml:",omitempty"` // Maximum number of LES client peers
@@ -85,6 +98,12 @@ type Config struct {
 	DatabaseHandles    int  `toml:"-"`
 	DatabaseCache      int
 
+	// Trie pruning options, a pruning node keeps only the recent block states
+	NoPruning        bool          // Whether to disable pruning and write every state to disk
+	TrieCache        int           // Memory (MB) of cached tries at which the oldest kept state is flushed
+	TrieTimeout      time.Duration // Time after which the oldest kept state is flushed
+	TrieStateHistory uint64        // Number of recent block states kept, zero for the chain default
+
 	// Mining-related options
 	Etherbase    common.Address `toml:",omitempty"`
 	MinerThreads int            `toml:",omitempty"`
@@ -108,6 +127,9 @@ type Config struct {
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Index the OTAs received by the keystore accounts watched through personal_listOTAs
+	OTAScan bool
+
 	// Miscellaneous options
 	DocRoot   string `toml:"-"`
 	PowFake   bool   `toml:"-"`
@@ -0,0 +1,198 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/pos/cfm"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+)
+
+var (
+	errNoCheckpoint       = errors.New("checkpoint sync requested without a checkpoint")
+	errCheckpointMismatch = errors.New("checkpoint hash mismatch")
+	errCheckpointUnstable = errors.New("checkpoint not stable on the remote chain")
+)
+
+// Checkpoint is a trusted stable block from which a node syncs the chain
+// without executing the blocks before it.
+type Checkpoint struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+// ParseCheckpoint parses a checkpoint given as <number>:<hash>.
+func ParseCheckpoint(s string) (*Checkpoint, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid checkpoint %q, want <number>:<hash>", s)
+	}
+	number, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checkpoint number %q: %v", parts[0], err)
+	}
+	hash, err := hexutil.Decode(parts[1])
+	if err != nil || len(hash) != common.HashLength {
+		return nil, fmt.Errorf("invalid checkpoint hash %q", parts[1])
+	}
+	return &Checkpoint{Number: number, Hash: common.BytesToHash(hash)}, nil
+}
+
+// String implements the stringer interface, in the format of ParseCheckpoint.
+func (c *Checkpoint) String() string {
+	return fmt.Sprintf("%d:%s", c.Number, c.Hash.Hex())
+}
+
+// Checkpointer rebuilds the consensus data which a node derives while executing
+// blocks, for a chain synced up to a checkpoint.
+type Checkpointer interface {
+	// StateHeaders returns the ancestors of the checkpoint whose state is needed
+	// to rebuild the consensus data, besides the state of the checkpoint.
+	StateHeaders(checkpoint *types.Header) ([]*types.Header, error)
+
+	// Rebuild rebuilds the consensus data once the states are downloaded.
+	Rebuild(checkpoint *types.Header) error
+
+	// Stable tells whether the checkpoint is stable on a remote chain, given
+	// the remote headers after it up to the remote head.
+	Stable(checkpoint *types.Header, suffix []*types.Header) bool
+}
+
+// SetCheckpoint sets the trusted block checkpoint sync pivots at, and the
+// checkpointer rebuilding the consensus data from its state.
+func (d *Downloader) SetCheckpoint(checkpoint *Checkpoint, checkpointer Checkpointer) {
+	d.checkpoint = checkpoint
+	d.checkpointer = checkpointer
+}
+
+// checkpointSyncWithPeer fast syncs with the pivot locked in at the checkpoint,
+// after checking the peer agrees on it and it is stable on the remote chain.
+func (d *Downloader) checkpointSyncWithPeer(p *peerConnection, origin uint64, height uint64, heightHeader *types.Header, td *big.Int) error {
+	if d.checkpoint == nil || d.checkpointer == nil {
+		return errNoCheckpoint
+	}
+	// Nothing to skip once the local chain is past the checkpoint
+	if origin >= d.checkpoint.Number {
+		d.mode = FullSync
+		return d.fullSyncWithPeer(p, origin, height, heightHeader, td)
+	}
+	if d.checkpoint.Number+cfm.SecBlkDiff > height {
+		return errCheckpointUnstable
+	}
+	header, _, err := d.fetchHeaderTd(p, d.checkpoint.Number)
+	if err != nil {
+		return err
+	}
+	if header == nil || header.Number.Uint64() != d.checkpoint.Number || header.Hash() != d.checkpoint.Hash {
+		p.log.Warn("Checkpoint mismatch", "header", header, "want", d.checkpoint)
+		return errCheckpointMismatch
+	}
+	// The stable blocks are found scanning the K blocks below the head, the
+	// checkpoint is checked against the remote blocks after it if it's among them
+	if d.checkpoint.Number+posconfig.K > height {
+		suffix, err := d.fetchCheckpointSuffix(p, header, height)
+		if err != nil {
+			return err
+		}
+		if !d.checkpointer.Stable(header, suffix) {
+			p.log.Warn("Checkpoint not stable", "checkpoint", d.checkpoint, "height", height)
+			return errCheckpointUnstable
+		}
+	}
+	d.fsPivotLock = header
+
+	d.mode = FastSync
+	return d.fastSyncWithPeerPow(p, origin, height, heightHeader, td, true)
+}
+
+// fetchCheckpointSuffix retrieves the remote headers after the checkpoint up to
+// height, checking they are chained to it.
+func (d *Downloader) fetchCheckpointSuffix(p *peerConnection, checkpoint *types.Header, height uint64) ([]*types.Header, error) {
+	suffix := make([]*types.Header, 0, height-checkpoint.Number.Uint64())
+	parent := checkpoint
+	for parent.Number.Uint64() < height {
+		from := parent.Number.Uint64() + 1
+		count := MaxHeaderFetch
+		if left := height - parent.Number.Uint64(); left < uint64(count) {
+			count = int(left)
+		}
+		go p.peer.RequestHeadersByNumber(from, count, 0, false, uint64(0))
+
+		headers, err := d.waitHeaders(p)
+		if err != nil {
+			return nil, err
+		}
+		if len(headers) == 0 {
+			return nil, errEmptyHeaderSet
+		}
+		for _, header := range headers {
+			if header.Number.Uint64() != parent.Number.Uint64()+1 || header.ParentHash != parent.Hash() {
+				p.log.Warn("Checkpoint suffix broke chain ordering", "number", header.Number, "parent", parent.Number)
+				return nil, errInvalidChain
+			}
+			suffix = append(suffix, header)
+			parent = header
+		}
+	}
+	return suffix, nil
+}
+
+// waitHeaders waits for the response of a peer to a header request.
+func (d *Downloader) waitHeaders(p *peerConnection) ([]*types.Header, error) {
+	ttl := d.requestTTL()
+	timeout := time.After(ttl)
+	for {
+		select {
+		case <-d.cancelCh:
+			return nil, errCancelHeaderFetch
+
+		case packet := <-d.headerCh:
+			// Discard anything not from the origin peer
+			if packet.PeerId() != p.id {
+				log.Debug("Received headers from incorrect peer", "peer", packet.PeerId())
+				break
+			}
+			return packet.(*headerPack).headers, nil
+
+		case <-timeout:
+			p.log.Debug("Waiting for checkpoint suffix timed out", "elapsed", ttl)
+			return nil, errTimeout
+
+		case <-d.bodyCh:
+		case <-d.receiptCh:
+			// Out of bounds delivery, ignore
+		}
+	}
+}
+
+// isCheckpoint reports whether the header is the one of the checkpoint.
+func (d *Downloader) isCheckpoint(header *types.Header) bool {
+	return d.checkpoint != nil && d.checkpointer != nil &&
+		header.Number.Uint64() == d.checkpoint.Number && header.Hash() == d.checkpoint.Hash
+}
+
+// syncCheckpointStates downloads the states of the checkpoint ancestors needed
+// to rebuild the consensus data.
+func (d *Downloader) syncCheckpointStates(checkpoint *types.Header) error {
+	headers, err := d.checkpointer.StateHeaders(checkpoint)
+	if err != nil {
+		return err
+	}
+	for _, header := range headers {
+		log.Info("Syncing checkpoint ancestor state", "number", header.Number, "hash", header.Hash())
+		if err := d.syncState(header.Root).Wait(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package downloader
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/event"
+	"github.com/wanchain/go-wanchain/log"
+)
+
+func TestParseCheckpoint(t *testing.T) {
+	hash := common.HexToHash("0x4f2a8d0a3c8ee1b4b1b0cc2d1d31e4a4b0b1dbbdc1a9c5f67c8c3ad8c7d2e6f1")
+
+	checkpoint, err := ParseCheckpoint("4046000:" + hash.Hex())
+	if err != nil {
+		t.Fatalf("failed to parse checkpoint: %v", err)
+	}
+	if checkpoint.Number != 4046000 || checkpoint.Hash != hash {
+		t.Fatalf("checkpoint mismatch: have %v, want 4046000:%s", checkpoint, hash.Hex())
+	}
+	if again, err := ParseCheckpoint(checkpoint.String()); err != nil || *again != *checkpoint {
+		t.Fatalf("checkpoint round trip mismatch: have %v (%v), want %v", again, err, checkpoint)
+	}
+	for _, invalid := range []string{"", "4046000", hash.Hex(), "-1:" + hash.Hex(), "4046000:0x1234", "4046000:" + hash.Hex()[2:], "1:2:3"} {
+		if _, err := ParseCheckpoint(invalid); err == nil {
+			t.Errorf("invalid checkpoint %q accepted", invalid)
+		}
+	}
+}
+
+func TestCheckpointSyncModeText(t *testing.T) {
+	text, err := CheckpointSync.MarshalText()
+	if err != nil || string(text) != "checkpoint" {
+		t.Fatalf("marshal mismatch: have %q (%v), want %q", text, err, "checkpoint")
+	}
+	var mode SyncMode
+	if err := mode.UnmarshalText(text); err != nil || mode != CheckpointSync {
+		t.Fatalf("unmarshal mismatch: have %v (%v), want %v", mode, err, CheckpointSync)
+	}
+	if !mode.IsValid() || mode.String() != "checkpoint" {
+		t.Fatalf("checkpoint sync mode invalid or misnamed: %v", mode)
+	}
+}
+
+// checkpointTestPeer serves the headers of a chain, the other requests are
+// left to the embedded nil peer.
+type checkpointTestPeer struct {
+	Peer
+	id      string
+	dl      *Downloader
+	headers []*types.Header
+}
+
+func (p *checkpointTestPeer) RequestHeaderTdByNumber(number uint64) error {
+	go p.dl.DeliverHeaderTd(p.id, &types.HeaderTdData{Header: p.headers[number], Td: big.NewInt(int64(number))})
+	return nil
+}
+
+func (p *checkpointTestPeer) RequestHeadersByNumber(origin uint64, amount int, skip int, reverse bool, to uint64) error {
+	end := origin + uint64(amount)
+	if end > uint64(len(p.headers)) {
+		end = uint64(len(p.headers))
+	}
+	go p.dl.DeliverHeaders(p.id, p.headers[origin:end])
+	return nil
+}
+
+// checkpointTestCheckpointer records the suffix the stability is checked with.
+type checkpointTestCheckpointer struct {
+	stable bool
+	suffix []*types.Header
+}
+
+func (c *checkpointTestCheckpointer) StateHeaders(*types.Header) ([]*types.Header, error) {
+	return nil, nil
+}
+func (c *checkpointTestCheckpointer) Rebuild(*types.Header) error { return nil }
+func (c *checkpointTestCheckpointer) Stable(checkpoint *types.Header, suffix []*types.Header) bool {
+	c.suffix = suffix
+	return c.stable
+}
+
+func TestCheckpointSyncWithPeer(t *testing.T) {
+	headers := []*types.Header{{Number: new(big.Int)}}
+	for i := 1; i <= 300; i++ {
+		headers = append(headers, &types.Header{Number: big.NewInt(int64(i)), ParentHash: headers[i-1].Hash()})
+	}
+	head := headers[len(headers)-1]
+
+	db, _ := ethdb.NewMemDatabase()
+	dl := New(CheckpointSync, db, new(event.TypeMux), nil, nil, func(string) {})
+	defer dl.Terminate()
+	dl.cancelCh = make(chan struct{})
+
+	peer := &checkpointTestPeer{id: "peer", dl: dl, headers: headers}
+	conn := newPeerConnection(peer.id, 64, peer, log.New("peer", peer.id))
+	sync := func(checkpoint *Checkpoint, checkpointer Checkpointer) error {
+		dl.SetCheckpoint(checkpoint, checkpointer)
+		return dl.checkpointSyncWithPeer(conn, 0, head.Number.Uint64(), head, big.NewInt(300))
+	}
+
+	if err := sync(nil, nil); err != errNoCheckpoint {
+		t.Errorf("missing checkpoint error mismatch: have %v, want %v", err, errNoCheckpoint)
+	}
+	// A checkpoint the peer doesn't agree on is rejected
+	checkpointer := new(checkpointTestCheckpointer)
+	if err := sync(&Checkpoint{Number: 100, Hash: headers[101].Hash()}, checkpointer); err != errCheckpointMismatch {
+		t.Errorf("mismatching checkpoint error mismatch: have %v, want %v", err, errCheckpointMismatch)
+	}
+	// So is one too close to the remote head
+	if err := sync(&Checkpoint{Number: 280, Hash: headers[280].Hash()}, checkpointer); err != errCheckpointUnstable {
+		t.Errorf("recent checkpoint error mismatch: have %v, want %v", err, errCheckpointUnstable)
+	}
+	// A matching checkpoint is checked with the remote blocks after it
+	if err := sync(&Checkpoint{Number: 100, Hash: headers[100].Hash()}, checkpointer); err != errCheckpointUnstable {
+		t.Errorf("unstable checkpoint error mismatch: have %v, want %v", err, errCheckpointUnstable)
+	}
+	if len(checkpointer.suffix) != 200 || checkpointer.suffix[0] != headers[101] || checkpointer.suffix[199] != head {
+		t.Errorf("checkpoint suffix mismatch: have %d headers", len(checkpointer.suffix))
+	}
+	if dl.fsPivotLock != nil {
+		t.Errorf("pivot locked at an unstable checkpoint")
+	}
+
+	// The suffix must be chained to the checkpoint
+	headers[150] = &types.Header{Number: big.NewInt(150)}
+	checkpointer.stable = true
+	if err := sync(&Checkpoint{Number: 100, Hash: headers[100].Hash()}, checkpointer); err != errInvalidChain {
+		t.Errorf("broken suffix error mismatch: have %v, want %v", err, errInvalidChain)
+	}
+}
@@ -109,6 +109,9 @@ type Downloader struct {
 	fsPivotLock  *types.Header // Pivot header on critical section entry (cannot change between retries)
 	fsPivotFails uint32        // Number of subsequent fast sync failures in the critical section
 
+	checkpoint   *Checkpoint  // Trusted block checkpoint sync pivots at
+	checkpointer Checkpointer // Rebuilds the consensus data from the checkpoint state
+
 	rttEstimate   uint64 // Round trip time to target for download requests
 	rttConfidence uint64 // Confidence in the estimated RTT (unit: millionths to allow atomic ops)
 
@@ -334,7 +337,7 @@ func (d *Downloader) Synchronise(id string, head common.Hash, td *big.Int, mode
 
 	case errTimeout, errBadPeer, errStallingPeer,
 		errEmptyHeaderSet, errPeersUnavailable, errTooOld,
-		errInvalidAncestor, errInvalidChain:
+		errInvalidAncestor, errInvalidChain, errCheckpointMismatch:
 		log.Warn("Synchronisation failed, dropping peer", "peer", id, "err", err)
 		d.dropPeer(id)
 
@@ -447,6 +450,9 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.I
 		log.Error("find ancestor error")
 		return err
 	}
+	if d.mode == CheckpointSync {
+		return d.checkpointSyncWithPeer(p, origin, height, latest, td)
+	}
 	onlyPow := 0
 	posFirst := d.blockchain.GetFirstPosBlockNumber()
 	if origin+1 < posFirst  {
@@ -738,7 +744,7 @@ func (d *Downloader) findAncestor(p *peerConnection, height uint64) (uint64, err
 	p.log.Debug("Looking for common ancestor", "local", ceil, "remote", height)
 	if d.mode == FullSync {
 		ceil = d.blockchain.CurrentBlock().NumberU64()
-	} else if d.mode == FastSync {
+	} else if d.mode == FastSync || d.mode == CheckpointSync {
 		ceil = d.blockchain.CurrentFastBlock().NumberU64()
 		ceilFull := d.blockchain.CurrentBlock().NumberU64()
 		if ceilFull > ceil {
@@ -1631,10 +1637,24 @@ func (d *Downloader) commitPivotBlock(result *fetchResult) error {
 	if err := d.syncState(b.Root()).Wait(); err != nil {
 		return err
 	}
+	checkpoint := d.isCheckpoint(b.Header())
+	if checkpoint {
+		if err := d.syncCheckpointStates(b.Header()); err != nil {
+			return err
+		}
+	}
 	log.Debug("Committing fast sync pivot as new head", "number", b.Number(), "hash", b.Hash())
 	if _, err := d.blockchain.InsertReceiptChain([]*types.Block{b}, []types.Receipts{result.Receipts}); err != nil {
 		return err
 	}
+	// Rebuild the consensus data before the head moves, the blocks after the
+	// checkpoint can't be verified without it
+	if checkpoint {
+		if err := d.checkpointer.Rebuild(b.Header()); err != nil {
+			log.Error("Failed to rebuild the checkpoint consensus data", "number", b.Number(), "hash", b.Hash(), "err", err)
+			return err
+		}
+	}
 	return d.blockchain.FastSyncCommitHead(b.Hash())
 }
 
@@ -109,6 +109,19 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if f.end == -1 {
 		end = head
 	}
+	if f.begin == rpc.StableBlockNumber.Int64() || f.end == rpc.StableBlockNumber.Int64() {
+		header, err := f.backend.HeaderByNumber(ctx, rpc.StableBlockNumber)
+		if header == nil || err != nil {
+			return nil, err
+		}
+		stable := header.Number.Uint64()
+		if f.begin == rpc.StableBlockNumber.Int64() {
+			f.begin = int64(stable)
+		}
+		if f.end == rpc.StableBlockNumber.Int64() {
+			end = stable
+		}
+	}
 	// Gather all indexed logs, and finish with non indexed ones
 	var (
 		logs []*types.Log
@@ -50,6 +50,8 @@ const (
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// StableBlocksSubscription queries headers of blocks that become stable
+	StableBlocksSubscription
 	// LastSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -86,12 +88,13 @@ type subscription struct {
 // EventSystem creates subscriptions, processes events and broadcasts them to the
 // subscription which match the subscription criteria.
 type EventSystem struct {
-	mux       *event.TypeMux
-	backend   Backend
-	lightMode bool
-	lastHead  *types.Header
-	install   chan *subscription // install filter for event notification
-	uninstall chan *subscription // remove filter for event notification
+	mux        *event.TypeMux
+	backend    Backend
+	lightMode  bool
+	lastHead   *types.Header
+	lastStable uint64             // Number of the last block notified as stable
+	install    chan *subscription // install filter for event notification
+	uninstall  chan *subscription // remove filter for event notification
 }
 
 // NewEventSystem creates a new manager that listens for event on the given mux,
@@ -175,6 +178,10 @@ func (es *EventSystem) SubscribeLogs(crit FilterCriteria, logs chan []*types.Log
 		to = rpc.BlockNumber(crit.ToBlock.Int64())
 	}
 
+	// stable logs are only known after the blocks were confirmed
+	if from == rpc.StableBlockNumber || to == rpc.StableBlockNumber {
+		return nil, fmt.Errorf("stable block tag is not supported for log subscriptions")
+	}
 	// only interested in pending logs
 	if from == rpc.PendingBlockNumber && to == rpc.PendingBlockNumber {
 		return es.subscribePendingLogs(crit, logs), nil
@@ -265,6 +272,22 @@ func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscripti
 	return es.subscribe(sub)
 }
 
+// SubscribeStableHeads creates a subscription that writes the header of a block
+// once the confirmation engine deems it irreversible.
+func (es *EventSystem) SubscribeStableHeads(headers chan *types.Header) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       StableBlocksSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan common.Hash),
+		headers:   headers,
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
 // SubscribePendingTxEvents creates a subscription that writes transaction hashes for
 // transactions that enter the transaction pool.
 func (es *EventSystem) SubscribePendingTxEvents(hashes chan common.Hash) *Subscription {
@@ -323,6 +346,13 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 		for _, f := range filters[BlocksSubscription] {
 			f.headers <- e.Block.Header()
 		}
+		if len(filters[StableBlocksSubscription]) > 0 {
+			es.stableNewHead(func(header *types.Header) {
+				for _, f := range filters[StableBlocksSubscription] {
+					f.headers <- header
+				}
+			})
+		}
 		if es.lightMode && len(filters[LogsSubscription]) > 0 {
 			es.lightFilterNewHead(e.Block.Header(), func(header *types.Header, remove bool) {
 				for _, f := range filters[LogsSubscription] {
@@ -335,6 +365,23 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 	}
 }
 
+// stableNewHead looks the stable block up once for all the subscriptions, and
+// calls back with every block that became stable since the last one.
+func (es *EventSystem) stableNewHead(callBack func(*types.Header)) {
+	stable, err := es.backend.HeaderByNumber(context.Background(), rpc.StableBlockNumber)
+	if stable == nil || err != nil {
+		return
+	}
+	for n := es.lastStable + 1; n <= stable.Number.Uint64(); n++ {
+		header, err := es.backend.HeaderByNumber(context.Background(), rpc.BlockNumber(n))
+		if header == nil || err != nil {
+			return
+		}
+		callBack(header)
+		es.lastStable = n
+	}
+}
+
 func (es *EventSystem) lightFilterNewHead(newHeader *types.Header, callBack func(*types.Header, bool)) {
 	oldh := es.lastHead
 	es.lastHead = newHeader
@@ -446,6 +493,13 @@ func (es *EventSystem) eventLoop() {
 				index[LogsSubscription][f.id] = f
 				index[PendingLogsSubscription][f.id] = f
 			} else {
+				// the first stable subscription starts from the current stable block
+				if f.typ == StableBlocksSubscription && len(index[f.typ]) == 0 {
+					es.lastStable = 0
+					if stable, _ := es.backend.HeaderByNumber(context.Background(), rpc.StableBlockNumber); stable != nil {
+						es.lastStable = stable.Number.Uint64()
+					}
+				}
 				index[f.typ][f.id] = f
 			}
 			close(f.installed)
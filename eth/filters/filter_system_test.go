@@ -37,6 +37,10 @@ import (
 	"github.com/wanchain/go-wanchain/rpc"
 )
 
+// testStableLag is the number of blocks the test backend keeps above its
+// stable block.
+const testStableLag = 10
+
 type testBackend struct {
 	mux        *event.TypeMux
 	db         ethdb.Database
@@ -61,6 +65,13 @@ func (b *testBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumbe
 	if blockNr == rpc.LatestBlockNumber {
 		hash = core.GetHeadBlockHash(b.db)
 		num = core.GetBlockNumber(b.db, hash)
+	} else if blockNr == rpc.StableBlockNumber {
+		num = core.GetBlockNumber(b.db, core.GetHeadBlockHash(b.db))
+		if num < testStableLag {
+			return nil, nil
+		}
+		num -= testStableLag
+		hash = core.GetCanonicalHash(b.db, num)
 	} else {
 		num = uint64(blockNr)
 		hash = core.GetCanonicalHash(b.db, num)
@@ -193,6 +204,71 @@ func TestBlockSubscription(t *testing.T) {
 	<-sub1.Err()
 }
 
+// TestStableHeadsSubscription tests if every stable heads subscription receives
+// the headers of the blocks which became stable since it was created.
+func TestStableHeadsSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mux        = new(event.TypeMux)
+		db, _      = ethdb.NewMemDatabase()
+		engine     = ethash.NewFaker(db)
+		txFeed     = new(event.Feed)
+		rmLogsFeed = new(event.Feed)
+		logsFeed   = new(event.Feed)
+		chainFeed  = new(event.Feed)
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		api        = NewPublicFilterAPI(backend, false)
+	)
+
+	gspec := core.DefaultPPOWTestingGenesisBlock()
+	genesis := gspec.MustCommit(db)
+	blockChain, _ := core.NewBlockChain(db, gspec.Config, engine, vm.Config{})
+	defer blockChain.Stop()
+
+	chainEnv := core.NewChainEnv(gspec.Config, gspec, engine, blockChain, db)
+	chain, _ := chainEnv.GenerateChain(genesis, testStableLag+5, func(i int, gen *core.BlockGen) {})
+
+	// The subscriptions start from the stable block 2
+	if _, err := blockChain.InsertChain(chain[:testStableLag+2]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chan0 := make(chan *types.Header)
+	sub0 := api.events.SubscribeStableHeads(chan0)
+	chan1 := make(chan *types.Header)
+	sub1 := api.events.SubscribeStableHeads(chan1)
+
+	if _, err := blockChain.InsertChain(chain[testStableLag+2:]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	go func() {
+		for _, blk := range chain[testStableLag+2:] {
+			chainFeed.Send(core.ChainEvent{Hash: blk.Hash(), Block: blk})
+		}
+	}()
+
+	// Blocks 3, 4 and 5 are stable once the head is 15
+	want := chain[2:5]
+	for i0, i1 := 0, 0; i0 != len(want) || i1 != len(want); {
+		select {
+		case header := <-chan0:
+			if header.Hash() != want[i0].Hash() {
+				t.Errorf("sub0 received invalid header on index %d, want %d, got %d", i0, want[i0].NumberU64(), header.Number)
+			}
+			i0++
+		case header := <-chan1:
+			if header.Hash() != want[i1].Hash() {
+				t.Errorf("sub1 received invalid header on index %d, want %d, got %d", i1, want[i1].NumberU64(), header.Number)
+			}
+			i1++
+		case <-time.After(time.Second):
+			t.Fatalf("stable headers missed, sub0 got %d, sub1 got %d", i0, i1)
+		}
+	}
+	sub0.Unsubscribe()
+	sub1.Unsubscribe()
+}
+
 // TestPendingTxFilter tests whether pending tx filters retrieve all pending transactions that are posted to the event mux.
 func TestPendingTxFilter(t *testing.T) {
 	t.Parallel()
@@ -326,6 +402,8 @@ func TestInvalidLogFilterCreation(t *testing.T) {
 		0: {FromBlock: big.NewInt(rpc.PendingBlockNumber.Int64()), ToBlock: big.NewInt(rpc.LatestBlockNumber.Int64())},
 		1: {FromBlock: big.NewInt(rpc.PendingBlockNumber.Int64()), ToBlock: big.NewInt(100)},
 		2: {FromBlock: big.NewInt(rpc.LatestBlockNumber.Int64()), ToBlock: big.NewInt(100)},
+		// Reason: stable logs are not streamed
+		3: {FromBlock: big.NewInt(rpc.StableBlockNumber.Int64()), ToBlock: big.NewInt(rpc.LatestBlockNumber.Int64())},
 	}
 
 	for i, test := range testCases {
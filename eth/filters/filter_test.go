@@ -31,6 +31,7 @@ import (
 	"github.com/wanchain/go-wanchain/crypto"
 	"github.com/wanchain/go-wanchain/ethdb"
 	"github.com/wanchain/go-wanchain/event"
+	"github.com/wanchain/go-wanchain/rpc"
 )
 
 func makeReceipt(addr common.Address) *types.Receipt {
@@ -235,6 +236,19 @@ func TestFilters(t *testing.T) {
 		t.Errorf("expected log[0].Topics[0] to be %x, got %x", hash3, logs[0].Topics[0])
 	}
 
+	stable := rpc.StableBlockNumber.Int64()
+	filter = New(backend, 0, stable, []common.Address{addr}, [][]common.Hash{{hash1, hash2, hash3, hash4}})
+	logs, _ = filter.Logs(context.Background())
+	if len(logs) != 2 {
+		t.Error("expected 2 stable log, got", len(logs))
+	}
+
+	filter = New(backend, stable, -1, []common.Address{addr}, [][]common.Hash{{hash1, hash2, hash3, hash4}})
+	logs, _ = filter.Logs(context.Background())
+	if len(logs) != 2 {
+		t.Error("expected 2 log above the stable block, got", len(logs))
+	}
+
 	filter = New(backend, 1, 10, nil, [][]common.Hash{{hash1, hash2}})
 
 	logs, _ = filter.Logs(context.Background())
@@ -0,0 +1,92 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+// FeeHistory returns the number of the oldest of the blockCount blocks up to
+// lastBlock, the given percentiles of the gas prices paid in each of them
+// weighed by the gas used, and their gas used ratios. There is no base fee,
+// the effective tip of a transaction is its gas price. Pos transactions don't
+// compete for the gas and are left out of the percentiles, a block without
+// other transactions reports the pos floor price. The history reaches back
+// maxFeeHistory blocks at most.
+func (gpo *Oracle) FeeHistory(ctx context.Context, blockCount int, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []float64, error) {
+	if blockCount < 1 {
+		return new(big.Int), nil, nil, nil
+	}
+	if blockCount > maxFeeHistory {
+		blockCount = maxFeeHistory
+	}
+	for i, p := range rewardPercentiles {
+		if p < 0 || p > 100 || (i > 0 && p < rewardPercentiles[i-1]) {
+			return nil, nil, nil, errInvalidPercentile
+		}
+	}
+	// The pending block isn't sampled, its history ends at the head
+	if lastBlock == rpc.PendingBlockNumber {
+		lastBlock = rpc.LatestBlockNumber
+	}
+	head, err := gpo.backend.HeaderByNumber(ctx, lastBlock)
+	if head == nil {
+		if err == nil {
+			err = errUnknownBlock
+		}
+		return nil, nil, nil, err
+	}
+	last := head.Number.Uint64()
+	if uint64(blockCount) > last+1 {
+		blockCount = int(last + 1)
+	}
+	oldest := last + 1 - uint64(blockCount)
+
+	weighed := len(rewardPercentiles) > 0
+	var reward [][]*big.Int
+	if weighed {
+		reward = make([][]*big.Int, blockCount)
+	}
+	gasUsedRatio := make([]float64, blockCount)
+	floor := gpo.floor(new(big.Int))
+	for i := 0; i < blockCount; i++ {
+		samples, err := gpo.blockSamples(ctx, oldest+uint64(i), weighed)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		gasUsedRatio[i] = samples.gasUsedRatio
+		if weighed {
+			reward[i] = samples.rewards(rewardPercentiles, floor)
+		}
+	}
+	return new(big.Int).SetUint64(oldest), reward, gasUsedRatio, nil
+}
+
+// rewards returns the given percentiles of the weighed samples, or floor for
+// each of them if no transaction competed for the block.
+func (s *blockSamples) rewards(percentiles []float64, floor *big.Int) []*big.Int {
+	rewards := make([]*big.Int, len(percentiles))
+	if len(s.txs) == 0 {
+		for i := range rewards {
+			rewards[i] = new(big.Int).Set(floor)
+		}
+		return rewards
+	}
+	var total uint64
+	for _, tx := range s.txs {
+		total += tx.gasUsed
+	}
+	index, sum := 0, s.txs[0].gasUsed
+	for i, p := range percentiles {
+		threshold := uint64(float64(total) * p / 100)
+		for sum < threshold && index < len(s.txs)-1 {
+			index++
+			sum += s.txs[index].gasUsed
+		}
+		rewards[i] = new(big.Int).Set(s.txs[index].price)
+	}
+	return rewards
+}
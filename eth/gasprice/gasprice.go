@@ -18,39 +18,64 @@ package gasprice
 
 import (
 	"context"
+	"errors"
 	"math/big"
 	"sort"
 	"sync"
 
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/wanchain/go-wanchain/common"
-	"github.com/wanchain/go-wanchain/internal/ethapi"
+	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
 	"github.com/wanchain/go-wanchain/rpc"
 )
 
 var maxPrice = big.NewInt(0).Mul(big.NewInt(500 * params.Shannon),params.WanGasTimesFactor)
 
+const (
+	// sampleCacheSize is the number of blocks whose price samples are kept.
+	sampleCacheSize = 2048
+	// maxFeeHistory is the number of blocks a fee history reaches back at most.
+	maxFeeHistory = 1024
+)
+
+var (
+	errUnknownBlock      = errors.New("unknown block")
+	errMissingReceipts   = errors.New("missing receipts")
+	errInvalidPercentile = errors.New("invalid reward percentile")
+)
+
 type Config struct {
 	Blocks     int
 	Percentile int
 	Default    *big.Int `toml:",omitempty"`
 }
 
+// OracleBackend is the chain the oracle samples, either the full or the light
+// one.
+type OracleBackend interface {
+	HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error)
+	GetBlock(ctx context.Context, blockHash common.Hash) (*types.Block, error)
+	GetReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, error)
+}
+
 // Oracle recommends gas prices based on the content of recent
 // blocks. Suitable for both light and full clients.
 type Oracle struct {
-	backend   ethapi.Backend
+	backend   OracleBackend
 	lastHead  common.Hash
 	lastPrice *big.Int
 	cacheLock sync.RWMutex
 	fetchLock sync.Mutex
+	samples   *lru.Cache // Price samples of the recent blocks by hash
 
 	checkBlocks, maxEmpty, maxBlocks int
 	percentile                       int
 }
 
 // NewOracle returns a new oracle.
-func NewOracle(backend ethapi.Backend, params Config) *Oracle {
+func NewOracle(backend OracleBackend, params Config) *Oracle {
 	blocks := params.Blocks
 	if blocks < 1 {
 		blocks = 1
@@ -62,9 +87,11 @@ func NewOracle(backend ethapi.Backend, params Config) *Oracle {
 	if percent > 100 {
 		percent = 100
 	}
+	samples, _ := lru.New(sampleCacheSize)
 	return &Oracle{
 		backend:     backend,
 		lastPrice:   params.Default,
+		samples:     samples,
 		checkBlocks: blocks,
 		maxEmpty:    blocks / 2,
 		maxBlocks:   blocks * 5,
@@ -72,7 +99,8 @@ func NewOracle(backend ethapi.Backend, params Config) *Oracle {
 	}
 }
 
-// SuggestPrice returns the recommended gas price.
+// SuggestPrice returns the recommended gas price. Pos transactions are left
+// out, and the price is never below the pos floor price.
 func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	gpo.cacheLock.RLock()
 	lastHead := gpo.lastHead
@@ -80,6 +108,9 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	gpo.cacheLock.RUnlock()
 
 	head, _ := gpo.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head == nil {
+		return gpo.floor(lastPrice), nil
+	}
 	headHash := head.Hash()
 	if headHash == lastHead {
 		return lastPrice, nil
@@ -138,6 +169,7 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	if price.Cmp(maxPrice) > 0 {
 		price = new(big.Int).Set(maxPrice)
 	}
+	price = gpo.floor(price)
 
 	gpo.cacheLock.Lock()
 	gpo.lastHead = headHash
@@ -151,20 +183,104 @@ type getBlockPricesResult struct {
 	err    error
 }
 
-// getLowestPrice calculates the lowest transaction gas price in a given block
-// and sends it to the result channel. If the block is empty, price is nil.
+// getBlockPrices sends the gas prices the transactions of a given block
+// competed with to the result channel. If there are none, prices is empty.
 func (gpo *Oracle) getBlockPrices(ctx context.Context, blockNum uint64, ch chan getBlockPricesResult) {
-	block, err := gpo.backend.BlockByNumber(ctx, rpc.BlockNumber(blockNum))
-	if block == nil {
+	samples, err := gpo.blockSamples(ctx, blockNum, false)
+	if err != nil {
 		ch <- getBlockPricesResult{nil, err}
 		return
 	}
+	prices := make([]*big.Int, len(samples.txs))
+	for i, tx := range samples.txs {
+		prices[i] = tx.price
+	}
+	ch <- getBlockPricesResult{prices, nil}
+}
+
+// floor raises price to the pos floor price the system transactions are sent
+// at, below which transactions aren't mined.
+func (gpo *Oracle) floor(price *big.Int) *big.Int {
+	floor := posconfig.Cfg().DefaultGasPrice
+	if floor == nil || (price != nil && price.Cmp(floor) >= 0) {
+		return price
+	}
+	return new(big.Int).Set(floor)
+}
+
+// txSample is the gas price a transaction competed for the gas of its block
+// with, and the gas it used.
+type txSample struct {
+	price   *big.Int
+	gasUsed uint64
+}
+
+type txSamples []txSample
+
+func (s txSamples) Len() int           { return len(s) }
+func (s txSamples) Less(i, j int) bool { return s[i].price.Cmp(s[j].price) < 0 }
+func (s txSamples) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// blockSamples are the price samples of a block sorted by price. The gas
+// used by the transactions is only known if the samples are weighed.
+type blockSamples struct {
+	gasUsedRatio float64
+	txs          txSamples
+	weighed      bool
+}
+
+// blockSamples returns the price samples of the given block, from the cache if
+// it's there. Pos transactions go to the system precompiles at the floor price
+// whatever the demand, so they don't compete for the gas and aren't sampled.
+// The receipts are only read for weighed samples.
+func (gpo *Oracle) blockSamples(ctx context.Context, number uint64, weighed bool) (*blockSamples, error) {
+	header, err := gpo.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+	if header == nil {
+		if err == nil {
+			err = errUnknownBlock
+		}
+		return nil, err
+	}
+	hash := header.Hash()
+	if cached, ok := gpo.samples.Get(hash); ok {
+		if samples := cached.(*blockSamples); samples.weighed || !weighed {
+			return samples, nil
+		}
+	}
+	block, err := gpo.backend.GetBlock(ctx, hash)
+	if block == nil {
+		if err == nil {
+			err = errUnknownBlock
+		}
+		return nil, err
+	}
 	txs := block.Transactions()
-	prices := make([]*big.Int, len(txs))
+	var receipts types.Receipts
+	if weighed {
+		if receipts, err = gpo.backend.GetReceipts(ctx, hash); err != nil {
+			return nil, err
+		}
+		if len(receipts) != len(txs) {
+			return nil, errMissingReceipts
+		}
+	}
+	samples := &blockSamples{weighed: weighed}
+	if header.GasLimit != nil && header.GasLimit.Sign() > 0 && header.GasUsed != nil {
+		samples.gasUsedRatio, _ = new(big.Float).Quo(new(big.Float).SetInt(header.GasUsed), new(big.Float).SetInt(header.GasLimit)).Float64()
+	}
 	for i, tx := range txs {
-		prices[i] = tx.GasPrice()
+		if types.IsPosTransaction(tx.Txtype()) {
+			continue
+		}
+		sample := txSample{price: tx.GasPrice()}
+		if weighed && receipts[i].GasUsed != nil {
+			sample.gasUsed = receipts[i].GasUsed.Uint64()
+		}
+		samples.txs = append(samples.txs, sample)
 	}
-	ch <- getBlockPricesResult{prices, nil}
+	sort.Sort(samples.txs)
+	gpo.samples.Add(hash, samples)
+	return samples, nil
 }
 
 type bigIntArray []*big.Int
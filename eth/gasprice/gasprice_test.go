@@ -0,0 +1,175 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+// testBackend is a chain of blocks without state.
+type testBackend struct {
+	blocks   []*types.Block
+	receipts []types.Receipts
+	reads    int
+}
+
+// testTx is a transaction of a test block.
+type testTx struct {
+	price, gas int64
+	pos        bool
+}
+
+func newTestBackend(blocks ...[]testTx) *testBackend {
+	b := &testBackend{
+		blocks:   []*types.Block{types.NewBlock(&types.Header{Number: new(big.Int), GasLimit: big.NewInt(1000000), GasUsed: new(big.Int)}, nil, nil, nil)},
+		receipts: []types.Receipts{nil},
+	}
+	for i, txs := range blocks {
+		var (
+			gasUsed      = new(big.Int)
+			transactions []*types.Transaction
+			receipts     types.Receipts
+		)
+		for nonce, tx := range txs {
+			transaction := types.NewTransaction(uint64(nonce), common.Address{}, new(big.Int), big.NewInt(tx.gas), big.NewInt(tx.price), nil)
+			if tx.pos {
+				transaction.SetTxtype(types.POS_TX)
+			}
+			gasUsed.Add(gasUsed, big.NewInt(tx.gas))
+			receipt := types.NewReceipt(nil, false, new(big.Int).Set(gasUsed))
+			receipt.GasUsed = big.NewInt(tx.gas)
+			transactions = append(transactions, transaction)
+			receipts = append(receipts, receipt)
+		}
+		header := &types.Header{Number: big.NewInt(int64(i + 1)), GasLimit: big.NewInt(1000000), GasUsed: gasUsed}
+		b.blocks = append(b.blocks, types.NewBlock(header, transactions, nil, receipts))
+		b.receipts = append(b.receipts, receipts)
+	}
+	return b
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error) {
+	if blockNr == rpc.LatestBlockNumber {
+		blockNr = rpc.BlockNumber(len(b.blocks) - 1)
+	}
+	if blockNr < 0 || int(blockNr) >= len(b.blocks) {
+		return nil, nil
+	}
+	return b.blocks[blockNr].Header(), nil
+}
+
+func (b *testBackend) GetBlock(ctx context.Context, blockHash common.Hash) (*types.Block, error) {
+	b.reads++
+	for _, block := range b.blocks {
+		if block.Hash() == blockHash {
+			return block, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, error) {
+	for i, block := range b.blocks {
+		if block.Hash() == blockHash {
+			return b.receipts[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// testChain has a block of pos transactions only between two competing ones.
+func testChain() *testBackend {
+	return newTestBackend(
+		[]testTx{{price: 30, gas: 21000}, {price: 1, gas: 100000, pos: true}, {price: 10, gas: 21000}, {price: 20, gas: 21000}},
+		[]testTx{{price: 1, gas: 100000, pos: true}, {price: 1, gas: 100000, pos: true}},
+		[]testTx{{price: 50, gas: 21000}, {price: 40, gas: 50000}},
+	)
+}
+
+// setFloor sets the pos floor price, returning a function restoring it.
+func setFloor(floor *big.Int) func() {
+	old := posconfig.Cfg().DefaultGasPrice
+	posconfig.Cfg().DefaultGasPrice = floor
+	return func() { posconfig.Cfg().DefaultGasPrice = old }
+}
+
+func TestSuggestPrice(t *testing.T) {
+	defer setFloor(nil)()
+
+	// The pos transactions would lower the median to 20
+	backend := testChain()
+	gpo := NewOracle(backend, Config{Blocks: 3, Percentile: 50, Default: big.NewInt(1)})
+	if price, err := gpo.SuggestPrice(context.Background()); err != nil || price.Int64() != 30 {
+		t.Fatalf("suggested price mismatch: have %v, %v, want 30", price, err)
+	}
+
+	// The suggestion never undercuts the floor
+	setFloor(big.NewInt(35))
+	gpo = NewOracle(backend, Config{Blocks: 3, Percentile: 50, Default: big.NewInt(1)})
+	if price, err := gpo.SuggestPrice(context.Background()); err != nil || price.Int64() != 35 {
+		t.Errorf("floored price mismatch: have %v, %v, want 35", price, err)
+	}
+}
+
+func TestFeeHistory(t *testing.T) {
+	defer setFloor(big.NewInt(5))()
+
+	backend := testChain()
+	gpo := NewOracle(backend, Config{Blocks: 3, Percentile: 50})
+	oldest, reward, ratios, err := gpo.FeeHistory(context.Background(), 3, rpc.LatestBlockNumber, []float64{0, 50, 100})
+	if err != nil {
+		t.Fatalf("failed to get fee history: %v", err)
+	}
+	if oldest.Uint64() != 1 {
+		t.Errorf("oldest block mismatch: have %v, want 1", oldest)
+	}
+	want := [][]int64{{10, 20, 30}, {5, 5, 5}, {40, 40, 50}}
+	for i := range want {
+		for j := range want[i] {
+			if reward[i][j].Int64() != want[i][j] {
+				t.Errorf("block %d reward %d mismatch: have %v, want %d", i+1, j, reward[i][j], want[i][j])
+			}
+		}
+	}
+	wantRatios := []float64{0.163, 0.2, 0.071}
+	for i, ratio := range ratios {
+		if ratio != wantRatios[i] {
+			t.Errorf("block %d gas used ratio mismatch: have %v, want %v", i+1, ratio, wantRatios[i])
+		}
+	}
+
+	// The history is cached and ends at the genesis
+	reads := backend.reads
+	oldest, reward, ratios, err = gpo.FeeHistory(context.Background(), 10, rpc.PendingBlockNumber, []float64{50})
+	if err != nil {
+		t.Fatalf("failed to get fee history: %v", err)
+	}
+	if oldest.Uint64() != 0 || len(reward) != 4 || len(ratios) != 4 {
+		t.Errorf("history mismatch: have oldest %v, %d rewards, %d ratios", oldest, len(reward), len(ratios))
+	}
+	if backend.reads != reads+1 {
+		t.Errorf("cached blocks read again: have %d reads, want %d", backend.reads-reads, 1)
+	}
+	if _, reward, _, _ = gpo.FeeHistory(context.Background(), 1, 2, nil); reward != nil {
+		t.Errorf("rewards without percentiles: have %v", reward)
+	}
+}
+
+func TestFeeHistoryPercentiles(t *testing.T) {
+	gpo := NewOracle(testChain(), Config{Blocks: 3, Percentile: 50})
+	for _, percentiles := range [][]float64{{-1}, {101}, {50, 10}} {
+		if _, _, _, err := gpo.FeeHistory(context.Background(), 1, rpc.LatestBlockNumber, percentiles); err != errInvalidPercentile {
+			t.Errorf("percentiles %v error mismatch: have %v, want %v", percentiles, err, errInvalidPercentile)
+		}
+	}
+	if _, _, _, err := gpo.FeeHistory(context.Background(), 1, 10, nil); err != errUnknownBlock {
+		t.Errorf("unknown block error mismatch: have %v, want %v", err, errUnknownBlock)
+	}
+}
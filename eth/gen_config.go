@@ -4,6 +4,7 @@ package eth
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/common/hexutil"
@@ -14,42 +15,57 @@ import (
 
 func (c Config) MarshalTOML() (interface{}, error) {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               uint64
-		SyncMode                downloader.SyncMode
-		LightServ               int  `toml:",omitempty"`
-		LightPeers              int  `toml:",omitempty"`
-		MaxPeers                int  `toml:"-"`
-		SkipBcVersionCheck      bool `toml:"-"`
-		DatabaseHandles         int  `toml:"-"`
-		DatabaseCache           int
-		Etherbase               common.Address `toml:",omitempty"`
-		MinerThreads            int            `toml:",omitempty"`
-		ExtraData               hexutil.Bytes  `toml:",omitempty"`
-		GasPrice                *big.Int
-		EthashCacheDir          string
-		EthashCachesInMem       int
-		EthashCachesOnDisk      int
-		EthashDatasetDir        string
-		EthashDatasetsInMem     int
-		EthashDatasetsOnDisk    int
-		TxPool                  core.TxPoolConfig
-		GPO                     gasprice.Config
-		EnablePreimageRecording bool
-		DocRoot                 string `toml:"-"`
-		PowFake                 bool   `toml:"-"`
-		PowTest                 bool   `toml:"-"`
-		PowShared               bool   `toml:"-"`
+		Genesis                  *core.Genesis `toml:",omitempty"`
+		NetworkId                uint64
+		SyncMode                 downloader.SyncMode
+		Checkpoint               *downloader.Checkpoint `toml:",omitempty"`
+		EquivocationGossip       bool
+		ValidatorHistoryBackfill bool
+		LightServ                int  `toml:",omitempty"`
+		LightPeers               int  `toml:",omitempty"`
+		MaxPeers                 int  `toml:"-"`
+		SkipBcVersionCheck       bool `toml:"-"`
+		DatabaseHandles          int  `toml:"-"`
+		DatabaseCache            int
+		NoPruning                bool
+		TrieCache                int
+		TrieTimeout              time.Duration
+		TrieStateHistory         uint64
+		Etherbase                common.Address `toml:",omitempty"`
+		MinerThreads             int            `toml:",omitempty"`
+		ExtraData                hexutil.Bytes  `toml:",omitempty"`
+		GasPrice                 *big.Int
+		EthashCacheDir           string
+		EthashCachesInMem        int
+		EthashCachesOnDisk       int
+		EthashDatasetDir         string
+		EthashDatasetsInMem      int
+		EthashDatasetsOnDisk     int
+		TxPool                   core.TxPoolConfig
+		GPO                      gasprice.Config
+		EnablePreimageRecording  bool
+		OTAScan                  bool
+		DocRoot                  string `toml:"-"`
+		PowFake                  bool   `toml:"-"`
+		PowTest                  bool   `toml:"-"`
+		PowShared                bool   `toml:"-"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
 	enc.NetworkId = c.NetworkId
 	enc.SyncMode = c.SyncMode
+	enc.Checkpoint = c.Checkpoint
+	enc.EquivocationGossip = c.EquivocationGossip
+	enc.ValidatorHistoryBackfill = c.ValidatorHistoryBackfill
 	enc.LightServ = c.LightServ
 	enc.LightPeers = c.LightPeers
 	enc.SkipBcVersionCheck = c.SkipBcVersionCheck
 	enc.DatabaseHandles = c.DatabaseHandles
 	enc.DatabaseCache = c.DatabaseCache
+	enc.NoPruning = c.NoPruning
+	enc.TrieCache = c.TrieCache
+	enc.TrieTimeout = c.TrieTimeout
+	enc.TrieStateHistory = c.TrieStateHistory
 	enc.Etherbase = c.Etherbase
 	enc.MinerThreads = c.MinerThreads
 	enc.ExtraData = c.ExtraData
@@ -63,6 +79,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
+	enc.OTAScan = c.OTAScan
 	enc.DocRoot = c.DocRoot
 	enc.PowFake = c.PowFake
 	enc.PowTest = c.PowTest
@@ -72,32 +89,40 @@ func (c Config) MarshalTOML() (interface{}, error) {
 
 func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               *uint64
-		SyncMode                *downloader.SyncMode
-		LightServ               *int  `toml:",omitempty"`
-		LightPeers              *int  `toml:",omitempty"`
-		MaxPeers                *int  `toml:"-"`
-		SkipBcVersionCheck      *bool `toml:"-"`
-		DatabaseHandles         *int  `toml:"-"`
-		DatabaseCache           *int
-		Etherbase               *common.Address `toml:",omitempty"`
-		MinerThreads            *int            `toml:",omitempty"`
-		ExtraData               hexutil.Bytes   `toml:",omitempty"`
-		GasPrice                *big.Int
-		EthashCacheDir          *string
-		EthashCachesInMem       *int
-		EthashCachesOnDisk      *int
-		EthashDatasetDir        *string
-		EthashDatasetsInMem     *int
-		EthashDatasetsOnDisk    *int
-		TxPool                  *core.TxPoolConfig
-		GPO                     *gasprice.Config
-		EnablePreimageRecording *bool
-		DocRoot                 *string `toml:"-"`
-		PowFake                 *bool   `toml:"-"`
-		PowTest                 *bool   `toml:"-"`
-		PowShared               *bool   `toml:"-"`
+		Genesis                  *core.Genesis `toml:",omitempty"`
+		NetworkId                *uint64
+		SyncMode                 *downloader.SyncMode
+		Checkpoint               *downloader.Checkpoint `toml:",omitempty"`
+		EquivocationGossip       *bool
+		ValidatorHistoryBackfill *bool
+		LightServ                *int  `toml:",omitempty"`
+		LightPeers               *int  `toml:",omitempty"`
+		MaxPeers                 *int  `toml:"-"`
+		SkipBcVersionCheck       *bool `toml:"-"`
+		DatabaseHandles          *int  `toml:"-"`
+		DatabaseCache            *int
+		NoPruning                *bool
+		TrieCache                *int
+		TrieTimeout              *time.Duration
+		TrieStateHistory         *uint64
+		Etherbase                *common.Address `toml:",omitempty"`
+		MinerThreads             *int            `toml:",omitempty"`
+		ExtraData                hexutil.Bytes   `toml:",omitempty"`
+		GasPrice                 *big.Int
+		EthashCacheDir           *string
+		EthashCachesInMem        *int
+		EthashCachesOnDisk       *int
+		EthashDatasetDir         *string
+		EthashDatasetsInMem      *int
+		EthashDatasetsOnDisk     *int
+		TxPool                   *core.TxPoolConfig
+		GPO                      *gasprice.Config
+		EnablePreimageRecording  *bool
+		OTAScan                  *bool
+		DocRoot                  *string `toml:"-"`
+		PowFake                  *bool   `toml:"-"`
+		PowTest                  *bool   `toml:"-"`
+		PowShared                *bool   `toml:"-"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -112,6 +137,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SyncMode != nil {
 		c.SyncMode = *dec.SyncMode
 	}
+	if dec.Checkpoint != nil {
+		c.Checkpoint = dec.Checkpoint
+	}
+	if dec.EquivocationGossip != nil {
+		c.EquivocationGossip = *dec.EquivocationGossip
+	}
+	if dec.ValidatorHistoryBackfill != nil {
+		c.ValidatorHistoryBackfill = *dec.ValidatorHistoryBackfill
+	}
 	if dec.LightServ != nil {
 		c.LightServ = *dec.LightServ
 	}
@@ -127,6 +161,18 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DatabaseCache != nil {
 		c.DatabaseCache = *dec.DatabaseCache
 	}
+	if dec.NoPruning != nil {
+		c.NoPruning = *dec.NoPruning
+	}
+	if dec.TrieCache != nil {
+		c.TrieCache = *dec.TrieCache
+	}
+	if dec.TrieTimeout != nil {
+		c.TrieTimeout = *dec.TrieTimeout
+	}
+	if dec.TrieStateHistory != nil {
+		c.TrieStateHistory = *dec.TrieStateHistory
+	}
 	if dec.Etherbase != nil {
 		c.Etherbase = *dec.Etherbase
 	}
@@ -166,6 +212,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
+	if dec.OTAScan != nil {
+		c.OTAScan = *dec.OTAScan
+	}
 	if dec.DocRoot != nil {
 		c.DocRoot = *dec.DocRoot
 	}
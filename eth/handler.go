@@ -29,6 +29,7 @@ import (
 
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/consensus"
+	"github.com/wanchain/go-wanchain/consensus/pluto"
 	"github.com/wanchain/go-wanchain/core"
 	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/eth/downloader"
@@ -39,6 +40,7 @@ import (
 	"github.com/wanchain/go-wanchain/p2p"
 	"github.com/wanchain/go-wanchain/p2p/discover"
 	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/posdb"
 	"github.com/wanchain/go-wanchain/rlp"
 )
 
@@ -49,6 +51,9 @@ const (
 	// txChanSize is the size of channel listening to TxPreEvent.
 	// The number is referenced from the size of tx pool.
 	txChanSize = 4096
+
+	// equivocationChanSize is the size of channel listening to EquivocationEvent.
+	equivocationChanSize = 16
 )
 
 var (
@@ -66,8 +71,9 @@ func errResp(code errCode, format string, v ...interface{}) error {
 type ProtocolManager struct {
 	networkId uint64
 
-	fastSync  uint32 // Flag whether fast sync is enabled (gets disabled if we already have blocks)
-	acceptTxs uint32 // Flag whether we're considered synchronised (enables transaction processing)
+	fastSync       uint32 // Flag whether fast sync is enabled (gets disabled if we already have blocks)
+	checkpointSync uint32 // Flag whether checkpoint sync is enabled (gets disabled if we already have blocks)
+	acceptTxs      uint32 // Flag whether we're considered synchronised (enables transaction processing)
 
 	txpool      txPool
 	blockchain  *core.BlockChain
@@ -86,6 +92,11 @@ type ProtocolManager struct {
 	txSub         event.Subscription
 	minedBlockSub *event.TypeMuxSubscription
 
+	// evidence of equivocating slot leaders, only gossiped when enabled
+	equivocations   *pluto.Pluto
+	equivocationCh  chan pluto.EquivocationEvent
+	equivocationSub event.Subscription
+
 	// channels for fetcher, syncer, txsyncLoop
 	newPeerCh   chan *peer
 	txsyncCh    chan *txsync
@@ -115,18 +126,21 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		quitSync:    make(chan struct{}),
 	}
 	// Figure out whether to allow fast sync or not
-	if mode == downloader.FastSync && blockchain.CurrentBlock().NumberU64() > 0 {
-		log.Warn("Blockchain not empty, fast sync disabled")
+	if (mode == downloader.FastSync || mode == downloader.CheckpointSync) && blockchain.CurrentBlock().NumberU64() > 0 {
+		log.Warn("Blockchain not empty, fast sync disabled", "mode", mode)
 		mode = downloader.FullSync
 	}
 	if mode == downloader.FastSync {
 		manager.fastSync = uint32(1)
 	}
+	if mode == downloader.CheckpointSync {
+		manager.checkpointSync = uint32(1)
+	}
 	// Initiate a sub-protocol for every implemented version we can handle
 	manager.SubProtocols = make([]p2p.Protocol, 0, len(ProtocolVersions))
 	for i, version := range ProtocolVersions {
 		// Skip protocol version if incompatible with the mode of operation
-		if mode == downloader.FastSync && version < eth63 {
+		if (mode == downloader.FastSync || mode == downloader.CheckpointSync) && version < eth63 {
 			continue
 		}
 		// Compatible; initialise the sub-protocol
@@ -224,6 +238,13 @@ func (pm *ProtocolManager) Start(maxPeers int) {
 	pm.minedBlockSub = pm.eventMux.Subscribe(core.NewMinedBlockEvent{})
 	go pm.minedBroadcastLoop()
 
+	// broadcast evidence of equivocating slot leaders
+	if pm.equivocations != nil {
+		pm.equivocationCh = make(chan pluto.EquivocationEvent, equivocationChanSize)
+		pm.equivocationSub = pm.equivocations.SubscribeEquivocationEvent(pm.equivocationCh)
+		go pm.equivocationBroadcastLoop()
+	}
+
 	// start sync handlers
 	go pm.syncer()
 	go pm.txsyncLoop()
@@ -237,6 +258,9 @@ func (pm *ProtocolManager) Stop() {
 
 	pm.txSub.Unsubscribe()         // quits txBroadcastLoop
 	pm.minedBlockSub.Unsubscribe() // quits blockBroadcastLoop
+	if pm.equivocationSub != nil {
+		pm.equivocationSub.Unsubscribe() // quits equivocationBroadcastLoop
+	}
 
 	// Quit the sync loop.
 	// After this send has completed, no new peers will be accepted.
@@ -596,7 +620,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 				return errResp(ErrDecode, "msg %v: %v", msg, err)
 			}
 			// Retrieve the requested state entry, stopping if enough was found
-			if entry, err := pm.chaindb.Get(hash.Bytes()); err == nil {
+			if entry, err := pm.blockchain.TrieDB().Get(hash.Bytes()); err == nil {
 				data = append(data, entry)
 				bytes += len(entry)
 			}
@@ -747,6 +771,29 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			log.Debug("Failed to deliver header td", "err", err)
 		}
 
+	case p.version >= eth64 && msg.Code == EquivocationMsg:
+		// Evidence is only relayed by nodes gossiping it, drop it otherwise
+		if pm.equivocations == nil {
+			break
+		}
+		var ev posdb.Equivocation
+		if err := msg.Decode(&ev); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		// Verifying evidence checks signatures and slot proofs, so the same
+		// evidence is only verified once per peer and its rate is bounded
+		hash := ev.Hash()
+		if p.knownEquivocations.Has(hash) {
+			break
+		}
+		p.MarkEquivocation(hash)
+		if !p.AllowEquivocation() {
+			return errResp(ErrDecode, "too many equivocation evidences")
+		}
+		if _, err := pm.equivocations.AddEquivocation(&ev); err != nil {
+			return errResp(ErrDecode, "invalid equivocation evidence: %v", err)
+		}
+
 	default:
 		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
 	}
@@ -848,6 +895,17 @@ func (pm *ProtocolManager) sendBufferTxsLoop() {
 }
 
 
+// BroadcastEquivocation propagates the evidence of an equivocating slot leader
+// to all peers which are not known to already have it.
+func (pm *ProtocolManager) BroadcastEquivocation(ev *posdb.Equivocation) {
+	hash := ev.Hash()
+	peers := pm.peers.PeersWithoutEquivocation(hash)
+	for _, peer := range peers {
+		peer.SendEquivocation(ev)
+	}
+	log.Trace("Broadcast equivocation", "hash", hash, "recipients", len(peers))
+}
+
 // Mined broadcast loop
 func (self *ProtocolManager) minedBroadcastLoop() {
 	// automatically stops if unsubscribe
@@ -873,6 +931,19 @@ func (self *ProtocolManager) txBroadcastLoop() {
 	}
 }
 
+func (pm *ProtocolManager) equivocationBroadcastLoop() {
+	for {
+		select {
+		case ev := <-pm.equivocationCh:
+			pm.BroadcastEquivocation(ev.Evidence)
+
+		// Err() channel will be closed when unsubscribing.
+		case <-pm.equivocationSub.Err():
+			return
+		}
+	}
+}
+
 // EthNodeInfo represents a short summary of the Ethereum sub-protocol metadata known
 // about the host peer.
 type EthNodeInfo struct {
@@ -21,6 +21,7 @@ import (
 	"math/big"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/core"
@@ -31,6 +32,7 @@ import (
 	"github.com/wanchain/go-wanchain/ethdb"
 	"github.com/wanchain/go-wanchain/p2p"
 	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/posdb"
 )
 
 var bigTxGas = new(big.Int).SetUint64(params.TxGas)
@@ -45,6 +47,7 @@ func TestProtocolCompatibility(t *testing.T) {
 	}{
 		{61, downloader.FullSync, true}, {62, downloader.FullSync, true}, {63, downloader.FullSync, true},
 		{61, downloader.FastSync, false}, {62, downloader.FastSync, false}, {63, downloader.FastSync, true},
+		{64, downloader.FullSync, true}, {64, downloader.FastSync, true},
 	}
 	// Make sure anything we screw up is restored
 	backup := ProtocolVersions
@@ -430,3 +433,49 @@ func testGetReceipt(t *testing.T, protocol int) {
 		t.Errorf("receipts mismatch: %v", err)
 	}
 }
+
+// Tests that equivocation evidence is only sent to the peers speaking eth/64.
+func TestBroadcastEquivocation(t *testing.T) {
+	pm := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	defer pm.Stop()
+
+	peer63, _ := newTestPeer("peer63", 63, pm, true)
+	defer peer63.close()
+	peer64, _ := newTestPeer("peer64", 64, pm, true)
+	defer peer64.close()
+	for i := 0; pm.peers.Len() < 2; i++ {
+		if i == 100 {
+			t.Fatalf("peers not registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	a := &types.Header{Number: big.NewInt(1), Extra: []byte{1}}
+	b := &types.Header{Number: big.NewInt(1), Extra: []byte{2}}
+	ev := posdb.NewEquivocation(1, 2, common.Address{3}, a, b)
+	peers := pm.peers.PeersWithoutEquivocation(ev.Hash())
+	if len(peers) != 1 || peers[0].version != eth64 {
+		t.Fatalf("evidence recipients mismatch: have %d peers, want the eth/64 one", len(peers))
+	}
+	go pm.BroadcastEquivocation(ev)
+	if err := p2p.ExpectMsg(peer64.app, EquivocationMsg, ev); err != nil {
+		t.Errorf("evidence mismatch: %v", err)
+	}
+}
+
+// Tests that a peer may only send a bounded amount of evidence per window.
+func TestEquivocationRate(t *testing.T) {
+	p := new(peer)
+	for i := 0; i < maxEquivocationsWindow; i++ {
+		if !p.AllowEquivocation() {
+			t.Fatalf("evidence %d rejected within the rate", i)
+		}
+	}
+	if p.AllowEquivocation() {
+		t.Fatal("evidence beyond the rate accepted")
+	}
+	p.equivocationStart = time.Now().Add(-2 * equivocationWindow)
+	if !p.AllowEquivocation() {
+		t.Fatal("evidence of a new window rejected")
+	}
+}
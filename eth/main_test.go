@@ -0,0 +1,24 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package eth
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/pos/posconfig/posconfigtest"
+)
+
+// TestMain keeps the PoS databases the tests open by name out of the
+// package directory.
+func TestMain(m *testing.M) {
+	cleanup, err := posconfigtest.UseTempDbPath()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	cleanup()
+	os.Exit(code)
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package otascan
+
+import (
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+// APIs returns the OTA scanner RPC services.
+func APIs(s *Scanner) []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "personal",
+			Version:   "1.0",
+			Service:   NewPrivateOTAScanAPI(s),
+			Public:    false,
+		},
+	}
+}
+
+// PrivateOTAScanAPI lets the owner of unlocked accounts manage the OTA index.
+type PrivateOTAScanAPI struct {
+	s *Scanner
+}
+
+// NewPrivateOTAScanAPI creates a new private OTA scanner API.
+func NewPrivateOTAScanAPI(s *Scanner) *PrivateOTAScanAPI {
+	return &PrivateOTAScanAPI{s}
+}
+
+// ListOTAs returns every OTA owned by the account, spent or not. The account is
+// scanned first if it is not watched yet, which requires it to be unlocked.
+func (api *PrivateOTAScanAPI) ListOTAs(addr common.Address) ([]OwnedOTA, error) {
+	if !api.s.Watching(addr) {
+		if err := api.s.Watch(addr); err != nil {
+			return nil, err
+		}
+	}
+	return api.s.OwnedOTAs(addr)
+}
+
+// RescanOTAs rebuilds the OTA index of an unlocked account from the latest state.
+func (api *PrivateOTAScanAPI) RescanOTAs(addr common.Address) (int, error) {
+	if err := api.s.Watch(addr); err != nil {
+		return 0, err
+	}
+	owned, err := api.s.OwnedOTAs(addr)
+	return len(owned), err
+}
+
+// UnwatchOTAs stops indexing the OTAs of the account.
+func (api *PrivateOTAScanAPI) UnwatchOTAs(addr common.Address) bool {
+	watching := api.s.Watching(addr)
+	api.s.Unwatch(addr)
+	return watching
+}
+
+// SpendableOTA is an unspent OTA of a watched account.
+type SpendableOTA struct {
+	OTA     hexutil.Bytes `json:"ota"`
+	Balance *hexutil.Big  `json:"balance"`
+	Stamp   bool          `json:"stamp"`
+}
+
+// GetOwnedOTAs returns the unspent OTAs of an account watched through personal_listOTAs,
+// that is the ones a wallet can spend, without their key images.
+func (api *PrivateOTAScanAPI) GetOwnedOTAs(addr common.Address) ([]SpendableOTA, error) {
+	owned, err := api.s.OwnedOTAs(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]SpendableOTA, 0, len(owned))
+	for _, ota := range owned {
+		if ota.Spent {
+			continue
+		}
+		ret = append(ret, SpendableOTA{OTA: ota.OTA, Balance: ota.Balance, Stamp: ota.Stamp})
+	}
+	return ret, nil
+}
@@ -0,0 +1,518 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+// Package otascan implements a wallet side scanner which finds the privacy
+// payments (OTAs) received by the local keystore accounts.
+//
+// The index is kept in memory only, since it links the accounts to their OTAs
+// and must not outlive the unlocked keys on disk. After a restart every account
+// has to be watched again while unlocked, which rebuilds its index by a full
+// scan of the OTA storage: one view key check per stored OTA of every
+// supported balance, in the order of the number of privacy payments ever made.
+//
+// New OTAs are found in the OTA storage of the privacy precompiles rather than
+// in the transactions of the blocks, so OTAs bought through contracts are
+// indexed the same as the ones bought directly.
+package otascan
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/wanchain/go-wanchain/accounts"
+	"github.com/wanchain/go-wanchain/accounts/keystore"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/event"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/rpc"
+	"github.com/wanchain/go-wanchain/trie"
+)
+
+const chainHeadChanSize = 16
+
+var (
+	ErrNoKeyStore  = errors.New("no keystore backend available")
+	ErrNotWatching = errors.New("account is not watched by the OTA scanner")
+	ErrIndexStale  = errors.New("account was locked during the OTA scan, unlock it to catch up")
+)
+
+// Backend is the part of the node the scanner reads the chain and the keys from.
+type Backend interface {
+	AccountManager() *accounts.Manager
+	StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error)
+	BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Block, error)
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// OwnedOTA is an OTA found to belong to a watched account.
+type OwnedOTA struct {
+	OTA      hexutil.Bytes `json:"ota"`
+	Balance  *hexutil.Big  `json:"balance"`
+	Stamp    bool          `json:"stamp"`
+	KeyImage hexutil.Bytes `json:"keyImage"`
+	Spent    bool          `json:"spent"`
+	Block    uint64        `json:"blockNumber"`
+}
+
+// watched is the index of a watched account. The stored OTAs and the full scans
+// missed while the account was locked are caught up once it is unlocked.
+type watched struct {
+	owned  map[common.Hash]*OwnedOTA // OTA AX -> info
+	behind bool                      // Whether the OTAs stored after block since were missed while locked
+	since  uint64                    // Last block whose stored OTAs were checked, if behind
+	rescan bool                      // Whether a full scan was missed while locked
+}
+
+func (w *watched) stale() bool {
+	return w.rescan || w.behind
+}
+
+// Scanner keeps a local index of the OTAs owned by the watched accounts. An
+// account is indexed by a full scan of the OTA storage when it is first
+// watched, afterwards the OTAs each new head adds to the storage are checked
+// and the spent status of every indexed OTA is refreshed through its key image.
+type Scanner struct {
+	backend Backend
+
+	mu       sync.RWMutex
+	index    map[common.Address]*watched
+	lastHead uint64
+	lastHash common.Hash
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates an OTA scanner on top of the given backend.
+func New(backend Backend) *Scanner {
+	return &Scanner{
+		backend: backend,
+		index:   make(map[common.Address]*watched),
+		quit:    make(chan struct{}),
+	}
+}
+
+// Start launches the goroutine following the chain head.
+func (s *Scanner) Start() {
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop terminates the scanner.
+func (s *Scanner) Stop() {
+	close(s.quit)
+	s.wg.Wait()
+}
+
+func (s *Scanner) keyStore() (*keystore.KeyStore, error) {
+	backends := s.backend.AccountManager().Backends(keystore.KeyStoreType)
+	if len(backends) == 0 {
+		return nil, ErrNoKeyStore
+	}
+	return backends[0].(*keystore.KeyStore), nil
+}
+
+// Watch starts indexing the OTAs of an account. The account has to be unlocked
+// since both its view key and its spend key are needed.
+func (s *Scanner) Watch(addr common.Address) error {
+	ks, err := s.keyStore()
+	if err != nil {
+		return err
+	}
+	if !unlocked(ks, addr) {
+		return keystore.ErrLocked
+	}
+
+	statedb, header, err := s.backend.StateAndHeaderByNumber(context.Background(), rpc.LatestBlockNumber)
+	if statedb == nil || err != nil {
+		return err
+	}
+
+	owned, err := scanState(ks, accounts.Account{Address: addr}, statedb, header.Number.Uint64())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.index[addr] = &watched{owned: owned}
+	if header.Number.Uint64() > s.lastHead {
+		s.lastHead, s.lastHash = header.Number.Uint64(), header.Hash()
+	}
+	s.mu.Unlock()
+
+	log.Info("OTA scanner watching account", "address", addr, "owned", len(owned))
+	return nil
+}
+
+// Unwatch drops the index of an account.
+func (s *Scanner) Unwatch(addr common.Address) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.index, addr)
+}
+
+// Watching reports whether the account is indexed.
+func (s *Scanner) Watching(addr common.Address) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.index[addr]
+	return ok
+}
+
+// OwnedOTAs returns a copy of the indexed OTAs of an account. An index which
+// missed OTAs while the account was locked is caught up first, if the
+// account is still locked ErrIndexStale is returned.
+func (s *Scanner) OwnedOTAs(addr common.Address) ([]OwnedOTA, error) {
+	s.mu.RLock()
+	w, ok := s.index[addr]
+	stale := ok && w.stale()
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrNotWatching
+	}
+	if stale {
+		ks, err := s.keyStore()
+		if err != nil {
+			return nil, err
+		}
+		statedb, header, err := s.backend.StateAndHeaderByNumber(context.Background(), rpc.LatestBlockNumber)
+		if statedb == nil || err != nil {
+			return nil, err
+		}
+		s.catchUp(ks, statedb, header.Number.Uint64(), addr)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if w, ok = s.index[addr]; !ok {
+		return nil, ErrNotWatching
+	}
+	if w.stale() {
+		return nil, ErrIndexStale
+	}
+	ret := make([]OwnedOTA, 0, len(w.owned))
+	for _, ota := range w.owned {
+		ret = append(ret, *ota)
+	}
+	return ret, nil
+}
+
+// unlocked reports whether the keys of the account are unlocked.
+func unlocked(ks *keystore.KeyStore, addr common.Address) bool {
+	_, _, err := ks.CheckOTAOwner(accounts.Account{Address: addr}, nil)
+	return err != keystore.ErrLocked
+}
+
+// scanState checks every OTA in the storage of statedb against the account.
+func scanState(ks *keystore.KeyStore, account accounts.Account, statedb *state.StateDB, number uint64) (map[common.Hash]*OwnedOTA, error) {
+	owned := make(map[common.Hash]*OwnedOTA)
+
+	scan := func(balances []*big.Int, stamp bool) error {
+		for _, balance := range balances {
+			var scanErr error
+			err := vm.ForEachOTA(statedb, balance, func(otaWanAddr []byte) bool {
+				ota, err := checkOTA(ks, account, statedb, otaWanAddr, balance, stamp, number)
+				if err != nil {
+					scanErr = err
+					return false
+				}
+				if ota != nil {
+					ax, _ := vm.GetAXFromWanAddr(otaWanAddr)
+					owned[common.BytesToHash(ax)] = ota
+				}
+				return true
+			})
+			if err != nil {
+				return err
+			}
+			if scanErr != nil {
+				return scanErr
+			}
+		}
+		return nil
+	}
+
+	if err := scan(vm.GetSupportWanCoinOTABalances(), false); err != nil {
+		return nil, err
+	}
+	if err := scan(vm.GetSupportStampOTABalances(), true); err != nil {
+		return nil, err
+	}
+	return owned, nil
+}
+
+// checkOTA returns the OTA info if the wanaddr belongs to the account, or nil otherwise.
+func checkOTA(ks *keystore.KeyStore, account accounts.Account, statedb *state.StateDB, otaWanAddr []byte,
+	balance *big.Int, stamp bool, number uint64) (*OwnedOTA, error) {
+	mine, image, err := ks.CheckOTAOwner(account, otaWanAddr)
+	if err == keystore.ErrLocked {
+		return nil, err
+	}
+	if err != nil || !mine {
+		// malformed OTAs in storage are simply not ours
+		return nil, nil
+	}
+
+	spent, _, err := vm.CheckOTAImageExist(statedb, image)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OwnedOTA{
+		OTA:      common.CopyBytes(otaWanAddr),
+		Balance:  (*hexutil.Big)(new(big.Int).Set(balance)),
+		Stamp:    stamp,
+		KeyImage: image,
+		Spent:    spent,
+		Block:    number,
+	}, nil
+}
+
+func (s *Scanner) loop() {
+	defer s.wg.Done()
+
+	heads := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	sub := s.backend.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-heads:
+			if ev.Block != nil {
+				s.update(ev.Block.Header())
+			}
+		case <-sub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// watchedAccounts returns the addresses of the watched accounts.
+func (s *Scanner) watchedAccounts() []common.Address {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	addrs := make([]common.Address, 0, len(s.index))
+	for addr := range s.index {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// update indexes the OTAs stored since the last head up to head and refreshes
+// the spent status of the known OTAs.
+func (s *Scanner) update(head *types.Header) {
+	s.mu.RLock()
+	empty := len(s.index) == 0
+	lastHead, lastHash := s.lastHead, s.lastHash
+	s.mu.RUnlock()
+
+	number := head.Number.Uint64()
+	if empty {
+		s.mu.Lock()
+		s.lastHead, s.lastHash = number, head.Hash()
+		s.mu.Unlock()
+		return
+	}
+
+	ks, err := s.keyStore()
+	if err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	statedb, _, err := s.backend.StateAndHeaderByNumber(ctx, rpc.BlockNumber(head.Number.Int64()))
+	if statedb == nil || err != nil {
+		log.Debug("OTA scanner failed to get state", "number", head.Number, "err", err)
+		return
+	}
+
+	reorged := number <= lastHead
+	if !reorged && lastHash != (common.Hash{}) {
+		last, _ := s.backend.BlockByNumber(ctx, rpc.BlockNumber(lastHead))
+		reorged = last == nil || last.Hash() != lastHash
+	}
+	var base *state.StateDB
+	if !reorged {
+		// the state of the last head may be pruned already
+		base, _, err = s.backend.StateAndHeaderByNumber(ctx, rpc.BlockNumber(lastHead))
+		reorged = base == nil || err != nil
+	}
+	if reorged {
+		// reorg or missing state, rescan the storage of every watched account
+		for _, addr := range s.watchedAccounts() {
+			s.rescan(ks, statedb, number, addr)
+		}
+		s.mu.Lock()
+		s.lastHead, s.lastHash = number, head.Hash()
+		s.mu.Unlock()
+		return
+	}
+
+	s.mu.Lock()
+	for _, addr := range s.indexStorage(ks, base, statedb, number, nil) {
+		if w := s.index[addr]; !w.behind {
+			w.behind, w.since = true, lastHead
+		}
+	}
+	s.lastHead, s.lastHash = number, head.Hash()
+	s.mu.Unlock()
+
+	s.catchUp(ks, statedb, number, s.watchedAccounts()...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, w := range s.index {
+		for _, ota := range w.owned {
+			if ota.Spent {
+				continue
+			}
+			spent, _, err := vm.CheckOTAImageExist(statedb, ota.KeyImage)
+			if err == nil {
+				ota.Spent = spent
+			}
+		}
+	}
+}
+
+// rescan rebuilds the index of an account from the OTA storage of statedb. A
+// locked account is marked for a rescan once it's unlocked.
+func (s *Scanner) rescan(ks *keystore.KeyStore, statedb *state.StateDB, number uint64, addr common.Address) {
+	var (
+		owned map[common.Hash]*OwnedOTA
+		err   = keystore.ErrLocked
+	)
+	if unlocked(ks, addr) {
+		owned, err = scanState(ks, accounts.Account{Address: addr}, statedb, number)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.index[addr]
+	switch {
+	case !ok:
+	case err == keystore.ErrLocked:
+		w.rescan = true
+	case err != nil:
+		log.Debug("OTA scanner rescan failed", "address", addr, "err", err)
+	default:
+		w.owned, w.rescan, w.behind = owned, false, false
+	}
+}
+
+// catchUp checks the stored OTAs and rescans the accounts missed while they
+// were locked, leaving the ones still locked stale.
+func (s *Scanner) catchUp(ks *keystore.KeyStore, statedb *state.StateDB, number uint64, addrs ...common.Address) {
+	for _, addr := range addrs {
+		s.mu.RLock()
+		w, ok := s.index[addr]
+		var (
+			rescan = ok && w.rescan
+			behind = ok && w.behind
+			since  uint64
+		)
+		if ok {
+			since = w.since
+		}
+		s.mu.RUnlock()
+
+		if !unlocked(ks, addr) || !(rescan || behind) {
+			continue
+		}
+		if rescan {
+			s.rescan(ks, statedb, number, addr)
+			continue
+		}
+		base, _, err := s.backend.StateAndHeaderByNumber(context.Background(), rpc.BlockNumber(since))
+		if base == nil || err != nil {
+			// the state the account fell behind at is gone, scan it all
+			s.rescan(ks, statedb, number, addr)
+			continue
+		}
+		s.mu.Lock()
+		if w, ok := s.index[addr]; ok && w.behind && w.since == since {
+			if len(s.indexStorage(ks, base, statedb, number, []common.Address{addr})) == 0 {
+				w.behind = false
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// indexStorage checks the OTAs stored in statedb but not in base against the
+// given accounts, or every watched one if addrs is nil. It returns the accounts
+// it couldn't check since they're locked. The caller holds the lock of the index.
+func (s *Scanner) indexStorage(ks *keystore.KeyStore, base, statedb *state.StateDB, number uint64, addrs []common.Address) []common.Address {
+	if addrs == nil {
+		for addr := range s.index {
+			addrs = append(addrs, addr)
+		}
+	}
+	locked := make(map[common.Address]bool)
+	check := func(balances []*big.Int, stamp bool) {
+		for _, balance := range balances {
+			addedOTAs(base, statedb, balance, func(otaWanAddr []byte) {
+				ax, err := vm.GetAXFromWanAddr(otaWanAddr)
+				if err != nil {
+					return
+				}
+				for _, addr := range addrs {
+					w, ok := s.index[addr]
+					if !ok || locked[addr] {
+						continue
+					}
+					ota, err := checkOTA(ks, accounts.Account{Address: addr}, statedb, otaWanAddr, balance, stamp, number)
+					if err == keystore.ErrLocked {
+						locked[addr] = true
+						continue
+					}
+					if err != nil || ota == nil {
+						continue
+					}
+					w.owned[common.BytesToHash(ax)] = ota
+				}
+			})
+		}
+	}
+	check(vm.GetSupportWanCoinOTABalances(), false)
+	check(vm.GetSupportStampOTABalances(), true)
+
+	ret := make([]common.Address, 0, len(locked))
+	for addr := range locked {
+		ret = append(ret, addr)
+	}
+	return ret
+}
+
+// addedOTAs calls cb with every OTA of the given balance stored in statedb but
+// not in base. Only the parts of the storage trie which differ are walked.
+func addedOTAs(base, statedb *state.StateDB, balance *big.Int, cb func(otaWanAddr []byte)) {
+	addr := vm.OTABalance2ContractAddr(balance)
+	tr := statedb.StorageTrie(addr)
+	if tr == nil {
+		return
+	}
+	it := tr.NodeIterator(nil)
+	if baseTr := base.StorageTrie(addr); baseTr != nil {
+		it, _ = trie.NewDifferenceIterator(baseTr.NodeIterator(nil), it)
+	}
+	for leaves := trie.NewIterator(it); leaves.Next(); {
+		if len(leaves.Value) == common.WAddressLength {
+			cb(leaves.Value)
+		}
+	}
+}
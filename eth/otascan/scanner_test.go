@@ -0,0 +1,280 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package otascan
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/accounts"
+	"github.com/wanchain/go-wanchain/accounts/abi"
+	"github.com/wanchain/go-wanchain/accounts/keystore"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/event"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+const (
+	testPassword = "wanchain_test"
+	buyCoinABI   = `[{"constant": false,"type": "function","inputs": [{"name": "OtaAddr","type":"string"},{"name": "Value","type": "uint256"}],"name": "buyCoinNote","outputs": [{"name": "OtaAddr","type":"string"},{"name": "Value","type": "uint256"}]}]`
+)
+
+var wanCoinAddr = common.BytesToAddress([]byte{100})
+
+// testBackend is a chain of blocks with the state after each of them.
+type testBackend struct {
+	am     *accounts.Manager
+	blocks []*types.Block
+	states []*state.StateDB
+	heads  event.Feed
+}
+
+func newTestBackend(t *testing.T, am *accounts.Manager) *testBackend {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("failed to create state: %v", err)
+	}
+	genesis := types.NewBlock(&types.Header{Number: new(big.Int)}, nil, nil, nil)
+	return &testBackend{am: am, blocks: []*types.Block{genesis}, states: []*state.StateDB{statedb}}
+}
+
+// addBlock appends a block buying the given OTAs on top of block parent,
+// dropping the blocks after parent.
+func (b *testBackend) addBlock(t *testing.T, parent int, otas ...[]byte) *types.Header {
+	return b.addBlockVia(t, parent, wanCoinAddr, otas...)
+}
+
+// addBlockVia is addBlock with the purchases sent to the given address, a
+// contract buying the OTAs when it isn't the precompile.
+func (b *testBackend) addBlockVia(t *testing.T, parent int, to common.Address, otas ...[]byte) *types.Header {
+	statedb := b.states[parent].Copy()
+	buyCoin, _ := abi.JSON(strings.NewReader(buyCoinABI))
+	balance := vm.GetSupportWanCoinOTABalances()[0]
+
+	var txs []*types.Transaction
+	for i, ota := range otas {
+		if _, err := vm.AddOTAIfNotExist(statedb, balance, ota); err != nil {
+			t.Fatalf("failed to add OTA: %v", err)
+		}
+		data, err := buyCoin.Pack("buyCoinNote", hexutil.Encode(ota), balance)
+		if err != nil {
+			t.Fatalf("failed to pack purchase: %v", err)
+		}
+		txs = append(txs, types.NewTransaction(uint64(i), to, balance, big.NewInt(200000), big.NewInt(1), data))
+	}
+	header := &types.Header{
+		Number:     big.NewInt(int64(parent + 1)),
+		ParentHash: b.blocks[parent].Hash(),
+		Extra:      []byte{byte(len(b.blocks))}, // Sets apart the blocks of a reorg
+	}
+	b.blocks = append(b.blocks[:parent+1], types.NewBlock(header, txs, nil, nil))
+	b.states = append(b.states[:parent+1], statedb)
+	return b.blocks[parent+1].Header()
+}
+
+func (b *testBackend) AccountManager() *accounts.Manager { return b.am }
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	if blockNr == rpc.LatestBlockNumber {
+		blockNr = rpc.BlockNumber(len(b.blocks) - 1)
+	}
+	if int(blockNr) >= len(b.blocks) {
+		return nil, nil, nil
+	}
+	return b.states[blockNr], b.blocks[blockNr].Header(), nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Block, error) {
+	if blockNr == rpc.LatestBlockNumber {
+		blockNr = rpc.BlockNumber(len(b.blocks) - 1)
+	}
+	if int(blockNr) >= len(b.blocks) {
+		return nil, nil
+	}
+	return b.blocks[blockNr], nil
+}
+
+func (b *testBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.heads.Subscribe(ch)
+}
+
+// newTestScanner creates a scanner over a keystore with a single account.
+func newTestScanner(t *testing.T) (*Scanner, *testBackend, *keystore.KeyStore, accounts.Account, func()) {
+	dir, err := ioutil.TempDir("", "otascan-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.NewAccount(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	am := accounts.NewManager(ks)
+	backend := newTestBackend(t, am)
+	return New(backend), backend, ks, account, func() {
+		am.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// newOTA generates a one-time address of the account.
+func newOTA(t *testing.T, ks *keystore.KeyStore, account accounts.Account) []byte {
+	wAddr, err := ks.GetWanAddress(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk1, pk2, err := keystore.GeneratePKPairFromWAddress(wAddr[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair := hexutil.PKPair2HexSlice(pk1, pk2)
+	ota, err := crypto.GenerateOneTimeKey(pair[0], pair[1], pair[2], pair[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := hexutil.Decode("0x" + strings.Replace(strings.Join(ota, ""), "0x", "", -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otaWAddr, err := keystore.WaddrFromUncompressedRawBytes(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return otaWAddr[:]
+}
+
+func checkOwned(t *testing.T, s *Scanner, addr common.Address, want ...[]byte) {
+	owned, err := s.OwnedOTAs(addr)
+	if err != nil {
+		t.Fatalf("failed to get owned OTAs: %v", err)
+	}
+	if len(owned) != len(want) {
+		t.Fatalf("owned OTA count mismatch: have %d, want %d", len(owned), len(want))
+	}
+	for _, ota := range want {
+		found := false
+		for _, o := range owned {
+			found = found || string(o.OTA) == string(ota)
+		}
+		if !found {
+			t.Errorf("OTA %x not indexed", ota)
+		}
+	}
+}
+
+func TestScannerIndex(t *testing.T) {
+	s, backend, ks, account, cleanup := newTestScanner(t)
+	defer cleanup()
+
+	if err := s.Watch(account.Address); err != keystore.ErrLocked {
+		t.Fatalf("watching a locked account error mismatch: have %v, want %v", err, keystore.ErrLocked)
+	}
+	ks.Unlock(account, testPassword)
+	other, _ := ks.NewAccount(testPassword)
+
+	// An OTA bought before the account is watched is found by the first scan
+	first := newOTA(t, ks, account)
+	backend.addBlock(t, 0, first)
+	if err := s.Watch(account.Address); err != nil {
+		t.Fatalf("failed to watch: %v", err)
+	}
+	checkOwned(t, s, account.Address, first)
+
+	// Later ones are found in the new blocks, OTAs of other accounts aren't
+	second := newOTA(t, ks, account)
+	s.update(backend.addBlock(t, 1, second, newOTA(t, ks, other)))
+	checkOwned(t, s, account.Address, first, second)
+
+	// Spending an OTA records its key image
+	owned, _ := s.OwnedOTAs(account.Address)
+	head := backend.addBlock(t, 2)
+	vm.AddOTAImage(backend.states[3], owned[0].KeyImage, []byte{1})
+	s.update(head)
+	if owned, _ = s.OwnedOTAs(account.Address); owned[0].Spent == owned[1].Spent {
+		t.Errorf("spent status mismatch: have %v and %v", owned[0].Spent, owned[1].Spent)
+	}
+	if _, err := s.OwnedOTAs(other.Address); err != ErrNotWatching {
+		t.Errorf("unwatched account error mismatch: have %v, want %v", err, ErrNotWatching)
+	}
+}
+
+func TestScannerContractPurchase(t *testing.T) {
+	s, backend, ks, account, cleanup := newTestScanner(t)
+	defer cleanup()
+
+	ks.Unlock(account, testPassword)
+	if err := s.Watch(account.Address); err != nil {
+		t.Fatalf("failed to watch: %v", err)
+	}
+	// An OTA bought by a contract is found although no transaction of the block calls the precompile
+	ota := newOTA(t, ks, account)
+	s.update(backend.addBlockVia(t, 0, common.HexToAddress("0x1234"), ota))
+	checkOwned(t, s, account.Address, ota)
+}
+
+func TestScannerReorg(t *testing.T) {
+	s, backend, ks, account, cleanup := newTestScanner(t)
+	defer cleanup()
+
+	ks.Unlock(account, testPassword)
+	if err := s.Watch(account.Address); err != nil {
+		t.Fatalf("failed to watch: %v", err)
+	}
+	ota := newOTA(t, ks, account)
+	s.update(backend.addBlock(t, 0, ota))
+	checkOwned(t, s, account.Address, ota)
+
+	// A longer chain without the purchase drops the OTA
+	backend.addBlock(t, 0)
+	s.update(backend.addBlock(t, 1))
+	checkOwned(t, s, account.Address)
+
+	// So does a shorter one, and the purchase is found again when it's back
+	s.update(backend.addBlock(t, 0, ota))
+	checkOwned(t, s, account.Address, ota)
+	s.update(backend.addBlock(t, 0))
+	checkOwned(t, s, account.Address)
+}
+
+func TestScannerLocked(t *testing.T) {
+	s, backend, ks, account, cleanup := newTestScanner(t)
+	defer cleanup()
+
+	ks.Unlock(account, testPassword)
+	if err := s.Watch(account.Address); err != nil {
+		t.Fatalf("failed to watch: %v", err)
+	}
+	ks.Lock(account.Address)
+
+	// The OTAs stored while the account is locked are caught up on unlock
+	ota := newOTA(t, ks, account)
+	s.update(backend.addBlock(t, 0, ota))
+	s.update(backend.addBlock(t, 1))
+	if _, err := s.OwnedOTAs(account.Address); err != ErrIndexStale {
+		t.Fatalf("locked account error mismatch: have %v, want %v", err, ErrIndexStale)
+	}
+	ks.Unlock(account, testPassword)
+	checkOwned(t, s, account.Address, ota)
+
+	// So are the rescans
+	ks.Lock(account.Address)
+	s.update(backend.addBlock(t, 0))
+	if _, err := s.OwnedOTAs(account.Address); err != ErrIndexStale {
+		t.Fatalf("locked account error mismatch: have %v, want %v", err, ErrIndexStale)
+	}
+	ks.Unlock(account, testPassword)
+	s.update(backend.addBlock(t, 1))
+	checkOwned(t, s, account.Address)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/p2p"
+	"github.com/wanchain/go-wanchain/pos/posdb"
 	"github.com/wanchain/go-wanchain/rlp"
 	"gopkg.in/fatih/set.v0"
 )
@@ -37,9 +38,13 @@ var (
 )
 
 const (
-	maxKnownTxs      = 32768 // Maximum transactions hashes to keep in the known list (prevent DOS)
-	maxKnownBlocks   = 1024  // Maximum block hashes to keep in the known list (prevent DOS)
-	handshakeTimeout = 5 * time.Second
+	maxKnownTxs           = 32768 // Maximum transactions hashes to keep in the known list (prevent DOS)
+	maxKnownBlocks        = 1024  // Maximum block hashes to keep in the known list (prevent DOS)
+	maxKnownEquivocations = 256   // Maximum equivocation hashes to keep in the known list (prevent DOS)
+	handshakeTimeout      = 5 * time.Second
+
+	equivocationWindow     = time.Minute // Time span the evidence sent by a peer is counted over
+	maxEquivocationsWindow = 16          // Maximum evidence a peer may send per window before it's dropped
 )
 
 // PeerInfo represents a short summary of the Ethereum sub-protocol metadata known
@@ -63,8 +68,12 @@ type peer struct {
 	td   *big.Int
 	lock sync.RWMutex
 
-	knownTxs    *set.Set // Set of transaction hashes known to be known by this peer
-	knownBlocks *set.Set // Set of block hashes known to be known by this peer
+	knownTxs           *set.Set // Set of transaction hashes known to be known by this peer
+	knownBlocks        *set.Set // Set of block hashes known to be known by this peer
+	knownEquivocations *set.Set // Set of equivocation hashes known to be known by this peer
+
+	equivocationStart time.Time // Start of the current evidence counting window
+	equivocationCount int       // Evidence received from the peer in the current window
 
 	bufferTxs  *set.Set
 	receiveTxs *set.Set
@@ -80,14 +89,15 @@ func newPeer(version int, p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
 	id := p.ID()
 
 	newp := &peer{
-		Peer:        p,
-		rw:          rw,
-		version:     version,
-		id:          fmt.Sprintf("%x", id[:8]),
-		knownTxs:    set.New(),
-		knownBlocks: set.New(),
-		bufferTxs:   set.New(),
-		receiveTxs:  set.New(),
+		Peer:               p,
+		rw:                 rw,
+		version:            version,
+		id:                 fmt.Sprintf("%x", id[:8]),
+		knownTxs:           set.New(),
+		knownBlocks:        set.New(),
+		knownEquivocations: set.New(),
+		bufferTxs:          set.New(),
+		receiveTxs:         set.New(),
 	}
 
 
@@ -145,6 +155,27 @@ func (p *peer) MarkTransaction(hash common.Hash) {
 	p.knownTxs.Add(hash)
 }
 
+// AllowEquivocation counts a new evidence received from the peer and reports
+// whether it is still within the rate the peer may send evidence at. It is
+// only called from the message handler of the peer.
+func (p *peer) AllowEquivocation() bool {
+	if now := time.Now(); now.Sub(p.equivocationStart) > equivocationWindow {
+		p.equivocationStart, p.equivocationCount = now, 0
+	}
+	p.equivocationCount++
+	return p.equivocationCount <= maxEquivocationsWindow
+}
+
+// MarkEquivocation marks an equivocation evidence as known for the peer,
+// ensuring that it will never be propagated to this particular peer.
+func (p *peer) MarkEquivocation(hash common.Hash) {
+	// If we reached the memory allowance, drop a previously known evidence hash
+	for p.knownEquivocations.Size() >= maxKnownEquivocations {
+		p.knownEquivocations.Pop()
+	}
+	p.knownEquivocations.Add(hash)
+}
+
 // SendTransactions sends transactions to the peer and includes the hashes
 // in its transaction hash set for future reference.
 func (p *peer) SendTransactions(txs types.Transactions) error {
@@ -189,6 +220,13 @@ func (p *peer) SendNewBlock(block *types.Block, td *big.Int) error {
 	return p2p.Send(p.rw, NewBlockMsg, []interface{}{block, td})
 }
 
+// SendEquivocation propagates the evidence of an equivocating slot leader to
+// a remote peer.
+func (p *peer) SendEquivocation(ev *posdb.Equivocation) error {
+	p.MarkEquivocation(ev.Hash())
+	return p2p.Send(p.rw, EquivocationMsg, ev)
+}
+
 // SendBlockHeaders sends a batch of block headers to the remote peer.
 func (p *peer) SendBlockHeaders(headers []*types.Header) error {
 	return p2p.Send(p.rw, BlockHeadersMsg, headers)
@@ -464,6 +502,21 @@ func (ps *peerSet) PeersWithoutTx(hash common.Hash) []*peer {
 	return list
 }
 
+// PeersWithoutEquivocation retrieves a list of peers speaking eth/64 that do
+// not have the given equivocation evidence in their set of known hashes.
+func (ps *peerSet) PeersWithoutEquivocation(hash common.Hash) []*peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	list := make([]*peer, 0, len(ps.peers))
+	for _, p := range ps.peers {
+		if p.version >= eth64 && !p.knownEquivocations.Has(hash) {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
 func (ps *peerSet) PeersList() []*peer {
 	ps.lock.RLock()
 	defer ps.lock.RUnlock()
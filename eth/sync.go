@@ -194,6 +194,10 @@ func (pm *ProtocolManager) synchronise(peer *peer) {
 	if posconfig.FirstEpochId != 0 {
 		mode = downloader.FullSync
 	}
+	if atomic.LoadUint32(&pm.checkpointSync) == 1 {
+		// Checkpoint sync covers the pos blocks, the checkpoint state holds their data
+		mode = downloader.CheckpointSync
+	}
 	// Run the sync cycle, and disable fast sync if we've went past the pivot block
 	err := pm.downloader.Synchronise(peer.id, pHead, pTd, mode)
 
@@ -204,6 +208,12 @@ func (pm *ProtocolManager) synchronise(peer *peer) {
 			atomic.StoreUint32(&pm.fastSync, 0)
 		}
 	}
+	if atomic.LoadUint32(&pm.checkpointSync) == 1 {
+		if pm.blockchain.CurrentBlock().NumberU64() > 0 {
+			log.Info("Checkpoint sync complete, auto disabling")
+			atomic.StoreUint32(&pm.checkpointSync, 0)
+		}
+	}
 	if err != nil {
 		return
 	}
@@ -326,7 +326,7 @@ func (s *Service) loop() {
 		}
 
 		// Keep sending status updates until the connection breaks
-		fullReport := time.NewTicker(posconfig.SlotTime * time.Second)
+		fullReport := time.NewTicker(time.Duration(posconfig.SlotTime) * time.Second)
 
 		for err == nil {
 			log.Debug("wanstats report small loop begin..")
@@ -0,0 +1,945 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+// Package graphql provides a GraphQL interface to Wanchain node data.
+package graphql
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/common/math"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/internal/ethapi"
+	"github.com/wanchain/go-wanchain/pos/posapi"
+	posutil "github.com/wanchain/go-wanchain/pos/util"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+// maxBlocksRange is the largest number of blocks a single blocks query may
+// span, so that one query cannot load the whole chain.
+const maxBlocksRange = 1024
+
+var (
+	errBlockNotFound = errors.New("block not found")
+	errStateNotFound = errors.New("state not found")
+	errBlocksRange   = fmt.Errorf("blocks range exceeds %d blocks", maxBlocksRange)
+)
+
+// Long is a 64 bit integer, marshaled as a JSON number.
+type Long int64
+
+// ImplementsGraphQLType returns true if Long implements the provided GraphQL type.
+func (b Long) ImplementsGraphQLType(name string) bool { return name == "Long" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data. Literals beyond
+// the range of an Int arrive as floats, so those are accepted too, as well as
+// decimal and hexadecimal strings.
+func (b *Long) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		value, err := strconv.ParseInt(input, 0, 64)
+		if err != nil {
+			return err
+		}
+		*b = Long(value)
+		return nil
+	case int32:
+		*b = Long(input)
+		return nil
+	case float64:
+		*b = Long(input)
+		return nil
+	}
+	return fmt.Errorf("unexpected type %T for Long", input)
+}
+
+// BlockNumberArgs selects the block an account is resolved at, the latest one
+// if none is given.
+type BlockNumberArgs struct {
+	Block *Long
+}
+
+// number returns the selected block number.
+func (a BlockNumberArgs) number() rpc.BlockNumber {
+	if a.Block != nil {
+		return rpc.BlockNumber(*a.Block)
+	}
+	return rpc.LatestBlockNumber
+}
+
+// toBig converts a PoS API amount to a GraphQL BigInt.
+func toBig(value *math.HexOrDecimal256) hexutil.Big {
+	if value == nil {
+		return hexutil.Big{}
+	}
+	return hexutil.Big(*value)
+}
+
+// Account represents a Wanchain account at a particular block.
+type Account struct {
+	backend     ethapi.Backend
+	address     common.Address
+	blockNumber rpc.BlockNumber
+}
+
+func (a *Account) getState(ctx context.Context) (*state.StateDB, error) {
+	state, _, err := a.backend.StateAndHeaderByNumber(ctx, a.blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, errStateNotFound
+	}
+	return state, nil
+}
+
+func (a *Account) Address(ctx context.Context) (common.Address, error) {
+	return a.address, nil
+}
+
+func (a *Account) Balance(ctx context.Context) (hexutil.Big, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*state.GetBalance(a.address)), nil
+}
+
+func (a *Account) TransactionCount(ctx context.Context) (Long, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(state.GetNonce(a.address)), nil
+}
+
+func (a *Account) Code(ctx context.Context) (hexutil.Bytes, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(state.GetCode(a.address)), nil
+}
+
+func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return state.GetState(a.address, args.Slot), nil
+}
+
+// Log represents an individual log message. All arguments are mandatory.
+type Log struct {
+	backend     ethapi.Backend
+	transaction *Transaction
+	log         *types.Log
+}
+
+func (l *Log) Transaction(ctx context.Context) *Transaction {
+	return l.transaction
+}
+
+func (l *Log) Account(ctx context.Context, args BlockNumberArgs) *Account {
+	return &Account{
+		backend:     l.backend,
+		address:     l.log.Address,
+		blockNumber: args.number(),
+	}
+}
+
+func (l *Log) Index(ctx context.Context) int32 {
+	return int32(l.log.Index)
+}
+
+func (l *Log) Topics(ctx context.Context) []common.Hash {
+	return l.log.Topics
+}
+
+func (l *Log) Data(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(l.log.Data)
+}
+
+// Transaction represents a Wanchain transaction, which is either mined in the
+// given block or still pending in the transaction pool.
+type Transaction struct {
+	r     *Resolver
+	tx    *types.Transaction
+	block *Block // Nil if the transaction is pending
+	index uint64
+}
+
+func (t *Transaction) Hash(ctx context.Context) common.Hash {
+	return t.tx.Hash()
+}
+
+func (t *Transaction) InputData(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(t.tx.Data())
+}
+
+func (t *Transaction) Gas(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*t.tx.Gas())
+}
+
+func (t *Transaction) GasPrice(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*t.tx.GasPrice())
+}
+
+func (t *Transaction) Value(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*t.tx.Value())
+}
+
+func (t *Transaction) Nonce(ctx context.Context) Long {
+	return Long(t.tx.Nonce())
+}
+
+// TxType returns the kind of the transaction. The transaction types the
+// chain accepts as normal ones, including those of old transactions, all
+// map to NORMAL.
+func (t *Transaction) TxType(ctx context.Context) string {
+	switch txType := t.tx.Txtype(); {
+	case types.IsPosTransaction(txType):
+		return "POS"
+	case types.IsPrivacyTransaction(txType):
+		return "PRIVACY"
+	default:
+		return "NORMAL"
+	}
+}
+
+func (t *Transaction) To(ctx context.Context, args BlockNumberArgs) *Account {
+	to := t.tx.To()
+	if to == nil {
+		return nil
+	}
+	return &Account{
+		backend:     t.r.backend,
+		address:     *to,
+		blockNumber: args.number(),
+	}
+}
+
+func (t *Transaction) From(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	var signer types.Signer = types.FrontierSigner{}
+	if t.tx.Protected() {
+		signer = types.NewEIP155Signer(t.tx.ChainId())
+	}
+	from, err := types.Sender(signer, t.tx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     t.r.backend,
+		address:     from,
+		blockNumber: args.number(),
+	}, nil
+}
+
+func (t *Transaction) Block(ctx context.Context) *Block {
+	return t.block
+}
+
+func (t *Transaction) Index(ctx context.Context) *int32 {
+	if t.block == nil {
+		return nil
+	}
+	index := int32(t.index)
+	return &index
+}
+
+// getReceipt returns the receipt of a mined transaction, nil if the
+// transaction is pending.
+func (t *Transaction) getReceipt(ctx context.Context) (*types.Receipt, error) {
+	if t.block == nil {
+		return nil, nil
+	}
+	receipts, err := t.r.backend.GetReceipts(ctx, t.block.hash)
+	if err != nil {
+		return nil, err
+	}
+	if t.index >= uint64(len(receipts)) {
+		return nil, nil
+	}
+	return receipts[t.index], nil
+}
+
+func (t *Transaction) Status(ctx context.Context) (*Long, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	status := Long(receipt.Status)
+	return &status, nil
+}
+
+func (t *Transaction) GasUsed(ctx context.Context) (*hexutil.Big, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil || receipt.GasUsed == nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(receipt.GasUsed), nil
+}
+
+func (t *Transaction) CumulativeGasUsed(ctx context.Context) (*hexutil.Big, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil || receipt.CumulativeGasUsed == nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(receipt.CumulativeGasUsed), nil
+}
+
+func (t *Transaction) CreatedContract(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	if t.tx.To() != nil {
+		return nil, nil
+	}
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     t.r.backend,
+		address:     receipt.ContractAddress,
+		blockNumber: args.number(),
+	}, nil
+}
+
+func (t *Transaction) Logs(ctx context.Context) (*[]*Log, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		ret = append(ret, &Log{
+			backend:     t.r.backend,
+			transaction: t,
+			log:         log,
+		})
+	}
+	return &ret, nil
+}
+
+// Block represents a Wanchain block, loaded from the chain on first access.
+type Block struct {
+	r     *Resolver
+	hash  common.Hash
+	block *types.Block
+}
+
+// resolve returns the internal Block object representing this block, fetching
+// it if necessary.
+func (b *Block) resolve(ctx context.Context) (*types.Block, error) {
+	if b.block != nil {
+		return b.block, nil
+	}
+	block, err := b.r.backend.GetBlock(ctx, b.hash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errBlockNotFound
+	}
+	b.block = block
+	return block, nil
+}
+
+func (b *Block) Number(ctx context.Context) (Long, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(block.NumberU64()), nil
+}
+
+func (b *Block) Hash(ctx context.Context) common.Hash {
+	return b.hash
+}
+
+func (b *Block) Parent(ctx context.Context) (*Block, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block.NumberU64() == 0 {
+		return nil, err
+	}
+	return &Block{r: b.r, hash: block.ParentHash()}, nil
+}
+
+func (b *Block) Nonce(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	nonce := block.Header().Nonce
+	return hexutil.Bytes(nonce[:]), nil
+}
+
+func (b *Block) TransactionsRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.TxHash(), nil
+}
+
+func (b *Block) TransactionCount(ctx context.Context) (*int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	count := int32(len(block.Transactions()))
+	return &count, nil
+}
+
+func (b *Block) StateRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.Root(), nil
+}
+
+func (b *Block) ReceiptsRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.ReceiptHash(), nil
+}
+
+func (b *Block) Miner(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     b.r.backend,
+		address:     block.Coinbase(),
+		blockNumber: args.number(),
+	}, nil
+}
+
+func (b *Block) ExtraData(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(block.Extra()), nil
+}
+
+func (b *Block) GasLimit(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.GasLimit()), nil
+}
+
+func (b *Block) GasUsed(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.GasUsed()), nil
+}
+
+func (b *Block) Timestamp(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.Time()), nil
+}
+
+func (b *Block) LogsBloom(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(block.Bloom().Bytes()), nil
+}
+
+func (b *Block) Difficulty(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.Difficulty()), nil
+}
+
+func (b *Block) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
+	td := b.r.backend.GetTd(b.hash)
+	if td == nil {
+		return hexutil.Big{}, fmt.Errorf("total difficulty not found %x", b.hash)
+	}
+	return hexutil.Big(*td), nil
+}
+
+// isPos reports whether the block was sealed by the PoS engine, whose block
+// difficulty carries the epoch and the slot of the block.
+func (b *Block) isPos(block *types.Block) bool {
+	config := b.r.backend.ChainConfig()
+	return config.PosFirstBlock != nil && config.IsPosBlockNumber(block.Number())
+}
+
+func (b *Block) Epoch(ctx context.Context) (*Epoch, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || !b.isPos(block) {
+		return nil, err
+	}
+	epochID, _ := posutil.GetEpochSlotIDFromDifficulty(block.Difficulty())
+	return &Epoch{r: b.r, id: epochID}, nil
+}
+
+func (b *Block) SlotId(ctx context.Context) (*Long, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || !b.isPos(block) {
+		return nil, err
+	}
+	_, slotID := posutil.GetEpochSlotIDFromDifficulty(block.Difficulty())
+	slot := Long(slotID)
+	return &slot, nil
+}
+
+func (b *Block) Transactions(ctx context.Context) (*[]*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		ret = append(ret, &Transaction{
+			r:     b.r,
+			tx:    tx,
+			block: b,
+			index: uint64(i),
+		})
+	}
+	return &ret, nil
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) (*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if args.Index < 0 || int(args.Index) >= len(txs) {
+		return nil, nil
+	}
+	return &Transaction{
+		r:     b.r,
+		tx:    txs[args.Index],
+		block: b,
+		index: uint64(args.Index),
+	}, nil
+}
+
+func (b *Block) Account(ctx context.Context, args struct{ Address common.Address }) (*Account, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     b.r.backend,
+		address:     args.Address,
+		blockNumber: rpc.BlockNumber(block.NumberU64()),
+	}, nil
+}
+
+// SlotLeader represents the epoch leader elected to seal the block of a slot.
+type SlotLeader struct {
+	pub []byte
+}
+
+func (s *SlotLeader) PublicKey(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(s.pub)
+}
+
+func (s *SlotLeader) Address(ctx context.Context) common.Address {
+	return crypto.PubkeyToAddress(*crypto.ToECDSAPub(s.pub))
+}
+
+// DelegatorIncentive represents the incentive paid to a delegator.
+type DelegatorIncentive struct {
+	info posapi.DelegatorInfo
+}
+
+func (d *DelegatorIncentive) Address(ctx context.Context) common.Address {
+	return d.info.Address
+}
+
+func (d *DelegatorIncentive) Incentive(ctx context.Context) hexutil.Big {
+	return toBig(d.info.Incentive)
+}
+
+// ValidatorIncentive represents the incentive paid to a validator.
+type ValidatorIncentive struct {
+	info posapi.ValidatorInfo
+}
+
+func (v *ValidatorIncentive) Address(ctx context.Context) common.Address {
+	return v.info.Address
+}
+
+func (v *ValidatorIncentive) WalletAddress(ctx context.Context) common.Address {
+	return v.info.WalletAddress
+}
+
+func (v *ValidatorIncentive) Incentive(ctx context.Context) hexutil.Big {
+	return toBig(v.info.Incentive)
+}
+
+func (v *ValidatorIncentive) Delegators(ctx context.Context) []*DelegatorIncentive {
+	ret := make([]*DelegatorIncentive, 0, len(v.info.Delegators))
+	for _, info := range v.info.Delegators {
+		ret = append(ret, &DelegatorIncentive{info})
+	}
+	return ret
+}
+
+// StakerProbability represents the selection probability of a staker or of
+// one of its delegators.
+type StakerProbability struct {
+	info posapi.ApiClientProbability
+}
+
+func (s *StakerProbability) Address(ctx context.Context) common.Address {
+	return s.info.Addr
+}
+
+func (s *StakerProbability) Probability(ctx context.Context) hexutil.Big {
+	return toBig(s.info.Probability)
+}
+
+// EpochStaker represents the selection probability of a staker for an epoch.
+type EpochStaker struct {
+	info posapi.ApiStakerInfo
+}
+
+func (s *EpochStaker) Address(ctx context.Context) common.Address {
+	return s.info.Addr
+}
+
+func (s *EpochStaker) FeeRate(ctx context.Context) Long {
+	return Long(s.info.FeeRate)
+}
+
+func (s *EpochStaker) TotalProbability(ctx context.Context) hexutil.Big {
+	return toBig(s.info.TotalProbability)
+}
+
+func (s *EpochStaker) Probabilities(ctx context.Context) []*StakerProbability {
+	ret := make([]*StakerProbability, 0, len(s.info.Infors))
+	for _, info := range s.info.Infors {
+		ret = append(ret, &StakerProbability{info})
+	}
+	return ret
+}
+
+// Epoch represents a PoS epoch, resolved against the PoS API.
+type Epoch struct {
+	r  *Resolver
+	id uint64
+}
+
+func (e *Epoch) Id(ctx context.Context) Long {
+	return Long(e.id)
+}
+
+// SlotLeader returns the leader of a slot. The PoS API reports its failures
+// in place of the public key, which are surfaced as errors.
+func (e *Epoch) SlotLeader(ctx context.Context, args struct{ Slot Long }) (*SlotLeader, error) {
+	leader := e.r.pos.GetSlotLeaderByEpochIDAndSlotID(e.id, uint64(args.Slot))
+	pub, err := hex.DecodeString(leader)
+	if err != nil || crypto.ToECDSAPub(pub) == nil {
+		return nil, errors.New(leader)
+	}
+	return &SlotLeader{pub: pub}, nil
+}
+
+func (e *Epoch) EpochLeaders(ctx context.Context) ([]common.Address, error) {
+	return e.r.pos.GetEpochLeadersAddrByEpochID(e.id)
+}
+
+func (e *Epoch) RandomProposers(ctx context.Context) ([]common.Address, error) {
+	return e.r.pos.GetRandomProposersAddrByEpochID(e.id)
+}
+
+func (e *Epoch) Random(ctx context.Context) (*hexutil.Big, error) {
+	random, err := e.r.pos.GetRandom(e.id, -1)
+	if err != nil || random == nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(random), nil
+}
+
+func (e *Epoch) BlockCount(ctx context.Context) (Long, error) {
+	count, err := e.r.pos.GetEpochBlkCnt(e.id)
+	return Long(count), err
+}
+
+func (e *Epoch) IncentivePayDetail(ctx context.Context) ([]*ValidatorIncentive, error) {
+	infos, err := e.r.pos.GetEpochIncentivePayDetail(e.id)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*ValidatorIncentive, 0, len(infos))
+	for _, info := range infos {
+		ret = append(ret, &ValidatorIncentive{info})
+	}
+	return ret, nil
+}
+
+func (e *Epoch) StakerInfo(ctx context.Context, args struct{ Address common.Address }) (*EpochStaker, error) {
+	info, err := e.r.pos.GetEpochStakerInfo(e.id, args.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &EpochStaker{info}, nil
+}
+
+// StakerClient represents a delegator of a staker.
+type StakerClient struct {
+	info posapi.ClientInfo
+}
+
+func (c *StakerClient) Address(ctx context.Context) common.Address {
+	return c.info.Address
+}
+
+func (c *StakerClient) Amount(ctx context.Context) hexutil.Big {
+	return toBig(c.info.Amount)
+}
+
+func (c *StakerClient) VotingPower(ctx context.Context) hexutil.Big {
+	return toBig(c.info.StakeAmount)
+}
+
+func (c *StakerClient) QuitEpoch(ctx context.Context) Long {
+	return Long(c.info.QuitEpoch)
+}
+
+// StakerPartner represents a partner of a staker.
+type StakerPartner struct {
+	info posapi.PartnerInfo
+}
+
+func (p *StakerPartner) Address(ctx context.Context) common.Address {
+	return p.info.Address
+}
+
+func (p *StakerPartner) Amount(ctx context.Context) hexutil.Big {
+	return toBig(p.info.Amount)
+}
+
+func (p *StakerPartner) VotingPower(ctx context.Context) hexutil.Big {
+	return toBig(p.info.StakeAmount)
+}
+
+func (p *StakerPartner) Renewal(ctx context.Context) bool {
+	return p.info.Renewal
+}
+
+func (p *StakerPartner) LockEpochs(ctx context.Context) Long {
+	return Long(p.info.LockEpochs)
+}
+
+func (p *StakerPartner) StakingEpoch(ctx context.Context) Long {
+	return Long(p.info.StakingEpoch)
+}
+
+// Staker represents a registered validator.
+type Staker struct {
+	info *posapi.StakerJson
+}
+
+func (s *Staker) Address(ctx context.Context) common.Address {
+	return s.info.Address
+}
+
+func (s *Staker) From(ctx context.Context) common.Address {
+	return s.info.From
+}
+
+func (s *Staker) PubSec256(ctx context.Context) (hexutil.Bytes, error) {
+	return hexutil.Decode(s.info.PubSec256)
+}
+
+func (s *Staker) PubBn256(ctx context.Context) (hexutil.Bytes, error) {
+	return hexutil.Decode(s.info.PubBn256)
+}
+
+func (s *Staker) Amount(ctx context.Context) hexutil.Big {
+	return toBig(s.info.Amount)
+}
+
+func (s *Staker) VotingPower(ctx context.Context) hexutil.Big {
+	return toBig(s.info.StakeAmount)
+}
+
+func (s *Staker) LockEpochs(ctx context.Context) Long {
+	return Long(s.info.LockEpochs)
+}
+
+func (s *Staker) NextLockEpochs(ctx context.Context) Long {
+	return Long(s.info.NextLockEpochs)
+}
+
+func (s *Staker) StakingEpoch(ctx context.Context) Long {
+	return Long(s.info.StakingEpoch)
+}
+
+func (s *Staker) FeeRate(ctx context.Context) Long {
+	return Long(s.info.FeeRate)
+}
+
+func (s *Staker) MaxFeeRate(ctx context.Context) Long {
+	return Long(s.info.MaxFeeRate)
+}
+
+func (s *Staker) FeeRateChangedEpoch(ctx context.Context) Long {
+	return Long(s.info.FeeRateChangedEpoch)
+}
+
+func (s *Staker) Clients(ctx context.Context) []*StakerClient {
+	ret := make([]*StakerClient, 0, len(s.info.Clients))
+	for _, info := range s.info.Clients {
+		ret = append(ret, &StakerClient{info})
+	}
+	return ret
+}
+
+func (s *Staker) Partners(ctx context.Context) []*StakerPartner {
+	ret := make([]*StakerPartner, 0, len(s.info.Partners))
+	for _, info := range s.info.Partners {
+		ret = append(ret, &StakerPartner{info})
+	}
+	return ret
+}
+
+// Resolver is the top level object in the GraphQL hierarchy.
+type Resolver struct {
+	backend ethapi.Backend
+	pos     *posapi.PosApi
+}
+
+// newResolver creates the query resolver, reaching the PoS data through the
+// same PosApi service the pos namespace of the RPC API is served by.
+func newResolver(backend ethapi.Backend, chain posapi.PosChainReader) (*Resolver, error) {
+	apis := posapi.APIs(chain, backend)
+	pos, ok := apis[0].Service.(*posapi.PosApi)
+	if !ok {
+		return nil, errors.New("pos api service not found")
+	}
+	return &Resolver{backend: backend, pos: pos}, nil
+}
+
+// newBlock wraps a loaded block, nil if the block is missing.
+func (r *Resolver) newBlock(block *types.Block) *Block {
+	if block == nil {
+		return nil
+	}
+	return &Block{r: r, hash: block.Hash(), block: block}
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Number *Long
+	Hash   *common.Hash
+}) (*Block, error) {
+	if args.Number != nil && args.Hash != nil {
+		return nil, errors.New("only one of number or hash may be given")
+	}
+	if args.Hash != nil {
+		block, err := r.backend.GetBlock(ctx, *args.Hash)
+		return r.newBlock(block), err
+	}
+	number := rpc.LatestBlockNumber
+	if args.Number != nil {
+		number = rpc.BlockNumber(*args.Number)
+	}
+	block, err := r.backend.BlockByNumber(ctx, number)
+	return r.newBlock(block), err
+}
+
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	From Long
+	To   *Long
+}) ([]*Block, error) {
+	to := Long(r.backend.CurrentBlock().NumberU64())
+	if args.To != nil && *args.To < to {
+		to = *args.To
+	}
+	if to >= args.From && to-args.From >= maxBlocksRange {
+		return nil, errBlocksRange
+	}
+	ret := make([]*Block, 0)
+	for number := args.From; number <= to; number++ {
+		block, err := r.backend.BlockByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			break
+		}
+		ret = append(ret, r.newBlock(block))
+	}
+	return ret, nil
+}
+
+// Transaction looks a transaction up in the chain first, then in the
+// transaction pool.
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	if tx, blockHash, _, index := core.GetTransaction(r.backend.ChainDb(), args.Hash); tx != nil {
+		return &Transaction{
+			r:     r,
+			tx:    tx,
+			block: &Block{r: r, hash: blockHash},
+			index: index,
+		}, nil
+	}
+	if tx := r.backend.GetPoolTransaction(args.Hash); tx != nil {
+		return &Transaction{r: r, tx: tx}, nil
+	}
+	return nil, nil
+}
+
+func (r *Resolver) Account(ctx context.Context, args struct {
+	Address common.Address
+	Block   *Long
+}) *Account {
+	return &Account{
+		backend:     r.backend,
+		address:     args.Address,
+		blockNumber: BlockNumberArgs{Block: args.Block}.number(),
+	}
+}
+
+func (r *Resolver) Epoch(ctx context.Context, args struct{ Id *Long }) *Epoch {
+	if args.Id != nil {
+		return &Epoch{r: r, id: uint64(*args.Id)}
+	}
+	return &Epoch{r: r, id: r.pos.GetEpochID()}
+}
+
+func (r *Resolver) Stakers(ctx context.Context, args BlockNumberArgs) ([]*Staker, error) {
+	number := r.backend.CurrentBlock().NumberU64()
+	if args.Block != nil {
+		number = uint64(*args.Block)
+	}
+	infos, err := r.pos.GetStakerInfo(number)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Staker, 0, len(infos))
+	for _, info := range infos {
+		ret = append(ret, &Staker{info})
+	}
+	return ret, nil
+}
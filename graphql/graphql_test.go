@@ -0,0 +1,171 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/internal/ethapi"
+	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+// testBackend serves a single block holding one transaction and its receipt.
+type testBackend struct {
+	ethapi.Backend
+	block    *types.Block
+	receipts types.Receipts
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.LatestBlockNumber || uint64(number) == b.block.NumberU64() {
+		return b.block, nil
+	}
+	return nil, nil
+}
+
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	if hash == b.block.Hash() {
+		return b.block, nil
+	}
+	return nil, nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.receipts, nil
+}
+
+func (b *testBackend) GetTd(hash common.Hash) *big.Int { return big.NewInt(131072) }
+
+func (b *testBackend) ChainConfig() *params.ChainConfig { return params.TestChainConfig }
+
+func (b *testBackend) CurrentBlock() *types.Block { return b.block }
+
+func newTestBackend(t *testing.T) *testBackend {
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(params.TestChainConfig.ChainId)
+	tx, err := types.SignTx(types.NewTransaction(0, common.HexToAddress("0x1000"), big.NewInt(10), big.NewInt(21000), big.NewInt(1), nil), signer, key)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	receipts := types.Receipts{{
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: big.NewInt(21000),
+		GasUsed:           big.NewInt(21000),
+		TxHash:            tx.Hash(),
+	}}
+	header := &types.Header{
+		Number:     big.NewInt(0),
+		Difficulty: big.NewInt(131072),
+		GasLimit:   big.NewInt(4712388),
+		GasUsed:    big.NewInt(21000),
+		Time:       big.NewInt(1),
+	}
+	return &testBackend{
+		block:    types.NewBlock(header, []*types.Transaction{tx}, nil, receipts),
+		receipts: receipts,
+	}
+}
+
+func TestBuildSchema(t *testing.T) {
+	resolver, err := newResolver(newTestBackend(t), nil)
+	if err != nil {
+		t.Fatalf("failed to create resolver: %v", err)
+	}
+	if _, err := graphqlgo.ParseSchema(schema, resolver); err != nil {
+		t.Fatalf("schema does not match the resolvers: %v", err)
+	}
+}
+
+func TestBlockQuery(t *testing.T) {
+	backend := newTestBackend(t)
+	resolver, _ := newResolver(backend, nil)
+	handler := &relay.Handler{Schema: graphqlgo.MustParseSchema(schema, resolver)}
+
+	query := `{"query": "{ block(number: 0) { number hash gasUsed totalDifficulty epoch { id } transactionCount transactions { index value txType status gasUsed } } }"}`
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(query)))
+
+	var result struct {
+		Errors []interface{}
+		Data   struct {
+			Block struct {
+				Number           int64
+				Hash             common.Hash
+				GasUsed          string
+				TotalDifficulty  string
+				Epoch            *struct{ Id int64 }
+				TransactionCount int
+				Transactions     []struct {
+					Index   int
+					Value   string
+					TxType  string
+					Status  int64
+					GasUsed string
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to decode response %s: %v", w.Body.String(), err)
+	}
+	if len(result.Errors) != 0 {
+		t.Fatalf("query failed: %v", result.Errors)
+	}
+	block := result.Data.Block
+	if block.Hash != backend.block.Hash() || block.GasUsed != "0x5208" || block.TotalDifficulty != "0x20000" {
+		t.Errorf("block mismatch: %+v", block)
+	}
+	if block.Epoch != nil {
+		t.Errorf("epoch of a pre-PoS block: %+v", block.Epoch)
+	}
+	if block.TransactionCount != 1 || len(block.Transactions) != 1 {
+		t.Fatalf("transaction count mismatch: have %d/%d, want 1", block.TransactionCount, len(block.Transactions))
+	}
+	tx := block.Transactions[0]
+	if tx.Value != "0xa" || tx.TxType != "NORMAL" || tx.Status != 1 || tx.GasUsed != "0x5208" {
+		t.Errorf("transaction mismatch: %+v", tx)
+	}
+}
+
+func TestBlocksRange(t *testing.T) {
+	backend := newTestBackend(t)
+	header := backend.block.Header()
+	header.Number = big.NewInt(5000)
+	backend.block = types.NewBlockWithHeader(header)
+	resolver, _ := newResolver(backend, nil)
+	handler := &relay.Handler{Schema: graphqlgo.MustParseSchema(schema, resolver)}
+
+	for query, fail := range map[string]bool{
+		`{"query": "{ blocks(from: 0) { number } }"}`:              true,
+		`{"query": "{ blocks(from: 0, to: 1024) { number } }"}`:    true,
+		`{"query": "{ blocks(from: 3977) { number } }"}`:           false,
+		`{"query": "{ blocks(from: 4000, to: 5000) { number } }"}`: false,
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(query)))
+
+		var result struct {
+			Errors []interface{}
+			Data   struct {
+				Blocks []struct{ Number int64 }
+			}
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("failed to decode response %s: %v", w.Body.String(), err)
+		}
+		if failed := len(result.Errors) != 0; failed != fail {
+			t.Errorf("query %s error mismatch: have %v, want failure %v", query, result.Errors, fail)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package graphql
+
+const schema string = `
+    # Bytes32 is a 32 byte binary string, represented as 0x-prefixed hexadecimal.
+    scalar Bytes32
+    # Address is a 20 byte Wanchain address, represented as 0x-prefixed hexadecimal.
+    scalar Address
+    # Bytes is an arbitrary length binary string, represented as 0x-prefixed hexadecimal.
+    # An empty byte string is represented as '0x'.
+    scalar Bytes
+    # BigInt is a large integer. Input is accepted as either a JSON number or as a string.
+    # Strings may be either decimal or 0x-prefixed hexadecimal. Output values are all
+    # 0x-prefixed hexadecimal.
+    scalar BigInt
+    # Long is a 64 bit unsigned integer.
+    scalar Long
+
+    schema {
+        query: Query
+    }
+
+    # Account is a Wanchain account at a particular block.
+    type Account {
+        # Address is the address owning the account.
+        address: Address!
+        # Balance is the balance of the account, in wei.
+        balance: BigInt!
+        # TransactionCount is the number of transactions sent from this account,
+        # or in the case of a contract, the number of contracts created. Otherwise
+        # known as the nonce.
+        transactionCount: Long!
+        # Code contains the smart contract code for this account, if the account
+        # is a (non-self-destructed) contract.
+        code: Bytes!
+        # Storage provides access to the storage of a contract account, indexed
+        # by its 32 byte slot identifier.
+        storage(slot: Bytes32!): Bytes32!
+    }
+
+    # Log is a Wanchain event log.
+    type Log {
+        # Index is the index of this log in the block.
+        index: Int!
+        # Account is the account which generated this log - this will always
+        # be a contract account.
+        account(block: Long): Account!
+        # Topics is a list of 0-4 indexed topics for the log.
+        topics: [Bytes32!]!
+        # Data is unindexed data for this log.
+        data: Bytes!
+        # Transaction is the transaction that generated this log entry.
+        transaction: Transaction!
+    }
+
+    # TxType is the kind of a transaction.
+    enum TxType {
+        # NORMAL is a plain value transfer or contract call.
+        NORMAL
+        # PRIVACY is a transfer to or from a one time address.
+        PRIVACY
+        # POS is a protocol transaction of the random beacon or the slot leader
+        # selection, sent by the epoch leaders and random proposers.
+        POS
+    }
+
+    # Transaction is a Wanchain transaction.
+    type Transaction {
+        # Hash is the hash of this transaction.
+        hash: Bytes32!
+        # Nonce is the nonce of the account this transaction was generated with.
+        nonce: Long!
+        # Index is the index of this transaction in the parent block. This will
+        # be null if the transaction has not yet been mined.
+        index: Int
+        # From is the account that sent this transaction - this will always be
+        # an externally owned account.
+        from(block: Long): Account!
+        # To is the account the transaction was sent to. This is null for
+        # contract-creating transactions.
+        to(block: Long): Account
+        # Value is the value, in wei, sent along with this transaction.
+        value: BigInt!
+        # GasPrice is the price offered to miners for gas, in wei per unit.
+        gasPrice: BigInt!
+        # Gas is the maximum amount of gas this transaction can consume.
+        gas: BigInt!
+        # InputData is the data supplied to the target of the transaction.
+        inputData: Bytes!
+        # TxType is the kind of the transaction.
+        txType: TxType!
+        # Block is the block this transaction was mined in. This will be null if
+        # the transaction has not yet been mined.
+        block: Block
+
+        # Status is the return status of the transaction. This will be 1 if the
+        # transaction succeeded, or 0 if it failed (due to a revert, or due to
+        # running out of gas). If the transaction has not yet been mined, this
+        # field will be null.
+        status: Long
+        # GasUsed is the amount of gas that was used processing this transaction.
+        # If the transaction has not yet been mined, this field will be null.
+        gasUsed: BigInt
+        # CumulativeGasUsed is the total gas used in the block up to and including
+        # this transaction. If the transaction has not yet been mined, this field
+        # will be null.
+        cumulativeGasUsed: BigInt
+        # CreatedContract is the account that was created by a contract creation
+        # transaction. If the transaction was not a contract creation transaction,
+        # or it has not yet been mined, this field will be null.
+        createdContract(block: Long): Account
+        # Logs is a list of log entries emitted by this transaction. If the
+        # transaction has not yet been mined, this field will be null.
+        logs: [Log!]
+    }
+
+    # Block is a Wanchain block.
+    type Block {
+        # Number is the number of this block, starting at 0 for the genesis block.
+        number: Long!
+        # Hash is the block hash of this block.
+        hash: Bytes32!
+        # Parent is the parent block of this block.
+        parent: Block
+        # Nonce is the block nonce, an 8 byte sequence determined by the miner.
+        nonce: Bytes!
+        # TransactionsRoot is the keccak256 hash of the root of the trie of
+        # transactions in this block.
+        transactionsRoot: Bytes32!
+        # TransactionCount is the number of transactions in this block.
+        transactionCount: Int
+        # StateRoot is the keccak256 hash of the state trie after this block was
+        # processed.
+        stateRoot: Bytes32!
+        # ReceiptsRoot is the keccak256 hash of the trie of transaction receipts
+        # in this block.
+        receiptsRoot: Bytes32!
+        # Miner is the account that mined this block.
+        miner(block: Long): Account!
+        # ExtraData is an arbitrary data field supplied by the miner, holding
+        # the slot leader proof and seal of a PoS block.
+        extraData: Bytes!
+        # GasLimit is the maximum amount of gas that was available to
+        # transactions in this block.
+        gasLimit: BigInt!
+        # GasUsed is the amount of gas that was used executing transactions in
+        # this block.
+        gasUsed: BigInt!
+        # Timestamp is the unix timestamp at which this block was mined.
+        timestamp: BigInt!
+        # LogsBloom is a bloom filter that can be used to check if a block may
+        # contain log entries matching a filter.
+        logsBloom: Bytes!
+        # Difficulty is a measure of the difficulty of mining this block, which
+        # encodes the epoch and the slot of a PoS block.
+        difficulty: BigInt!
+        # TotalDifficulty is the sum of all difficulty values up to and including
+        # this block.
+        totalDifficulty: BigInt!
+        # Epoch is the PoS epoch this block was sealed in. This will be null for
+        # the blocks before the PoS upgrade.
+        epoch: Epoch
+        # SlotId is the slot of the epoch this block was sealed in. This will be
+        # null for the blocks before the PoS upgrade.
+        slotId: Long
+        # Transactions is a list of transactions associated with this block.
+        transactions: [Transaction!]
+        # TransactionAt returns the transaction at the specified index.
+        transactionAt(index: Int!): Transaction
+        # Account fetches a Wanchain account at the current block's state.
+        account(address: Address!): Account!
+    }
+
+    # SlotLeader is the epoch leader elected to seal the block of a slot.
+    type SlotLeader {
+        # PublicKey is the secp256k1 public key the leader seals with.
+        publicKey: Bytes!
+        # Address is the address of the public key.
+        address: Address!
+    }
+
+    # DelegatorIncentive is the incentive paid to a delegator of a validator.
+    type DelegatorIncentive {
+        address: Address!
+        incentive: BigInt!
+    }
+
+    # ValidatorIncentive is the incentive paid to a validator for an epoch.
+    type ValidatorIncentive {
+        # Address is the address the validator works with.
+        address: Address!
+        # WalletAddress is the address the validator staked from.
+        walletAddress: Address!
+        incentive: BigInt!
+        delegators: [DelegatorIncentive!]!
+    }
+
+    # StakerProbability is the share of a staker, or of one of its delegators,
+    # in the epoch leader selection.
+    type StakerProbability {
+        address: Address!
+        probability: BigInt!
+    }
+
+    # EpochStaker is the selection probability of a staker for an epoch.
+    type EpochStaker {
+        address: Address!
+        feeRate: Long!
+        totalProbability: BigInt!
+        # Probabilities lists the staker itself first, then its delegators.
+        probabilities: [StakerProbability!]!
+    }
+
+    # Epoch is a PoS epoch.
+    type Epoch {
+        # Id is the number of the epoch.
+        id: Long!
+        # SlotLeader is the leader elected to seal the block of a slot of the epoch.
+        slotLeader(slot: Long!): SlotLeader!
+        # EpochLeaders are the addresses of the leaders the slot leaders of the
+        # epoch are elected from.
+        epochLeaders: [Address!]!
+        # RandomProposers are the addresses of the proposers of the random
+        # beacon of the epoch.
+        randomProposers: [Address!]!
+        # Random is the random beacon output of the epoch.
+        random: BigInt
+        # BlockCount is the number of blocks sealed in the epoch.
+        blockCount: Long!
+        # IncentivePayDetail lists the incentives paid for the epoch.
+        incentivePayDetail: [ValidatorIncentive!]!
+        # StakerInfo is the selection probability of a staker for the epoch.
+        stakerInfo(address: Address!): EpochStaker!
+    }
+
+    # StakerClient is a delegator of a staker.
+    type StakerClient {
+        address: Address!
+        amount: BigInt!
+        votingPower: BigInt!
+        quitEpoch: Long!
+    }
+
+    # StakerPartner is a partner of a staker.
+    type StakerPartner {
+        address: Address!
+        amount: BigInt!
+        votingPower: BigInt!
+        renewal: Boolean!
+        lockEpochs: Long!
+        stakingEpoch: Long!
+    }
+
+    # Staker is a registered validator.
+    type Staker {
+        address: Address!
+        # From is the address the stake was sent from.
+        from: Address!
+        pubSec256: Bytes!
+        pubBn256: Bytes!
+        amount: BigInt!
+        votingPower: BigInt!
+        lockEpochs: Long!
+        nextLockEpochs: Long!
+        stakingEpoch: Long!
+        feeRate: Long!
+        maxFeeRate: Long!
+        feeRateChangedEpoch: Long!
+        clients: [StakerClient!]!
+        partners: [StakerPartner!]!
+    }
+
+    type Query {
+        # Block fetches a Wanchain block by number or by hash. If neither is
+        # supplied, the most recent known block is returned.
+        block(number: Long, hash: Bytes32): Block
+        # Blocks returns all the blocks between two numbers, inclusive. If
+        # to is not supplied, it defaults to the most recent known block.
+        # At most 1024 blocks may be requested at once.
+        blocks(from: Long!, to: Long): [Block!]!
+        # Transaction returns a transaction specified by its hash.
+        transaction(hash: Bytes32!): Transaction
+        # Account fetches a Wanchain account at the given block, or at the most
+        # recent known block if it is not supplied.
+        account(address: Address!, block: Long): Account!
+        # Epoch fetches a PoS epoch by id, or the current epoch if it is not supplied.
+        epoch(id: Long): Epoch!
+        # Stakers returns the validators registered at the given block, or at
+        # the most recent known block if it is not supplied.
+        stakers(block: Long): [Staker!]!
+    }
+`
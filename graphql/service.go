@@ -0,0 +1,91 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package graphql
+
+import (
+	"net"
+	"net/http"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/rs/cors"
+	"github.com/wanchain/go-wanchain/internal/ethapi"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/p2p"
+	"github.com/wanchain/go-wanchain/pos/posapi"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+// Service encapsulates a GraphQL service.
+type Service struct {
+	endpoint string       // The host:port endpoint for this service.
+	cors     []string     // Allowed CORS domains
+	resolver *Resolver    // The resolver the schema is served by
+	listener net.Listener // The listening socket
+	handler  http.Handler // The GraphQL request handler
+	server   *http.Server // The server serving the handler
+}
+
+// New constructs a new GraphQL service instance serving the chain data of the
+// backend and the PoS data of the chain.
+func New(backend ethapi.Backend, chain posapi.PosChainReader, endpoint string, cors []string) (*Service, error) {
+	resolver, err := newResolver(backend, chain)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		endpoint: endpoint,
+		cors:     cors,
+		resolver: resolver,
+	}, nil
+}
+
+// Protocols implements node.Service, returning the P2P network protocols used
+// by the GraphQL service (nil as it doesn't use the devp2p overlay network).
+func (s *Service) Protocols() []p2p.Protocol { return nil }
+
+// APIs implements node.Service, returning the RPC API endpoints provided by
+// the GraphQL service (nil as it doesn't provide any user callable APIs).
+func (s *Service) APIs() []rpc.API { return nil }
+
+// Start implements node.Service, starting up the GraphQL endpoint.
+func (s *Service) Start(server *p2p.Server) error {
+	schema, err := graphqlgo.ParseSchema(schema, s.resolver)
+	if err != nil {
+		return err
+	}
+	s.handler = newCorsHandler(&relay.Handler{Schema: schema}, s.cors)
+
+	if s.listener, err = net.Listen("tcp", s.endpoint); err != nil {
+		return err
+	}
+	s.server = &http.Server{Handler: s.handler}
+	go s.server.Serve(s.listener)
+	log.Info("GraphQL endpoint opened", "url", "http://"+s.endpoint)
+	return nil
+}
+
+// Stop implements node.Service, terminating the GraphQL endpoint.
+func (s *Service) Stop() error {
+	if s.listener != nil {
+		s.listener.Close()
+		s.listener = nil
+		log.Info("GraphQL endpoint closed", "url", "http://"+s.endpoint)
+	}
+	return nil
+}
+
+// newCorsHandler wraps the handler with the same CORS policy the HTTP RPC
+// endpoint applies, leaving it unwrapped if no origins are allowed.
+func newCorsHandler(handler http.Handler, allowedOrigins []string) http.Handler {
+	if len(allowedOrigins) == 0 {
+		return handler
+	}
+	c := cors.New(cors.Options{
+		AllowedOrigins: allowedOrigins,
+		AllowedMethods: []string{"POST", "GET"},
+		MaxAge:         600,
+		AllowedHeaders: []string{"*"},
+	})
+	return c.Handler(handler)
+}
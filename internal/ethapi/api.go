@@ -98,6 +98,37 @@ func (s *PublicEthereumAPI) GasPrice(ctx context.Context) (*big.Int, error) {
 	return s.b.SuggestPrice(ctx)
 }
 
+// feeHistoryResult is the fee history of a range of blocks.
+type feeHistoryResult struct {
+	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
+	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
+	GasUsedRatio []float64        `json:"gasUsedRatio"`
+}
+
+// FeeHistory returns the gas used ratios of the blockCount blocks up to
+// newestBlock, and the given percentiles of the gas prices paid in them. Pos
+// transactions don't compete for the gas and are left out of the percentiles.
+func (s *PublicEthereumAPI) FeeHistory(ctx context.Context, blockCount hexutil.Uint64, newestBlock rpc.BlockNumber, rewardPercentiles []float64) (*feeHistoryResult, error) {
+	oldest, reward, gasUsedRatio, err := s.b.FeeHistory(ctx, int(blockCount), newestBlock, rewardPercentiles)
+	if err != nil {
+		return nil, err
+	}
+	results := &feeHistoryResult{
+		OldestBlock:  (*hexutil.Big)(oldest),
+		GasUsedRatio: gasUsedRatio,
+	}
+	if reward != nil {
+		results.Reward = make([][]*hexutil.Big, len(reward))
+		for i, w := range reward {
+			results.Reward[i] = make([]*hexutil.Big, len(w))
+			for j, v := range w {
+				results.Reward[i][j] = (*hexutil.Big)(v)
+			}
+		}
+	}
+	return results, nil
+}
+
 // ProtocolVersion returns the current Ethereum protocol version this node supports
 func (s *PublicEthereumAPI) ProtocolVersion() hexutil.Uint {
 	return hexutil.Uint(s.b.ProtocolVersion())
@@ -488,6 +519,16 @@ func (s *PrivateAccountAPI) SendPrivacyCxtTransaction(ctx context.Context, args
 
 // GenRingSignData generate ring sign data
 func (s *PrivateAccountAPI) GenRingSignData(ctx context.Context, hashMsg string, privateKey string, mixWanAdresses string) (string, error) {
+	return genRingSignDataWith(crypto.DefaultRingSigner, hashMsg, privateKey, mixWanAdresses)
+}
+
+// GenCompactRingSignData is GenRingSignData with the compact ring signature scheme, which
+// the wanCoin precompile accepts for refundCoin once the compact ring signature fork is active.
+func (s *PrivateAccountAPI) GenCompactRingSignData(ctx context.Context, hashMsg string, privateKey string, mixWanAdresses string) (string, error) {
+	return genRingSignDataWith(crypto.CompactRingSigner, hashMsg, privateKey, mixWanAdresses)
+}
+
+func genRingSignDataWith(signer crypto.RingSigner, hashMsg string, privateKey string, mixWanAdresses string) (string, error) {
 	if !hexutil.Has0xPrefix(privateKey) {
 		return "", ErrInvalidPrivateKey
 	}
@@ -516,10 +557,10 @@ func (s *PrivateAccountAPI) GenRingSignData(ctx context.Context, hashMsg string,
 		return "", ErrInvalidOTAMixSet
 	}
 
-	return genRingSignData(hmsg, privKey, &ecdsaPrivateKey.PublicKey, wanAddresses)
+	return genRingSignData(signer, hmsg, privKey, &ecdsaPrivateKey.PublicKey, wanAddresses)
 }
 
-func genRingSignData(hashMsg []byte, privateKey []byte, actualPub *ecdsa.PublicKey, mixWanAdress []string) (string, error) {
+func genRingSignData(signer crypto.RingSigner, hashMsg []byte, privateKey []byte, actualPub *ecdsa.PublicKey, mixWanAdress []string) (string, error) {
 	otaPrivD := new(big.Int).SetBytes(privateKey)
 
 	publicKeys := make([]*ecdsa.PublicKey, 0)
@@ -545,12 +586,20 @@ func genRingSignData(hashMsg []byte, privateKey []byte, actualPub *ecdsa.PublicK
 		publicKeys = append(publicKeys, publicKeyA)
 	}
 
-	retPublicKeys, keyImage, w_random, q_random, err := crypto.RingSign(hashMsg, otaPrivD, publicKeys)
+	sig, err := signer.Sign(hashMsg, otaPrivD, publicKeys)
+	if err != nil {
+		return "", err
+	}
+
+	outs, err := encodeRingSignOut(sig.PublicKeys, sig.KeyImage, sig.C, sig.R)
 	if err != nil {
 		return "", err
 	}
 
-	return encodeRingSignOut(retPublicKeys, keyImage, w_random, q_random)
+	if signer == crypto.CompactRingSigner {
+		outs = strings.Join([]string{outs, vm.CompactRingSignTag}, "+")
+	}
+	return outs, nil
 }
 
 //  encode all ring sign out data to a string
@@ -820,17 +869,13 @@ type CallArgs struct {
 	Data     hexutil.Bytes   `json:"data"`
 }
 
-func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config) ([]byte, *big.Int, bool, error) {
-	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
-
-	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
-	if state == nil || err != nil {
-		return nil, common.Big0, false, err
-	}
+// ToMessage converts the call arguments to a message, defaulting the sender to
+// the first local account and the gas and gas price if none were set.
+func (args *CallArgs) ToMessage(am *accounts.Manager) types.Message {
 	// Set sender address or use a default if none specified
 	addr := args.From
 	if addr == (common.Address{}) {
-		if wallets := s.b.AccountManager().Wallets(); len(wallets) > 0 {
+		if wallets := am.Wallets(); len(wallets) > 0 {
 			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
 				addr = accounts[0].Address
 			}
@@ -845,9 +890,18 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	if gasPrice.Sign() == 0 {
 		gasPrice = defaultGasPrice
 	}
+	return types.NewMessage(addr, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false)
+}
+
+func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config) ([]byte, *big.Int, bool, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, common.Big0, false, err
+	}
 	// Create new call message
-	msg := types.NewMessage(addr, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false)
+	msg := args.ToMessage(s.b.AccountManager())
 
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
@@ -4,10 +4,13 @@ package ethapi
 
 import (
 	"context"
+	"math/big"
 	"testing"
 	"time"
 
+	"github.com/wanchain/go-wanchain/accounts"
 	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
 )
 
 func TestGenerateOneTimeAddress(t *testing.T) {
@@ -56,3 +59,20 @@ func TestGenerateOneTimeAddress(t *testing.T) {
 		}
 	}
 }
+
+func TestCallArgsToMessage(t *testing.T) {
+	am := accounts.NewManager()
+
+	// Unset gas and gas price are defaulted
+	msg := (&CallArgs{}).ToMessage(am)
+	if msg.Gas().Cmp(big.NewInt(50000000)) != 0 || msg.GasPrice().Cmp(defaultGasPrice) != 0 {
+		t.Errorf("defaults mismatch: have gas %v price %v, want 50000000 and %v", msg.Gas(), msg.GasPrice(), defaultGasPrice)
+	}
+	// Set ones are kept
+	from := common.HexToAddress("0x01")
+	args := &CallArgs{From: from, Gas: hexutil.Big(*big.NewInt(21000)), GasPrice: hexutil.Big(*big.NewInt(7))}
+	msg = args.ToMessage(am)
+	if msg.From() != from || msg.Gas().Int64() != 21000 || msg.GasPrice().Int64() != 7 {
+		t.Errorf("call arguments overwritten: have from %x gas %v price %v", msg.From(), msg.Gas(), msg.GasPrice())
+	}
+}
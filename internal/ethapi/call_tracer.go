@@ -0,0 +1,107 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package ethapi
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/vm"
+)
+
+var errNoCallFrame = errors.New("no call frame traced")
+
+// callFrame is a call frame traced by the callTracer, holding the frames of
+// the calls it made.
+type callFrame struct {
+	Type    string         `json:"type"`
+	From    common.Address `json:"from"`
+	To      common.Address `json:"to"`
+	Value   *hexutil.Big   `json:"value,omitempty"`
+	Gas     hexutil.Uint64 `json:"gas"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+	Input   hexutil.Bytes  `json:"input"`
+	Output  hexutil.Bytes  `json:"output,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Calls   []*callFrame   `json:"calls,omitempty"`
+}
+
+// callTracer traces the nested call frames of a message, including the calls
+// into the precompiled contracts such as the staking, the wancoin and the
+// random beacon ones.
+type callTracer struct {
+	nativeStopper
+	root  *callFrame
+	stack []*callFrame // Frames entered and not yet left
+}
+
+func newCallTracer() *callTracer {
+	return &callTracer{}
+}
+
+// CaptureTxStart implements NativeTracer, the call tracer needs no state.
+func (t *callTracer) CaptureTxStart(statedb *state.StateDB) {}
+
+// CaptureState implements vm.Tracer, the call tracer traces no steps.
+func (t *callTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnter implements vm.Tracer, opening a call frame.
+func (t *callTracer) CaptureEnter(env *vm.EVM, typ vm.OpCode, from, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	frame := &callFrame{
+		Type:  typ.String(),
+		From:  from,
+		To:    to,
+		Gas:   hexutil.Uint64(gas),
+		Input: common.CopyBytes(input),
+	}
+	if value != nil {
+		frame.Value = (*hexutil.Big)(new(big.Int).Set(value))
+	}
+	if len(t.stack) == 0 {
+		t.capture(env)
+		t.root = frame
+	} else {
+		parent := t.stack[len(t.stack)-1]
+		parent.Calls = append(parent.Calls, frame)
+	}
+	t.stack = append(t.stack, frame)
+	return nil
+}
+
+// CaptureExit implements vm.Tracer, closing the innermost open call frame.
+func (t *callTracer) CaptureExit(env *vm.EVM, output []byte, gasUsed uint64, err error) error {
+	if len(t.stack) == 0 {
+		return nil
+	}
+	frame := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+
+	frame.GasUsed = hexutil.Uint64(gasUsed)
+	frame.Output = common.CopyBytes(output)
+	if err != nil {
+		frame.Error = err.Error()
+	}
+	return nil
+}
+
+// CaptureEnd implements vm.Tracer.
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// GetResult returns the outermost call frame.
+func (t *callTracer) GetResult() (interface{}, error) {
+	if err := t.stopped(); err != nil {
+		return nil, err
+	}
+	if t.root == nil {
+		return nil, errNoCallFrame
+	}
+	return t.root, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package ethapi
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/vm"
+)
+
+// fourByteTracer counts the method ids called by a message, keyed by the id
+// and the size of the call data following it, e.g. "0x27dc297e-128".
+type fourByteTracer struct {
+	nativeStopper
+	ids map[string]int
+}
+
+func newFourByteTracer() *fourByteTracer {
+	return &fourByteTracer{ids: make(map[string]int)}
+}
+
+// CaptureTxStart implements NativeTracer, the 4byte tracer needs no state.
+func (t *fourByteTracer) CaptureTxStart(statedb *state.StateDB) {}
+
+// CaptureState implements vm.Tracer, the 4byte tracer traces no steps.
+func (t *fourByteTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnter implements vm.Tracer, counting the method id of a call.
+func (t *fourByteTracer) CaptureEnter(env *vm.EVM, typ vm.OpCode, from, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	t.capture(env)
+	if typ == vm.CREATE || len(input) < 4 {
+		return nil
+	}
+	t.ids[fmt.Sprintf("%s-%d", hexutil.Encode(input[:4]), len(input)-4)]++
+	return nil
+}
+
+// CaptureExit implements vm.Tracer.
+func (t *fourByteTracer) CaptureExit(env *vm.EVM, output []byte, gasUsed uint64, err error) error {
+	return nil
+}
+
+// CaptureEnd implements vm.Tracer.
+func (t *fourByteTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// GetResult returns the counts of the method ids.
+func (t *fourByteTracer) GetResult() (interface{}, error) {
+	if err := t.stopped(); err != nil {
+		return nil, err
+	}
+	return t.ids, nil
+}
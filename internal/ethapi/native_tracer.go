@@ -0,0 +1,78 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package ethapi
+
+import (
+	"sync"
+
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/vm"
+)
+
+// NativeTracer is a Tracer implemented in Go, selected by name in place of
+// the code of a JavascriptTracer.
+type NativeTracer interface {
+	vm.Tracer
+
+	// CaptureTxStart is called with the state the traced message is applied to,
+	// before it is applied.
+	CaptureTxStart(statedb *state.StateDB)
+
+	// GetResult returns the result of the trace, or the error it was stopped with.
+	GetResult() (interface{}, error)
+
+	// Stop aborts the traced execution, failing the trace with the given error.
+	Stop(err error)
+}
+
+// nativeTracers are the constructors of the native tracers by name.
+var nativeTracers = map[string]func() NativeTracer{
+	"callTracer":     func() NativeTracer { return newCallTracer() },
+	"prestateTracer": func() NativeTracer { return newPrestateTracer() },
+	"4byteTracer":    func() NativeTracer { return newFourByteTracer() },
+}
+
+// NewNativeTracer returns a new instance of the native tracer with the given
+// name, or false if there is none.
+func NewNativeTracer(name string) (NativeTracer, bool) {
+	ctor, ok := nativeTracers[name]
+	if !ok {
+		return nil, false
+	}
+	return ctor(), true
+}
+
+// nativeStopper implements Stop for the native tracers by cancelling the EVM
+// they trace.
+type nativeStopper struct {
+	lock sync.Mutex
+	env  *vm.EVM
+	err  error
+}
+
+// capture remembers the EVM to be cancelled when the tracer is stopped.
+func (s *nativeStopper) capture(env *vm.EVM) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.env = env
+}
+
+// stopped returns the error the tracer was stopped with, if any.
+func (s *nativeStopper) stopped() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.err
+}
+
+// Stop cancels the traced EVM, failing the trace with the given error.
+func (s *nativeStopper) Stop(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.err = err
+	if s.env != nil {
+		s.env.Cancel()
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package ethapi
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/params"
+)
+
+var (
+	traceSender   = common.HexToAddress("0x1000")
+	traceContract = common.HexToAddress("0x2000")
+	traceSlot     = common.BigToHash(big.NewInt(1))
+)
+
+// runNativeTrace calls the test contract, which calls the identity precompile
+// and loads a storage slot, with the given native tracer.
+func runNativeTrace(t *testing.T, name string) interface{} {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	statedb.AddBalance(traceSender, big.NewInt(1000))
+	statedb.SetCode(traceContract, []byte{
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 4, byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 4, byte(vm.GAS), byte(vm.CALL), byte(vm.POP),
+		byte(vm.PUSH1), 1, byte(vm.SLOAD), byte(vm.STOP),
+	})
+	statedb.SetState(traceContract, traceSlot, common.HexToHash("0x42"))
+
+	tracer, ok := NewNativeTracer(name)
+	if !ok {
+		t.Fatalf("native tracer %s not found", name)
+	}
+	tracer.CaptureTxStart(statedb)
+
+	ctx := vm.Context{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		BlockNumber: big.NewInt(1),
+	}
+	env := vm.NewEVM(ctx, statedb, params.TestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	if _, _, err := env.Call(vm.AccountRef(traceSender), traceContract, []byte{0xde, 0xad, 0xbe, 0xef, 0x01}, 100000, big.NewInt(10)); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	result, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to get %s result: %v", name, err)
+	}
+	return result
+}
+
+func TestCallTracer(t *testing.T) {
+	root := runNativeTrace(t, "callTracer").(*callFrame)
+	if root.Type != "CALL" || root.From != traceSender || root.To != traceContract {
+		t.Fatalf("root frame mismatch: %+v", root)
+	}
+	if root.Value.ToInt().Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("root value mismatch: have %v, want 10", root.Value)
+	}
+	if len(root.Calls) != 1 {
+		t.Fatalf("call count mismatch: have %d, want 1", len(root.Calls))
+	}
+	if call := root.Calls[0]; call.To != common.BytesToAddress([]byte{4}) || len(call.Input) != 4 || call.Error != "" {
+		t.Errorf("precompile frame mismatch: %+v", call)
+	}
+	if root.GasUsed == 0 || root.GasUsed <= root.Calls[0].GasUsed {
+		t.Errorf("gas used mismatch: root %d, precompile %d", root.GasUsed, root.Calls[0].GasUsed)
+	}
+}
+
+func TestPrestateTracer(t *testing.T) {
+	accounts := runNativeTrace(t, "prestateTracer").(map[common.Address]*prestateAccount)
+	if have := accounts[traceSender].Balance.ToInt(); have.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("sender balance mismatch: have %v, want 1000", have)
+	}
+	contract := accounts[traceContract]
+	if contract == nil || len(contract.Code) == 0 {
+		t.Fatalf("contract account missing its code: %+v", contract)
+	}
+	if have := contract.Storage[traceSlot]; have != common.HexToHash("0x42") {
+		t.Errorf("storage slot mismatch: have %x, want 0x42", have)
+	}
+	if _, ok := accounts[common.BytesToAddress([]byte{4})]; !ok {
+		t.Errorf("precompile account missing")
+	}
+}
+
+func TestFourByteTracer(t *testing.T) {
+	ids := runNativeTrace(t, "4byteTracer").(map[string]int)
+	want := map[string]int{"0xdeadbeef-1": 1, "0x00000000-0": 1}
+	if len(ids) != len(want) {
+		t.Fatalf("id count mismatch: have %v, want %v", ids, want)
+	}
+	for id, count := range want {
+		if ids[id] != count {
+			t.Errorf("id %s count mismatch: have %d, want %d", id, ids[id], count)
+		}
+	}
+}
+
+func TestNativeTracerStop(t *testing.T) {
+	stop := errors.New("stahp")
+	tracer, _ := NewNativeTracer("callTracer")
+	tracer.Stop(stop)
+	if _, err := tracer.GetResult(); err != stop {
+		t.Errorf("stopped tracer error mismatch: have %v, want %v", err, stop)
+	}
+	if _, ok := NewNativeTracer("unknownTracer"); ok {
+		t.Errorf("unknown tracer found")
+	}
+}
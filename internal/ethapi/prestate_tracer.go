@@ -0,0 +1,120 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package ethapi
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/vm"
+)
+
+var errNoPrestate = errors.New("prestate tracer started without a state")
+
+// prestateAccount is an account touched by a traced message, as it was
+// before the message was applied.
+type prestateAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   uint64                      `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// prestateTracer traces the accounts and the storage slots a message touches,
+// returning them as they were before the message was applied.
+type prestateTracer struct {
+	nativeStopper
+	prestate *state.StateDB // Copy of the state before the message
+	accounts map[common.Address]*prestateAccount
+}
+
+func newPrestateTracer() *prestateTracer {
+	return &prestateTracer{accounts: make(map[common.Address]*prestateAccount)}
+}
+
+// CaptureTxStart implements NativeTracer, copying the state the touched
+// accounts are read from.
+func (t *prestateTracer) CaptureTxStart(statedb *state.StateDB) {
+	t.prestate = statedb.Copy()
+}
+
+// CaptureState implements vm.Tracer, looking up the accounts and the storage
+// slots accessed by the step.
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if err != nil || t.prestate == nil || len(stack.Data()) == 0 {
+		return nil
+	}
+	switch op {
+	case vm.SLOAD, vm.SSTORE:
+		t.lookupSlot(contract.Address(), common.BigToHash(stack.Back(0)))
+	case vm.BALANCE, vm.EXTCODESIZE, vm.EXTCODECOPY, vm.SELFDESTRUCT:
+		t.lookupAccount(common.BigToAddress(stack.Back(0)))
+	}
+	return nil
+}
+
+// CaptureEnter implements vm.Tracer, looking up the accounts of the frame.
+func (t *prestateTracer) CaptureEnter(env *vm.EVM, typ vm.OpCode, from, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	if t.prestate == nil {
+		return nil
+	}
+	if len(t.accounts) == 0 {
+		t.capture(env)
+		t.lookupAccount(env.Coinbase)
+	}
+	t.lookupAccount(from)
+	t.lookupAccount(to)
+	return nil
+}
+
+// CaptureExit implements vm.Tracer.
+func (t *prestateTracer) CaptureExit(env *vm.EVM, output []byte, gasUsed uint64, err error) error {
+	return nil
+}
+
+// CaptureEnd implements vm.Tracer.
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// GetResult returns the touched accounts by address.
+func (t *prestateTracer) GetResult() (interface{}, error) {
+	if err := t.stopped(); err != nil {
+		return nil, err
+	}
+	if t.prestate == nil {
+		return nil, errNoPrestate
+	}
+	return t.accounts, nil
+}
+
+// lookupAccount reads an account from the state before the message, unless
+// it was read already.
+func (t *prestateTracer) lookupAccount(addr common.Address) *prestateAccount {
+	if account, ok := t.accounts[addr]; ok {
+		return account
+	}
+	account := &prestateAccount{
+		Balance: (*hexutil.Big)(t.prestate.GetBalance(addr)),
+		Nonce:   t.prestate.GetNonce(addr),
+		Code:    t.prestate.GetCode(addr),
+	}
+	t.accounts[addr] = account
+	return account
+}
+
+// lookupSlot reads a storage slot from the state before the message, unless
+// it was read already.
+func (t *prestateTracer) lookupSlot(addr common.Address, key common.Hash) {
+	account := t.lookupAccount(addr)
+	if account.Storage == nil {
+		account.Storage = make(map[common.Hash]common.Hash)
+	}
+	if _, ok := account.Storage[key]; !ok {
+		account.Storage[key] = t.prestate.GetState(addr, key)
+	}
+}
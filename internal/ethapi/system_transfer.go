@@ -0,0 +1,131 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+// maxSystemTransferRange bounds the number of blocks scanned by one filter call.
+const maxSystemTransferRange = 100000
+
+var ErrInvalidBlockRange = errors.New("fromBlock is larger than toBlock")
+
+// SystemTransferQuery selects system transfers the way a log filter selects
+// logs: every given criterion must match, an empty one matches everything.
+type SystemTransferQuery struct {
+	FromBlock  *rpc.BlockNumber             `json:"fromBlock"`
+	ToBlock    *rpc.BlockNumber             `json:"toBlock"`
+	Recipients []common.Address             `json:"recipients"`
+	Validators []common.Address             `json:"validators"`
+	Reasons    []types.SystemTransferReason `json:"reasons"`
+}
+
+func (q *SystemTransferQuery) matches(transfer *types.SystemTransfer) bool {
+	if len(q.Recipients) > 0 && !containsAddress(q.Recipients, transfer.Recipient) {
+		return false
+	}
+	if len(q.Validators) > 0 && !containsAddress(q.Validators, transfer.Validator) {
+		return false
+	}
+	if len(q.Reasons) > 0 {
+		found := false
+		for _, reason := range q.Reasons {
+			if reason == transfer.Reason {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAddress(addrs []common.Address, addr common.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// PublicSystemTransferAPI exposes the balance changes done by the consensus
+// engine outside of any transaction: incentives and stake refunds.
+type PublicSystemTransferAPI struct {
+	b Backend
+}
+
+// NewPublicSystemTransferAPI creates a new system transfer API.
+func NewPublicSystemTransferAPI(b Backend) *PublicSystemTransferAPI {
+	return &PublicSystemTransferAPI{b}
+}
+
+// GetSystemTransfers returns the system transfers of a block.
+func (s *PublicSystemTransferAPI) GetSystemTransfers(ctx context.Context, blockNr rpc.BlockNumber) ([]*types.SystemTransfer, error) {
+	header, err := s.b.HeaderByNumber(ctx, blockNr)
+	if header == nil || err != nil {
+		return nil, err
+	}
+	transfers := core.GetSystemTransfers(s.b.ChainDb(), header.Hash(), header.Number.Uint64())
+	if transfers == nil {
+		transfers = make([]*types.SystemTransfer, 0)
+	}
+	return transfers, nil
+}
+
+// FilterSystemTransfers returns the system transfers of the canonical chain
+// matching the query. The block range defaults to the latest block.
+func (s *PublicSystemTransferAPI) FilterSystemTransfers(ctx context.Context, query SystemTransferQuery) ([]*types.SystemTransfer, error) {
+	for _, n := range []*rpc.BlockNumber{query.FromBlock, query.ToBlock} {
+		if n != nil && *n == rpc.StableBlockNumber {
+			header, err := s.b.HeaderByNumber(ctx, *n)
+			if header == nil || err != nil {
+				return nil, err
+			}
+			*n = rpc.BlockNumber(header.Number.Uint64())
+		}
+	}
+	head := s.b.CurrentBlock().NumberU64()
+	resolve := func(n *rpc.BlockNumber) uint64 {
+		if n == nil || *n < 0 || uint64(*n) > head {
+			return head
+		}
+		return uint64(*n)
+	}
+
+	from, to := resolve(query.FromBlock), resolve(query.ToBlock)
+	if from > to {
+		return nil, ErrInvalidBlockRange
+	}
+	if to-from >= maxSystemTransferRange {
+		return nil, fmt.Errorf("block range is larger than %d", maxSystemTransferRange)
+	}
+
+	db := s.b.ChainDb()
+	result := make([]*types.SystemTransfer, 0)
+	for n := from; n <= to; n++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		hash := core.GetCanonicalHash(db, n)
+		if hash == (common.Hash{}) {
+			break
+		}
+		for _, transfer := range core.GetSystemTransfers(db, hash, n) {
+			if query.matches(transfer) {
+				result = append(result, transfer)
+			}
+		}
+	}
+	return result, nil
+}
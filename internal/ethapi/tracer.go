@@ -345,6 +345,16 @@ func (jst *JavascriptTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode,
 	return nil
 }
 
+// CaptureEnter is called when the EVM enters a call frame
+func (jst *JavascriptTracer) CaptureEnter(env *vm.EVM, typ vm.OpCode, from, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureExit is called when the EVM leaves a call frame
+func (jst *JavascriptTracer) CaptureExit(env *vm.EVM, output []byte, gasUsed uint64, err error) error {
+	return nil
+}
+
 // CaptureEnd is called after the call finishes
 func (jst *JavascriptTracer) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
 	//TODO! @Arachnid please figure out of there's anything we can use this method for
@@ -31,6 +31,7 @@ var Modules = map[string]string{
 	"shh":        Shh_JS,
 	"swarmfs":    SWARMFS_JS,
 	"txpool":     TxPool_JS,
+	"wan":        Wan_JS,
 }
 
 const Chequebook_JS = `
@@ -194,6 +195,16 @@ web3._extend({
 			call: 'pos_getRbSignatureCount',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'verifyRandom',
+			call: 'pos_verifyRandom',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getEquivocations',
+			call: 'pos_getEquivocations',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'getChainQuality',
 			call: 'pos_getChainQuality',
@@ -406,6 +417,59 @@ web3._extend({
 			call: 'pos_getTps',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'getEpochSummary',
+			call: 'pos_getEpochSummary',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getValidatorHistory',
+			call: 'pos_getValidatorHistory',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getEpochSummaryRange',
+			call: 'pos_getEpochSummaryRange',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'getEpochStakerInfoAllRange',
+			call: 'pos_getEpochStakerInfoAllRange',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'getEpochIncentivePayDetailRange',
+			call: 'pos_getEpochIncentivePayDetailRange',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'getActivityRange',
+			call: 'pos_getActivityRange',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'getEpochBlkCntRange',
+			call: 'pos_getEpochBlkCntRange',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'getEpochLeadersAddrRange',
+			call: 'pos_getEpochLeadersAddrRange',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'getRandomRange',
+			call: 'pos_getRandomRange',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 	]
 });
 `
@@ -499,22 +563,26 @@ web3._extend({
 		new web3._extend.Method({
 			name: 'traceBlock',
 			call: 'debug_traceBlock',
-			params: 1
+			params: 2,
+			inputFormatter: [null, null]
 		}),
 		new web3._extend.Method({
 			name: 'traceBlockFromFile',
 			call: 'debug_traceBlockFromFile',
-			params: 1
+			params: 2,
+			inputFormatter: [null, null]
 		}),
 		new web3._extend.Method({
 			name: 'traceBlockByNumber',
 			call: 'debug_traceBlockByNumber',
-			params: 1
+			params: 2,
+			inputFormatter: [null, null]
 		}),
 		new web3._extend.Method({
 			name: 'traceBlockByHash',
 			call: 'debug_traceBlockByHash',
-			params: 1
+			params: 2,
+			inputFormatter: [null, null]
 		}),
 		new web3._extend.Method({
 			name: 'seedHash',
@@ -638,6 +706,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceCall',
+			call: 'debug_traceCall',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter, null]
+		}),
 		new web3._extend.Method({
 			name: 'preimage',
 			call: 'debug_preimage',
@@ -706,6 +780,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.utils.toHex]
 		}),
+		new web3._extend.Method({
+			name: 'feeHistory',
+			call: 'eth_feeHistory',
+			params: 3,
+			inputFormatter: [web3._extend.utils.toHex, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -807,6 +887,35 @@ web3._extend({
 			call: 'personal_deriveAccount',
 			params: 3
 		}),
+		new web3._extend.Method({
+			name: 'genCompactRingSignData',
+			call: 'personal_genCompactRingSignData',
+			params: 3
+		}),
+		new web3._extend.Method({
+			name: 'listOTAs',
+			call: 'personal_listOTAs',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'rescanOTAs',
+			call: 'personal_rescanOTAs',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'unwatchOTAs',
+			call: 'personal_unwatchOTAs',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getOwnedOTAs',
+			call: 'personal_getOwnedOTAs',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -900,3 +1009,22 @@ web3._extend({
 	]
 });
 `
+
+const Wan_JS = `
+web3._extend({
+	property: 'wan',
+	methods: [
+		new web3._extend.Method({
+			name: 'getSystemTransfers',
+			call: 'wan_getSystemTransfers',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'filterSystemTransfers',
+			call: 'wan_filterSystemTransfers',
+			params: 1
+		}),
+	]
+});
+`
@@ -18,6 +18,7 @@ package les
 
 import (
 	"context"
+	"errors"
 	"math/big"
 
 	"github.com/wanchain/go-wanchain/accounts"
@@ -37,6 +38,10 @@ import (
 	"github.com/wanchain/go-wanchain/rpc"
 )
 
+// errNoStableBlock is returned for the stable block tag, light clients do not
+// run the confirmation engine.
+var errNoStableBlock = errors.New("stable block unknown to light clients")
+
 type LesApiBackend struct {
 	eth *LightEthereum
 	gpo *gasprice.Oracle
@@ -56,6 +61,9 @@ func (b *LesApiBackend) SetHead(number uint64) {
 }
 
 func (b *LesApiBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error) {
+	if blockNr == rpc.StableBlockNumber {
+		return nil, errNoStableBlock
+	}
 	if blockNr == rpc.LatestBlockNumber || blockNr == rpc.PendingBlockNumber {
 		return b.eth.blockchain.CurrentHeader(), nil
 	}
@@ -161,6 +169,10 @@ func (b *LesApiBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	return b.gpo.SuggestPrice(ctx)
 }
 
+func (b *LesApiBackend) FeeHistory(ctx context.Context, blockCount int, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []float64, error) {
+	return b.gpo.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+}
+
 func (b *LesApiBackend) ChainDb() ethdb.Database {
 	return b.eth.chainDb
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/common/hexutil"
 	"github.com/wanchain/go-wanchain/consensus"
+	"github.com/wanchain/go-wanchain/consensus/pluto"
 	"github.com/wanchain/go-wanchain/core"
 	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/eth"
@@ -41,6 +42,9 @@ import (
 	"github.com/wanchain/go-wanchain/p2p"
 	"github.com/wanchain/go-wanchain/p2p/discv5"
 	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/slotleader"
+	posUtil "github.com/wanchain/go-wanchain/pos/util"
 	rpc "github.com/wanchain/go-wanchain/rpc"
 	"math/big"
 )
@@ -107,9 +111,11 @@ func New(ctx *node.ServiceContext, config *eth.Config) (*LightEthereum, error) {
 	eth.serverPool = newServerPool(chainDb, quitSync, &eth.wg)
 	eth.retriever = newRetrieveManager(peers, eth.reqDist, eth.serverPool)
 	eth.odr = NewLesOdr(chainDb, eth.retriever)
-	if eth.blockchain, err = light.NewLightChain(eth.odr, eth.chainConfig, eth.engine); err != nil {
+	posEngine := pluto.New(chainConfig.Pluto, chainDb)
+	if eth.blockchain, err = light.NewLightChain(eth.odr, eth.chainConfig, eth.engine, posEngine); err != nil {
 		return nil, err
 	}
+	posInit(eth.blockchain, chainConfig)
 	// Rewind the chain in case of an incompatible config upgrade.
 	if compat, ok := genesisErr.(*params.ConfigCompatError); ok {
 		log.Warn("Rewinding chain to upgrade configuration", "err", compat)
@@ -190,7 +196,7 @@ func (s *LightEthereum) ResetWithGenesisBlock(gb *types.Block) {
 
 func (s *LightEthereum) BlockChain() *light.LightChain      { return s.blockchain }
 func (s *LightEthereum) TxPool() *light.TxPool              { return s.txPool }
-func (s *LightEthereum) Engine() consensus.Engine           { return s.engine }
+func (s *LightEthereum) Engine() consensus.Engine           { return s.blockchain.Engine() }
 func (s *LightEthereum) LesVersion() int                    { return int(s.protocolManager.SubProtocols[0].Version) }
 func (s *LightEthereum) Downloader() *downloader.Downloader { return s.protocolManager.downloader }
 func (s *LightEthereum) EventMux() *event.TypeMux           { return s.eventMux }
@@ -227,3 +233,16 @@ func (s *LightEthereum) Stop() error {
 
 	return nil
 }
+
+// posInit prepares the slot leader selection the light chain verifies the pos
+// headers with, as miner.PosInit does for full nodes.
+func posInit(chain *light.LightChain, config *params.ChainConfig) {
+	posconfig.Pow2PosUpgradeBlockNumber = config.PosFirstBlock.Uint64()
+	if h := chain.GetHeaderByNumber(config.PosFirstBlock.Uint64()); h != nil {
+		posconfig.FirstEpochId, _ = posUtil.CalEpSlbyTd(h.Difficulty.Uint64())
+	}
+	if slotleader.GetSlotLeaderSelection() == nil {
+		slotleader.SlsInit()
+		slotleader.GetSlotLeaderSelection().Init(nil, nil, nil)
+	}
+}
@@ -64,6 +64,8 @@ func LesRequest(req light.OdrRequest) LesOdrRequest {
 		return (*CodeRequest)(r)
 	case *light.ChtRequest:
 		return (*ChtRequest)(r)
+	case *light.PosDataRequest:
+		return (*PosDataRequest)(r)
 	default:
 		return nil
 	}
@@ -354,3 +356,58 @@ func (r *ChtRequest) Validate(db ethdb.Database, msg *Msg) error {
 
 	return nil
 }
+
+type PosProofReq struct {
+	BHash   common.Hash
+	EpochID uint64
+	Kind    uint
+}
+
+// ODR request type for the pos data of an epoch, see LesOdrRequest interface
+type PosDataRequest light.PosDataRequest
+
+// GetCost returns the cost of the given ODR request according to the serving
+// peer's cost table (implementation of LesOdrRequest)
+func (r *PosDataRequest) GetCost(peer *peer) uint64 {
+	return peer.GetRequestCost(GetPosProofsMsg, 1)
+}
+
+// CanSend tells if a certain peer is suitable for serving the given request
+func (r *PosDataRequest) CanSend(peer *peer) bool {
+	return peer.version >= lpv2 && peer.HasBlock(r.Header.Hash(), r.Header.Number.Uint64())
+}
+
+// Request sends an ODR request to the LES network (implementation of LesOdrRequest)
+func (r *PosDataRequest) Request(reqID uint64, peer *peer) error {
+	peer.Log().Debug("Requesting pos data proof", "hash", r.Header.Hash(), "epochID", r.EpochID, "kind", r.Kind)
+	req := &PosProofReq{
+		BHash:   r.Header.Hash(),
+		EpochID: r.EpochID,
+		Kind:    r.Kind,
+	}
+	return peer.RequestPosProofs(reqID, r.GetCost(peer), []*PosProofReq{req})
+}
+
+// Valid processes an ODR request reply message from the LES network
+// returns true and stores results in memory if the message was a valid reply
+// to the request (implementation of LesOdrRequest)
+func (r *PosDataRequest) Validate(db ethdb.Database, msg *Msg) error {
+	log.Debug("Validating pos data proof", "hash", r.Header.Hash(), "epochID", r.EpochID, "kind", r.Kind)
+
+	// Ensure we have a correct message with a single proof
+	if msg.MsgType != MsgPosProofs {
+		return errInvalidMessageType
+	}
+	proofs := msg.Obj.([][]rlp.RawValue)
+	if len(proofs) != 1 {
+		return errMultipleEntries
+	}
+	// Read the data from the proof and store if checks out
+	data, err := light.VerifyPosData(r.Header.Root, proofs[0], r.Kind, r.EpochID)
+	if err != nil {
+		return fmt.Errorf("pos data proof verification failed: %v", err)
+	}
+	r.Proof = proofs[0]
+	r.Data = data
+	return nil
+}
@@ -207,6 +207,11 @@ func (p *peer) SendHeaderProofs(reqID, bv uint64, proofs []ChtResp) error {
 	return sendResponse(p.rw, HeaderProofsMsg, reqID, bv, proofs)
 }
 
+// SendPosProofs sends a batch of pos data proofs, corresponding to the ones requested.
+func (p *peer) SendPosProofs(reqID, bv uint64, proofs proofsData) error {
+	return sendResponse(p.rw, PosProofsMsg, reqID, bv, proofs)
+}
+
 // RequestHeadersByHash fetches a batch of blocks' headers corresponding to the
 // specified header query, based on the hash of an origin block.
 func (p *peer) RequestHeadersByHash(reqID, cost uint64, origin common.Hash, amount int, skip int, reverse bool) error {
@@ -253,6 +258,12 @@ func (p *peer) RequestHeaderProofs(reqID, cost uint64, reqs []*ChtReq) error {
 	return sendRequest(p.rw, GetHeaderProofsMsg, reqID, cost, reqs)
 }
 
+// RequestPosProofs fetches a batch of pos data proofs from a remote node.
+func (p *peer) RequestPosProofs(reqID, cost uint64, reqs []*PosProofReq) error {
+	p.Log().Debug("Fetching batch of pos proofs", "count", len(reqs))
+	return sendRequest(p.rw, GetPosProofsMsg, reqID, cost, reqs)
+}
+
 func (p *peer) SendTxs(reqID, cost uint64, txs types.Transactions) error {
 	p.Log().Debug("Fetching batch of transactions", "count", len(txs))
 	return p2p.Send(p.rw, SendTxMsg, txs)
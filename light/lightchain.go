@@ -32,6 +32,7 @@ import (
 	"github.com/wanchain/go-wanchain/event"
 	"github.com/wanchain/go-wanchain/log"
 	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
 	"github.com/wanchain/go-wanchain/rlp"
 )
 
@@ -66,16 +67,21 @@ type LightChain struct {
 	procInterrupt int32 // interrupt signaler for block processing
 	wg            sync.WaitGroup
 
-	engine consensus.Engine
+	engine    consensus.Engine
+	posEngine consensus.Engine
+
+	posDataCache *lru.Cache // Cache for the pos data of the most recent epochs
 }
 
 // NewLightChain returns a fully initialised light chain using information
 // available in the database. It initialises the default Ethereum header
-// validator.
-func NewLightChain(odr OdrBackend, config *params.ChainConfig, engine consensus.Engine) (*LightChain, error) {
+// validator, and the pos engine verifying the headers from the first pos
+// block on if given.
+func NewLightChain(odr OdrBackend, config *params.ChainConfig, engine consensus.Engine, posEngines ...consensus.Engine) (*LightChain, error) {
 	bodyCache, _ := lru.New(bodyCacheLimit)
 	bodyRLPCache, _ := lru.New(bodyCacheLimit)
 	blockCache, _ := lru.New(blockCacheLimit)
+	posDataCache, _ := lru.New(posDataCacheLen)
 
 	bc := &LightChain{
 		chainDb:      odr.Database(),
@@ -85,6 +91,10 @@ func NewLightChain(odr OdrBackend, config *params.ChainConfig, engine consensus.
 		bodyRLPCache: bodyRLPCache,
 		blockCache:   blockCache,
 		engine:       engine,
+		posDataCache: posDataCache,
+	}
+	if len(posEngines) > 0 {
+		bc.posEngine = posEngines[0]
 	}
 	var err error
 	bc.hc, err = core.NewHeaderChain(odr.Database(), config, bc.engine, bc.getProcInterrupt)
@@ -117,9 +127,20 @@ func NewLightChain(odr OdrBackend, config *params.ChainConfig, engine consensus.
 			log.Error("Chain rewind was successful, resuming normal operation")
 		}
 	}
+	if bc.posEngine != nil && (config.IsPosActive || bc.hc.CurrentHeader().Number.Uint64()+1 >= config.PosFirstBlock.Uint64()) {
+		bc.switchPosEngine()
+	}
+	bc.loadFirstEpochID()
 	return bc, nil
 }
 
+// switchPosEngine switches the chain to the pos engine.
+func (self *LightChain) switchPosEngine() {
+	log.Info("Switching light chain to the pos engine")
+	self.engine = self.posEngine
+	self.hc.SwitchEngine(self.posEngine)
+}
+
 func (self *LightChain) getProcInterrupt() bool {
 	return atomic.LoadInt32(&self.procInterrupt) == 1
 }
@@ -348,12 +369,41 @@ func (self *LightChain) postChainEvents(events []interface{}) {
 // because nonces can be verified sparsely, not needing to check each.
 //
 // In the case of a light chain, InsertHeaderChain also creates and posts light
-// chain events when necessary.
+// chain events when necessary. It returns the number of headers inserted if
+// no error occurred.
 func (self *LightChain) InsertHeaderChain(chain []*types.Header, checkFreq int) (int, error) {
+	// Headers from the first pos block on are verified by the pos engine
+	if self.posEngine != nil && self.engine != self.posEngine {
+		for i, header := range chain {
+			if !self.hc.Config().IsPosBlockNumber(header.Number) {
+				continue
+			}
+			if i > 0 {
+				if n, err := self.insertHeaderChain(chain[:i], checkFreq); err != nil {
+					return n, err
+				}
+			}
+			self.switchPosEngine()
+			n, err := self.insertHeaderChain(chain[i:], checkFreq)
+			return i + n, err
+		}
+	}
+	return self.insertHeaderChain(chain, checkFreq)
+}
+
+// insertHeaderChain inserts a chain of headers verified by the same engine.
+func (self *LightChain) insertHeaderChain(chain []*types.Header, checkFreq int) (int, error) {
 	start := time.Now()
 	if i, err := self.hc.ValidateHeaderChain(chain, checkFreq); err != nil {
 		return i, err
 	}
+	// The pos data the slot leader proofs are verified against may have to be
+	// retrieved from the network, which is done before locking the chain
+	if self.posEngine != nil && self.engine == self.posEngine {
+		if i, err := self.verifyPosHeaders(chain); err != nil {
+			return i, err
+		}
+	}
 
 	// Make sure only one thread manipulates the chain at once
 	self.chainmu.Lock()
@@ -384,8 +434,14 @@ func (self *LightChain) InsertHeaderChain(chain []*types.Header, checkFreq int)
 		return err
 	}
 	i, err := self.hc.InsertHeaderChain(chain, whFunc, start)
+	if self.posEngine != nil && self.engine == self.posEngine && posconfig.FirstEpochId == 0 {
+		self.loadFirstEpochID()
+	}
 	go self.postChainEvents(events)
-	return i, err
+	if err != nil {
+		return i, err
+	}
+	return len(chain), nil
 }
 
 // CurrentHeader retrieves the current head header of the canonical chain. The
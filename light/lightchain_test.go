@@ -171,8 +171,10 @@ func testFork(t *testing.T, LightChain *LightChain, i, n int, comparator func(td
 	}
 	// Extend the newly created chain
 	headerChainB := lce.makeHeaderChain(LightChain2.CurrentHeader(), n, forkSeed)
-	if _, err := LightChain2.InsertHeaderChain(headerChainB, 1); err != nil {
+	if n, err := LightChain2.InsertHeaderChain(headerChainB, 1); err != nil {
 		t.Fatalf("failed to insert forking chain: %v", err)
+	} else if n != len(headerChainB) {
+		t.Errorf("inserted header count mismatch: have %d, want %d", n, len(headerChainB))
 	}
 	// Sanity check that the forked chain can be imported into the original
 	var tdPre, tdPost *big.Int
@@ -0,0 +1,331 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package light
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/consensus"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/pos/epochLeader"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/slotleader"
+	posUtil "github.com/wanchain/go-wanchain/pos/util"
+	"github.com/wanchain/go-wanchain/rlp"
+	"github.com/wanchain/go-wanchain/trie"
+)
+
+// Kinds of pos data retrieved by a PosDataRequest.
+const (
+	// PosLeadersData are the leaders of the epoch before the requested one,
+	// read from the state at the end of the epoch three before.
+	PosLeadersData = iota
+
+	// PosSlotData are the random and the stage two data of the requested epoch,
+	// read from the state at the end of the epoch before.
+	PosSlotData
+)
+
+const (
+	posExtraSeal    = 65               // Fixed number of extra-data suffix bytes reserved for the signer seal
+	posDataTimeout  = 30 * time.Second // Maximum time spent retrieving the pos data of an epoch
+	posDataCacheLen = 8                // Number of epochs whose pos data is cached
+)
+
+var (
+	errUnknownPosData  = errors.New("unknown pos data kind")
+	errMissingPosNode  = errors.New("pos data proof misses a trie node")
+	errNoSlotLeaderSel = errors.New("slot leader selection not initialised")
+	errNoEpochHeader   = errors.New("epoch header not found")
+	errInvalidSlotLead = errors.New("invalid slot leader proof")
+)
+
+// PosDataRequest is the ODR request type for the pos data the slot leader
+// proofs of an epoch are verified against.
+type PosDataRequest struct {
+	OdrRequest
+	Header  *types.Header // Header of the state the data is read from
+	EpochID uint64
+	Kind    uint
+	Proof   []rlp.RawValue
+	Data    *slotleader.EpochProofData
+}
+
+// StoreResult stores the retrieved data in local database
+func (req *PosDataRequest) StoreResult(db ethdb.Database) {
+	storeProof(db, req.Proof)
+}
+
+// ReadPosData reads the pos data of the given kind for an epoch from the state
+// into data, the way a full node reads it while verifying the epoch's blocks.
+func ReadPosData(statedb *state.StateDB, kind uint, epochID uint64, data *slotleader.EpochProofData) error {
+	switch kind {
+	case PosLeadersData:
+		leaders := epochLeader.EpochLeadersAt(statedb, epochID-1)
+		data.PreEpochLeaders = make([]*ecdsa.PublicKey, len(leaders))
+		for i, leader := range leaders {
+			data.PreEpochLeaders[i] = crypto.ToECDSAPub(leader)
+		}
+	case PosSlotData:
+		data.Random = vm.GetR(statedb, epochID)
+		if data.Random == nil {
+			data.Random = posconfig.GetRandomGenesis()
+		}
+		// Without stage two data the proofs are verified by the genesis leaders
+		data.ValidLeaders, data.AlphaPKi, _ = slotleader.StageTwoFromState(statedb, epochID)
+	default:
+		return errUnknownPosData
+	}
+	return statedb.Error()
+}
+
+// ProvePosData reads the pos data of the given kind from the state with the
+// given root, returning the trie nodes the reads touched as its proof.
+func ProvePosData(db trie.Database, root common.Hash, kind uint, epochID uint64) ([]rlp.RawValue, error) {
+	recorder := &proofRecorder{Database: db, nodes: make(map[string][]byte)}
+	statedb, err := state.New(root, state.NewTrieDatabase(recorder))
+	if err != nil {
+		return nil, err
+	}
+	if err := ReadPosData(statedb, kind, epochID, new(slotleader.EpochProofData)); err != nil {
+		return nil, err
+	}
+	proof := make([]rlp.RawValue, 0, len(recorder.nodes))
+	for _, node := range recorder.nodes {
+		proof = append(proof, node)
+	}
+	return proof, nil
+}
+
+// VerifyPosData reads the pos data of the given kind from the trie nodes of
+// its proof against the given state root. The proof is invalid if any trie
+// node read is missing from it.
+func VerifyPosData(root common.Hash, proof []rlp.RawValue, kind uint, epochID uint64) (*slotleader.EpochProofData, error) {
+	memdb, _ := ethdb.NewMemDatabase()
+	for _, node := range proof {
+		memdb.Put(crypto.Keccak256(node), node)
+	}
+	db := &proofDatabase{MemDatabase: memdb}
+	statedb, err := state.New(root, state.NewDatabase(db))
+	if err != nil {
+		return nil, err
+	}
+	data := new(slotleader.EpochProofData)
+	if err := ReadPosData(statedb, kind, epochID, data); err != nil {
+		return nil, err
+	}
+	if db.missing {
+		return nil, errMissingPosNode
+	}
+	return data, nil
+}
+
+// proofRecorder records the trie nodes read from a database.
+type proofRecorder struct {
+	trie.Database
+	nodes map[string][]byte
+}
+
+func (db *proofRecorder) Get(key []byte) ([]byte, error) {
+	value, err := db.Database.Get(key)
+	// Only trie nodes are keyed by their hash, preimages are not needed
+	if err == nil && len(key) == common.HashLength {
+		db.nodes[string(key)] = value
+	}
+	return value, err
+}
+
+// proofDatabase serves the trie nodes of a proof, recording whether a node
+// missing from it was read.
+type proofDatabase struct {
+	*ethdb.MemDatabase
+	missing bool
+}
+
+func (db *proofDatabase) Get(key []byte) ([]byte, error) {
+	value, err := db.MemDatabase.Get(key)
+	if err != nil && len(key) == common.HashLength {
+		db.missing = true
+	}
+	return value, err
+}
+
+// headerLookup retrieves a header by hash and number.
+type headerLookup func(hash common.Hash, number uint64) *types.Header
+
+// loadFirstEpochID sets the first pos epoch from the stored canonical first pos
+// block, which has passed verification, the pos data of the epochs after it
+// being retrieved from the network.
+func (self *LightChain) loadFirstEpochID() {
+	config := self.hc.Config()
+	if self.posEngine == nil || config.PosFirstBlock == nil {
+		return
+	}
+	if first := self.GetHeaderByNumber(config.PosFirstBlock.Uint64()); first != nil {
+		posconfig.FirstEpochId, _ = posUtil.GetEpochSlotIDFromDifficulty(first.Difficulty)
+	}
+}
+
+// verifyPosHeaders verifies the slot leader proofs of the pos headers of a
+// chain not inserted yet, returning the index of the first invalid one. The
+// ancestors of a header may be earlier headers of the chain.
+func (self *LightChain) verifyPosHeaders(chain []*types.Header) (int, error) {
+	pending := make(map[common.Hash]*types.Header, len(chain))
+	getHeader := func(hash common.Hash, number uint64) *types.Header {
+		if header, ok := pending[hash]; ok {
+			return header
+		}
+		return self.GetHeader(hash, number)
+	}
+	for i, header := range chain {
+		if !self.hc.HasHeader(header.Hash(), header.Number.Uint64()) {
+			if err := self.verifyPosHeader(header, getHeader); err != nil {
+				return i, err
+			}
+		}
+		pending[header.Hash()] = header
+	}
+	return 0, nil
+}
+
+// verifyPosHeader verifies the slot leader proof of a pos header against the
+// pos data of its epoch, which is retrieved with merkle proofs of the states of
+// its ancestors.
+func (self *LightChain) verifyPosHeader(header *types.Header, getHeader headerLookup) error {
+	config := self.hc.Config()
+	if self.posEngine == nil || !config.IsPosBlockNumber(header.Number) {
+		return nil
+	}
+	sls := slotleader.GetSlotLeaderSelection()
+	if sls == nil {
+		return errNoSlotLeaderSel
+	}
+	epochID, slotID := posUtil.GetEpochSlotIDFromDifficulty(header.Difficulty)
+	firstEpochID, ok := self.firstEpochID(header, getHeader)
+	if !ok {
+		return consensus.ErrUnknownAncestor
+	}
+	if len(header.Extra) <= posExtraSeal {
+		return errInvalidSlotLead
+	}
+	proof, proofMeg, err := sls.GetInfoFromHeadExtra(epochID, header.Extra[:len(header.Extra)-posExtraSeal])
+	if err != nil {
+		return err
+	}
+	data, err := self.posProofData(header, epochID, firstEpochID, getHeader)
+	if err != nil {
+		return err
+	}
+	if !sls.VerifySlotProofWithData(data, epochID, slotID, proof, proofMeg) {
+		log.Warn("Invalid slot leader proof", "number", header.Number, "hash", header.Hash(), "epochID", epochID, "slotID", slotID)
+		return errInvalidSlotLead
+	}
+	return nil
+}
+
+// firstEpochID returns the epoch of the first pos block in the chain of the
+// header. It is the stored one once the first pos block has been inserted,
+// before that the one of the first pos block being verified along with it.
+func (self *LightChain) firstEpochID(header *types.Header, getHeader headerLookup) (uint64, bool) {
+	if posconfig.FirstEpochId != 0 {
+		return posconfig.FirstEpochId, true
+	}
+	first := self.hc.Config().PosFirstBlock.Uint64()
+	for header != nil && header.Number.Uint64() > first {
+		header = getHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	if header == nil {
+		return 0, false
+	}
+	epochID, _ := posUtil.GetEpochSlotIDFromDifficulty(header.Difficulty)
+	return epochID, true
+}
+
+// posDataKey identifies the pos data of an epoch in a chain by the headers
+// whose states it is read from, so that forks never share it.
+type posDataKey struct {
+	epochID uint64
+	leaders common.Hash
+	slot    common.Hash
+}
+
+// posProofData returns the pos data the slot leader proofs of the epoch of the
+// header are verified against, or nil for the epochs led by the genesis leaders.
+func (self *LightChain) posProofData(header *types.Header, epochID, firstEpochID uint64, getHeader headerLookup) (*slotleader.EpochProofData, error) {
+	if epochID <= firstEpochID+2 {
+		return nil, nil
+	}
+	parent := getHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	leadersHeader := self.epochLastHeader(parent, epochID-3, getHeader)
+	if leadersHeader == nil {
+		return nil, errNoEpochHeader
+	}
+	// The random and the stage two data of the epoch are final at the end of
+	// the previous one, the state there serves every header of the epoch
+	slotHeader := self.epochLastHeader(parent, epochID-1, getHeader)
+	if slotHeader == nil {
+		return nil, errNoEpochHeader
+	}
+	key := posDataKey{epochID, leadersHeader.Hash(), slotHeader.Hash()}
+	if data, ok := self.posDataCache.Get(key); ok {
+		return data.(*slotleader.EpochProofData), nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), posDataTimeout)
+	defer cancel()
+
+	leaders := &PosDataRequest{Header: leadersHeader, EpochID: epochID, Kind: PosLeadersData}
+	if err := self.odr.Retrieve(ctx, leaders); err != nil {
+		return nil, err
+	}
+	slot := &PosDataRequest{Header: slotHeader, EpochID: epochID, Kind: PosSlotData}
+	if err := self.odr.Retrieve(ctx, slot); err != nil {
+		return nil, err
+	}
+	data := slot.Data
+	data.PreEpochLeaders = leaders.Data.PreEpochLeaders
+
+	self.posDataCache.Add(key, data)
+	return data, nil
+}
+
+// epochLastHeader returns the last pos header in the chain of the given header
+// whose epoch is not after the given one.
+func (self *LightChain) epochLastHeader(header *types.Header, epochID uint64, getHeader headerLookup) *types.Header {
+	// Walk back to the canonical chain, which is then searched by number
+	for header != nil && core.GetCanonicalHash(self.chainDb, header.Number.Uint64()) != header.Hash() {
+		if id, _ := posUtil.GetEpochSlotIDFromDifficulty(header.Difficulty); id <= epochID {
+			return header
+		}
+		header = getHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	first := self.hc.Config().PosFirstBlock.Uint64()
+	if header == nil || header.Number.Uint64() < first {
+		return nil
+	}
+	n := sort.Search(int(header.Number.Uint64()-first+1), func(i int) bool {
+		h := self.GetHeaderByNumber(first + uint64(i))
+		if h == nil {
+			return true
+		}
+		id, _ := posUtil.GetEpochSlotIDFromDifficulty(h.Difficulty)
+		return id > epochID
+	})
+	if n == 0 {
+		return nil
+	}
+	return self.GetHeaderByNumber(first + uint64(n) - 1)
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package light
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/consensus/ethash"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+func TestPosDataProof(t *testing.T) {
+	const epochID = 5
+
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	for i := int64(1); i <= 64; i++ {
+		statedb.AddBalance(common.BigToAddress(big.NewInt(i)), big.NewInt(i))
+	}
+	r := big.NewInt(0x1234567)
+	statedb.SetStateByteArray(vm.GetRBAddress(), *vm.GetRBRKeyHash(epochID), r.Bytes())
+	root, err := statedb.CommitTo(db, false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+
+	proof, err := ProvePosData(db, root, PosSlotData, epochID)
+	if err != nil {
+		t.Fatalf("failed to prove pos data: %v", err)
+	}
+	data, err := VerifyPosData(root, proof, PosSlotData, epochID)
+	if err != nil {
+		t.Fatalf("failed to verify pos data: %v", err)
+	}
+	if data.Random.Cmp(r) != 0 {
+		t.Fatalf("random mismatch: have %v, want %v", data.Random, r)
+	}
+	// Every node of the proof is needed to read the data
+	for i := range proof {
+		partial := append(append([]rlp.RawValue{}, proof[:i]...), proof[i+1:]...)
+		if _, err := VerifyPosData(root, partial, PosSlotData, epochID); err == nil {
+			t.Errorf("proof without node %d accepted", i)
+		}
+	}
+	if _, err := VerifyPosData(root, proof, PosSlotData+1, epochID); err != errUnknownPosData {
+		t.Errorf("unknown kind error mismatch: have %v, want %v", err, errUnknownPosData)
+	}
+}
+
+func TestFirstEpochOnStartup(t *testing.T) {
+	defer func(id uint64) { posconfig.FirstEpochId = id }(posconfig.FirstEpochId)
+	posconfig.FirstEpochId = 0
+
+	db, _ := ethdb.NewMemDatabase()
+	gspec := core.DefaultPPOWTestingGenesisBlock()
+	config := *gspec.Config
+	config.PosFirstBlock = big.NewInt(1)
+	gspec.Config = &config
+	genesis := gspec.MustCommit(db)
+
+	// The first pos block stored by a previous run sets the first epoch
+	first := &types.Header{Number: big.NewInt(1), ParentHash: genesis.Hash(), Difficulty: big.NewInt(100<<32 | 1<<8)}
+	core.WriteHeader(db, first)
+	core.WriteCanonicalHash(db, first.Hash(), 1)
+
+	if _, err := NewLightChain(&dummyOdr{db: db}, gspec.Config, ethash.NewFaker(db)); err != nil {
+		t.Fatalf("failed to create light chain: %v", err)
+	}
+	if posconfig.FirstEpochId != 0 {
+		t.Errorf("first epoch set without pos engine: have %d", posconfig.FirstEpochId)
+	}
+	if _, err := NewLightChain(&dummyOdr{db: db}, gspec.Config, ethash.NewFaker(db), ethash.NewFaker(db)); err != nil {
+		t.Fatalf("failed to create light chain: %v", err)
+	}
+	if posconfig.FirstEpochId != 100 {
+		t.Errorf("first epoch mismatch: have %d, want 100", posconfig.FirstEpochId)
+	}
+}
+
+func TestFirstEpochOfUnverifiedChain(t *testing.T) {
+	defer func(id uint64) { posconfig.FirstEpochId = id }(posconfig.FirstEpochId)
+	posconfig.FirstEpochId = 0
+
+	db, _ := ethdb.NewMemDatabase()
+	gspec := core.DefaultPPOWTestingGenesisBlock()
+	config := *gspec.Config
+	config.PosFirstBlock = big.NewInt(1)
+	gspec.Config = &config
+	genesis := gspec.MustCommit(db)
+
+	lc, err := NewLightChain(&dummyOdr{db: db}, gspec.Config, ethash.NewFaker(db), ethash.NewFaker(db))
+	if err != nil {
+		t.Fatalf("failed to create light chain: %v", err)
+	}
+	first := &types.Header{Number: big.NewInt(1), ParentHash: genesis.Hash(), Difficulty: big.NewInt(100<<32 | 1<<8)}
+	second := &types.Header{Number: big.NewInt(2), ParentHash: first.Hash(), Difficulty: big.NewInt(100<<32 | 2<<8)}
+	pending := map[common.Hash]*types.Header{first.Hash(): first, second.Hash(): second}
+	getHeader := func(hash common.Hash, number uint64) *types.Header { return pending[hash] }
+
+	// The first epoch of a chain being verified is read from its own first
+	// pos header, without changing the one of the stored chain
+	if id, ok := lc.firstEpochID(second, getHeader); !ok || id != 100 {
+		t.Fatalf("first epoch mismatch: have %d (%v), want 100", id, ok)
+	}
+	if posconfig.FirstEpochId != 0 {
+		t.Errorf("first epoch set from an unverified header: have %d", posconfig.FirstEpochId)
+	}
+	if _, ok := lc.firstEpochID(second, func(common.Hash, uint64) *types.Header { return nil }); ok {
+		t.Error("first epoch found without the first pos header")
+	}
+}
@@ -31,6 +31,10 @@ import (
 // MetricsEnabledFlag is the CLI flag name to use to enable metrics collections.
 const MetricsEnabledFlag = "metrics"
 
+// MetricsPrometheusFlag is the CLI flag name of the Prometheus exporter address,
+// which enables metrics collections too.
+const MetricsPrometheusFlag = "metrics.prometheus"
+
 // Enabled is the flag specifying if metrics are enable or not.
 var Enabled = false
 
@@ -39,7 +43,7 @@ var Enabled = false
 // and peek into the command line args for the metrics flag.
 func init() {
 	for _, arg := range os.Args {
-		if strings.TrimLeft(arg, "-") == MetricsEnabledFlag {
+		if flag := strings.TrimLeft(arg, "-"); flag == MetricsEnabledFlag || strings.HasPrefix(flag, MetricsPrometheusFlag) {
 			log.Info("Enabling metrics collection")
 			Enabled = true
 		}
@@ -56,6 +60,33 @@ func NewCounter(name string) metrics.Counter {
 	return metrics.GetOrRegisterCounter(name, metrics.DefaultRegistry)
 }
 
+// NewGauge create a new metrics Gauge, either a real one of a NOP stub depending
+// on the metrics flag.
+func NewGauge(name string) metrics.Gauge {
+	if !Enabled {
+		return new(metrics.NilGauge)
+	}
+	return metrics.GetOrRegisterGauge(name, metrics.DefaultRegistry)
+}
+
+// NewGaugeFloat64 create a new metrics GaugeFloat64, either a real one of a NOP
+// stub depending on the metrics flag.
+func NewGaugeFloat64(name string) metrics.GaugeFloat64 {
+	if !Enabled {
+		return new(metrics.NilGaugeFloat64)
+	}
+	return metrics.GetOrRegisterGaugeFloat64(name, metrics.DefaultRegistry)
+}
+
+// NewFunctionalGauge create a new metrics Gauge reading its value from f when
+// collected, either a real one of a NOP stub depending on the metrics flag.
+func NewFunctionalGauge(name string, f func() int64) metrics.Gauge {
+	if !Enabled {
+		return new(metrics.NilGauge)
+	}
+	return metrics.DefaultRegistry.GetOrRegister(name, metrics.NewFunctionalGauge(f)).(metrics.Gauge)
+}
+
 // NewMeter create a new metrics Meter, either a real one of a NOP stub depending
 // on the metrics flag.
 func NewMeter(name string) metrics.Meter {
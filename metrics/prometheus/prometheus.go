@@ -0,0 +1,118 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+// Package prometheus exports the metrics of a registry in the OpenMetrics text
+// format, which Prometheus scrapes.
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rcrowley/go-metrics"
+	"github.com/wanchain/go-wanchain/log"
+)
+
+// ContentType is the content type of the OpenMetrics text format.
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// Quantiles reported for the timers and histograms.
+var quantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+// Handler returns an HTTP handler exporting the metrics of reg.
+func Handler(reg metrics.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		w.Write(Export(reg))
+	})
+}
+
+// ListenAndServe serves the registered metrics on /metrics at addr, logging
+// the error which stops it.
+func ListenAndServe(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(metrics.DefaultRegistry))
+
+	log.Info("Starting Prometheus metrics exporter", "addr", fmt.Sprintf("http://%s/metrics", addr))
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Error("Failure in running Prometheus metrics exporter", "err", err)
+	}
+}
+
+// Export renders the metrics of reg, sorted by name.
+func Export(reg metrics.Registry) []byte {
+	all := make(map[string]interface{})
+	reg.Each(func(name string, metric interface{}) {
+		all[name] = metric
+	})
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := new(bytes.Buffer)
+	for _, name := range names {
+		writeMetric(buf, MetricName(name), all[name])
+	}
+	buf.WriteString("# EOF\n")
+	return buf.Bytes()
+}
+
+// MetricName converts a registry name, such as "txpool/pending/discard", into
+// a metric name, "txpool_pending_discard".
+func MetricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func writeMetric(buf *bytes.Buffer, name string, metric interface{}) {
+	switch m := metric.(type) {
+	case metrics.Counter:
+		writeHeader(buf, name, "counter")
+		writeSample(buf, name+"_total", "", float64(m.Count()))
+	case metrics.Gauge:
+		writeHeader(buf, name, "gauge")
+		writeSample(buf, name, "", float64(m.Value()))
+	case metrics.GaugeFloat64:
+		writeHeader(buf, name, "gauge")
+		writeSample(buf, name, "", m.Value())
+	case metrics.Meter:
+		writeHeader(buf, name, "counter")
+		writeSample(buf, name+"_total", "", float64(m.Snapshot().Count()))
+	case metrics.Timer:
+		t := m.Snapshot()
+		writeSummary(buf, name, t.Count(), t.Sum(), t.Percentiles(quantiles))
+	case metrics.Histogram:
+		h := m.Snapshot()
+		writeSummary(buf, name, h.Count(), h.Sum(), h.Percentiles(quantiles))
+	}
+}
+
+func writeSummary(buf *bytes.Buffer, name string, count, sum int64, values []float64) {
+	writeHeader(buf, name, "summary")
+	for i, q := range quantiles {
+		writeSample(buf, name, `quantile="`+strconv.FormatFloat(q, 'f', -1, 64)+`"`, values[i])
+	}
+	writeSample(buf, name+"_sum", "", float64(sum))
+	writeSample(buf, name+"_count", "", float64(count))
+}
+
+func writeHeader(buf *bytes.Buffer, name, kind string) {
+	fmt.Fprintf(buf, "# TYPE %s %s\n", name, kind)
+}
+
+func writeSample(buf *bytes.Buffer, name, labels string, value float64) {
+	buf.WriteString(name)
+	if labels != "" {
+		buf.WriteString("{" + labels + "}")
+	}
+	buf.WriteString(" " + strconv.FormatFloat(value, 'g', -1, 64) + "\n")
+}
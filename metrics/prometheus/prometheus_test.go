@@ -0,0 +1,51 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package prometheus
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+func TestExport(t *testing.T) {
+	reg := metrics.NewRegistry()
+	metrics.GetOrRegisterCounter("txpool/protocol/included", reg).Inc(3)
+	metrics.GetOrRegisterGauge("pos/epochleader", reg).Update(1)
+	metrics.GetOrRegisterGaugeFloat64("pos/incentive/paid", reg).Update(1.5)
+	metrics.GetOrRegisterMeter("p2p/InboundTraffic", reg).Mark(42)
+	reg.Register("pos/slot", metrics.NewFunctionalGauge(func() int64 { return 7 }))
+	metrics.GetOrRegisterTimer("chain/inserts", reg).Update(time.Millisecond)
+
+	want := `# TYPE chain_inserts summary
+chain_inserts{quantile="0.5"} 1e+06
+chain_inserts{quantile="0.75"} 1e+06
+chain_inserts{quantile="0.95"} 1e+06
+chain_inserts{quantile="0.99"} 1e+06
+chain_inserts{quantile="0.999"} 1e+06
+chain_inserts_sum 1e+06
+chain_inserts_count 1
+# TYPE p2p_InboundTraffic counter
+p2p_InboundTraffic_total 42
+# TYPE pos_epochleader gauge
+pos_epochleader 1
+# TYPE pos_incentive_paid gauge
+pos_incentive_paid 1.5
+# TYPE pos_slot gauge
+pos_slot 7
+# TYPE txpool_protocol_included counter
+txpool_protocol_included_total 3
+# EOF
+`
+	rec := httptest.NewRecorder()
+	Handler(reg).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if have := rec.Body.String(); have != want {
+		t.Fatalf("export mismatch:\nhave:\n%s\nwant:\n%s", have, want)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/openmetrics-text") {
+		t.Fatalf("content type mismatch: %s", ct)
+	}
+}
@@ -166,6 +166,13 @@ func (self *Miner) Mining() bool {
 	return atomic.LoadInt32(&self.mining) > 0
 }
 
+// WaitStopped blocks until the PoS timer loop of a stopped miner has returned,
+// so another miner of the process may take the PoS engine over.
+func (self *Miner) WaitStopped() {
+	self.mu.Lock()
+	self.mu.Unlock()
+}
+
 func (self *Miner) HashRate() (tot int64) {
 	if pow, ok := self.engine.(consensus.PoW); ok {
 		tot += int64(pow.Hashrate())
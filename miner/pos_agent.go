@@ -1,6 +1,7 @@
 package miner
 
 import (
+	"crypto/ecdsa"
 	"encoding/hex"
 	"fmt"
 
@@ -14,6 +15,7 @@ import (
 
 	"github.com/wanchain/go-wanchain/crypto"
 	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/metrics"
 	"github.com/wanchain/go-wanchain/pos/cfm"
 	"github.com/wanchain/go-wanchain/pos/epochLeader"
 	"github.com/wanchain/go-wanchain/pos/incentive"
@@ -24,6 +26,20 @@ import (
 	"github.com/wanchain/go-wanchain/rpc"
 )
 
+var (
+	// Current epoch and slot, as given by the clock
+	epochGauge = metrics.NewFunctionalGauge("pos/epoch", func() int64 {
+		epochID, _ := util.CalEpochSlotID(uint64(util.Now().Unix()))
+		return int64(epochID)
+	})
+	slotGauge = metrics.NewFunctionalGauge("pos/slot", func() int64 {
+		_, slotID := util.CalEpochSlotID(uint64(util.Now().Unix()))
+		return int64(slotID)
+	})
+
+	epochLeaderGauge = metrics.NewGauge("pos/epochleader") // 1 if the local node is a leader of the current epoch
+)
+
 func posWhiteList() {
 
 }
@@ -140,7 +156,7 @@ func (self *Miner) backendTimerLoop(s Backend) {
 	}
 
 	for {
-		cur := uint64(time.Now().Unix())
+		cur := uint64(util.Now().Unix())
 		sleepTime := posconfig.SlotTime - cur%posconfig.SlotTime
 		//select {
 		////case <-self.timerStop:
@@ -148,7 +164,7 @@ func (self *Miner) backendTimerLoop(s Backend) {
 		////	return
 		//case <-time.After(time.Duration(time.Second * time.Duration(sleepTime))):
 		//}
-		time.Sleep(time.Second * time.Duration(sleepTime))
+		util.Sleep(time.Second * time.Duration(sleepTime))
 		if !self.Mining() {
 			randombeacon.GetRandonBeaconInst().Stop()
 			return
@@ -161,17 +177,10 @@ func (self *Miner) backendTimerLoop(s Backend) {
 		sls := slotleader.GetSlotLeaderSelection()
 		sls.Loop(rc, key, epochID, slotID)
 
-		prePks, isDefault := sls.GetPreEpochLeadersPK(epochID)
-		targetEpochLeaderID := epochID
-		if isDefault {
-			if epochID > posconfig.FirstEpochId+2 {
-				log.Info("backendTimerLoop use default epoch leader.")
-			}
-			targetEpochLeaderID = 0
-		}
-		if sls.IsLocalPkInEpochLeaders(prePks) {
-			leaderPub, err := sls.GetSlotLeader(targetEpochLeaderID, slotID)
-			if err == nil {
+		leaderPub, isEpochLeader := slotLeader(epochID, slotID)
+		if isEpochLeader {
+			epochLeaderGauge.Update(1)
+			if leaderPub != nil {
 				slotTime := (epochID*posconfig.SlotCount + slotID) * posconfig.SlotTime
 				leader := hex.EncodeToString(crypto.FromECDSAPub(leaderPub))
 				log.Info("leader ", "leader", leader)
@@ -179,6 +188,8 @@ func (self *Miner) backendTimerLoop(s Backend) {
 					self.worker.chainSlotTimer <- slotTime
 				}
 			}
+		} else {
+			epochLeaderGauge.Update(0)
 		}
 
 		// get state of k blocks ahead the last block
@@ -198,6 +209,35 @@ func (self *Miner) backendTimerLoop(s Backend) {
 	}
 }
 
+// SlotLeader returns the leader of a slot the local node seals blocks for, nil
+// if the local node is not an epoch leader of the slot.
+func SlotLeader(epochID, slotID uint64) *ecdsa.PublicKey {
+	leader, _ := slotLeader(epochID, slotID)
+	return leader
+}
+
+// slotLeader returns the leader of a slot, chosen from the epoch leaders of the
+// previous epoch, and whether the local node is one of those epoch leaders.
+func slotLeader(epochID, slotID uint64) (*ecdsa.PublicKey, bool) {
+	sls := slotleader.GetSlotLeaderSelection()
+	prePks, isDefault := sls.GetPreEpochLeadersPK(epochID)
+	targetEpochLeaderID := epochID
+	if isDefault {
+		if epochID > posconfig.FirstEpochId+2 {
+			log.Info("backendTimerLoop use default epoch leader.")
+		}
+		targetEpochLeaderID = 0
+	}
+	if !sls.IsLocalPkInEpochLeaders(prePks) {
+		return nil, false
+	}
+	leaderPub, err := sls.GetSlotLeader(targetEpochLeaderID, slotID)
+	if err != nil {
+		return nil, true
+	}
+	return leaderPub, true
+}
+
 func (self *Miner) posStartInit(s Backend, localPublicKey string) (stop bool) {
 
 	h0 := s.BlockChain().GetHeaderByNumber(s.BlockChain().Config().PosFirstBlock.Uint64() - 1)
@@ -219,12 +259,12 @@ func (self *Miner) posStartInit(s Backend, localPublicKey string) (stop bool) {
 	log.Info("posStartInit leader ", "leader", leader)
 
 	if leader == localPublicKey {
-		cur := uint64(time.Now().Unix())
+		cur := uint64(util.Now().Unix())
 		//epochID, slotID := util.CalEpochSlotID(cur)
 
 		slotTime := (epochID*posconfig.SlotCount + slotID) * posconfig.SlotTime
 		if slotTime > cur {
-			time.Sleep(time.Duration(time.Second * time.Duration(slotTime-cur)))
+			util.Sleep(time.Duration(time.Second * time.Duration(slotTime-cur)))
 			//select {
 			////case <-self.timerStop:
 			////	return true
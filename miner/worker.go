@@ -36,6 +36,7 @@ import (
 	"github.com/wanchain/go-wanchain/event"
 	"github.com/wanchain/go-wanchain/log"
 	"github.com/wanchain/go-wanchain/params"
+	posUtil "github.com/wanchain/go-wanchain/pos/util"
 	set "gopkg.in/fatih/set.v0"
 )
 
@@ -431,15 +432,15 @@ func (self *worker) commitNewWork(isPush bool, slotTime uint64) {
 	tstart := time.Now()
 	parent := self.chain.CurrentBlock()
 
-	tstamp := tstart.Unix()
+	tstamp := posUtil.Now().Unix()
 	if parent.Time().Cmp(new(big.Int).SetInt64(tstamp)) >= 0 {
 		tstamp = parent.Time().Int64() + 1
 	}
 	// this will ensure we're not going off too far in the future
-	if now := time.Now().Unix(); tstamp > now+1 {
+	if now := posUtil.Now().Unix(); tstamp > now+1 {
 		wait := time.Duration(tstamp-now) * time.Second
 		log.Info("Mining too far in the future", "wait", common.PrettyDuration(wait))
-		time.Sleep(wait)
+		posUtil.Sleep(wait)
 	}
 
 	num := parent.Number()
@@ -34,6 +34,7 @@ import (
 	"github.com/wanchain/go-wanchain/p2p"
 	"github.com/wanchain/go-wanchain/p2p/nat"
 	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
 	whisper "github.com/wanchain/go-wanchain/whisper/whisperv5"
 )
 
@@ -149,6 +150,13 @@ func NewNode(datadir string, config *NodeConfig) (stack *Node, _ error) {
 		ethConf.SyncMode = downloader.LightSync
 		ethConf.NetworkId = uint64(config.EthereumNetworkID)
 		ethConf.DatabaseCache = config.EthereumDatabaseCache
+		var pluto *params.PlutoConfig
+		if genesis != nil && genesis.Config != nil {
+			pluto = genesis.Config.Pluto
+		}
+		if err := posconfig.Init(nodeConf, ethConf.NetworkId, pluto); err != nil {
+			return nil, fmt.Errorf("pos init: %v", err)
+		}
 		if err := rawStack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
 			return les.New(ctx, &ethConf)
 		}); err != nil {
@@ -0,0 +1,106 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+// Contains a wrapper for the pos and privacy client.
+
+package geth
+
+import (
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/posclient"
+)
+
+// PosClient provides access to the pos and privacy APIs.
+type PosClient struct {
+	client *posclient.Client
+}
+
+// NewPosClient connects a client to the given URL.
+func NewPosClient(rawurl string) (client *PosClient, _ error) {
+	rawClient, err := posclient.Dial(rawurl)
+	return &PosClient{rawClient}, err
+}
+
+// GetEpochID returns the current epoch.
+func (pc *PosClient) GetEpochID(ctx *Context) (epochID int64, _ error) {
+	rawEpochID, err := pc.client.EpochID(ctx.context)
+	return int64(rawEpochID), err
+}
+
+// GetSlotID returns the current slot of the current epoch.
+func (pc *PosClient) GetSlotID(ctx *Context) (slotID int64, _ error) {
+	rawSlotID, err := pc.client.SlotID(ctx.context)
+	return int64(rawSlotID), err
+}
+
+// GetMaxStableBlockNumber returns the highest block which can no longer be reorged.
+func (pc *PosClient) GetMaxStableBlockNumber(ctx *Context) (number int64, _ error) {
+	rawNumber, err := pc.client.MaxStableBlockNumber(ctx.context)
+	return int64(rawNumber), err
+}
+
+// GetEpochLeaders returns the addresses of the epoch leaders of an epoch.
+func (pc *PosClient) GetEpochLeaders(ctx *Context, epochID int64) (leaders *Addresses, _ error) {
+	rawLeaders, err := pc.client.EpochLeaders(ctx.context, uint64(epochID))
+	return &Addresses{rawLeaders}, err
+}
+
+// GetRandomProposers returns the addresses of the random proposers of an epoch.
+func (pc *PosClient) GetRandomProposers(ctx *Context, epochID int64) (proposers *Addresses, _ error) {
+	rawProposers, err := pc.client.RandomProposers(ctx.context, uint64(epochID))
+	return &Addresses{rawProposers}, err
+}
+
+// GetRandom returns the random number of an epoch.
+// The block number can be <0, in which case the number is taken from the latest known block.
+func (pc *PosClient) GetRandom(ctx *Context, epochID int64, number int64) (random *BigInt, _ error) {
+	if number < 0 {
+		rawRandom, err := pc.client.Random(ctx.context, uint64(epochID), nil)
+		return &BigInt{rawRandom}, err
+	}
+	rawRandom, err := pc.client.Random(ctx.context, uint64(epochID), big.NewInt(number))
+	return &BigInt{rawRandom}, err
+}
+
+// GetEpochBlockCount returns the number of blocks sealed in an epoch.
+func (pc *PosClient) GetEpochBlockCount(ctx *Context, epochID int64) (count int64, _ error) {
+	rawCount, err := pc.client.EpochBlockCount(ctx.context, uint64(epochID))
+	return int64(rawCount), err
+}
+
+// GetEpochIncentive returns the incentive paid for an epoch.
+func (pc *PosClient) GetEpochIncentive(ctx *Context, epochID int64) (incentive *BigInt, _ error) {
+	rawIncentive, err := pc.client.EpochIncentive(ctx.context, uint64(epochID))
+	return &BigInt{rawIncentive}, err
+}
+
+// GenerateOneTimeAddress returns a fresh one-time address of a wan address.
+func (pc *PosClient) GenerateOneTimeAddress(ctx *Context, wanAddr []byte) (ota []byte, _ error) {
+	return pc.client.GenerateOneTimeAddress(ctx.context, wanAddr)
+}
+
+// GetOTABalance returns the balance of a one-time address.
+// The block number can be <0, in which case the balance is taken from the latest known block.
+func (pc *PosClient) GetOTABalance(ctx *Context, ota []byte, number int64) (balance *BigInt, _ error) {
+	if number < 0 {
+		rawBalance, err := pc.client.OTABalance(ctx.context, ota, nil)
+		return &BigInt{rawBalance}, err
+	}
+	rawBalance, err := pc.client.OTABalance(ctx.context, ota, big.NewInt(number))
+	return &BigInt{rawBalance}, err
+}
+
+// GetOTAMixSet returns size hex encoded one-time addresses of the same balance
+// as ota.
+func (pc *PosClient) GetOTAMixSet(ctx *Context, ota []byte, size int) (set *Strings, _ error) {
+	rawSet, err := pc.client.OTAMixSet(ctx.context, ota, size)
+	if err != nil {
+		return nil, err
+	}
+	strs := make([]string, len(rawSet))
+	for i, ota := range rawSet {
+		strs[i] = hexutil.Encode(ota)
+	}
+	return &Strings{strs}, nil
+}
@@ -85,6 +85,11 @@ type Config struct {
 	// NoUSB disables hardware wallet monitoring and connectivity.
 	NoUSB bool `toml:",omitempty"`
 
+	// KeyEncryptor selects the backend which envelope encrypts the keystore
+	// files, used to unlock them with --kms and by the account encrypt and
+	// decrypt commands.
+	KeyEncryptor keystore.KeyEncryptorConfig
+
 	// IPCPath is the requested location to place the IPC endpoint. If the path is
 	// a simple file name, it is placed inside the data directory (or on the root
 	// pipe path on Windows), whereas if it's a resolvable path name (absolute or
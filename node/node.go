@@ -28,6 +28,7 @@ import (
 
 	"github.com/prometheus/prometheus/util/flock"
 	"github.com/wanchain/go-wanchain/accounts"
+	"github.com/wanchain/go-wanchain/accounts/keystore"
 	"github.com/wanchain/go-wanchain/ethdb"
 	"github.com/wanchain/go-wanchain/event"
 	"github.com/wanchain/go-wanchain/internal/debug"
@@ -611,6 +612,12 @@ func (n *Node) AccountManager() *accounts.Manager {
 	return n.accman
 }
 
+// KeyEncryptorConfig retrieves the key encryption backend of the keystore
+// files, without its secrets.
+func (n *Node) KeyEncryptorConfig() keystore.KeyEncryptorConfig {
+	return n.config.KeyEncryptor
+}
+
 // IPCEndpoint retrieves the current IPC endpoint used by the protocol stack.
 func (n *Node) IPCEndpoint() string {
 	return n.ipcEndpoint
@@ -75,7 +75,13 @@ func (s *SimAdapter) NewNode(config *NodeConfig) (Node, error) {
 		}
 	}
 
+	var ipcPath string
+	if config.DataDir != "" {
+		ipcPath = "gwan.ipc"
+	}
 	n, err := node.New(&node.Config{
+		DataDir: config.DataDir,
+		IPCPath: ipcPath,
 		P2P: p2p.Config{
 			PrivateKey:      config.PrivateKey,
 			MaxPeers:        math.MaxInt32,
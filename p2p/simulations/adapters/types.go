@@ -95,6 +95,11 @@ type NodeConfig struct {
 	// services registered by calling the RegisterService function)
 	Services []string
 
+	// DataDir is the data directory of a SimNode. Its services keep their
+	// databases and keystore there and it opens an IPC endpoint in it. The
+	// SimNode is kept in memory without an IPC endpoint if it is empty.
+	DataDir string
+
 	// function to sanction or prevent suggesting a peer
 	Reachable func(id discover.NodeID) bool
 }
@@ -106,6 +111,7 @@ type nodeConfigJSON struct {
 	PrivateKey string   `json:"private_key"`
 	Name       string   `json:"name"`
 	Services   []string `json:"services"`
+	DataDir    string   `json:"data_dir,omitempty"`
 }
 
 // MarshalJSON implements the json.Marshaler interface by encoding the config
@@ -115,6 +121,7 @@ func (n *NodeConfig) MarshalJSON() ([]byte, error) {
 		ID:       n.ID.String(),
 		Name:     n.Name,
 		Services: n.Services,
+		DataDir:  n.DataDir,
 	}
 	if n.PrivateKey != nil {
 		confJSON.PrivateKey = hex.EncodeToString(crypto.FromECDSA(n.PrivateKey))
@@ -152,6 +159,7 @@ func (n *NodeConfig) UnmarshalJSON(data []byte) error {
 
 	n.Name = confJSON.Name
 	n.Services = confJSON.Services
+	n.DataDir = confJSON.DataDir
 
 	return nil
 }
@@ -148,7 +148,7 @@ var (
 	// means that all fields must be set at all times. This forces
 	// anyone adding flags to the config to also have to set these
 	// fields.
-	AllProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), big.NewInt(100), false, new(EthashConfig), nil, nil}
+	AllProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), big.NewInt(100), false, big.NewInt(0), big.NewInt(0), new(EthashConfig), nil, nil}
 
 	TestChainConfig = &ChainConfig{
 		ChainId:        big.NewInt(1),
@@ -187,6 +187,9 @@ type ChainConfig struct {
 	PosFirstBlock  *big.Int `json:"posFirstBlock,omitempty"`
 	IsPosActive    bool     `json:"isPosActive,omitempty"`
 
+	CompactRingSigBlock *big.Int `json:"compactRingSigBlock,omitempty"` // Compact ring signature switch block for the wanCoin precompile (nil = no fork)
+	IstanbulBlock       *big.Int `json:"istanbulBlock,omitempty"`       // Istanbul switch block for the constantinople and istanbul EVM changes (nil = no fork)
+
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
@@ -216,6 +219,22 @@ func (c *CliqueConfig) String() string {
 type PlutoConfig struct {
 	Period uint64 `json:"period"` // Number of seconds between blocks to enforce
 	Epoch  uint64 `json:"epoch"`  // Epoch length to reset votes and checkpoint
+
+	// PoS schedule, zero values keep the mainnet schedule
+	SlotTime    uint64 `json:"slotTime,omitempty"`    // Number of seconds of a slot
+	K           uint64 `json:"k,omitempty"`           // Number of slots of a stage, an epoch has 12 stages
+	RBThres     uint64 `json:"rbThres,omitempty"`     // Random beacon signatures needed to recover the random
+	PolymDegree uint64 `json:"polymDegree,omitempty"` // Degree of the random beacon dkg polynomial
+
+	// Random beacon dkg stage windows in slots of an epoch, zero values derive them from K
+	Dkg1End   uint64 `json:"dkg1End,omitempty"`
+	Dkg2Begin uint64 `json:"dkg2Begin,omitempty"`
+	Dkg2End   uint64 `json:"dkg2End,omitempty"`
+	SignBegin uint64 `json:"signBegin,omitempty"`
+	SignEnd   uint64 `json:"signEnd,omitempty"`
+
+	MercuryEpoch *big.Int `json:"mercuryEpoch,omitempty"` // Mercury upgrade epoch (nil = network default)
+	VenusEpoch   *big.Int `json:"venusEpoch,omitempty"`   // Venus upgrade epoch (nil = network default)
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -237,7 +256,7 @@ func (c *ChainConfig) String() string {
 		engine = "unknown"
 	}
 	//return fmt.Sprintf("{ChainID: %v Homestead: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Engine: %v}",
-	return fmt.Sprintf("{ChainID: %v Byzantium: %v Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v Byzantium: %v CompactRingSig: %v Istanbul: %v Engine: %v}",
 		c.ChainId,
 		//c.HomesteadBlock,
 		//c.DAOForkBlock,
@@ -247,6 +266,8 @@ func (c *ChainConfig) String() string {
 		//c.EIP158Block,
 
 		c.ByzantiumBlock,
+		c.CompactRingSigBlock,
+		c.IstanbulBlock,
 		engine,
 	)
 }
@@ -277,6 +298,16 @@ func (c *ChainConfig) String() string {
 //	return isForked(c.ByzantiumBlock, num)
 //}
 
+// IsCompactRingSig returns whether num is either equal to the compact ring signature fork block or greater.
+func (c *ChainConfig) IsCompactRingSig(num *big.Int) bool {
+	return isForked(c.CompactRingSigBlock, num)
+}
+
+// IsIstanbul returns whether num is either equal to the istanbul fork block or greater.
+func (c *ChainConfig) IsIstanbul(num *big.Int) bool {
+	return isForked(c.IstanbulBlock, num)
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
@@ -296,6 +327,9 @@ func (c *ChainConfig) GasTable(num *big.Int) GasTable {
 	//	return GasTableHomestead
 	//}
 
+	if c.IsIstanbul(num) {
+		return GasTableIstanbul
+	}
 	return GasTableEIP158
 }
 
@@ -350,6 +384,14 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	//	return newCompatError("Byzantium fork block", c.ByzantiumBlock, newcfg.ByzantiumBlock)
 	//}
 
+	if isForkIncompatible(c.CompactRingSigBlock, newcfg.CompactRingSigBlock, head) {
+		return newCompatError("Compact ring signature fork block", c.CompactRingSigBlock, newcfg.CompactRingSigBlock)
+	}
+
+	if isForkIncompatible(c.IstanbulBlock, newcfg.IstanbulBlock, head) {
+		return newCompatError("Istanbul fork block", c.IstanbulBlock, newcfg.IstanbulBlock)
+	}
+
 	return nil
 }
 
@@ -417,6 +459,7 @@ type Rules struct {
 	ChainId *big.Int
 	//IsHomestead, IsEIP150, IsEIP155, IsEIP158 bool
 	//IsByzantium                               bool
+	IsIstanbul bool
 }
 
 func (c *ChainConfig) Rules(num *big.Int) Rules {
@@ -426,7 +469,7 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 	}
 	//return Rules{ChainId: new(big.Int).Set(chainId), IsHomestead: /*c.IsHomestead(num)*/false, IsEIP150: false/*c.IsEIP150(num)*/, IsEIP155: false/*c.IsEIP155(num)*/, IsEIP158:false/* c.IsEIP158(num)*/, IsByzantium: c.IsByzantium(num)}
 
-	return Rules{ChainId: new(big.Int).Set(chainId)}
+	return Rules{ChainId: new(big.Int).Set(chainId), IsIstanbul: c.IsIstanbul(num)}
 }
 
 func (c *ChainConfig) SetPosActive() {
@@ -37,6 +37,17 @@ func TestCheckCompatible(t *testing.T) {
 			head:    9,
 			wantErr: nil,
 		},
+		{
+			stored: &ChainConfig{CompactRingSigBlock: big.NewInt(30)},
+			new:    &ChainConfig{CompactRingSigBlock: big.NewInt(25)},
+			head:   25,
+			wantErr: &ConfigCompatError{
+				What:         "Compact ring signature fork block",
+				StoredConfig: big.NewInt(30),
+				NewConfig:    big.NewInt(25),
+				RewindTo:     24,
+			},
+		},
 		//{
 		//	stored: AllProtocolChanges,
 		//	new:    &ChainConfig{ByzantiumBlock: nil},
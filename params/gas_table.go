@@ -19,6 +19,7 @@ package params
 type GasTable struct {
 	ExtcodeSize uint64
 	ExtcodeCopy uint64
+	ExtcodeHash uint64
 	Balance     uint64
 	SLoad       uint64
 	Calls       uint64
@@ -64,6 +65,7 @@ var (
 	GasTableEIP158 = GasTable{
 		ExtcodeSize: 700,
 		ExtcodeCopy: 700,
+		ExtcodeHash: 400,
 		Balance:     400,
 		SLoad:       200,
 		Calls:       700,
@@ -72,4 +74,19 @@ var (
 
 		CreateBySuicide: 25000,
 	}
+
+	// GasTableIstanbul contain the gas re-prices of the state
+	// accessing operations for the istanbul phase.
+	GasTableIstanbul = GasTable{
+		ExtcodeSize: 700,
+		ExtcodeCopy: 700,
+		ExtcodeHash: 700,
+		Balance:     700,
+		SLoad:       800,
+		Calls:       700,
+		Suicide:     5000,
+		ExpByte:     50,
+
+		CreateBySuicide: 25000,
+	}
 )
@@ -5,10 +5,12 @@ import (
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/common/hexutil"
 	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/crypto"
 	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/metrics"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
-	"time"
+	"github.com/wanchain/go-wanchain/pos/util"
 )
 
 const (
@@ -21,6 +23,9 @@ const (
 
 var (
 	ErrNullBlk = errors.New("can not read block")
+
+	// stableLagGauge is the number of blocks above the max stable block
+	stableLagGauge = metrics.NewFunctionalGauge("pos/cfm/stablelag", stableLag)
 )
 
 type CFM struct {
@@ -43,14 +48,20 @@ var c *CFM
 func InitCFM(bc *core.BlockChain) {
 	c = &CFM{}
 	c.bc = bc
-	c.whiteList = make(map[common.Address]int, 0)
+	c.whiteList = newWhiteList()
+	log.Info("InitCFM success")
+}
+
+// newWhiteList returns the addresses of the whitelisted leaders.
+func newWhiteList() map[common.Address]int {
+	whiteList := make(map[common.Address]int, 0)
 	for _, value := range posconfig.WhiteList {
 
 		b := hexutil.MustDecode(value)
 		address := crypto.PubkeyToAddress(*(crypto.ToECDSAPub(b)))
-		c.whiteList[address] = 1
+		whiteList[address] = 1
 	}
-	log.Info("InitCFM success")
+	return whiteList
 }
 
 func GetCFM() *CFM {
@@ -63,7 +74,7 @@ func (c *CFM) GetMaxStableBlkNumber() uint64 {
 		return c.getPowMaxStableBlkNumber(c.getCurrentBlkNumber())
 	}
 	// In pos phase
-	timeNow := uint64(time.Now().Unix())
+	timeNow := uint64(util.Now().Unix())
 	// stopNumber is the min block number, startNumber is max bock number
 	blkStatusArr, stopNumber, startNumber, err := c.scanAllBlockStatus(timeNow)
 
@@ -81,6 +92,18 @@ func (c *CFM) GetMaxStableBlkNumber() uint64 {
 	return posconfig.Pow2PosUpgradeBlockNumber
 }
 
+// stableLag returns the number of blocks above the max stable block.
+func stableLag() int64 {
+	if c == nil {
+		return 0
+	}
+	current, stable := c.getCurrentBlkNumber(), c.GetMaxStableBlkNumber()
+	if current < stable {
+		return 0
+	}
+	return int64(current - stable)
+}
+
 func (c *CFM) getCurrentBlkNumber() uint64 {
 	curBlk := c.bc.CurrentBlock()
 	if curBlk == nil {
@@ -214,3 +237,23 @@ func (c *CFM) getSlotsCount(startTime, stopTime uint64, slotTime uint64) uint64
 	}
 	return uint64((stopTime-startTime)/slotTime + 1)
 }
+
+// IsStableBySuffix tells whether a block is stable by the rule of
+// GetMaxStableBlkNumber, given the consecutive headers sealed after it up to
+// the chain head. It checks blocks of a remote chain, which the scan of the
+// local chain can't. The blocks older than the K ones the scan goes through
+// are stable anyway.
+func IsStableBySuffix(block *types.Header, suffix []*types.Header, timeNow uint64) bool {
+	rule := &CFM{whiteList: newWhiteList()}
+	sbs := SuffixBlkStatic{0, 0}
+	for _, header := range append([]*types.Header{block}, suffix...) {
+		if rule.isInWhiteList(header.Coinbase) {
+			sbs.SuffixBlockTrusted = sbs.SuffixBlockTrusted + 1
+		} else {
+			sbs.SuffixBlockNonTrusted = sbs.SuffixBlockNonTrusted + 1
+		}
+	}
+	slotsCount := rule.getSlotsCount(block.Time.Uint64(), timeNow, posconfig.SlotTime)
+	diffBlk := int64(2*sbs.SuffixBlockTrusted + sbs.SuffixBlockNonTrusted - slotsCount)
+	return diffBlk > SecBlkDiff
+}
@@ -1,11 +1,13 @@
 package cfm
 
 import (
+	"math/big"
 	"testing"
 	"time"
 
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/crypto"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
 )
@@ -17,7 +19,7 @@ func TestIsInWhiteList(t *testing.T) {
 	}
 	var networkId uint64
 	networkId = 6
-	posconfig.Init(nil,networkId)
+	posconfig.Init(nil, networkId, nil)
 	InitCFM(nil)
 	c := GetCFM()
 	c.whiteList = make(map[common.Address]int, 0)
@@ -53,7 +55,7 @@ func TestGetCFM(t *testing.T) {
 func TestInitCFM(t *testing.T) {
 	var networkId uint64
 	networkId = 6
-	posconfig.Init(nil,networkId)
+	posconfig.Init(nil, networkId, nil)
 	InitCFM(nil)
 	c := GetCFM()
 
@@ -67,7 +69,7 @@ func TestInitCFM(t *testing.T) {
 func TestGetSlotsCount(t *testing.T) {
 	var networkId uint64
 	networkId = 6
-	posconfig.Init(nil,networkId)
+	posconfig.Init(nil, networkId, nil)
 	InitCFM(nil)
 	c := GetCFM()
 
@@ -97,7 +99,7 @@ func TestGetSlotsCount(t *testing.T) {
 func TestGetMaxStableBlkNumber(t *testing.T) {
 	var networkId uint64
 	networkId = 6
-	posconfig.Init(nil,networkId)
+	posconfig.Init(nil, networkId, nil)
 
 	blkStatusArr := make([]*BlkStatus, 0)
 	InitCFM(nil)
@@ -150,3 +152,26 @@ func TestGetMaxStableBlkNumber(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestIsStableBySuffix(t *testing.T) {
+	posconfig.Init(nil, 6, nil)
+	trusted := crypto.PubkeyToAddress(*(crypto.ToECDSAPub(hexutil.MustDecode(posconfig.WhiteList[0]))))
+
+	block := &types.Header{Coinbase: trusted, Time: big.NewInt(1000)}
+	suffix := make([]*types.Header, 0)
+	for i := 0; i < 40; i++ {
+		suffix = append(suffix, &types.Header{Coinbase: trusted})
+	}
+	// 41 trusted blocks sealed within one slot
+	if !IsStableBySuffix(block, suffix, 1000) {
+		t.Errorf("block with a trusted suffix not stable")
+	}
+	// 41 trusted blocks out of 50 slots
+	if IsStableBySuffix(block, suffix, 1000+49*posconfig.SlotTime) {
+		t.Errorf("block with a sparse suffix stable")
+	}
+	// 21 trusted blocks
+	if IsStableBySuffix(block, suffix[:20], 1000) {
+		t.Errorf("block with a short suffix stable")
+	}
+}
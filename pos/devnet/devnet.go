@@ -0,0 +1,496 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+// Package devnet runs a PoS network of gwan nodes in-process on the
+// p2p/simulations adapters. A simulated clock drives the epochs and slots of
+// the network, so the PoS flows run deterministically under go test.
+//
+// The PoS engine keeps its epoch leader, slot leader and random beacon state
+// per process, so a single validator runs the miner at a time while the others
+// follow the chain over the simulated p2p network. The validators are equally
+// staked and white listed, and the miner is handed over to the next validator
+// at every epoch, so each of them seals the slots it leads and runs the slot
+// leader selection and random beacon stages of its epoch. The epoch leaders
+// are only elected from the third epoch of the chain on, the selection of the
+// first two has no leaders to run it. The chain quality is
+// only enforced from the fourth epoch of the chain on, which bounds how long
+// a network may run. A process runs a single network.
+package devnet
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/wanchain/go-wanchain/accounts"
+	"github.com/wanchain/go-wanchain/accounts/keystore"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+	bn256 "github.com/wanchain/go-wanchain/crypto/bn256/cloudflare"
+	"github.com/wanchain/go-wanchain/eth"
+	"github.com/wanchain/go-wanchain/eth/downloader"
+	"github.com/wanchain/go-wanchain/miner"
+	"github.com/wanchain/go-wanchain/node"
+	"github.com/wanchain/go-wanchain/p2p/simulations"
+	"github.com/wanchain/go-wanchain/p2p/simulations/adapters"
+	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/posconfig/posconfigtest"
+	"github.com/wanchain/go-wanchain/pos/posdb"
+	"github.com/wanchain/go-wanchain/pos/slotleader"
+	"github.com/wanchain/go-wanchain/pos/util"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+const (
+	networkID   = 6
+	serviceName = "eth"
+	password    = "devnet"
+
+	// syncTimeout bounds the wall time the network may take to seal and
+	// propagate the block of a slot.
+	syncTimeout = 30 * time.Second
+)
+
+var (
+	validatorStake   = new(big.Int).Mul(big.NewInt(4000000), big.NewInt(params.Wan))
+	validatorBalance = new(big.Int).Mul(big.NewInt(1000000000), big.NewInt(params.Wan))
+
+	errNoValidators = errors.New("devnet needs at least one validator")
+	errCreated      = errors.New("devnet runs a single network per process")
+	errNoLeader     = errors.New("devnet validators do not lead the first pos slot")
+
+	created int32 // Set once a network was created in the process
+)
+
+// Config is the configuration of a devnet.
+type Config struct {
+	Validators  int    // Number of validator nodes
+	SlotTime    uint64 // Time span of a slot in seconds
+	K           uint64 // Slot count of an epoch stage
+	GenesisTime uint64 // Timestamp of the genesis block, the simulated clock starts there
+	RBThres     uint64 // Random beacon signature threshold, zero for the mainnet one
+	PolymDegree uint64 // Random beacon dkg polynomial degree, zero for the mainnet one
+}
+
+// DefaultConfig is a three validator network with two minute epochs, whose
+// genesis starts an epoch.
+var DefaultConfig = Config{
+	Validators:  3,
+	SlotTime:    1,
+	K:           10,
+	GenesisTime: 1561976760,
+}
+
+// Validator is a validator staked in the genesis and the node running it.
+type Validator struct {
+	Key  *keystore.Key
+	Node *simulations.Node
+
+	eth *eth.Ethereum
+}
+
+// Address returns the address of the validator.
+func (v *Validator) Address() common.Address {
+	return v.Key.Address
+}
+
+// PublicKey returns the secp256k1 public key the validator is elected with.
+func (v *Validator) PublicKey() *ecdsa.PublicKey {
+	return &v.Key.PrivateKey.PublicKey
+}
+
+// Ethereum returns the wanchain service of the validator node.
+func (v *Validator) Ethereum() *eth.Ethereum {
+	return v.eth
+}
+
+// Client returns an in-process RPC client of the validator node.
+func (v *Validator) Client() (*rpc.Client, error) {
+	return v.Node.Client()
+}
+
+// Network is a running devnet.
+type Network struct {
+	Validators []*Validator
+
+	config  Config
+	dir     string
+	clock   *util.SimulatedClock
+	genesis *core.Genesis
+	sim     *simulations.Network
+	saved   *posconfigtest.Snapshot // PoS configuration of the process before the network
+	sealer  *Validator              // Validator running the miner
+}
+
+// New creates a devnet with deterministic validator keys. It replaces the PoS
+// clock and configuration of the process, which Stop restores.
+func New(config Config) (*Network, error) {
+	if config.Validators < 1 {
+		return nil, errNoValidators
+	}
+	if !atomic.CompareAndSwapInt32(&created, 0, 1) {
+		return nil, errCreated
+	}
+	dir, err := ioutil.TempDir("", "devnet")
+	if err != nil {
+		return nil, err
+	}
+	n := &Network{
+		config: config,
+		dir:    dir,
+		saved:  posconfigtest.Save(),
+	}
+	if err := n.init(); err != nil {
+		n.restore()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return n, nil
+}
+
+// restore puts the wall clock and the PoS configuration of the process back.
+func (n *Network) restore() {
+	util.SetClock(nil)
+	n.saved.Restore()
+}
+
+func (n *Network) init() error {
+	// Generate the validators and the genesis staking them
+	for i := 0; i < n.config.Validators; i++ {
+		key, err := n.newValidatorKey(i)
+		if err != nil {
+			return err
+		}
+		n.Validators = append(n.Validators, &Validator{Key: key})
+	}
+	n.genesis = n.makeGenesis()
+
+	// Run the PoS engine of the process on the schedule and clock of the network
+	genesisTime := n.config.GenesisTime - n.config.GenesisTime%n.config.SlotTime
+	n.genesis.Timestamp = genesisTime
+	n.clock = util.NewSimulatedClock(time.Unix(int64(genesisTime), 0))
+	util.SetClock(n.clock)
+
+	posdb.DbInitAll(n.dir)
+	minerCfg := &node.Config{DataDir: n.nodeDir(0), IPCPath: "gwan.ipc"}
+	if err := posconfig.Init(minerCfg, networkID, n.genesis.Config.Pluto); err != nil {
+		return err
+	}
+	pks := make([]string, len(n.Validators))
+	for i, v := range n.Validators {
+		pks[i] = hexutil.Encode(crypto.FromECDSAPub(v.PublicKey()))
+	}
+	posconfig.SetWhiteList(pks)
+	posconfig.Cfg().DefaultGasPrice = eth.DefaultConfig.GasPrice
+	// Send the pos txs right away, a random wall clock delay is not deterministic
+	posconfig.TxDelay = 0
+
+	// Create the simulated nodes of the validators
+	services := adapters.Services{serviceName: n.newService}
+	n.sim = simulations.NewNetwork(adapters.NewSimAdapter(services), &simulations.NetworkConfig{
+		ID:             "devnet",
+		DefaultService: serviceName,
+	})
+	for i, v := range n.Validators {
+		conf := adapters.RandomNodeConfig()
+		conf.Name = fmt.Sprintf("validator%02d", i)
+		conf.DataDir = n.nodeDir(i)
+		node, err := n.sim.NewNodeWithConfig(conf)
+		if err != nil {
+			return err
+		}
+		v.Node = node
+	}
+	return nil
+}
+
+// newValidatorKey derives the keys of a validator from its index, and stores
+// them in the keystore of its node.
+func (n *Network) newValidatorKey(index int) (*keystore.Key, error) {
+	seed := []byte(fmt.Sprintf("devnet validator %d", index))
+	priv1, err := crypto.ToECDSA(crypto.Keccak256(seed))
+	if err != nil {
+		return nil, err
+	}
+	priv2, err := crypto.ToECDSA(crypto.Keccak256(seed, []byte("privacy")))
+	if err != nil {
+		return nil, err
+	}
+	ks := keystore.NewKeyStore(filepath.Join(n.nodeDir(index), "keystore"), keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.ImportECDSA(priv1, priv2, password)
+	if err != nil {
+		return nil, err
+	}
+	return ks.GetKey(account, password)
+}
+
+// makeGenesis creates a pluto genesis on the schedule of the network, which
+// funds and equally stakes every validator.
+func (n *Network) makeGenesis() *core.Genesis {
+	config := *params.PlutoChainConfig
+	config.Pluto = &params.PlutoConfig{
+		Period:      params.PlutoChainConfig.Pluto.Period,
+		Epoch:       params.PlutoChainConfig.Pluto.Epoch,
+		SlotTime:    n.config.SlotTime,
+		K:           n.config.K,
+		RBThres:     n.config.RBThres,
+		PolymDegree: n.config.PolymDegree,
+	}
+	alloc := make(core.GenesisAlloc)
+	for _, v := range n.Validators {
+		bn256Pk := new(bn256.G1).ScalarBaseMult(posconfig.GenerateD3byKey2(v.Key.PrivateKey2))
+		alloc[v.Address()] = core.GenesisAccount{
+			Balance: validatorBalance,
+			Staking: core.GenesisAccountStaking{
+				Amount:  validatorStake,
+				S256pk:  crypto.FromECDSAPub(v.PublicKey()),
+				Bn256pk: bn256Pk.Marshal(),
+			},
+		}
+	}
+	return &core.Genesis{
+		Config:     &config,
+		ExtraData:  n.Validators[0].Address().Bytes(),
+		GasLimit:   0x47b760, // 4700000
+		Difficulty: big.NewInt(1),
+		Alloc:      alloc,
+	}
+}
+
+// newService creates the wanchain service of a validator node and unlocks the
+// validator key for it.
+func (n *Network) newService(ctx *adapters.ServiceContext) (node.Service, error) {
+	var validator *Validator
+	for _, v := range n.Validators {
+		if v.Node != nil && v.Node.ID() == ctx.Config.ID {
+			validator = v
+		}
+	}
+	if validator == nil {
+		return nil, fmt.Errorf("unknown devnet node %s", ctx.Config.ID)
+	}
+	config := eth.DefaultConfig
+	config.Genesis = n.genesis
+	config.NetworkId = networkID
+	config.SyncMode = downloader.FullSync
+	config.Etherbase = validator.Address()
+
+	ethereum, err := eth.New(ctx.NodeContext, &config)
+	if err != nil {
+		return nil, err
+	}
+	ks := ctx.NodeContext.AccountManager.Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	if err := ks.Unlock(accounts.Account{Address: validator.Address()}, password); err != nil {
+		return nil, err
+	}
+	validator.eth = ethereum
+	return ethereum, nil
+}
+
+func (n *Network) nodeDir(index int) string {
+	return filepath.Join(n.dir, fmt.Sprintf("validator%02d", index))
+}
+
+// Clock returns the simulated clock of the network.
+func (n *Network) Clock() *util.SimulatedClock {
+	return n.clock
+}
+
+// Genesis returns the genesis of the network.
+func (n *Network) Genesis() *core.Genesis {
+	return n.genesis
+}
+
+// Start starts the validator nodes, connects each to all the others and starts
+// mining on the leader of the first pos slot. The miner waits on the simulated
+// clock for its first slot.
+func (n *Network) Start() error {
+	for _, v := range n.Validators {
+		if err := n.sim.Start(v.Node.ID()); err != nil {
+			return err
+		}
+	}
+	_, slotID := util.CalEpochSlotID(n.genesis.Timestamp)
+	leader, _ := slotleader.GetSlotLeaderSelection().GetSlotLeader(0, (slotID+1)%posconfig.SlotCount)
+	sealer := n.validator(leader)
+	if sealer == nil {
+		return errNoLeader
+	}
+	if err := n.startMining(sealer); err != nil {
+		return err
+	}
+	for i, v := range n.Validators {
+		for _, peer := range n.Validators[:i] {
+			if err := n.sim.Connect(v.Node.ID(), peer.Node.ID()); err != nil {
+				return err
+			}
+		}
+	}
+	return n.waitFor(func() bool {
+		for _, v := range n.Validators {
+			if n.peerCount(v) != len(n.Validators)-1 {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Stop stops the network, and restores the wall clock and the PoS
+// configuration of the process.
+func (n *Network) Stop() {
+	if n.sealer != nil && n.sealer.eth.IsMining() {
+		n.stopMining()
+	}
+	n.sim.Shutdown()
+	n.restore()
+	os.RemoveAll(n.dir)
+}
+
+// Sealer returns the validator running the miner.
+func (n *Network) Sealer() *Validator {
+	return n.sealer
+}
+
+// startMining makes the validator the miner of the process, sending its PoS
+// transactions through its own node.
+func (n *Network) startMining(v *Validator) error {
+	posconfig.Cfg().NodeCfg = &node.Config{DataDir: n.nodeDir(n.index(v)), IPCPath: "gwan.ipc"}
+	if err := v.eth.StartMining(true); err != nil {
+		return err
+	}
+	n.sealer = v
+	return nil
+}
+
+// stopMining stops the miner, and waits for it to release the PoS engine. It
+// moves the clock one slot forward to wake the miner up.
+func (n *Network) stopMining() {
+	n.sealer.eth.StopMining()
+	n.clock.Run(n.slotDuration())
+	n.sealer.eth.Miner().WaitStopped()
+}
+
+// RunSlots moves the simulated clock forward slot by slot. After every slot
+// it waits for the miner to handle it and, if the miner leads the slot, for
+// all validators to import its block. The first slot of an epoch hands the
+// miner over to the next validator, and passes unsealed.
+func (n *Network) RunSlots(count int) error {
+	for i := 0; i < count; i++ {
+		next := uint64(n.clock.Now().Add(n.slotDuration()).Unix())
+		if _, slotID := util.CalEpochSlotID(next); slotID == 0 {
+			sealer := n.Validators[(n.index(n.sealer)+1)%len(n.Validators)]
+			n.clock.WaitForSleepers(1)
+			n.stopMining()
+			if err := n.startMining(sealer); err != nil {
+				return err
+			}
+			continue
+		}
+		n.clock.WaitForSleepers(1)
+		n.clock.Run(n.slotDuration())
+		n.clock.WaitForSleepers(1)
+
+		now := uint64(n.clock.Now().Unix())
+		epochID, slotID := util.CalEpochSlotID(now)
+		if !n.leads(n.sealer, epochID, slotID) {
+			continue
+		}
+		if err := n.waitFor(func() bool { return n.synced(now) }); err != nil {
+			return fmt.Errorf("block of epoch %d slot %d: %v", epochID, slotID, err)
+		}
+	}
+	return nil
+}
+
+// RunEpochs moves the simulated clock forward by whole epochs.
+func (n *Network) RunEpochs(count int) error {
+	return n.RunSlots(count * int(posconfig.SlotCount))
+}
+
+// CurrentBlock returns the head block of every validator.
+func (n *Network) CurrentBlock() []*types.Block {
+	blocks := make([]*types.Block, len(n.Validators))
+	for i, v := range n.Validators {
+		blocks[i] = v.eth.BlockChain().CurrentBlock()
+	}
+	return blocks
+}
+
+// leads reports whether the validator leads the slot. The first pos block is
+// sealed by the leader of the slot after the genesis, whatever the epoch
+// leaders are.
+func (n *Network) leads(v *Validator, epochID, slotID uint64) bool {
+	if v.eth.BlockChain().CurrentBlock().NumberU64() == 0 {
+		return true
+	}
+	leader := miner.SlotLeader(epochID, slotID)
+	return leader != nil && util.PkEqual(leader, v.PublicKey())
+}
+
+// synced reports whether every validator imported the block of the slot
+// starting at the given time.
+func (n *Network) synced(slotTime uint64) bool {
+	for _, block := range n.CurrentBlock() {
+		if block.Time().Uint64() < slotTime {
+			return false
+		}
+	}
+	return true
+}
+
+// validator returns the validator with the public key, nil if there is none.
+func (n *Network) validator(pk *ecdsa.PublicKey) *Validator {
+	for _, v := range n.Validators {
+		if pk != nil && util.PkEqual(pk, v.PublicKey()) {
+			return v
+		}
+	}
+	return nil
+}
+
+func (n *Network) index(v *Validator) int {
+	for i, validator := range n.Validators {
+		if validator == v {
+			return i
+		}
+	}
+	return -1
+}
+
+func (n *Network) peerCount(v *Validator) int {
+	client, err := v.Client()
+	if err != nil {
+		return 0
+	}
+	var count hexutil.Uint
+	if err := client.Call(&count, "net_peerCount"); err != nil {
+		return 0
+	}
+	return int(count)
+}
+
+func (n *Network) slotDuration() time.Duration {
+	return time.Duration(posconfig.SlotTime) * time.Second
+}
+
+// waitFor polls the condition until it holds or the sync timeout expires.
+func (n *Network) waitFor(cond func() bool) error {
+	deadline := time.Now().Add(syncTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return errors.New("devnet timed out")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil
+}
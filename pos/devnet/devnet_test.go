@@ -0,0 +1,196 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package devnet
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/posdb"
+	"github.com/wanchain/go-wanchain/pos/util"
+)
+
+// The PoS engine state is per process, so a single test drives the network
+// through its genesis epoch and the epochs led by the stakers.
+func TestDevnet(t *testing.T) {
+	network, err := New(DefaultConfig)
+	if err != nil {
+		t.Fatalf("failed to create devnet: %v", err)
+	}
+	defer network.Stop()
+
+	if err := network.Start(); err != nil {
+		t.Fatalf("failed to start devnet: %v", err)
+	}
+	if _, err := New(DefaultConfig); err != errCreated {
+		t.Fatalf("second devnet error mismatch: have %v, want %v", err, errCreated)
+	}
+	client, err := network.Validators[1].Client()
+	if err != nil {
+		t.Fatalf("failed to attach to validator: %v", err)
+	}
+	var start uint64
+	if err := client.Call(&start, "pos_getEpochID"); err != nil {
+		t.Fatalf("failed to get epoch id: %v", err)
+	}
+
+	stableHeads := make(chan *types.Header, 100)
+	sub, err := client.EthSubscribe(context.Background(), stableHeads, "newStableHeads")
+	if err != nil {
+		t.Fatalf("failed to subscribe to stable heads: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// The miner seals the slots it leads among the white listed validators
+	if err := network.RunSlots(20); err != nil {
+		t.Fatalf("failed to run slots: %v", err)
+	}
+	checkHeads(t, network)
+	if head := network.CurrentBlock()[0]; head.NumberU64() == 0 || head.NumberU64() > 20 {
+		t.Errorf("block count mismatch: have %d, want 1..20", head.NumberU64())
+	}
+	var stable *types.Header
+	if err := client.Call(&stable, "eth_getBlockByNumber", "stable", false); err != nil {
+		t.Fatalf("failed to get stable block: %v", err)
+	}
+	if stable.Number.Sign() == 0 || stable.Number.Uint64() > 20 {
+		t.Fatalf("stable block mismatch: have %v, want 1..20", stable.Number)
+	}
+	// Stable heads are notified in order as the chain head moves
+	for n := uint64(1); n < stable.Number.Uint64(); n++ {
+		select {
+		case head := <-stableHeads:
+			if head.Number.Uint64() != n {
+				t.Fatalf("stable head mismatch: have %v, want %d", head.Number, n)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("stable head subscription failed: %v", err)
+		case <-time.After(syncTimeout):
+			t.Fatalf("stable head %d not notified", n)
+		}
+	}
+	if testing.Short() {
+		return
+	}
+	// Every validator mines an epoch in turn, up to the slot leader selection
+	// stage of the first epoch whose epoch leaders are drawn from the stakers
+	sealers := []*Validator{network.Sealer()}
+	for i := 0; i < 2; i++ {
+		if err := network.RunSlots(int(posconfig.SlotCount)); err != nil {
+			t.Fatalf("failed to run epoch: %v", err)
+		}
+		sealers = append(sealers, network.Sealer())
+	}
+	if err := network.RunSlots(int(posconfig.Sma1End - 20)); err != nil {
+		t.Fatalf("failed to run slots: %v", err)
+	}
+	checkHeads(t, network)
+	checkSealers(t, network, start, sealers)
+
+	var epochID uint64
+	if err := client.Call(&epochID, "pos_getEpochID"); err != nil {
+		t.Fatalf("failed to get epoch id: %v", err)
+	}
+	if epochID != start+2 {
+		t.Fatalf("epoch id mismatch: have %d, want %d", epochID, start+2)
+	}
+	var blocks uint64
+	if err := client.Call(&blocks, "pos_getEpochBlkCnt", epochID-1); err != nil {
+		t.Fatalf("failed to get epoch block count: %v", err)
+	}
+	if blocks == 0 || blocks > posconfig.SlotCount {
+		t.Errorf("epoch block count mismatch: have %d, want 1..%d", blocks, posconfig.SlotCount)
+	}
+
+	validators := make(map[string]bool)
+	for _, v := range network.Validators {
+		validators[hex.EncodeToString(crypto.FromECDSAPub(v.PublicKey()))] = true
+	}
+	var leaders map[string]string
+	if err := client.Call(&leaders, "pos_getEpochLeadersByEpochID", epochID); err != nil {
+		t.Fatalf("failed to get epoch leaders: %v", err)
+	}
+	if len(leaders) != posconfig.EpochLeaderCount {
+		t.Errorf("epoch leader count mismatch: have %d, want %d", len(leaders), posconfig.EpochLeaderCount)
+	}
+	for i, pk := range leaders {
+		if !validators[pk] {
+			t.Errorf("epoch leader %s is not a validator: %s", i, pk)
+		}
+	}
+	var proposers map[string]string
+	if err := client.Call(&proposers, "pos_getRandomProposersByEpochID", epochID); err != nil {
+		t.Fatalf("failed to get random proposers: %v", err)
+	}
+	if len(proposers) != posconfig.RandomProperCount {
+		t.Errorf("random proposer count mismatch: have %d, want %d", len(proposers), posconfig.RandomProperCount)
+	}
+}
+
+func checkHeads(t *testing.T, network *Network) {
+	heads := network.CurrentBlock()
+	for i, head := range heads {
+		if head.Hash() != heads[0].Hash() {
+			t.Errorf("validator %d head mismatch: have %x, want %x", i, head.Hash(), heads[0].Hash())
+		}
+	}
+}
+
+// checkSealers verifies every epoch from start on is sealed by its miner only,
+// and holds the slot leader selection transactions of that miner when it is
+// an epoch leader. The first two epochs of the chain have no elected epoch
+// leaders, so no validator runs the selection in them.
+func checkSealers(t *testing.T, network *Network, start uint64, sealers []*Validator) {
+	seen := make(map[*Validator]bool)
+	for _, sealer := range sealers {
+		if seen[sealer] {
+			t.Fatalf("validator %x mined two epochs", sealer.Address())
+		}
+		seen[sealer] = true
+	}
+	chain := network.Validators[0].Ethereum().BlockChain()
+	sent := make(map[uint64]bool)
+	for block := chain.CurrentBlock(); block.NumberU64() > 0; block = chain.GetBlock(block.ParentHash(), block.NumberU64()-1) {
+		epochID, _ := util.GetEpochSlotIDFromDifficulty(block.Difficulty())
+		sealer := sealers[epochID-start]
+		if block.Coinbase() != sealer.Address() {
+			t.Errorf("block %d of epoch %d sealed by %x, want %x", block.NumberU64(), epochID, block.Coinbase(), sealer.Address())
+		}
+		signer := types.MakeSigner(chain.Config(), block.Number())
+		for _, tx := range block.Transactions() {
+			from, err := types.Sender(signer, tx)
+			if err == nil && from == sealer.Address() && tx.To() != nil && *tx.To() == vm.SlotLeaderPrecompileAddr {
+				sent[epochID] = true
+			}
+		}
+	}
+	last := start + uint64(len(sealers)) - 1
+	if len(posdb.GetEpochLeaderGroup(last)) == 0 {
+		t.Fatalf("no epoch leaders elected for epoch %d", last)
+	}
+	for i, sealer := range sealers {
+		epochID := start + uint64(i)
+		if isEpochLeader(sealer, epochID) && !sent[epochID] {
+			t.Errorf("slot leader selection transactions of %x missing in epoch %d", sealer.Address(), epochID)
+		}
+	}
+}
+
+// isEpochLeader reports whether the validator is an elected epoch leader of
+// the epoch.
+func isEpochLeader(v *Validator, epochID uint64) bool {
+	pk := crypto.FromECDSAPub(v.PublicKey())
+	for _, leader := range posdb.GetEpochLeaderGroup(epochID) {
+		if bytes.Equal(leader, pk) {
+			return true
+		}
+	}
+	return false
+}
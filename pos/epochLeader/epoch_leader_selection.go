@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"math/big"
 	"sort"
-	"time"
 
 	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/pos/util"
@@ -130,7 +129,7 @@ func (e *Epocher) GetEpochLastBlkNumber(targetEpochId uint64) uint64 {
 	}
 
 	targetBlkNum := curNum
-	epochid, _ := util.CalEpochSlotID(uint64(time.Now().Unix()))
+	epochid, _ := util.CalEpochSlotID(uint64(util.Now().Unix()))
 	if targetEpochId < epochid && targetEpochId >= posconfig.FirstEpochId {
 		util.SetEpochBlock(targetEpochId, targetBlkNum, curBlockHeader.Hash())
 	}
@@ -272,6 +271,12 @@ func (e *Epocher) CalProbability(amountWin *big.Int, lockTime uint64) *big.Int {
 }
 
 func (e *Epocher) createStakerProbabilityArray(statedb *state.StateDB, epochID uint64) (ProposerSorter, error) {
+	return stakerProbabilityArray(statedb, epochID)
+}
+
+// stakerProbabilityArray returns the stakers of the state sorted by their
+// probability in the epoch, each probability accumulating the previous ones.
+func stakerProbabilityArray(statedb *state.StateDB, epochID uint64) (ProposerSorter, error) {
 	if statedb == nil {
 		return nil, vm.ErrUnknown
 	}
@@ -322,44 +327,75 @@ func (e *Epocher) epochLeaderSelection(r []byte, ps ProposerSorter, epochId uint
 		return ErrInvalidRandomProposerSelection
 	}
 
-	//the last one is total properties
-	tp := ps[len(ps)-1].Probabilities
-
-	var Byte0 = []byte{byte(0)}
-	var buffer bytes.Buffer
-	buffer.Write(Byte0)
-	buffer.Write(r)
-	r0 := buffer.Bytes()       //r0 = 0||r
-	cr := crypto.Keccak256(r0) //cr = hash(r0)
-
-	//randomProposerPublicKeys := make([]*ecdsa.PublicKey, 0)  //store the selected publickeys
 	log.Debug("epochLeaderSelection selecting")
 	selectionCount := posconfig.EpochLeaderCount
 	info, err := e.GetWhiteInfo(epochId)
 	if err == nil {
 		selectionCount = posconfig.EpochLeaderCount - int(info.WlCount.Uint64())
 	}
-	for i := 0; i < selectionCount; i++ {
+	for i, leader := range sampleProposers(0, r, ps, selectionCount) {
+		log.Debug("select epoch leader", "epochid=", epochId, "idx=", i, "pub=", leader.PubSec256)
+		val, err := rlp.EncodeToBytes(&leader)
+		if err != nil {
+			continue
+		}
+		e.epochLeadersDb.PutWithIndex(epochId, uint64(i), "", val)
+	}
+
+	return nil
+}
+
+// sampleProposers samples count proposers from ps based on proportion of
+// Probabilities, hashing prefix||r to seed the random sequence.
+func sampleProposers(prefix byte, r []byte, ps ProposerSorter, count int) []Proposer {
+	//the last one is total properties
+	tp := ps[len(ps)-1].Probabilities
+
+	var buffer bytes.Buffer
+	buffer.WriteByte(prefix)
+	buffer.Write(r)
+	cr := crypto.Keccak256(buffer.Bytes()) //cr = hash(prefix||r)
 
+	selected := make([]Proposer, 0, count)
+	for i := 0; i < count; i++ {
 		crBig := new(big.Int).SetBytes(cr)
 		crBig = crBig.Mod(crBig, tp) //cr_big = cr mod tp
 
-		//fmt.Println("epoch leader mod tp=" + common.ToHex(crBig.Bytes()))
 		//select pki whose probability bigger than cr_big left
 		idx := sort.Search(len(ps), func(i int) bool { return ps[i].Probabilities.Cmp(crBig) > 0 })
-
-		log.Debug("select epoch leader", "epochid=", epochId, "idx=", i, "pub=", ps[idx].PubSec256)
-		//randomProposerPublicKeys = append(randomProposerPublicKeys, ps[idx].PubSec256)
-		val, err := rlp.EncodeToBytes(&ps[idx])
-		if err != nil {
-			continue
-		}
-		e.epochLeadersDb.PutWithIndex(epochId, uint64(i), "", val)
+		selected = append(selected, ps[idx])
 
 		cr = crypto.Keccak256(cr)
 	}
+	return selected
+}
+
+// EpochLeadersAt selects the leaders of an epoch from the state at the end of
+// the epoch two before, as SelectLeadersLoop and GetEpochLeaders do, without
+// storing them.
+func EpochLeadersAt(statedb *state.StateDB, epochID uint64) [][]byte {
+	epochIdIn := epochID
+	if epochIdIn > 0 {
+		epochIdIn--
+	}
+	rb := vm.GetR(statedb, epochIdIn)
+	if rb == nil {
+		rb = new(big.Int).SetBytes(crypto.Keccak256(big.NewInt(1).Bytes()))
+	}
 
-	return nil
+	info := vm.GetEpochWLInfo(statedb, epochID)
+	wa := posconfig.EpochLeadersHold[info.WlIndex.Uint64() : info.WlIndex.Uint64()+info.WlCount.Uint64()]
+
+	leaders := make([][]byte, 0, posconfig.EpochLeaderCount)
+	if ps, err := stakerProbabilityArray(statedb, epochID); err == nil && len(ps) != 0 {
+		for _, leader := range sampleProposers(0, rb.Bytes(), ps, posconfig.EpochLeaderCount-len(wa)) {
+			leaders = append(leaders, leader.PubSec256)
+		}
+	}
+	if len(leaders) == posconfig.EpochLeaderCount-len(wa) {
+		leaders = append(leaders, wa...)
+	}
+	return leaders
 }
 
 func (e *Epocher) GetWhiteInfo(epochId uint64) (*vm.UpgradeWhiteEpochLeaderParam, error) {
@@ -403,34 +439,15 @@ func (e *Epocher) randomProposerSelection(r []byte, ps ProposerSorter, epochId u
 		return ErrInvalidEpochProposerSelection
 	}
 
-	//the last one is total properties
-	tp := ps[len(ps)-1].Probabilities
-
-	var Byte1 = []byte{byte(1)}
-	var buffer bytes.Buffer
-	buffer.Write(Byte1)
-	buffer.Write(r)
-	r1 := buffer.Bytes()       //r1 = 1||r
-	cr := crypto.Keccak256(r1) //cr = hash(r1)
-
 	log.Info("random proposer selecting...\n")
-	for i := 0; i < posconfig.RandomProperCount; i++ {
-
-		crBig := new(big.Int).SetBytes(cr)
-		crBig = crBig.Mod(crBig, tp) //cr_big = cr mod tp
-
-		//select pki whose probability bigger than cr_big left
-		idx := sort.Search(len(ps), func(i int) bool { return ps[i].Probabilities.Cmp(crBig) > 0 })
-
-		val, err := rlp.EncodeToBytes(ps[idx])
+	for i, proposer := range sampleProposers(1, r, ps, posconfig.RandomProperCount) {
+		val, err := rlp.EncodeToBytes(proposer)
 
 		if err != nil {
 			continue
 		}
 
 		e.rbLeadersDb.PutWithIndex(epochId, uint64(i), "", val)
-
-		cr = crypto.Keccak256(cr)
 	}
 
 	return nil
@@ -723,9 +740,19 @@ func saveStakeOut(stakeOutInfo []RefundInfo, epochID uint64) error {
 	log.Info("Save refund information done.","epochID",epochID)
 	return nil
 }
-func coreTransfer(db vm.StateDB, sender, recipient common.Address, amount *big.Int) {
-	if core.CanTransfer(db, sender, amount) {
-		core.Transfer(db, sender, recipient,amount)
+// coreTransfer refunds a stake from the staking contract and records it as a
+// system transfer of the block.
+func coreTransfer(db *state.StateDB, recipient, validator common.Address, amount *big.Int, epochID uint64, reason types.SystemTransferReason) {
+	if core.CanTransfer(db, vm.WanCscPrecompileAddr, amount) {
+		core.Transfer(db, vm.WanCscPrecompileAddr, recipient, amount)
+		db.AddSystemTransfer(&types.SystemTransfer{
+			Reason:    reason,
+			From:      vm.WanCscPrecompileAddr,
+			Recipient: recipient,
+			Validator: validator,
+			Epoch:     epochID,
+			Amount:    new(big.Int).Set(amount),
+		})
 	}
 }
 func isInactiveValidator(state *state.StateDB, addr common.Address, baseEpochId uint64) bool{
@@ -753,12 +780,12 @@ func CleanInactiveValidator(stateDb *state.StateDB, epochID uint64){
 		if isInactiveValidator(stateDb, staker.Address, epochID) {
 			log.Info("CleanInactiveValidator", "address",staker.Address)
 			for j := 0; j < len(staker.Clients); j++ {
-				coreTransfer(stateDb, vm.WanCscPrecompileAddr, staker.Clients[j].Address, staker.Clients[j].Amount)
+				coreTransfer(stateDb, staker.Clients[j].Address, staker.Address, staker.Clients[j].Amount, epochID, types.SystemTransferInactiveValidator)
 			}
 			for j := 0; j < len(staker.Partners); j++ {
-				coreTransfer(stateDb, vm.WanCscPrecompileAddr, staker.Partners[j].Address, staker.Partners[j].Amount)
+				coreTransfer(stateDb, staker.Partners[j].Address, staker.Address, staker.Partners[j].Amount, epochID, types.SystemTransferInactiveValidator)
 			}
-			coreTransfer(stateDb, vm.WanCscPrecompileAddr, staker.From, staker.Amount)
+			coreTransfer(stateDb, staker.From, staker.Address, staker.Amount, epochID, types.SystemTransferInactiveValidator)
 			key := vm.GetStakeInKeyHash(staker.Address)
 
 			vm.UpdateInfo(stateDb, vm.StakersInfoAddr, key, nil)
@@ -818,7 +845,7 @@ func StakeOutRun(stateDb *state.StateDB, epochID uint64) bool {
 		for j := 0; j < len(staker.Clients); j++ {
 			// edit the validator Amount
 			if epochID >= staker.Clients[j].QuitEpoch && staker.Clients[j].QuitEpoch != 0 {
-				coreTransfer(stateDb, vm.WanCscPrecompileAddr, staker.Clients[j].Address, staker.Clients[j].Amount)
+				coreTransfer(stateDb, staker.Clients[j].Address, staker.Address, staker.Clients[j].Amount, epochID, types.SystemTransferStakeOut)
 				stakeOutInfo = recordStakeOut(stakeOutInfo, staker.Clients[j].Address, staker.Clients[j].Amount)
 				clientChanged = true
 			} else {
@@ -835,7 +862,7 @@ func StakeOutRun(stateDb *state.StateDB, epochID uint64) bool {
 		for j := 0; j < len(staker.Partners); j++ {
 			// edit the validator Amount
 			if epochID >= staker.Partners[j].StakingEpoch+staker.Partners[j].LockEpochs {
-				coreTransfer(stateDb, vm.WanCscPrecompileAddr, staker.Partners[j].Address, staker.Partners[j].Amount)
+				coreTransfer(stateDb, staker.Partners[j].Address, staker.Address, staker.Partners[j].Amount, epochID, types.SystemTransferStakeOut)
 				stakeOutInfo = recordStakeOut(stakeOutInfo, staker.Partners[j].Address, staker.Partners[j].Amount)
 				partnerchanged = true
 			} else {
@@ -848,16 +875,16 @@ func StakeOutRun(stateDb *state.StateDB, epochID uint64) bool {
 
 		if epochID >= staker.StakingEpoch+staker.LockEpochs {
 			for j := 0; j < len(staker.Clients); j++ {
-				coreTransfer(stateDb, vm.WanCscPrecompileAddr, staker.Clients[j].Address, staker.Clients[j].Amount)
+				coreTransfer(stateDb, staker.Clients[j].Address, staker.Address, staker.Clients[j].Amount, epochID, types.SystemTransferStakeOut)
 				stakeOutInfo = recordStakeOut(stakeOutInfo,staker.Clients[j].Address, staker.Clients[j].Amount)
 			}
 			for j := 0; j < len(staker.Partners); j++ {
-				coreTransfer(stateDb, vm.WanCscPrecompileAddr, staker.Partners[j].Address, staker.Partners[j].Amount)
+				coreTransfer(stateDb, staker.Partners[j].Address, staker.Address, staker.Partners[j].Amount, epochID, types.SystemTransferStakeOut)
 				stakeOutInfo = recordStakeOut(stakeOutInfo, staker.Partners[j].Address, staker.Partners[j].Amount)
 			}
 			key := vm.GetStakeInKeyHash(staker.Address)
 			// quit the validator
-			coreTransfer(stateDb, vm.WanCscPrecompileAddr, staker.From, staker.Amount)
+			coreTransfer(stateDb, staker.From, staker.Address, staker.Amount, epochID, types.SystemTransferStakeOut)
 			stakeOutInfo = recordStakeOut(stakeOutInfo, staker.From, staker.Amount)
 			vm.UpdateInfo(stateDb, vm.StakersInfoAddr, key, nil)
 			newFeeBytes, err := vm.GetInfo(stateDb, vm.StakersFeeAddr, key)
@@ -162,7 +162,7 @@ func newTestBlockChain(fake bool) (*core.BlockChain, *core.ChainEnv) {
 func TestGetEpochLeaders(t *testing.T) {
 	var networkId uint64
 	networkId = 6
-	posconfig.Init(nil,networkId)
+	posconfig.Init(nil, networkId, nil)
 	epochID, slotID := util.GetEpochSlotID()
 	fmt.Println("epochID:", epochID, " slotID:", slotID)
 
@@ -36,7 +36,7 @@ func (t *TestChainReader) GetBlock(hash common.Hash, number uint64) *types.Block
 func (t *TestSelectLead) GetCurrentHeader() *types.Header {return nil}
 
 func TestGetSlotLeaderActivity(t *testing.T) {
-	posconfig.Init(nil, 4)
+	posconfig.Init(nil, 4, nil)
 	activityInit()
 	generateTestAddrs()
 	generateTestStaker()
@@ -86,7 +86,7 @@ func (t *TestSelectLead) GetRBProposerG1(epochID uint64) []bn256.G1 { return nil
 func (t *TestSelectLead) GetEpochLastBlkNumber(epochID uint64) uint64 { return 0 }
 
 func TestGetEpochLeaderAddressAndActivity(t *testing.T) {
-	posconfig.Init(nil, 4)
+	posconfig.Init(nil, 4, nil)
 	activityInit()
 	epochID := uint64(0)
 	util.SetEpocherInst(&TestSelectLead{})
@@ -142,7 +142,7 @@ func testSimulateData(epochID uint64, index uint32) {
 }
 
 func TestGetRandomProposerActivity(t *testing.T) {
-	posconfig.Init(nil, 4)
+	posconfig.Init(nil, 4, nil)
 	activityInit()
 	//test bad input
 	clearTestAddrs()
@@ -186,7 +186,7 @@ func TestGetRandomProposerActivity(t *testing.T) {
 }
 
 func TestWhiteList(t *testing.T) {
-	posconfig.Init(nil, 4)
+	posconfig.Init(nil, 4, nil)
 	activityInit()
 	if isInWhiteList(common.HexToAddress("0xb0Daf2a0a61B0f721486D3B88235a0714D60bAa6")) {
 		t.FailNow()
@@ -204,5 +204,30 @@ func GetEpochRBLeaderActivity(stateDb vm.StateDB, epochID uint64) ([]common.Addr
 
 // GetSlotLeaderActivity can get the address, blockCnt, and activity of slotleader
 func GetSlotLeaderActivity(chain consensus.ChainReader, epochID uint64) ([]common.Address, []int, float64, int) {
-	return getSlotLeaderActivity(chain, epochID, posconfig.SlotCount)
+	return getSlotLeaderActivity(chain, epochID, int(posconfig.SlotCount))
+}
+
+// EstimateValidatorIncentive estimates what the validator and its delegators
+// would be paid in epochID, assuming the validator wins its share of every
+// role (its probability over the total probability) and is fully active.
+// The gas pool of future epochs is unknown, only the foundation subsidy is used.
+func EstimateValidatorIncentive(stateDb *state.StateDB, epochID uint64, validator *vm.ValidatorInfo,
+	totalProbability *big.Int) []vm.ClientIncentive {
+	if stateDb == nil || validator == nil || len(validator.Infos) == 0 ||
+		totalProbability == nil || totalProbability.Sign() <= 0 {
+		return nil
+	}
+
+	foundation := calcWanFromFoundation(stateDb, epochID)
+	percentOfEpochLeader, percentOfRandomProposer, percentOfSlotLeader := calcIncentivePercent(stateDb, epochID)
+
+	value := big.NewInt(0)
+	value.Add(value, calcPercent(foundation, percentOfEpochLeader*100.0))
+	value.Add(value, calcPercent(foundation, percentOfRandomProposer*100.0))
+	value.Add(value, calcPercent(foundation, percentOfSlotLeader*100.0))
+	value.Mul(value, validator.TotalProbability)
+	value.Div(value, totalProbability)
+
+	incentives, _ := delegateDivision(validator.ValidatorAddr, value, validator.Infos, validator.FeeRate, validator.TotalProbability)
+	return incentives
 }
@@ -6,8 +6,10 @@ import (
 	"math/big"
 
 	"github.com/wanchain/go-wanchain/consensus"
+	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/core/vm"
 	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/metrics"
 
 	"github.com/wanchain/go-wanchain/pos/posconfig"
 
@@ -22,12 +24,17 @@ import (
 var (
 	redutionYears            = 1
 	redutionRateBase         = 0.88                                                                                   //88% redution for every year
-	subsidyReductionInterval = uint64((365 * 24 * 3600 * redutionYears) / (posconfig.SlotTime * posconfig.SlotCount)) // Epoch count in 1 years
+	subsidyReductionInterval = reductionInterval()                                                                    // Epoch count in 1 years
 	ceilingPercentS0         = 100.0                                                                                  //100% Turn off in current version.
 	openIncentive            = true                                                                                   //If the incentive function is open
 	firstPeriodReward        = big.NewInt(0).Mul(big.NewInt(2.5e6), big.NewInt(1e18))                                 // 2500000 wan coin for first year
 )
 
+var (
+	paidEpochGauge = metrics.NewGauge("pos/incentive/epoch")       // Last epoch whose incentive was paid
+	paidGauge      = metrics.NewGaugeFloat64("pos/incentive/paid") // Incentive paid for that epoch, in wan
+)
+
 const (
 	dictGasCollection = "gas_collection"
 	dictEpochRun      = "epoch_run"
@@ -43,6 +50,7 @@ func Init(get GetStakerInfoFn, set SetStakerInfoFn, getRbAddr GetRandomProposerA
 		log.SyslogErr("incentive Init input param error (get == nil || set == nil || getRbAddr == nil)")
 	}
 
+	subsidyReductionInterval = reductionInterval()
 	setStakerInterface(get, set)
 	setActivityInterface(getEpochLeaderActivity, getRandomProposerActivity, getSlotLeaderActivity)
 	setRBAddressInterface(getRbAddr)
@@ -51,6 +59,12 @@ func Init(get GetStakerInfoFn, set SetStakerInfoFn, getRbAddr GetRandomProposerA
 	log.Info("--------Incentive Init Finish----------")
 }
 
+// reductionInterval returns the epoch count of a subsidy reduction period
+// under the PoS schedule of the chain.
+func reductionInterval() uint64 {
+	return uint64(365*24*3600*redutionYears) / (posconfig.SlotTime * posconfig.SlotCount)
+}
+
 // Run is use to run the incentive should be called in Finalize of consensus
 func Run(chain consensus.ChainReader, stateDb *state.StateDB, epochID uint64) bool {
 	if chain == nil || stateDb == nil {
@@ -72,7 +86,7 @@ func Run(chain consensus.ChainReader, stateDb *state.StateDB, epochID uint64) bo
 	rpAddrs, rpAct := getRandomProposerInfo(stateDb, epochID)
 	log.Info("rp Addrs", "len", len(rpAddrs))
 
-	slAddrs, slBlk, slAct, ctrlCount := getSlotLeaderInfo(chain, epochID, posconfig.SlotCount)
+	slAddrs, slBlk, slAct, ctrlCount := getSlotLeaderInfo(chain, epochID, int(posconfig.SlotCount))
 	log.Info("sl Addr ", "len", len(slAddrs), "slAct", slAct, "ctrlCount", ctrlCount)
 	log.Info("sl Blk ", "len", len(slBlk), "blks", slBlk)
 
@@ -120,7 +134,7 @@ func Run(chain consensus.ChainReader, stateDb *state.StateDB, epochID uint64) bo
 
 	remainsAll.Add(remainsAll, remains)
 
-	incentives, remains, err = slotLeaderAllocate(slotLeaderSubsidy, slAddrs, slBlk, slAct, posconfig.SlotCount-ctrlCount, epochID)
+	incentives, remains, err = slotLeaderAllocate(slotLeaderSubsidy, slAddrs, slBlk, slAct, int(posconfig.SlotCount)-ctrlCount, epochID)
 	if err != nil {
 		log.SyslogErr("Incentive slotLeaderAllocate error", "slotLeaderSubsidy", slotLeaderSubsidy.String(), "slAddrs", slAddrs)
 		return false
@@ -146,7 +160,10 @@ func Run(chain consensus.ChainReader, stateDb *state.StateDB, epochID uint64) bo
 	addRemainIncentivePool(stateDb, epochID, remainsAll)
 	saveRemain(epochID, remainsAll)
 
-	pay(finalIncentive, stateDb)
+	pay(finalIncentive, stateDb, epochID)
+	paidEpochGauge.Update(int64(epochID))
+	paid, _ := new(big.Float).Quo(new(big.Float).SetInt(sumPay), big.NewFloat(1e18)).Float64()
+	paidGauge.Update(paid)
 
 	setStakerInfo(epochID, finalIncentive)
 	saveIncentiveHistory(epochID, finalIncentive)
@@ -295,10 +312,17 @@ func checkTotalValue(total *big.Int, sumPay, remain *big.Int) bool {
 	return true
 }
 
-func pay(incentives [][]vm.ClientIncentive, stateDb *state.StateDB) {
+func pay(incentives [][]vm.ClientIncentive, stateDb *state.StateDB, epochID uint64) {
 	for i := 0; i < len(incentives); i++ {
 		for m := 0; m < len(incentives[i]); m++ {
 			stateDb.AddBalance(incentives[i][m].WalletAddr, incentives[i][m].Incentive)
+			stateDb.AddSystemTransfer(&types.SystemTransfer{
+				Reason:    types.SystemTransferIncentive,
+				Recipient: incentives[i][m].WalletAddr,
+				Validator: incentives[i][m].ValidatorAddr,
+				Epoch:     epochID,
+				Amount:    new(big.Int).Set(incentives[i][m].Incentive),
+			})
 		}
 	}
 }
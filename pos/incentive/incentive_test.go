@@ -19,7 +19,7 @@ var (
 )
 
 func TestRun(t *testing.T) {
-	posconfig.Init(nil, 4)
+	posconfig.Init(nil, 4, nil)
 	Init(getInfo, setInfo, testGetRBAddress)
 	TestSetActivityInterface(t)
 	TestSetStakerInterface(t)
@@ -27,7 +27,7 @@ func TestRun(t *testing.T) {
 	testTimes := 1
 
 	for i := 0; i < testTimes; i++ {
-		for m := 0; m < posconfig.SlotCount; m++ {
+		for m := 0; m < int(posconfig.SlotCount); m++ {
 			if !Run(&TestChainReader{}, statedb, uint64(i)) {
 				t.FailNow()
 			}
@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sort"
-	"time"
 
 	"github.com/wanchain/go-wanchain/core/types"
 
@@ -73,7 +72,7 @@ func APIs(chain PosChainReader, backend ethapi.Backend) []rpc.API {
 	return []rpc.API{{
 		Namespace: "pos",
 		Version:   "1.0",
-		Service:   &PosApi{chain, backend},
+		Service:   &PosApi{chain: chain, backend: backend},
 		Public:    true,
 	}}
 }
@@ -328,6 +327,31 @@ func (a PosApi) GetRbSignatureCount(epochId uint64, blockNr int64) (int, error)
 	return j, nil
 }
 
+// VerifyRandom checks the random beacon protocol of epochID on the latest
+// state and rebuilds the random number of the next epoch independently.
+func (a PosApi) VerifyRandom(epochID uint64) (*vm.RbVerifyResult, error) {
+	if !isPosStage() {
+		return nil, nil
+	}
+
+	epocherInst := epochLeader.GetEpocher()
+	if epocherInst == nil {
+		return nil, errors.New("epocher instance does not exist")
+	}
+
+	state, _, err := a.backend.StateAndHeaderByNumber(context.Background(), rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return vm.VerifyRandom(state, epochID, epocherInst.GetRBProposerG1(epochID))
+}
+
+// GetEquivocations returns the evidence of slot leaders which sealed two
+// different blocks for the same slot of epochID.
+func (a PosApi) GetEquivocations(epochID uint64) []*posdb.Equivocation {
+	return posdb.GetEquivocations(epochID)
+}
+
 func (a PosApi) GetEpochStakerInfo(epochID uint64, addr common.Address) (ApiStakerInfo, error) {
 	skInfo := ApiStakerInfo{}
 	epocherInst := epochLeader.GetEpocher()
@@ -692,21 +716,21 @@ func (a PosApi) GetValidatorActivity(epochID uint64) (*ValidatorActivity, error)
 }
 
 func (a PosApi) GetEpochID() uint64 {
-	ep, _ := util.CalEpochSlotID(uint64(time.Now().Unix()))
+	ep, _ := util.CalEpochSlotID(uint64(util.Now().Unix()))
 	return ep
 }
 
 func (a PosApi) GetSlotID() uint64 {
-	_, sl := util.CalEpochSlotID(uint64(time.Now().Unix()))
+	_, sl := util.CalEpochSlotID(uint64(util.Now().Unix()))
 	return sl
 }
 
 func (a PosApi) GetSlotCount() int {
-	return posconfig.SlotCount
+	return int(posconfig.SlotCount)
 }
 
 func (a PosApi) GetSlotTime() int {
-	return posconfig.SlotTime
+	return int(posconfig.SlotTime)
 }
 
 func (a PosApi) GetMaxStableBlkNumber() uint64 {
@@ -0,0 +1,104 @@
+package posapi
+
+import (
+	"errors"
+)
+
+const (
+	// maxEpochRangeLimit is the largest number of epochs a single range query returns.
+	maxEpochRangeLimit = 128
+)
+
+var (
+	errInvalidEpochRange = errors.New("fromEpoch must not be greater than toEpoch")
+)
+
+// epochRange walks [fromEpoch, toEpoch] calling fn for at most limit epochs.
+// The returned result carries a NextEpoch cursor when the range was truncated,
+// which the caller passes back as fromEpoch to fetch the following page.
+func epochRange(fromEpoch, toEpoch uint64, limit *uint64, fn func(epochID uint64) (interface{}, error)) (*EpochRangeResult, error) {
+	if fromEpoch > toEpoch {
+		return nil, errInvalidEpochRange
+	}
+
+	pageSize := uint64(maxEpochRangeLimit)
+	if limit != nil && *limit != 0 && *limit < pageSize {
+		pageSize = *limit
+	}
+
+	ret := &EpochRangeResult{
+		FromEpoch: fromEpoch,
+		Items:     make([]EpochRangeItem, 0),
+	}
+	epochID := fromEpoch
+	for ; epochID <= toEpoch && uint64(len(ret.Items)) < pageSize; epochID++ {
+		value, err := fn(epochID)
+		if err != nil {
+			return nil, err
+		}
+		ret.Items = append(ret.Items, EpochRangeItem{EpochID: epochID, Result: value})
+		if epochID == maxUint64 {
+			break
+		}
+	}
+	ret.ToEpoch = fromEpoch + uint64(len(ret.Items)) - 1
+	if ret.ToEpoch < toEpoch {
+		next := ret.ToEpoch + 1
+		ret.NextEpoch = &next
+	}
+	return ret, nil
+}
+
+// GetEpochStakerInfoAllRange returns GetEpochStakerInfoAll for each epoch in [fromEpoch, toEpoch].
+func (a PosApi) GetEpochStakerInfoAllRange(fromEpoch, toEpoch uint64, limit *uint64) (*EpochRangeResult, error) {
+	return epochRange(fromEpoch, toEpoch, limit, func(epochID uint64) (interface{}, error) {
+		return a.GetEpochStakerInfoAll(epochID)
+	})
+}
+
+// GetEpochIncentivePayDetailRange returns GetEpochIncentivePayDetail for each epoch in [fromEpoch, toEpoch].
+func (a PosApi) GetEpochIncentivePayDetailRange(fromEpoch, toEpoch uint64, limit *uint64) (*EpochRangeResult, error) {
+	return epochRange(fromEpoch, toEpoch, limit, func(epochID uint64) (interface{}, error) {
+		return a.GetEpochIncentivePayDetail(epochID)
+	})
+}
+
+// GetActivityRange returns GetActivity for each epoch in [fromEpoch, toEpoch].
+func (a PosApi) GetActivityRange(fromEpoch, toEpoch uint64, limit *uint64) (*EpochRangeResult, error) {
+	return epochRange(fromEpoch, toEpoch, limit, func(epochID uint64) (interface{}, error) {
+		return a.GetActivity(epochID)
+	})
+}
+
+// GetEpochBlkCntRange returns GetEpochBlkCnt for each epoch in [fromEpoch, toEpoch].
+func (a PosApi) GetEpochBlkCntRange(fromEpoch, toEpoch uint64, limit *uint64) (*EpochRangeResult, error) {
+	return epochRange(fromEpoch, toEpoch, limit, func(epochID uint64) (interface{}, error) {
+		return a.GetEpochBlkCnt(epochID)
+	})
+}
+
+// GetEpochLeadersAddrRange returns GetEpochLeadersAddrByEpochID for each epoch in [fromEpoch, toEpoch].
+func (a PosApi) GetEpochLeadersAddrRange(fromEpoch, toEpoch uint64, limit *uint64) (*EpochRangeResult, error) {
+	return epochRange(fromEpoch, toEpoch, limit, func(epochID uint64) (interface{}, error) {
+		return a.GetEpochLeadersAddrByEpochID(epochID)
+	})
+}
+
+// GetRandomRange returns the random number of each epoch in [fromEpoch, toEpoch] at the latest block.
+// Epochs without a random number yield a nil result instead of failing the whole page.
+func (a PosApi) GetRandomRange(fromEpoch, toEpoch uint64, limit *uint64) (*EpochRangeResult, error) {
+	return epochRange(fromEpoch, toEpoch, limit, func(epochID uint64) (interface{}, error) {
+		r, err := a.GetRandom(epochID, -1)
+		if err != nil {
+			return nil, nil
+		}
+		return r, nil
+	})
+}
+
+// GetEpochSummaryRange returns GetEpochSummary for each epoch in [fromEpoch, toEpoch].
+func (a PosApi) GetEpochSummaryRange(fromEpoch, toEpoch uint64, limit *uint64) (*EpochRangeResult, error) {
+	return epochRange(fromEpoch, toEpoch, limit, func(epochID uint64) (interface{}, error) {
+		return a.GetEpochSummary(epochID)
+	})
+}
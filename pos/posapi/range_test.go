@@ -0,0 +1,45 @@
+package posapi
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEpochRangePaging(t *testing.T) {
+	limit := uint64(3)
+	ret, err := epochRange(10, 16, &limit, func(epochID uint64) (interface{}, error) {
+		return epochID * 2, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ret.Items) != 3 || ret.FromEpoch != 10 || ret.ToEpoch != 12 {
+		t.Fatalf("unexpected page: from %d to %d items %d", ret.FromEpoch, ret.ToEpoch, len(ret.Items))
+	}
+	if ret.NextEpoch == nil || *ret.NextEpoch != 13 {
+		t.Fatal("next epoch cursor should be 13")
+	}
+	if ret.Items[2].EpochID != 12 || ret.Items[2].Result.(uint64) != 24 {
+		t.Fatal("unexpected item", ret.Items[2])
+	}
+
+	ret, err = epochRange(13, 16, nil, func(epochID uint64) (interface{}, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ret.Items) != 4 || ret.NextEpoch != nil {
+		t.Fatal("last page should hold all remaining epochs without cursor")
+	}
+}
+
+func TestEpochRangeErrors(t *testing.T) {
+	if _, err := epochRange(5, 4, nil, nil); err != errInvalidEpochRange {
+		t.Fatal("expected invalid range error, got", err)
+	}
+	want := errors.New("fail")
+	if _, err := epochRange(1, 2, nil, func(uint64) (interface{}, error) { return nil, want }); err != want {
+		t.Fatal("expected callback error, got", err)
+	}
+}
@@ -0,0 +1,150 @@
+package posapi
+
+import (
+	"context"
+
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/internal/ethapi"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/util"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+// GetEpochSummary collects the leaders, random number, incentive pay and activity of an epoch.
+func (a PosApi) GetEpochSummary(epochID uint64) (*EpochSummary, error) {
+	if !isPosStage() {
+		return nil, nil
+	}
+
+	summary := &EpochSummary{EpochID: epochID}
+
+	epLeaders, err := a.GetEpochLeadersAddrByEpochID(epochID)
+	if err != nil {
+		return nil, err
+	}
+	summary.EpochLeaders = epLeaders
+
+	rbLeaders, err := a.GetRandomProposersAddrByEpochID(epochID)
+	if err != nil {
+		return nil, err
+	}
+	summary.RandomProposers = rbLeaders
+
+	if r, err := a.GetRandom(epochID, -1); err == nil && r != nil {
+		summary.Random = (*hexutil.Big)(r)
+	}
+
+	if number, err := a.GetEpochIncentiveBlockNumber(epochID); err == nil {
+		summary.IncentiveBlockNumber = number
+	}
+	if total, err := a.GetEpochIncentive(epochID); err == nil {
+		summary.TotalIncentive = total
+	}
+	summary.IncentivePay, err = a.GetEpochIncentivePayDetail(epochID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The activity is read from the current state, which may be unavailable
+	// while the rest of the summary is not, so it only marks the field.
+	if summary.Activity, err = a.GetActivity(epochID); err != nil {
+		log.Debug("pos epoch summary activity unavailable", "epochID", epochID, "err", err)
+		summary.Activity, summary.ActivityUnavailable = nil, true
+	}
+
+	return summary, nil
+}
+
+// finalizedEpoch returns the latest epoch whose incentive has been paid at header,
+// and false if no epoch is final yet.
+func finalizedEpoch(header *types.Header) (uint64, bool) {
+	epochID, slotID := util.GetEpochSlotIDFromDifficulty(header.Difficulty)
+	delay := uint64(posconfig.IncentiveDelayEpochs)
+	if slotID <= posconfig.IncentiveStartStage {
+		delay++
+	}
+	if epochID < delay {
+		return 0, false
+	}
+	return epochID - delay, true
+}
+
+// EpochSummary creates a subscription that pushes an epoch summary each time an epoch
+// is finalized, that is once its incentive has been paid in the canonical chain.
+func (a PosApi) EpochSummary(ctx context.Context) (*rpc.Subscription, error) {
+	return (&epochSummaries{chain: a.chain, backend: a.backend, summarizer: a}).subscribe(ctx)
+}
+
+// epochSummarizer is the source of the summaries pushed by epochSummaries.
+type epochSummarizer interface {
+	GetEpochSummary(epochID uint64) (*EpochSummary, error)
+}
+
+// epochSummaries pushes the summary of each epoch finalized by the chain heads
+// of backend to a subscription.
+type epochSummaries struct {
+	chain      PosChainReader
+	backend    ethapi.Backend
+	summarizer epochSummarizer
+}
+
+func (s *epochSummaries) subscribe(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		heads := make(chan core.ChainHeadEvent, 16)
+		headsSub := s.backend.SubscribeChainHeadEvent(heads)
+		defer headsSub.Unsubscribe()
+
+		var (
+			last    uint64
+			hasLast bool
+		)
+		if header := s.chain.CurrentHeader(); header != nil {
+			last, hasLast = finalizedEpoch(header)
+		}
+
+		for {
+			select {
+			case ev := <-heads:
+				if !isPosStage() || ev.Block == nil {
+					continue
+				}
+				epochID, ok := finalizedEpoch(ev.Block.Header())
+				if !ok {
+					continue
+				}
+				from := epochID
+				if hasLast {
+					if epochID <= last {
+						continue
+					}
+					from = last + 1
+				}
+				for id := from; id <= epochID; id++ {
+					summary, err := s.summarizer.GetEpochSummary(id)
+					if err != nil {
+						log.Debug("pos epoch summary failed", "epochID", id, "err", err)
+						continue
+					}
+					notifier.Notify(rpcSub.ID, summary)
+				}
+				last, hasLast = epochID, true
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -0,0 +1,107 @@
+package posapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/event"
+	"github.com/wanchain/go-wanchain/internal/ethapi"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+type headsBackend struct {
+	ethapi.Backend
+	feed event.Feed
+}
+
+func (b *headsBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.feed.Subscribe(ch)
+}
+
+type headChain struct {
+	PosChainReader
+	head *types.Header
+}
+
+func (c *headChain) CurrentHeader() *types.Header { return c.head }
+
+// fixedSummaries summarizes every epoch without reading the chain.
+type fixedSummaries struct{}
+
+func (fixedSummaries) GetEpochSummary(epochID uint64) (*EpochSummary, error) {
+	return &EpochSummary{EpochID: epochID, ActivityUnavailable: true}, nil
+}
+
+// summaryService serves the epochSummary subscription of the pos namespace.
+type summaryService struct {
+	summaries *epochSummaries
+}
+
+func (s summaryService) EpochSummary(ctx context.Context) (*rpc.Subscription, error) {
+	return s.summaries.subscribe(ctx)
+}
+
+func posHeader(number, epochID, slotID uint64) *types.Header {
+	difficulty := new(big.Int).SetUint64(epochID<<32 | slotID<<8)
+	return &types.Header{Number: new(big.Int).SetUint64(number), Difficulty: difficulty}
+}
+
+func TestEpochSummarySubscription(t *testing.T) {
+	defer func(first uint64) { posconfig.FirstEpochId = first }(posconfig.FirstEpochId)
+	posconfig.FirstEpochId = 1
+
+	slotID := posconfig.IncentiveStartStage + 1
+	backend := &headsBackend{}
+	api := summaryService{&epochSummaries{
+		chain:      &headChain{head: posHeader(100, 5, slotID)},
+		backend:    backend,
+		summarizer: fixedSummaries{},
+	}}
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("pos", api); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	summaries := make(chan *EpochSummary, 4)
+	sub, err := client.Subscribe(context.Background(), "pos", summaries, "epochSummary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	// The subscription goroutine subscribes to the heads asynchronously
+	deadline := time.Now().Add(time.Second)
+	for backend.feed.Send(core.ChainHeadEvent{Block: types.NewBlockWithHeader(posHeader(101, 5, slotID+1))}) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("subscription did not listen to chain heads")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Epoch 5 is final once epoch 6 passes the incentive stage
+	backend.feed.Send(core.ChainHeadEvent{Block: types.NewBlockWithHeader(posHeader(200, 6, slotID))})
+
+	select {
+	case summary := <-summaries:
+		if summary.EpochID != 5 || !summary.ActivityUnavailable {
+			t.Fatalf("unexpected summary: %+v", summary)
+		}
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(time.Second):
+		t.Fatal("epoch summary was not delivered")
+	}
+	select {
+	case summary := <-summaries:
+		t.Fatalf("unexpected second summary for epoch %d", summary.EpochID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -178,3 +178,31 @@ func ToStakerJson(staker *vm.StakerInfo) *StakerJson {
 
 	return &stakeJson
 }
+
+// EpochRangeItem is the result of a single epoch inside a range query.
+type EpochRangeItem struct {
+	EpochID uint64      `json:"epochId"`
+	Result  interface{} `json:"result"`
+}
+
+// EpochRangeResult is one page of a range query. NextEpoch is set when more
+// epochs remain and is used as fromEpoch of the next request.
+type EpochRangeResult struct {
+	FromEpoch uint64           `json:"fromEpoch"`
+	ToEpoch   uint64           `json:"toEpoch"`
+	NextEpoch *uint64          `json:"nextEpoch,omitempty"`
+	Items     []EpochRangeItem `json:"items"`
+}
+
+// EpochSummary is pushed by the pos epochSummary subscription once an epoch is final.
+type EpochSummary struct {
+	EpochID              uint64           `json:"epochId"`
+	EpochLeaders         []common.Address `json:"epochLeaders"`
+	RandomProposers      []common.Address `json:"randomProposers"`
+	Random               *hexutil.Big     `json:"random"`
+	IncentiveBlockNumber uint64           `json:"incentiveBlockNumber"`
+	TotalIncentive       string           `json:"totalIncentive"`
+	IncentivePay         []ValidatorInfo  `json:"incentivePay"`
+	Activity             *Activity        `json:"activity"`
+	ActivityUnavailable  bool             `json:"activityUnavailable,omitempty"`
+}
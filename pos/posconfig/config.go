@@ -3,6 +3,7 @@ package posconfig
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"fmt"
 	"math/big"
 
 	"github.com/wanchain/go-wanchain/accounts/keystore"
@@ -12,6 +13,7 @@ import (
 	bn256 "github.com/wanchain/go-wanchain/crypto/bn256/cloudflare"
 
 	"github.com/wanchain/go-wanchain/node"
+	"github.com/wanchain/go-wanchain/params"
 )
 
 var (
@@ -33,6 +35,7 @@ const (
 	PosLocalDB       = "pos"
 	IncentiveLocalDB = "incentive"
 	ReorgLocalDB     = "forkdb"
+	EquivocationLocalDB = "equivocation"
 	ApolloEpochID     = 18104
 	AugustEpochID     = 18116  //TODO change it as mainnet 8.8
 
@@ -54,58 +57,117 @@ const (
 	StakeOutEpochKey  = "StakeOutEpochKey"
 )
 const (
-	// SlotTime is the time span of a slot in second, So it's 1 hours for a epoch
-	SlotTime = 5
+	// DefaultSlotTime is the time span of a slot in second of the mainnet
+	// schedule, So it's 1 hours for a epoch
+	DefaultSlotTime = 5
+	// DefaultK is the slot count of a stage of the mainnet schedule
+	DefaultK = 1440
+	// DefaultRBThres is the random beacon signature threshold
+	DefaultRBThres = 13
+	// DefaultPolymDegree is the degree of the random beacon dkg polynomial
+	DefaultPolymDegree = 12
 
 	//Incentive should perform delay some epochs.
 	IncentiveDelayEpochs = 1
-	IncentiveStartStage  = Stage2K
 
 	// K count of each epoch
 	KCount = 12
-	K      = 1440
+
+	MinimumChainQuality     = 0.5 //BlockSecurityParam / SlotSecurityParam
+	CriticalReorgThreshold  = 3
+	CriticalChainQuality    = 0.618
+	NonCriticalChainQuality = 0.8
+
+	MainnetMercuryEpochId = 18250 //2019.12.20
+	TestnetMercuryEpochId = 18246 //2019.12.16
+
+	MainnetVenusEpochId = 11112222
+	TestnetVenusEpochId = 18369
+)
+
+// The PoS schedule, derived from the slot time and K by SetSchedule. It is
+// the mainnet schedule until Init applies the PlutoConfig of the chain.
+var (
+	// SlotTime is the time span of a slot in second
+	SlotTime uint64
+	K        uint64
 
 	// SlotCount is slot count in an epoch
+	SlotCount uint64
+
+	// Stage1K is divde a epoch into 12 pieces
+	Stage1K  uint64
+	Stage2K  uint64
+	Stage3K  uint64
+	Stage4K  uint64
+	Stage5K  uint64
+	Stage6K  uint64
+	Stage7K  uint64
+	Stage8K  uint64
+	Stage9K  uint64
+	Stage10K uint64
+	Stage11K uint64
+	Stage12K uint64
+
+	Sma1Start uint64
+	Sma1End   uint64
+	Sma2Start uint64
+	Sma2End   uint64
+	Sma3Start uint64
+	Sma3End   uint64
+
+	IncentiveStartStage uint64
+
+	// parameters for security and chain quality
+	BlockSecurityParam uint64
+	SlotSecurityParam  uint64
+
+	TxDelay int
+)
+
+func init() {
+	SetSchedule(DefaultSlotTime, DefaultK)
+}
+
+// SetSchedule sets the slot time and K of the epochs, and derives their stages.
+func SetSchedule(slotTime, k uint64) {
+	SlotTime, K = slotTime, k
 	SlotCount = K * KCount
 
-	// Stage1K is divde a epoch into 10 pieces
-	Stage1K  = uint64(K)
-	Stage2K  = Stage1K * 2
-	Stage3K  = Stage1K * 3
-	Stage4K  = Stage1K * 4
-	Stage5K  = Stage1K * 5
-	Stage6K  = Stage1K * 6
-	Stage7K  = Stage1K * 7
-	Stage8K  = Stage1K * 8
-	Stage9K  = Stage1K * 9
+	Stage1K = K
+	Stage2K = Stage1K * 2
+	Stage3K = Stage1K * 3
+	Stage4K = Stage1K * 4
+	Stage5K = Stage1K * 5
+	Stage6K = Stage1K * 6
+	Stage7K = Stage1K * 7
+	Stage8K = Stage1K * 8
+	Stage9K = Stage1K * 9
 	Stage10K = Stage1K * 10
 	Stage11K = Stage1K * 11
 	Stage12K = Stage1K * 12
 
 	Sma1Start = Stage2K
-	Sma1End   = Stage4K
+	Sma1End = Stage4K
 	Sma2Start = Stage6K
-	Sma2End   = Stage8K
+	Sma2End = Stage8K
 	Sma3Start = Stage10K
-	Sma3End   = Stage12K
+	Sma3End = Stage12K
 
-	// parameters for security and chain quality
-	BlockSecurityParam = K
-	SlotSecurityParam  = 2 * K
+	IncentiveStartStage = Stage2K
 
-	MinimumChainQuality     = 0.5 //BlockSecurityParam / SlotSecurityParam
-	CriticalReorgThreshold  = 3
-	CriticalChainQuality    = 0.618
-	NonCriticalChainQuality = 0.8
-
-	MainnetMercuryEpochId = 18250 //2019.12.20
-	TestnetMercuryEpochId = 18246 //2019.12.16
+	BlockSecurityParam = K
+	SlotSecurityParam = 2 * K
 
-	MainnetVenusEpochId = 11112222
-	TestnetVenusEpochId = 18369
-)
+	TxDelay = int(K)
 
-var TxDelay = K
+	DefaultConfig.K = uint(K)
+	DefaultConfig.Dkg1End = Stage2K - 1
+	DefaultConfig.Dkg2Begin = Stage4K
+	DefaultConfig.Dkg2End = Stage6K - 1
+	DefaultConfig.SignBegin = Stage8K
+	DefaultConfig.SignEnd = Stage10K - 1
+}
 
 var GenesisPK string
 
@@ -138,26 +200,11 @@ type Config struct {
 	SyncTargetBlokcNum uint64
 }
 
+// DefaultConfig holds the dkg stage windows of the mainnet schedule until
+// Init applies the PlutoConfig of the chain.
 var DefaultConfig = Config{
-	12,
-	K,
-	13,
-	0,
-	0,
-	nil,
-	"",
-	nil,
-	Stage2K - 1,
-	Stage4K,
-	Stage6K - 1,
-	Stage8K,
-	Stage10K - 1,
-	0,
-	0,
-
-	nil,
-
-	0,
+	PolymDegree: DefaultPolymDegree,
+	RBThres:     DefaultRBThres,
 }
 
 func Cfg() *Config {
@@ -197,7 +244,9 @@ func (c *Config) GetMinerBn256SK() *big.Int {
 	return GenerateD3byKey2(c.MinerKey.PrivateKey2)
 }
 
-func Init(nodeCfg *node.Config, networkId uint64) {
+// Init configures the PoS parameters of the network, taking the schedule and
+// the upgrade epochs from the PlutoConfig of the chain if it has one.
+func Init(nodeCfg *node.Config, networkId uint64, pluto *params.PlutoConfig) error {
 	if networkId == 1 {
 		// this is mainnet. *****
 		WhiteList = WhiteListMainnet
@@ -233,6 +282,92 @@ func Init(nodeCfg *node.Config, networkId uint64) {
 		EpochLeadersHold[i] = hexutil.MustDecode(WhiteList[i])
 	}
 	DefaultConfig.NodeCfg = nodeCfg
+
+	return applyPlutoConfig(pluto)
+}
+
+// SetWhiteList fills the white list of the default epoch leaders with the
+// given public keys, so a private network is led by its own validators. The
+// list is built afresh, the built-in network lists are left untouched.
+func SetWhiteList(pks []string) {
+	var list [len(WhiteList)]string
+	hold := make([][]byte, len(list))
+	for i := range list {
+		list[i] = pks[i%len(pks)]
+		hold[i] = hexutil.MustDecode(list[i])
+	}
+	WhiteList, EpochLeadersHold = list, hold
+}
+
+// PlutoSchedule returns the slot time and K of a chain with the given
+// PlutoConfig, the mainnet ones for the values it leaves out.
+func PlutoSchedule(pluto *params.PlutoConfig) (slotTime, k uint64) {
+	slotTime, k = DefaultSlotTime, DefaultK
+	if pluto == nil {
+		return slotTime, k
+	}
+	if pluto.SlotTime != 0 {
+		slotTime = pluto.SlotTime
+	}
+	if pluto.K != 0 {
+		k = pluto.K
+	}
+	return slotTime, k
+}
+
+// applyPlutoConfig replaces the mainnet schedule and the network's upgrade
+// epochs with the ones the PlutoConfig of the chain sets.
+func applyPlutoConfig(pluto *params.PlutoConfig) error {
+	if pluto == nil {
+		pluto = new(params.PlutoConfig)
+	}
+	SetSchedule(PlutoSchedule(pluto))
+
+	DefaultConfig.RBThres, DefaultConfig.PolymDegree = DefaultRBThres, DefaultPolymDegree
+	if pluto.RBThres != 0 {
+		DefaultConfig.RBThres = uint(pluto.RBThres)
+	}
+	if pluto.PolymDegree != 0 {
+		DefaultConfig.PolymDegree = uint(pluto.PolymDegree)
+	}
+	for _, window := range []struct {
+		value  uint64
+		target *uint64
+	}{
+		{pluto.Dkg1End, &DefaultConfig.Dkg1End},
+		{pluto.Dkg2Begin, &DefaultConfig.Dkg2Begin},
+		{pluto.Dkg2End, &DefaultConfig.Dkg2End},
+		{pluto.SignBegin, &DefaultConfig.SignBegin},
+		{pluto.SignEnd, &DefaultConfig.SignEnd},
+	} {
+		if window.value != 0 {
+			*window.target = window.value
+		}
+	}
+	if pluto.MercuryEpoch != nil {
+		DefaultConfig.MercuryEpochId = pluto.MercuryEpoch.Uint64()
+	}
+	if pluto.VenusEpoch != nil {
+		DefaultConfig.VenusEpochId = pluto.VenusEpoch.Uint64()
+	}
+	return checkSchedule(&DefaultConfig)
+}
+
+// checkSchedule verifies the dkg stages follow each other within an epoch and
+// the random can be recovered from the random proposers' signatures.
+func checkSchedule(c *Config) error {
+	if c.SignEnd >= SlotCount {
+		return fmt.Errorf("random beacon sign stage ends at slot %d, beyond the %d slots of an epoch", c.SignEnd, SlotCount)
+	}
+	if !(0 < c.Dkg1End && c.Dkg1End < c.Dkg2Begin && c.Dkg2Begin <= c.Dkg2End && c.Dkg2End < c.SignBegin && c.SignBegin <= c.SignEnd) {
+		return fmt.Errorf("invalid random beacon stages: dkg1 end %d, dkg2 %d-%d, sign %d-%d",
+			c.Dkg1End, c.Dkg2Begin, c.Dkg2End, c.SignBegin, c.SignEnd)
+	}
+	if c.PolymDegree >= c.RBThres || c.RBThres > RandomProperCount {
+		return fmt.Errorf("invalid random beacon threshold %d for polynomial degree %d and %d proposers",
+			c.RBThres, c.PolymDegree, RandomProperCount)
+	}
+	return nil
 }
 
 func GetRandomGenesis() *big.Int {
@@ -0,0 +1,59 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package posconfig
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/params"
+)
+
+func TestInitPlutoSchedule(t *testing.T) {
+	defer Init(nil, 4, nil)
+
+	pluto := &params.PlutoConfig{
+		SlotTime:   1,
+		K:          10,
+		VenusEpoch: big.NewInt(5),
+	}
+	if err := Init(nil, 4, pluto); err != nil {
+		t.Fatalf("failed to apply pluto config: %v", err)
+	}
+	if SlotTime != 1 || SlotCount != 120 || SlotSecurityParam != 20 || Sma2Start != 60 {
+		t.Errorf("schedule mismatch: slot time %d, slot count %d, security %d, sma2 %d", SlotTime, SlotCount, SlotSecurityParam, Sma2Start)
+	}
+	if cfg := Cfg(); cfg.Dkg1End != 19 || cfg.SignEnd != 99 || cfg.RBThres != DefaultRBThres {
+		t.Errorf("random beacon config mismatch: %+v", cfg)
+	}
+	if have := Cfg().VenusEpochId; have != 5 {
+		t.Errorf("venus epoch mismatch: have %d, want 5", have)
+	}
+	if have := Cfg().MercuryEpochId; have != TestnetMercuryEpochId {
+		t.Errorf("mercury epoch mismatch: have %d, want network default %d", have, TestnetMercuryEpochId)
+	}
+
+	// A chain without a schedule runs the mainnet one
+	if err := Init(nil, 4, nil); err != nil {
+		t.Fatalf("failed to apply default config: %v", err)
+	}
+	if SlotCount != DefaultK*KCount || Cfg().Dkg1End != Stage2K-1 || Cfg().VenusEpochId != TestnetVenusEpochId {
+		t.Errorf("default schedule not restored: slot count %d, %+v", SlotCount, Cfg())
+	}
+}
+
+func TestInitPlutoScheduleInvalid(t *testing.T) {
+	defer Init(nil, 4, nil)
+
+	tests := []*params.PlutoConfig{
+		{K: 10, SignEnd: 120},            // Sign stage beyond the epoch
+		{K: 10, Dkg2Begin: 10},           // Dkg2 before dkg1 ends
+		{RBThres: 12},                    // Threshold not above the polynomial degree
+		{RBThres: RandomProperCount + 1}, // More signatures than proposers
+	}
+	for i, pluto := range tests {
+		if err := Init(nil, 4, pluto); err == nil {
+			t.Errorf("test %d: invalid config accepted: %+v", i, pluto)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+// Package posconfigtest saves and restores the process-wide PoS configuration,
+// which the in-process networks and backends of tests replace for their
+// lifetime.
+package posconfigtest
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+)
+
+// Snapshot is a saved PoS configuration of the process.
+type Snapshot struct {
+	slotTime, k               uint64
+	txDelay                   int
+	config                    posconfig.Config
+	whiteList                 [len(posconfig.WhiteList)]string
+	epochLeadersHold          [][]byte
+	posOwnerAddr              common.Address
+	firstEpochID              uint64
+	pow2PosUpgradeBlockNumber uint64
+}
+
+// Save returns the current PoS configuration of the process.
+func Save() *Snapshot {
+	return &Snapshot{
+		slotTime:                  posconfig.SlotTime,
+		k:                         posconfig.K,
+		txDelay:                   posconfig.TxDelay,
+		config:                    posconfig.DefaultConfig,
+		whiteList:                 posconfig.WhiteList,
+		epochLeadersHold:          posconfig.EpochLeadersHold,
+		posOwnerAddr:              posconfig.PosOwnerAddr,
+		firstEpochID:              posconfig.FirstEpochId,
+		pow2PosUpgradeBlockNumber: posconfig.Pow2PosUpgradeBlockNumber,
+	}
+}
+
+// Restore puts the saved PoS configuration of the process back.
+func (s *Snapshot) Restore() {
+	posconfig.SetSchedule(s.slotTime, s.k)
+	posconfig.TxDelay = s.txDelay
+	posconfig.DefaultConfig = s.config
+	posconfig.WhiteList, posconfig.EpochLeadersHold = s.whiteList, s.epochLeadersHold
+	posconfig.PosOwnerAddr = s.posOwnerAddr
+	posconfig.FirstEpochId = s.firstEpochID
+	posconfig.Pow2PosUpgradeBlockNumber = s.pow2PosUpgradeBlockNumber
+}
+
+// UseTempDbPath points the PoS databases the process opens by name at a new
+// temporary directory. The returned function removes the directory and puts
+// the previous path back.
+func UseTempDbPath() (func(), error) {
+	dir, err := ioutil.TempDir("", "wanpos-test-")
+	if err != nil {
+		return nil, err
+	}
+	oldPath := posconfig.Cfg().Dbpath
+	posconfig.Cfg().Dbpath = dir
+	return func() {
+		posconfig.Cfg().Dbpath = oldPath
+		os.RemoveAll(dir)
+	}, nil
+}
@@ -62,6 +62,18 @@ func DbInitAll(pathname string) {
 	NewDb(posconfig.EpLocalDB)
 }
 
+// DbCloseAll closes every open db file, so the next DbInitAll opens them
+// afresh in its path.
+func DbCloseAll() {
+	mu.Lock()
+	for name, db := range dbInstMap {
+		db.DbClose()
+		delete(dbInstMap, name)
+	}
+	mu.Unlock()
+	dbInstance = NewDb("")
+}
+
 //GetDb can get a Db instance to use
 func GetDb() *Db {
 	return dbInstance
@@ -0,0 +1,88 @@
+package posdb
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+var errIncompleteEquivocation = errors.New("equivocation needs two different headers")
+
+// Equivocation is the evidence that the leader of a slot sealed two different
+// blocks for the same slot. Both headers carry the seal of the signer, so the
+// evidence can be checked by anyone.
+type Equivocation struct {
+	EpochId uint64         `json:"epochId"`
+	SlotId  uint64         `json:"slotId"`
+	Signer  common.Address `json:"signer"`
+	HeaderA *types.Header  `json:"headerA"`
+	HeaderB *types.Header  `json:"headerB"`
+}
+
+// NewEquivocation creates the evidence of two headers, ordered by hash so the
+// same pair always gives the same evidence.
+func NewEquivocation(epochId, slotId uint64, signer common.Address, a, b *types.Header) *Equivocation {
+	if bytes.Compare(a.Hash().Bytes(), b.Hash().Bytes()) > 0 {
+		a, b = b, a
+	}
+	return &Equivocation{EpochId: epochId, SlotId: slotId, Signer: signer, HeaderA: a, HeaderB: b}
+}
+
+// Hash identifies the evidence.
+func (e *Equivocation) Hash() common.Hash {
+	data, _ := rlp.EncodeToBytes(e)
+	return crypto.Keccak256Hash(data)
+}
+
+// PutEquivocation stores the evidence, keeping the first one of a signer for a
+// slot. It reports whether the evidence is new.
+func PutEquivocation(e *Equivocation) (bool, error) {
+	if e.HeaderA == nil || e.HeaderB == nil || e.HeaderA.Hash() == e.HeaderB.Hash() {
+		return false, errIncompleteEquivocation
+	}
+	db := NewDb(posconfig.EquivocationLocalDB)
+	if db == nil {
+		log.SyslogErr("PutEquivocation create db error")
+		return false, errors.New("equivocation db unavailable")
+	}
+
+	key := common.ToHex(e.Signer[:])
+	if old, err := db.GetWithIndex(e.EpochId, e.SlotId, key); err == nil && len(old) != 0 {
+		return false, nil
+	}
+	value, err := rlp.EncodeToBytes(e)
+	if err != nil {
+		return false, err
+	}
+	if _, err := db.PutWithIndex(e.EpochId, e.SlotId, key, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetEquivocations returns the evidence stored for an epoch.
+func GetEquivocations(epochId uint64) []*Equivocation {
+	db := NewDb(posconfig.EquivocationLocalDB)
+	if db == nil {
+		log.SyslogErr("GetEquivocations create db error")
+		return nil
+	}
+
+	values := db.GetStorageByteArray(epochId)
+	result := make([]*Equivocation, 0, len(values))
+	for _, value := range values {
+		e := new(Equivocation)
+		if err := rlp.DecodeBytes(value, e); err != nil {
+			log.Error("can't rlp decode equivocation", "err", err)
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package posdb
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+)
+
+func TestEquivocationStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "posdb-equivocation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldPath := posconfig.Cfg().Dbpath
+	posconfig.Cfg().Dbpath = dir
+	defer func() { posconfig.Cfg().Dbpath = oldPath }()
+
+	signer := common.HexToAddress("0x01")
+	a := &types.Header{Number: big.NewInt(10), Extra: []byte("a")}
+	b := &types.Header{Number: big.NewInt(10), Extra: []byte("b")}
+
+	if _, err := PutEquivocation(NewEquivocation(5, 7, signer, a, a)); err == nil {
+		t.Fatal("evidence with a single header accepted")
+	}
+
+	ev := NewEquivocation(5, 7, signer, a, b)
+	if ev.Hash() != NewEquivocation(5, 7, signer, b, a).Hash() {
+		t.Fatal("evidence depends on the order of the headers")
+	}
+	if isNew, err := PutEquivocation(ev); err != nil || !isNew {
+		t.Fatalf("first evidence not stored: new %v, err %v", isNew, err)
+	}
+	if isNew, err := PutEquivocation(ev); err != nil || isNew {
+		t.Fatalf("duplicate evidence stored: new %v, err %v", isNew, err)
+	}
+	c := &types.Header{Number: big.NewInt(10), Extra: []byte("c")}
+	if isNew, err := PutEquivocation(NewEquivocation(5, 8, signer, a, c)); err != nil || !isNew {
+		t.Fatalf("evidence of another slot not stored: new %v, err %v", isNew, err)
+	}
+
+	stored := GetEquivocations(5)
+	if len(stored) != 2 {
+		t.Fatalf("stored evidence count mismatch: have %d, want 2", len(stored))
+	}
+	if stored[0].Hash() != ev.Hash() {
+		t.Fatalf("stored evidence mismatch: have %x, want %x", stored[0].Hash(), ev.Hash())
+	}
+	if len(GetEquivocations(6)) != 0 {
+		t.Fatal("evidence found for an epoch without any")
+	}
+	NewDb(posconfig.EquivocationLocalDB).DbClose()
+	delete(dbInstMap, posconfig.EquivocationLocalDB)
+}
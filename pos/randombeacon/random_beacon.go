@@ -14,6 +14,7 @@ import (
 	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/core/vm"
 	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/metrics"
 
 	"math/big"
 
@@ -149,6 +150,11 @@ var (
 	rbPloys        = "RB_PLOYS"
 )
 
+var (
+	proposerGauge = metrics.NewGauge("pos/rb/proposer")  // Random beacon proposer seats of the local node in the epoch
+	txSentCounter = metrics.NewCounter("pos/rb/tx/sent") // Random beacon transactions sent by the local node
+)
+
 var (
 	errInvalidInParam  = errors.New("invalid input param")
 	errEpochIdRollback = errors.New("epoch id rollback")
@@ -251,6 +257,7 @@ func (rb *RandomBeacon) updateEpochId(epochId uint64) {
 	oldEpochId := rb.epochId
 	rb.epochId = epochId
 	rb.myPropserIds = rb.getMyRBProposerId(epochId)
+	proposerGauge.Update(int64(len(rb.myPropserIds)))
 
 	// reset state
 	rb.epochStage = vm.RbDkg1Stage
@@ -696,7 +703,7 @@ func (rb *RandomBeacon) sendSIG(payloadObj *vm.RbSIGTxPayload) error {
 func (rb *RandomBeacon) doSendRBTx(payload []byte) error {
 	to := vm.GetRBAddress()
 	data := hexutil.Bytes(payload)
-	gas := core.IntrinsicGas(data, &to, true)
+	gas := core.IntrinsicGas(data, &to, true, rb.nextIsIstanbul())
 
 	arg := map[string]interface{}{}
 	arg["from"] = rb.getTxFrom()
@@ -708,10 +715,22 @@ func (rb *RandomBeacon) doSendRBTx(payload []byte) error {
 
 
 	log.SyslogInfo("do send rb tx", "payload len", len(payload))
+	txSentCounter.Inc(1)
 	go util.SendPosTx(rb.rpcClient, arg)
 	return nil
 }
 
+// nextIsIstanbul reports whether the next block, the first one the transaction
+// may be mined in, is past the istanbul fork. Without a chain the pre-istanbul
+// calldata price, an upper bound of the intrinsic gas, is used.
+func (rb *RandomBeacon) nextIsIstanbul() bool {
+	if rb.epocher == nil || rb.epocher.GetBlkChain() == nil {
+		return false
+	}
+	chain := rb.epocher.GetBlkChain()
+	return chain.Config().IsIstanbul(new(big.Int).Add(chain.CurrentBlock().Number(), big.NewInt(1)))
+}
+
 func (rb *RandomBeacon) getTxFrom() common.Address {
 	return posconfig.Cfg().GetMinerAddr()
 }
@@ -0,0 +1,397 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+// Package simulator replays a staking plan on a copy of a historical state
+// with the pos staking precompile, then projects the selection probability,
+// the expected incentive and the stake out refunds of every following epoch.
+package simulator
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
+	bn256 "github.com/wanchain/go-wanchain/crypto/bn256/cloudflare"
+	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/epochLeader"
+	"github.com/wanchain/go-wanchain/pos/incentive"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+)
+
+const (
+	// MaxEpochs bounds the length of a projection.
+	MaxEpochs = 365
+
+	stakingGas = 1000000
+)
+
+var (
+	ErrEmptyPlan     = errors.New("staking plan is empty")
+	ErrInvalidEpochs = fmt.Errorf("epochs must be between 1 and %d", MaxEpochs)
+	ErrNoPosStage    = errors.New("chain config has no pos stage")
+	ErrPosSchedule   = errors.New("pos schedule is not initialized from the chain config")
+)
+
+// Validator is a new validator registered by the plan through stakeIn. The
+// keys may be left out, random ones are used since they do not change the
+// projection.
+type Validator struct {
+	From       common.Address `json:"from"`
+	SecPk      hexutil.Bytes  `json:"secPk,omitempty"`
+	Bn256Pk    hexutil.Bytes  `json:"bn256Pk,omitempty"`
+	Amount     uint64         `json:"amount"` // in wan
+	LockEpochs uint64         `json:"lockEpochs"`
+	FeeRate    uint64         `json:"feeRate"`
+}
+
+// Delegation is a delegateIn of the plan. A delegateOut is sent in OutEpoch
+// when it is set.
+type Delegation struct {
+	From      common.Address `json:"from"`
+	Validator common.Address `json:"validator"` // zero to delegate to the plan validator
+	Amount    uint64         `json:"amount"`    // in wan
+	OutEpoch  uint64         `json:"outEpoch,omitempty"`
+}
+
+// Partner is a partnerIn of the plan.
+type Partner struct {
+	From      common.Address `json:"from"`
+	Validator common.Address `json:"validator"` // zero to join the plan validator
+	Amount    uint64         `json:"amount"`    // in wan
+	Renewal   bool           `json:"renewal"`
+}
+
+// Plan is a staking plan. Its accounts are credited with the staked amounts,
+// so the plan does not depend on their balances in the replayed state.
+type Plan struct {
+	Validator   *Validator   `json:"validator,omitempty"`
+	Delegations []Delegation `json:"delegations,omitempty"`
+	Partners    []Partner    `json:"partners,omitempty"`
+	Epochs      uint64       `json:"epochs"`
+}
+
+// Reward is the expected incentive of a plan account in an epoch.
+type Reward struct {
+	Address   common.Address `json:"address"`
+	Incentive *hexutil.Big   `json:"incentive"`
+}
+
+// ValidatorProjection is the projection of a validator used by the plan.
+type ValidatorProjection struct {
+	Address                 common.Address `json:"address"`
+	Probability             *hexutil.Big   `json:"probability"`
+	Share                   float64        `json:"share"` // probability over the total probability of all validators
+	ExpectedEpochLeaders    float64        `json:"expectedEpochLeaders"`
+	ExpectedRandomProposers float64        `json:"expectedRandomProposers"`
+	Rewards                 []Reward       `json:"rewards"`
+}
+
+// Refund is a stake returned to a plan account by the stake out of an epoch.
+type Refund struct {
+	Address common.Address `json:"address"`
+	Amount  *hexutil.Big   `json:"amount"`
+}
+
+// EpochProjection is the projection of one epoch.
+type EpochProjection struct {
+	EpochID          uint64                `json:"epochId"`
+	TotalProbability *hexutil.Big          `json:"totalProbability"`
+	Validators       []ValidatorProjection `json:"validators"`
+	Refunds          []Refund              `json:"refunds,omitempty"`
+}
+
+// Result is the outcome of a simulation.
+type Result struct {
+	BlockNumber uint64            `json:"blockNumber"`
+	EpochID     uint64            `json:"epochId"`
+	Validators  []common.Address  `json:"validators"`
+	Epochs      []EpochProjection `json:"epochs"`
+}
+
+type simulation struct {
+	config    *params.ChainConfig
+	statedb   *state.StateDB
+	header    *types.Header
+	epochSpan uint64 // Seconds of an epoch in the chain config
+
+	validators []common.Address
+	accounts   []common.Address
+}
+
+// Simulate replays the plan on a copy of statedb, the state after header, and
+// projects the following plan.Epochs epochs. Epochs start when the plan joins
+// the selection, i.e. vm.JoinDelay epochs after the epoch of header.
+func Simulate(config *params.ChainConfig, statedb *state.StateDB, header *types.Header, plan *Plan) (*Result, error) {
+	if config == nil || config.PosFirstBlock == nil {
+		return nil, ErrNoPosStage
+	}
+	if plan == nil || (plan.Validator == nil && len(plan.Delegations) == 0 && len(plan.Partners) == 0) {
+		return nil, ErrEmptyPlan
+	}
+	if plan.Epochs == 0 || plan.Epochs > MaxEpochs {
+		return nil, ErrInvalidEpochs
+	}
+
+	// The staking contract reads the epochs from the pos schedule, which has
+	// to be the one of the chain for the projection to be right.
+	slotTime, k := posconfig.PlutoSchedule(config.Pluto)
+	if posconfig.SlotTime != slotTime || posconfig.K != k {
+		return nil, ErrPosSchedule
+	}
+
+	s := &simulation{
+		config:    config,
+		statedb:   statedb.Copy(),
+		header:    header,
+		epochSpan: epochSpan(config),
+	}
+
+	epochID := header.Time.Uint64() / s.epochSpan
+	if err := s.apply(plan, epochID); err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		BlockNumber: header.Number.Uint64(),
+		EpochID:     epochID,
+		Validators:  s.validators,
+		Epochs:      make([]EpochProjection, 0, plan.Epochs),
+	}
+
+	firstEpoch := epochID + vm.JoinDelay
+	for eid := epochID + 1; eid < firstEpoch+plan.Epochs; eid++ {
+		for _, d := range plan.Delegations {
+			if d.OutEpoch == eid {
+				validator := d.Validator
+				if validator == (common.Address{}) {
+					validator = s.validators[0]
+				}
+				if err := s.call(d.From, eid, nil, "delegateOut", validator); err != nil {
+					return nil, fmt.Errorf("delegateOut of %s: %v", d.From.String(), err)
+				}
+			}
+		}
+
+		refunds := s.stakeOut(eid)
+		if eid < firstEpoch {
+			continue
+		}
+
+		result.Epochs = append(result.Epochs, s.project(eid, refunds))
+	}
+	return result, nil
+}
+
+// apply sends the plan calls in the epoch of the replayed state.
+func (s *simulation) apply(plan *Plan, epochID uint64) error {
+	if v := plan.Validator; v != nil {
+		secPk, bn256Pk := []byte(v.SecPk), []byte(v.Bn256Pk)
+		if len(secPk) == 0 {
+			key, err := crypto.GenerateKey()
+			if err != nil {
+				return err
+			}
+			secPk = crypto.FromECDSAPub(&key.PublicKey)
+		}
+		if len(bn256Pk) == 0 {
+			_, g1, err := bn256.RandomG1(rand.Reader)
+			if err != nil {
+				return err
+			}
+			bn256Pk = g1.Marshal()
+		}
+		pub := crypto.ToECDSAPub(secPk)
+		if pub == nil {
+			return errors.New("invalid validator secPk")
+		}
+
+		err := s.call(v.From, epochID, wan(v.Amount), "stakeIn", secPk, bn256Pk,
+			new(big.Int).SetUint64(v.LockEpochs), new(big.Int).SetUint64(v.FeeRate))
+		if err != nil {
+			return fmt.Errorf("stakeIn of %s: %v", v.From.String(), err)
+		}
+		s.addValidator(crypto.PubkeyToAddress(*pub))
+		s.addAccount(v.From)
+	}
+
+	for _, p := range plan.Partners {
+		validator, err := s.planValidator(p.Validator)
+		if err != nil {
+			return err
+		}
+		if err := s.call(p.From, epochID, wan(p.Amount), "partnerIn", validator, p.Renewal); err != nil {
+			return fmt.Errorf("partnerIn of %s: %v", p.From.String(), err)
+		}
+		s.addValidator(validator)
+		s.addAccount(p.From)
+	}
+
+	for _, d := range plan.Delegations {
+		validator, err := s.planValidator(d.Validator)
+		if err != nil {
+			return err
+		}
+		if err := s.call(d.From, epochID, wan(d.Amount), "delegateIn", validator); err != nil {
+			return fmt.Errorf("delegateIn of %s: %v", d.From.String(), err)
+		}
+		s.addValidator(validator)
+		s.addAccount(d.From)
+	}
+	return nil
+}
+
+func (s *simulation) planValidator(addr common.Address) (common.Address, error) {
+	if addr != (common.Address{}) {
+		return addr, nil
+	}
+	if len(s.validators) == 0 {
+		return addr, errors.New("no validator given and the plan has no validator")
+	}
+	return s.validators[0], nil
+}
+
+func (s *simulation) addValidator(addr common.Address) {
+	for _, v := range s.validators {
+		if v == addr {
+			return
+		}
+	}
+	s.validators = append(s.validators, addr)
+}
+
+func (s *simulation) addAccount(addr common.Address) {
+	for _, a := range s.accounts {
+		if a == addr {
+			return
+		}
+	}
+	s.accounts = append(s.accounts, addr)
+}
+
+// call runs a staking contract method from the account at the first slot of epochID.
+func (s *simulation) call(from common.Address, epochID uint64, value *big.Int, method string, args ...interface{}) error {
+	input, err := vm.PackStakingInput(method, args...)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	s.statedb.AddBalance(from, value)
+
+	context := vm.Context{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		Origin:      from,
+		GasPrice:    big.NewInt(0),
+		Coinbase:    s.header.Coinbase,
+		GasLimit:    new(big.Int).SetUint64(stakingGas),
+		BlockNumber: new(big.Int).Set(s.header.Number),
+		Time:        new(big.Int).SetUint64(epochID * s.epochSpan),
+		Difficulty:  new(big.Int).Set(s.header.Difficulty),
+	}
+	if context.Time.Cmp(s.header.Time) < 0 {
+		context.Time.Set(s.header.Time)
+	}
+
+	evm := vm.NewEVM(context, s.statedb, s.config, vm.Config{})
+	_, _, err = evm.Call(vm.AccountRef(from), vm.WanCscPrecompileAddr, input, stakingGas, value)
+	return err
+}
+
+// stakeOut runs the stake out of epochID and returns what the plan accounts got back.
+func (s *simulation) stakeOut(epochID uint64) []Refund {
+	before := make([]*big.Int, len(s.accounts))
+	for i, addr := range s.accounts {
+		before[i] = s.statedb.GetBalance(addr)
+	}
+
+	epochLeader.StakeOutRun(s.statedb, epochID)
+
+	refunds := make([]Refund, 0)
+	for i, addr := range s.accounts {
+		diff := new(big.Int).Sub(s.statedb.GetBalance(addr), before[i])
+		if diff.Sign() > 0 {
+			refunds = append(refunds, Refund{Address: addr, Amount: (*hexutil.Big)(diff)})
+		}
+	}
+	return refunds
+}
+
+// project computes the probability of every staker in epochID and the
+// expected incentive of the plan validators.
+func (s *simulation) project(epochID uint64, refunds []Refund) EpochProjection {
+	total := big.NewInt(0)
+	infos := make(map[common.Address]*vm.ValidatorInfo)
+	for _, staker := range vm.GetStakersSnap(s.statedb) {
+		staker := staker
+		clients, probability, err := epochLeader.CalEpochProbabilityStaker(&staker, epochID)
+		if err != nil || probability == nil {
+			continue
+		}
+		total.Add(total, probability)
+		infos[staker.Address] = &vm.ValidatorInfo{
+			TotalProbability: probability,
+			FeeRate:          staker.FeeRate,
+			ValidatorAddr:    staker.Address,
+			WalletAddr:       staker.From,
+			Infos:            clients,
+		}
+	}
+
+	projection := EpochProjection{
+		EpochID:          epochID,
+		TotalProbability: (*hexutil.Big)(total),
+		Validators:       make([]ValidatorProjection, 0, len(s.validators)),
+		Refunds:          refunds,
+	}
+	for _, addr := range s.validators {
+		vp := ValidatorProjection{
+			Address:     addr,
+			Probability: (*hexutil.Big)(big.NewInt(0)),
+			Rewards:     make([]Reward, 0),
+		}
+		info, ok := infos[addr]
+		if ok && total.Sign() > 0 {
+			vp.Probability = (*hexutil.Big)(info.TotalProbability)
+			vp.Share, _ = new(big.Rat).SetFrac(info.TotalProbability, total).Float64()
+			vp.ExpectedEpochLeaders = vp.Share * posconfig.EpochLeaderCount
+			vp.ExpectedRandomProposers = vp.Share * posconfig.RandomProperCount
+
+			for _, inc := range incentive.EstimateValidatorIncentive(s.statedb, epochID, info, total) {
+				if s.isAccount(inc.WalletAddr) {
+					vp.Rewards = append(vp.Rewards, Reward{Address: inc.WalletAddr, Incentive: (*hexutil.Big)(inc.Incentive)})
+				}
+			}
+		}
+		projection.Validators = append(projection.Validators, vp)
+	}
+	return projection
+}
+
+func (s *simulation) isAccount(addr common.Address) bool {
+	for _, a := range s.accounts {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// epochSpan returns the seconds of an epoch of the chain.
+func epochSpan(config *params.ChainConfig) uint64 {
+	slotTime, k := posconfig.PlutoSchedule(config.Pluto)
+	return slotTime * k * posconfig.KCount
+}
+
+func wan(amount uint64) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(amount), big.NewInt(params.Wan))
+}
@@ -0,0 +1,111 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package simulator
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+)
+
+func TestSimulate(t *testing.T) {
+	const epochID = uint64(20000)
+
+	firstEpochId := posconfig.FirstEpochId
+	posconfig.FirstEpochId = epochID - 10
+	defer func() { posconfig.FirstEpochId = firstEpochId }()
+
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	header := &types.Header{
+		Number:     big.NewInt(100),
+		Time:       new(big.Int).SetUint64(epochID*epochSpan(params.TestChainConfig) + 10),
+		Difficulty: big.NewInt(1),
+	}
+
+	validatorFrom := common.HexToAddress("0x01")
+	delegatorFrom := common.HexToAddress("0x02")
+	plan := &Plan{
+		Validator: &Validator{
+			From:       validatorFrom,
+			Amount:     60000,
+			LockEpochs: 10,
+			FeeRate:    1000,
+		},
+		Delegations: []Delegation{
+			{From: delegatorFrom, Amount: 1000, OutEpoch: epochID + 3},
+		},
+		Epochs: 8,
+	}
+
+	result, err := Simulate(params.TestChainConfig, statedb, header, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.EpochID != epochID || len(result.Validators) != 1 || len(result.Epochs) != int(plan.Epochs) {
+		t.Fatalf("unexpected result: epoch %d validators %d epochs %d", result.EpochID, len(result.Validators), len(result.Epochs))
+	}
+	if statedb.GetBalance(validatorFrom).Sign() != 0 {
+		t.Fatal("simulation modified the replayed state")
+	}
+
+	refundEpoch := plan.Delegations[0].OutEpoch + 3
+	for _, ep := range result.Epochs {
+		vp := ep.Validators[0]
+		if vp.Share != 1 || vp.ExpectedEpochLeaders != posconfig.EpochLeaderCount {
+			t.Fatalf("epoch %d: unexpected share %v", ep.EpochID, vp.Share)
+		}
+
+		rewarded := make(map[common.Address]bool)
+		for _, r := range vp.Rewards {
+			rewarded[r.Address] = r.Incentive.ToInt().Sign() > 0
+		}
+		if !rewarded[validatorFrom] {
+			t.Fatalf("epoch %d: validator not rewarded", ep.EpochID)
+		}
+		if delegating := ep.EpochID < refundEpoch-1; rewarded[delegatorFrom] != delegating {
+			t.Fatalf("epoch %d: delegator rewarded %v, want %v", ep.EpochID, rewarded[delegatorFrom], delegating)
+		}
+
+		if ep.EpochID == refundEpoch {
+			want := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Wan))
+			if len(ep.Refunds) != 1 || ep.Refunds[0].Address != delegatorFrom || ep.Refunds[0].Amount.ToInt().Cmp(want) != 0 {
+				t.Fatalf("epoch %d: unexpected refunds %v", ep.EpochID, ep.Refunds)
+			}
+		} else if len(ep.Refunds) != 0 {
+			t.Fatalf("epoch %d: unexpected refunds %v", ep.EpochID, ep.Refunds)
+		}
+	}
+}
+
+func TestSimulateInvalidPlan(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	header := &types.Header{Number: big.NewInt(1), Time: big.NewInt(0), Difficulty: big.NewInt(1)}
+
+	if _, err := Simulate(&params.ChainConfig{}, statedb, header, &Plan{Epochs: 1}); err != ErrNoPosStage {
+		t.Errorf("chain without pos error mismatch: have %v, want %v", err, ErrNoPosStage)
+	}
+	if _, err := Simulate(params.TestChainConfig, statedb, header, &Plan{Epochs: 1}); err != ErrEmptyPlan {
+		t.Fatalf("empty plan: got %v", err)
+	}
+	plan := &Plan{Delegations: []Delegation{{From: common.HexToAddress("0x01"), Amount: 100}}, Epochs: MaxEpochs + 1}
+	if _, err := Simulate(params.TestChainConfig, statedb, header, plan); err != ErrInvalidEpochs {
+		t.Fatalf("too many epochs: got %v", err)
+	}
+	plan.Epochs = 1
+	if _, err := Simulate(params.TestChainConfig, statedb, header, plan); err == nil {
+		t.Fatal("delegation without validator accepted")
+	}
+	config := *params.TestChainConfig
+	config.Pluto = &params.PlutoConfig{K: posconfig.K + 1}
+	if _, err := Simulate(&config, statedb, header, plan); err != ErrPosSchedule {
+		t.Fatalf("schedule of another chain: got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/wanchain/go-wanchain/pos/util"
 
+	"github.com/wanchain/go-wanchain/core/state"
 	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/core/vm"
 
@@ -71,6 +72,47 @@ func (s *SLS) VerifySlotProof(block *types.Block, epochID uint64, slotID uint64,
 		return s.verifySlotProofByGenesis(epochID, slotID, Proof, ProofMeg)
 	}
 
+	return s.verifySlotProofByLeaders(epochLeadersPtrPre, rbBytes, &validEpochLeadersIndex, &stageTwoAlphaPKi,
+		epochID, slotID, Proof, ProofMeg)
+}
+
+// EpochProofData is the chain data the slot leader proofs of an epoch are
+// verified against, for verifiers without the local pos databases.
+type EpochProofData struct {
+	PreEpochLeaders []*ecdsa.PublicKey
+	Random          *big.Int
+	ValidLeaders    [posconfig.EpochLeaderCount]bool
+	AlphaPKi        [posconfig.EpochLeaderCount][posconfig.EpochLeaderCount]*ecdsa.PublicKey
+}
+
+// VerifySlotProofWithData verifies a slot leader proof as VerifySlotProof does,
+// taking the pre epoch leaders, the random and the stage two data from data.
+func (s *SLS) VerifySlotProofWithData(data *EpochProofData, epochID uint64, slotID uint64, Proof []*big.Int,
+	ProofMeg []*ecdsa.PublicKey) bool {
+	if epochID <= posconfig.FirstEpochId+2 || data == nil || len(data.PreEpochLeaders) == 0 {
+		return s.verifySlotProofByGenesis(epochID, slotID, Proof, ProofMeg)
+	}
+
+	hasValidTx := false
+	for _, valid := range data.ValidLeaders {
+		if valid {
+			hasValidTx = true
+			break
+		}
+	}
+	if !hasValidTx {
+		return s.verifySlotProofByGenesis(epochID, slotID, Proof, ProofMeg)
+	}
+
+	return s.verifySlotProofByLeaders(data.PreEpochLeaders, data.Random.Bytes(), &data.ValidLeaders, &data.AlphaPKi,
+		epochID, slotID, Proof, ProofMeg)
+}
+
+func (s *SLS) verifySlotProofByLeaders(epochLeadersPtrPre []*ecdsa.PublicKey, rbBytes []byte,
+	validEpochLeadersIndex *[posconfig.EpochLeaderCount]bool,
+	stageTwoAlphaPKi *[posconfig.EpochLeaderCount][posconfig.EpochLeaderCount]*ecdsa.PublicKey,
+	epochID uint64, slotID uint64, Proof []*big.Int, ProofMeg []*ecdsa.PublicKey) bool {
+
 	var publicKey *ecdsa.PublicKey
 	publicKey = ProofMeg[0]
 
@@ -361,3 +403,29 @@ func (s *SLS) getStageTwoFromTrans(epochID uint64) (validEpochLeadersIndex [posc
 	}
 	return validEpochLeadersIndex, stageTwoAlphaPKi, nil
 }
+
+// StageTwoFromState reads the stage two data the slot leader proofs of an epoch
+// are verified against from a state, as getStageTwoFromTrans does.
+func StageTwoFromState(statedb *state.StateDB, epochID uint64) (validEpochLeadersIndex [posconfig.EpochLeaderCount]bool,
+	stageTwoAlphaPKi [posconfig.EpochLeaderCount][posconfig.EpochLeaderCount]*ecdsa.PublicKey, err error) {
+
+	indexesSentTran, err := stage2TxIndexes(statedb, epochID-1)
+	if err != nil {
+		return validEpochLeadersIndex, stageTwoAlphaPKi, err
+	}
+
+	for i := 0; i < posconfig.EpochLeaderCount; i++ {
+		if !indexesSentTran[i] {
+			continue
+		}
+		alphaPki, _, err := vm.GetStage2TxAlphaPki(statedb, epochID-1, uint64(i))
+		if err != nil {
+			continue
+		}
+		validEpochLeadersIndex[i] = true
+		for j := 0; j < posconfig.EpochLeaderCount; j++ {
+			stageTwoAlphaPKi[i][j] = alphaPki[j]
+		}
+	}
+	return validEpochLeadersIndex, stageTwoAlphaPKi, nil
+}
@@ -9,11 +9,14 @@ import (
 	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/core/vm"
 	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/metrics"
 	"github.com/wanchain/go-wanchain/rpc"
 )
 
 var (
 	errRCNotReady = errors.New("rc is not ready")
+
+	txSentCounter = metrics.NewCounter("pos/sl/tx/sent") // Slot leader selection transactions sent by the local node
 )
 
 type SendTxFn func(rc *rpc.Client, tx map[string]interface{})
@@ -25,7 +28,7 @@ func (s *SLS) sendSlotTx(payload []byte, posSender SendTxFn) error {
 
 	to := vm.GetSlotLeaderSCAddress()
 	data := hexutil.Bytes(payload)
-	gas := core.IntrinsicGas(data, &to, true)
+	gas := core.IntrinsicGas(data, &to, true, s.nextIsIstanbul())
 
 	arg := map[string]interface{}{}
 	arg["from"] = s.key.Address
@@ -36,6 +39,17 @@ func (s *SLS) sendSlotTx(payload []byte, posSender SendTxFn) error {
 	arg["data"] = data
 	log.Debug("Write data of payload", "length", len(data))
 
+	txSentCounter.Inc(1)
 	go posSender(s.rc, arg)
 	return nil
 }
+
+// nextIsIstanbul reports whether the next block, the first one the transaction
+// may be mined in, is past the istanbul fork. Without a chain the pre-istanbul
+// calldata price, an upper bound of the intrinsic gas, is used.
+func (s *SLS) nextIsIstanbul() bool {
+	if s.blockChain == nil {
+		return false
+	}
+	return s.blockChain.Config().IsIstanbul(new(big.Int).Add(s.blockChain.CurrentBlock().Number(), big.NewInt(1)))
+}
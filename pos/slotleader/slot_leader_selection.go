@@ -66,9 +66,9 @@ type SLS struct {
 	epochLeadersArray []string            // len(pki)=65 hex.EncodeToString
 	epochLeadersMap   map[string][]uint64 // key: pki value: []uint64 the indexes of this pki. hex.EncodeToString
 
-	slotLeadersPtrArray        [posconfig.SlotCount]*ecdsa.PublicKey
-	defaultSlotLeadersPtrArray [posconfig.SlotCount]*ecdsa.PublicKey
-	slotLeadersIndex           [posconfig.SlotCount]uint64
+	slotLeadersPtrArray        []*ecdsa.PublicKey // posconfig.SlotCount entries, allocated by SlsInit
+	defaultSlotLeadersPtrArray []*ecdsa.PublicKey
+	slotLeadersIndex           []uint64
 	epochLeadersPtrArray       [posconfig.EpochLeaderCount]*ecdsa.PublicKey
 	// true: can be used to slot leader false: can not be used to slot leader
 	validEpochLeadersIndex [posconfig.EpochLeaderCount]bool
@@ -232,14 +232,24 @@ func SlsInit() {
 	slotLeaderSelection.epochLeadersArray = make([]string, 0)
 	slotLeaderSelection.slotCreateStatus = make(map[uint64]bool)
 	slotLeaderSelection.slotCreateStatusLockCh = make(chan int, 1)
+	slotLeaderSelection.slotLeadersPtrArray = make([]*ecdsa.PublicKey, posconfig.SlotCount)
+	slotLeaderSelection.defaultSlotLeadersPtrArray = make([]*ecdsa.PublicKey, posconfig.SlotCount)
+	slotLeaderSelection.slotLeadersIndex = make([]uint64, posconfig.SlotCount)
 }
 
 func (s *SLS) getSlotLeaderStage2TxIndexes(epochID uint64) (indexesSentTran []bool, err error) {
-	var ret [posconfig.EpochLeaderCount]bool
 	stateDb, err := s.getCurrentStateDb()
 	if err != nil {
+		var ret [posconfig.EpochLeaderCount]bool
 		return ret[:], err
 	}
+	return stage2TxIndexes(stateDb, epochID)
+}
+
+// stage2TxIndexes reads which epoch leaders sent their stage two transaction in
+// the epoch from the state.
+func stage2TxIndexes(stateDb *state.StateDB, epochID uint64) (indexesSentTran []bool, err error) {
+	var ret [posconfig.EpochLeaderCount]bool
 
 	slotLeaderPrecompileAddr := vm.GetSlotLeaderSCAddress()
 
@@ -364,10 +374,17 @@ func (s *SLS) GetEpochDefaultLeadersPK(epochID uint64) []*ecdsa.PublicKey {
 			pks[i] = initPksStr[i%len(initPksStr)]
 		}
 	} else {
-		selector := epochLeader.GetEpocher()
-		initPksStr, err := selector.GetWhiteByEpochId(epochID)
-		if err != nil {
-			log.SyslogErr("GetEpochDefaultLeadersPK error", "err", err)
+		var initPksStr []string
+		if selector := epochLeader.GetEpocher(); selector != nil {
+			var err error
+			initPksStr, err = selector.GetWhiteByEpochId(epochID)
+			if err != nil {
+				log.SyslogErr("GetEpochDefaultLeadersPK error", "err", err)
+			}
+		} else {
+			// Light clients have no epocher, the genesis state has the default white list
+			info := vm.UpgradeWhiteEpochLeaderDefault
+			initPksStr = posconfig.WhiteList[info.WlIndex.Uint64() : info.WlIndex.Uint64()+info.WlCount.Uint64()]
 		}
 		for i := 0; i < posconfig.EpochLeaderCount; i++ {
 			pkBuf := common.FromHex(initPksStr[i%len(initPksStr)])
@@ -429,11 +446,11 @@ func (s *SLS) clearData() {
 		}
 	}
 
-	for i := 0; i < posconfig.SlotCount; i++ {
+	for i := range s.slotLeadersPtrArray {
 		s.slotLeadersPtrArray[i] = nil
 	}
 
-	for i := 0; i < posconfig.SlotCount; i++ {
+	for i := range s.slotLeadersIndex {
 		s.slotLeadersIndex[i] = 0
 	}
 }
@@ -63,8 +63,8 @@ func (s *SLS) GetAllSlotLeaders(epochID uint64) (slotLeader []*ecdsa.PublicKey)
 
 	slotLeadersPtrArray := make([]*ecdsa.PublicKey,0)
 	// read from local db
-	for i := 0; i < posconfig.SlotCount; i++ {
-		pkByte, err := posdb.GetDb().GetWithIndex(epochID, uint64(i), SlotLeader)
+	for i := uint64(0); i < posconfig.SlotCount; i++ {
+		pkByte, err := posdb.GetDb().GetWithIndex(epochID, i, SlotLeader)
 		if err != nil {
 			return nil
 		}
@@ -0,0 +1,128 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of the time the PoS epochs and slots are derived from.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// systemClock is the wall clock.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time        { return time.Now() }
+func (systemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+var (
+	clock   Clock = systemClock{}
+	clockMu sync.RWMutex
+)
+
+// SetClock replaces the clock of the PoS time, nil restores the wall clock.
+func SetClock(c Clock) {
+	clockMu.Lock()
+	defer clockMu.Unlock()
+
+	if c == nil {
+		c = systemClock{}
+	}
+	clock = c
+}
+
+// Now returns the current time of the PoS clock.
+func Now() time.Time {
+	clockMu.RLock()
+	defer clockMu.RUnlock()
+	return clock.Now()
+}
+
+// Sleep blocks for the duration d of the PoS clock.
+func Sleep(d time.Duration) {
+	clockMu.RLock()
+	c := clock
+	clockMu.RUnlock()
+	c.Sleep(d)
+}
+
+// SimulatedClock is a Clock whose time only moves when Run is called, so the
+// slots of a simulated network advance deterministically.
+type SimulatedClock struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	now      time.Time
+	sleepers []*sleeper
+}
+
+type sleeper struct {
+	at   time.Time
+	wake chan struct{}
+}
+
+// NewSimulatedClock creates a simulated clock starting at the given time.
+func NewSimulatedClock(start time.Time) *SimulatedClock {
+	c := &SimulatedClock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the current simulated time.
+func (c *SimulatedClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep blocks until the simulated time has moved forward by d.
+func (c *SimulatedClock) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	c.mu.Lock()
+	s := &sleeper{at: c.now.Add(d), wake: make(chan struct{})}
+	c.sleepers = append(c.sleepers, s)
+	c.cond.Broadcast()
+	c.mu.Unlock()
+
+	<-s.wake
+}
+
+// Run moves the simulated time forward by d, waking up the sleepers whose
+// deadline has passed.
+func (c *SimulatedClock) Run(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	sleeping := c.sleepers[:0]
+	for _, s := range c.sleepers {
+		if s.at.After(c.now) {
+			sleeping = append(sleeping, s)
+		} else {
+			close(s.wake)
+		}
+	}
+	c.sleepers = sleeping
+}
+
+// WaitForSleepers blocks until at least n goroutines are sleeping on the clock.
+func (c *SimulatedClock) WaitForSleepers(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.sleepers) < n {
+		c.cond.Wait()
+	}
+}
+
+// Sleepers returns the number of goroutines sleeping on the clock.
+func (c *SimulatedClock) Sleepers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.sleepers)
+}
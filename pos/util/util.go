@@ -8,7 +8,6 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/wanchain/go-wanchain/common/hexutil"
 
@@ -48,7 +47,7 @@ func CalEpochSlotIDByNow() {
 	//if posconfig.EpochBaseTime == 0 {
 	//	return
 	//}
-	timeUnix := uint64(time.Now().Unix())
+	timeUnix := uint64(Now().Unix())
 	epochTimeSpan := uint64(posconfig.SlotTime * posconfig.SlotCount)
 	curEpochId = uint64((timeUnix) / epochTimeSpan)
 	curSlotId = uint64((timeUnix) / posconfig.SlotTime % posconfig.SlotCount)
@@ -0,0 +1,99 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package validatorhistory
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/math"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+var errInvalidBlockRange = errors.New("fromBlock is larger than toBlock")
+
+// ValidatorEvent is a staking operation of a validator.
+type ValidatorEvent struct {
+	Event       string                `json:"event"`
+	BlockNumber uint64                `json:"blockNumber"`
+	TxHash      common.Hash           `json:"transactionHash"`
+	Sender      common.Address        `json:"sender"`
+	Validator   common.Address        `json:"validator"`
+	Amount      *math.HexOrDecimal256 `json:"amount,omitempty"`
+	LockEpochs  *uint64               `json:"lockEpochs,omitempty"`
+	FeeRate     *uint64               `json:"feeRate,omitempty"`
+	MaxFeeRate  *uint64               `json:"maxFeeRate,omitempty"`
+	Renewal     *bool                 `json:"renewal,omitempty"`
+}
+
+func newValidatorEvent(event *vm.StakingEvent, number uint64, txHash common.Hash) ValidatorEvent {
+	ve := ValidatorEvent{
+		Event:       event.Name,
+		BlockNumber: number,
+		TxHash:      txHash,
+		Sender:      event.Sender,
+		Validator:   event.Validator,
+		Amount:      (*math.HexOrDecimal256)(event.Amount),
+		Renewal:     event.Renewal,
+	}
+	if event.LockEpochs != nil {
+		v := event.LockEpochs.Uint64()
+		ve.LockEpochs = &v
+	}
+	if event.FeeRate != nil {
+		v := event.FeeRate.Uint64()
+		ve.FeeRate = &v
+	}
+	if event.MaxFeeRate != nil {
+		v := event.MaxFeeRate.Uint64()
+		ve.MaxFeeRate = &v
+	}
+	return ve
+}
+
+// APIs returns the validator history RPC service.
+func APIs(idx *Indexer) []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "pos",
+			Version:   "1.0",
+			Service:   NewPublicValidatorHistoryAPI(idx),
+			Public:    true,
+		},
+	}
+}
+
+// PublicValidatorHistoryAPI exposes the indexed validator histories.
+type PublicValidatorHistoryAPI struct {
+	idx *Indexer
+}
+
+// NewPublicValidatorHistoryAPI creates a new validator history API.
+func NewPublicValidatorHistoryAPI(idx *Indexer) *PublicValidatorHistoryAPI {
+	return &PublicValidatorHistoryAPI{idx}
+}
+
+// GetValidatorHistory returns the stakeIn, stakeAppend, stakeUpdate, fee rate,
+// delegate and partner operations of a validator between two blocks. The
+// latest and pending tags refer to the last indexed block. The blocks before
+// the node started indexing are only available if it backfilled the history.
+func (api *PublicValidatorHistoryAPI) GetValidatorHistory(addr common.Address, fromBlock, toBlock rpc.BlockNumber) ([]ValidatorEvent, error) {
+	tail, head := api.idx.IndexedRange()
+	resolve := func(n rpc.BlockNumber) uint64 {
+		if n < 0 || uint64(n) > head {
+			return head
+		}
+		return uint64(n)
+	}
+
+	from, to := resolve(fromBlock), resolve(toBlock)
+	if from > to {
+		return nil, errInvalidBlockRange
+	}
+	if from < tail {
+		return nil, fmt.Errorf("validator history is indexed from block %d", tail)
+	}
+	return api.idx.History(addr, from, to)
+}
@@ -0,0 +1,424 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+// Package validatorhistory indexes the operations of the pos staking contract
+// per validator, so the whole lifecycle of a validator can be queried.
+package validatorhistory
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/event"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/rlp"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+const chainHeadChanSize = 16
+
+var (
+	headKey             = []byte("pos-vh-head")
+	blockPrefix         = []byte("pos-vhb-") // blockPrefix + num (uint64 big endian) -> blockEntry
+	validatorPrefix     = []byte("pos-vhc-") // validatorPrefix + address -> count of indexed block numbers
+	validatorNumPrefix  = []byte("pos-vhn-") // validatorNumPrefix + address + index (uint64 big endian) -> block number
+	validatorLogsPrefix = []byte("pos-vhl-") // validatorLogsPrefix + address + num (uint64 big endian) -> []entry
+)
+
+// entry is an indexed staking operation, either a log of the staking contract
+// or, when the call wrote no log, the call itself.
+type entry struct {
+	TxHash common.Hash
+	Topics []common.Hash
+	Data   []byte
+	From   common.Address
+	Input  []byte
+	Value  *big.Int
+}
+
+func (e *entry) decode() (*vm.StakingEvent, error) {
+	if len(e.Topics) != 0 {
+		return vm.DecodeStakingLog(&types.Log{Address: vm.WanCscPrecompileAddr, Topics: e.Topics, Data: e.Data})
+	}
+	return vm.DecodeStakingCall(e.From, e.Input, e.Value)
+}
+
+// indexHead is the last indexed block. Tail is the first one, the index
+// starts at the genesis only if it was backfilled.
+type indexHead struct {
+	Number uint64
+	Hash   common.Hash
+	Tail   uint64
+}
+
+// blockEntry records the hash of an indexed block and the validators it
+// touched, so that it can be removed from the index after a reorg.
+type blockEntry struct {
+	Hash       common.Hash
+	Validators []common.Address
+}
+
+// Backend is the part of the node the indexer follows the chain through.
+type Backend interface {
+	ChainDb() ethdb.Database
+	ChainConfig() *params.ChainConfig
+	CurrentBlock() *types.Block
+	BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Block, error)
+	GetReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, error)
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// Indexer follows the canonical chain and records the staking operations of
+// every validator in the chain database.
+type Indexer struct {
+	backend  Backend
+	db       ethdb.Database
+	backfill bool // Whether to index the chain from the genesis on
+
+	mu sync.RWMutex // protects the index against concurrent update and query
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a validator history indexer on top of the given backend. A new
+// index starts at the current head, unless backfill is set.
+func New(backend Backend, backfill bool) *Indexer {
+	return &Indexer{
+		backend:  backend,
+		db:       backend.ChainDb(),
+		backfill: backfill,
+		quit:     make(chan struct{}),
+	}
+}
+
+// Start launches the goroutine following the chain head.
+func (idx *Indexer) Start() {
+	idx.wg.Add(1)
+	go idx.loop()
+}
+
+// Stop terminates the indexer.
+func (idx *Indexer) Stop() {
+	close(idx.quit)
+	idx.wg.Wait()
+}
+
+func (idx *Indexer) loop() {
+	defer idx.wg.Done()
+
+	heads := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	sub := idx.backend.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	idx.update(idx.backend.CurrentBlock().NumberU64())
+	for {
+		select {
+		case ev := <-heads:
+			if ev.Block != nil {
+				idx.update(ev.Block.NumberU64())
+			}
+		case <-sub.Err():
+			return
+		case <-idx.quit:
+			return
+		}
+	}
+}
+
+// start returns the head of the index, setting up a new one if there's none.
+// An index which didn't start at the genesis is dropped if backfill is set.
+func (idx *Indexer) start() indexHead {
+	head, ok := idx.readHead()
+	if ok && (!idx.backfill || head.Tail == 0) {
+		return head
+	}
+	if ok {
+		log.Info("Dropping validator history to backfill it", "tail", head.Tail, "head", head.Number)
+		for n := head.Number; n > head.Tail; n-- {
+			idx.unindexBlock(n)
+		}
+	}
+	head = indexHead{Hash: core.GetCanonicalHash(idx.db, 0)}
+	if !idx.backfill {
+		current := idx.backend.CurrentBlock()
+		head = indexHead{Number: current.NumberU64(), Hash: current.Hash(), Tail: current.NumberU64()}
+	}
+	idx.writeHead(idx.db, head)
+	return head
+}
+
+// update rolls back the blocks which left the canonical chain down to the
+// fork point, then indexes the blocks up to number. The lock is taken per
+// block so that queries are not blocked by a long catch up.
+func (idx *Indexer) update(number uint64) {
+	idx.mu.Lock()
+	head := idx.start()
+	for head.Number > head.Tail && core.GetCanonicalHash(idx.db, head.Number) != head.Hash {
+		idx.unindexBlock(head.Number)
+		head.Number--
+		if be := idx.readBlock(head.Number); be != nil {
+			head.Hash = be.Hash
+		} else {
+			head.Hash = core.GetCanonicalHash(idx.db, head.Number)
+		}
+	}
+	if head.Number == head.Tail {
+		head.Hash = core.GetCanonicalHash(idx.db, head.Number)
+	}
+	idx.writeHead(idx.db, head)
+	idx.mu.Unlock()
+
+	for n := head.Number + 1; n <= number; n++ {
+		select {
+		case <-idx.quit:
+			return
+		default:
+		}
+
+		block, err := idx.backend.BlockByNumber(context.Background(), rpc.BlockNumber(n))
+		if block == nil || err != nil {
+			log.Debug("Validator history failed to get block", "number", n, "err", err)
+			return
+		}
+
+		idx.mu.Lock()
+		head = indexHead{Number: n, Hash: block.Hash(), Tail: head.Tail}
+		err = idx.indexBlock(block, head)
+		idx.mu.Unlock()
+		if err != nil {
+			log.Debug("Validator history failed to index block", "number", n, "err", err)
+			return
+		}
+	}
+}
+
+// indexBlock records the successful calls to the staking contract of a block,
+// and moves the index to head in the same batch.
+func (idx *Indexer) indexBlock(block *types.Block, head indexHead) error {
+	entries := make(map[common.Address][]entry)
+	validators := make([]common.Address, 0)
+	add := func(validator common.Address, e entry) {
+		if _, ok := entries[validator]; !ok {
+			validators = append(validators, validator)
+		}
+		entries[validator] = append(entries[validator], e)
+	}
+
+	var receipts types.Receipts
+	signer := types.MakeSigner(idx.backend.ChainConfig(), block.Number())
+	for i, tx := range block.Transactions() {
+		if tx.To() == nil || *tx.To() != vm.WanCscPrecompileAddr {
+			continue
+		}
+		if receipts == nil {
+			var err error
+			receipts, err = idx.backend.GetReceipts(context.Background(), block.Hash())
+			if err != nil {
+				return err
+			}
+			if len(receipts) != len(block.Transactions()) {
+				// receipts of fast synced blocks may be missing
+				validators = validators[:0]
+				break
+			}
+		}
+		receipt := receipts[i]
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+
+		logged := false
+		for _, l := range receipt.Logs {
+			event, err := vm.DecodeStakingLog(l)
+			if err != nil {
+				continue
+			}
+			add(event.Validator, entry{TxHash: tx.Hash(), Topics: l.Topics, Data: l.Data})
+			logged = true
+		}
+		if logged {
+			continue
+		}
+
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			continue
+		}
+		event, err := vm.DecodeStakingCall(from, tx.Data(), tx.Value())
+		if err != nil {
+			continue
+		}
+		add(event.Validator, entry{TxHash: tx.Hash(), From: from, Input: tx.Data(), Value: tx.Value()})
+	}
+
+	number := block.NumberU64()
+	batch := idx.db.NewBatch()
+	for _, validator := range validators {
+		enc, err := rlp.EncodeToBytes(entries[validator])
+		if err != nil {
+			return err
+		}
+		batch.Put(validatorLogsKey(validator, number), enc)
+
+		// a block indexed again after an interrupted write is listed once
+		count := idx.readCount(validator)
+		if count > 0 && idx.readNumber(validator, count-1) == number {
+			continue
+		}
+		batch.Put(validatorNumKey(validator, count), encodeNumber(number))
+		batch.Put(validatorKey(validator), encodeNumber(count+1))
+	}
+	enc, err := rlp.EncodeToBytes(&blockEntry{Hash: block.Hash(), Validators: validators})
+	if err != nil {
+		return err
+	}
+	batch.Put(blockKey(number), enc)
+	idx.writeHead(batch, head)
+	return batch.Write()
+}
+
+// unindexBlock removes a reorged block from the index.
+func (idx *Indexer) unindexBlock(number uint64) {
+	be := idx.readBlock(number)
+	if be == nil {
+		return
+	}
+	for _, validator := range be.Validators {
+		count := idx.readCount(validator)
+		for count > 0 && idx.readNumber(validator, count-1) >= number {
+			count--
+			idx.db.Delete(validatorNumKey(validator, count))
+		}
+		idx.db.Put(validatorKey(validator), encodeNumber(count))
+		idx.db.Delete(validatorLogsKey(validator, number))
+	}
+	idx.db.Delete(blockKey(number))
+}
+
+// History returns the staking operations of a validator between two blocks, inclusive.
+func (idx *Indexer) History(validator common.Address, from, to uint64) ([]ValidatorEvent, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	count := idx.readCount(validator)
+	first := sort.Search(int(count), func(i int) bool {
+		return idx.readNumber(validator, uint64(i)) >= from
+	})
+
+	events := make([]ValidatorEvent, 0)
+	for i := uint64(first); i < count; i++ {
+		number := idx.readNumber(validator, i)
+		if number > to {
+			break
+		}
+
+		data, _ := idx.db.Get(validatorLogsKey(validator, number))
+		var entries []entry
+		if err := rlp.DecodeBytes(data, &entries); err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			event, err := e.decode()
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, newValidatorEvent(event, number, e.TxHash))
+		}
+	}
+	return events, nil
+}
+
+// IndexedRange returns the numbers of the first and the last indexed blocks.
+func (idx *Indexer) IndexedRange() (uint64, uint64) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	head, ok := idx.readHead()
+	if !ok {
+		return 0, 0
+	}
+	return head.Tail, head.Number
+}
+
+func (idx *Indexer) readHead() (indexHead, bool) {
+	var head indexHead
+	data, _ := idx.db.Get(headKey)
+	if len(data) == 0 {
+		return head, false
+	}
+	if err := rlp.DecodeBytes(data, &head); err != nil {
+		log.Error("Invalid validator history head", "err", err)
+		return head, false
+	}
+	return head, true
+}
+
+func (idx *Indexer) writeHead(db ethdb.Putter, head indexHead) {
+	enc, _ := rlp.EncodeToBytes(&head)
+	if err := db.Put(headKey, enc); err != nil {
+		log.Error("Failed to store validator history head", "err", err)
+	}
+}
+
+func (idx *Indexer) readBlock(number uint64) *blockEntry {
+	data, _ := idx.db.Get(blockKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	be := new(blockEntry)
+	if err := rlp.DecodeBytes(data, be); err != nil {
+		log.Error("Invalid validator history block entry", "number", number, "err", err)
+		return nil
+	}
+	return be
+}
+
+func (idx *Indexer) readCount(validator common.Address) uint64 {
+	data, _ := idx.db.Get(validatorKey(validator))
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+func (idx *Indexer) readNumber(validator common.Address, index uint64) uint64 {
+	data, _ := idx.db.Get(validatorNumKey(validator, index))
+	if len(data) != 8 {
+		log.Error("Missing validator history number", "validator", validator, "index", index)
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+func encodeNumber(number uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, number)
+	return enc
+}
+
+func blockKey(number uint64) []byte {
+	return append(append([]byte{}, blockPrefix...), encodeNumber(number)...)
+}
+
+func validatorKey(validator common.Address) []byte {
+	return append(append([]byte{}, validatorPrefix...), validator.Bytes()...)
+}
+
+func validatorNumKey(validator common.Address, index uint64) []byte {
+	key := append(append([]byte{}, validatorNumPrefix...), validator.Bytes()...)
+	return append(key, encodeNumber(index)...)
+}
+
+func validatorLogsKey(validator common.Address, number uint64) []byte {
+	key := append(append([]byte{}, validatorLogsPrefix...), validator.Bytes()...)
+	return append(key, encodeNumber(number)...)
+}
@@ -0,0 +1,170 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package validatorhistory
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/event"
+	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+var (
+	testKey, _ = crypto.GenerateKey()
+	validator  = common.HexToAddress("0x02")
+	other      = common.HexToAddress("0x03")
+)
+
+// testBackend is a canonical chain of blocks calling the staking contract.
+type testBackend struct {
+	db       ethdb.Database
+	blocks   []*types.Block
+	receipts map[common.Hash]types.Receipts
+	heads    event.Feed
+}
+
+func newTestBackend() *testBackend {
+	db, _ := ethdb.NewMemDatabase()
+	b := &testBackend{db: db, receipts: make(map[common.Hash]types.Receipts)}
+	genesis := types.NewBlock(&types.Header{Number: new(big.Int)}, nil, nil, nil)
+	b.blocks = []*types.Block{genesis}
+	core.WriteCanonicalHash(db, genesis.Hash(), 0)
+	return b
+}
+
+// addBlock appends a block with a partnerIn call to each of the validators
+// on top of block parent, dropping the blocks after parent.
+func (b *testBackend) addBlock(t *testing.T, parent int, validators ...common.Address) {
+	signer := types.MakeSigner(params.TestChainConfig, big.NewInt(int64(parent+1)))
+	var (
+		txs      []*types.Transaction
+		receipts types.Receipts
+	)
+	for i, v := range validators {
+		input, err := vm.PackStakingInput("partnerIn", v, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx := types.NewTransaction(uint64(i), vm.WanCscPrecompileAddr, big.NewInt(1), big.NewInt(200000), big.NewInt(1), input)
+		if tx, err = types.SignTx(tx, signer, testKey); err != nil {
+			t.Fatal(err)
+		}
+		txs = append(txs, tx)
+		receipts = append(receipts, types.NewReceipt(nil, false, new(big.Int)))
+	}
+	header := &types.Header{
+		Number:     big.NewInt(int64(parent + 1)),
+		ParentHash: b.blocks[parent].Hash(),
+		Extra:      []byte{byte(len(b.blocks))}, // Sets apart the blocks of a reorg
+	}
+	block := types.NewBlock(header, txs, nil, receipts)
+	for n := parent + 1; n < len(b.blocks); n++ {
+		core.DeleteCanonicalHash(b.db, uint64(n))
+	}
+	b.blocks = append(b.blocks[:parent+1], block)
+	b.receipts[block.Hash()] = receipts
+	core.WriteCanonicalHash(b.db, block.Hash(), block.NumberU64())
+}
+
+func (b *testBackend) head() uint64 { return uint64(len(b.blocks) - 1) }
+
+func (b *testBackend) ChainDb() ethdb.Database          { return b.db }
+func (b *testBackend) ChainConfig() *params.ChainConfig { return params.TestChainConfig }
+func (b *testBackend) CurrentBlock() *types.Block       { return b.blocks[len(b.blocks)-1] }
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.receipts[hash], nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Block, error) {
+	if blockNr < 0 || int(blockNr) >= len(b.blocks) {
+		return nil, nil
+	}
+	return b.blocks[blockNr], nil
+}
+
+func (b *testBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.heads.Subscribe(ch)
+}
+
+func checkHistory(t *testing.T, idx *Indexer, addr common.Address, want ...uint64) {
+	events, err := idx.History(addr, 0, 100)
+	if err != nil {
+		t.Fatalf("failed to get history: %v", err)
+	}
+	if len(events) != len(want) {
+		t.Fatalf("event count mismatch: have %d, want %d", len(events), len(want))
+	}
+	for i, e := range events {
+		if e.BlockNumber != want[i] || e.Event != "partnerIn" || e.Validator != addr {
+			t.Errorf("event %d mismatch: have %+v, want partnerIn at block %d", i, e, want[i])
+		}
+	}
+}
+
+func TestIndexerReorg(t *testing.T) {
+	backend := newTestBackend()
+	idx := New(backend, true)
+
+	// Two calls in the same block are listed once
+	backend.addBlock(t, 0, validator)
+	backend.addBlock(t, 1, validator, validator)
+	backend.addBlock(t, 2, other)
+	backend.addBlock(t, 3, validator)
+	idx.update(backend.head())
+	checkHistory(t, idx, validator, 1, 2, 2, 4)
+	checkHistory(t, idx, other, 3)
+
+	// A longer fork from block 1 drops all the blocks after it
+	backend.addBlock(t, 1)
+	backend.addBlock(t, 2, other)
+	backend.addBlock(t, 3)
+	backend.addBlock(t, 4, validator)
+	idx.update(backend.head())
+	checkHistory(t, idx, validator, 1, 5)
+	checkHistory(t, idx, other, 3)
+
+	// So does a shorter one
+	backend.addBlock(t, 0, other)
+	idx.update(backend.head())
+	checkHistory(t, idx, validator)
+	checkHistory(t, idx, other, 1)
+	if tail, head := idx.IndexedRange(); tail != 0 || head != 1 {
+		t.Errorf("indexed range mismatch: have [%d, %d], want [0, 1]", tail, head)
+	}
+}
+
+func TestIndexerBackfill(t *testing.T) {
+	backend := newTestBackend()
+	backend.addBlock(t, 0, validator)
+	backend.addBlock(t, 1, validator)
+
+	// Without backfill the index starts at the head
+	idx := New(backend, false)
+	idx.update(backend.head())
+	backend.addBlock(t, 2, validator)
+	idx.update(backend.head())
+	checkHistory(t, idx, validator, 3)
+	api := NewPublicValidatorHistoryAPI(idx)
+	if _, err := api.GetValidatorHistory(validator, 0, rpc.LatestBlockNumber); err == nil {
+		t.Errorf("history before the index tail returned no error")
+	}
+
+	// Turning it on later indexes the whole chain
+	idx = New(backend, true)
+	idx.update(backend.head())
+	checkHistory(t, idx, validator, 1, 2, 3)
+	api = NewPublicValidatorHistoryAPI(idx)
+	events, err := api.GetValidatorHistory(validator, 0, rpc.LatestBlockNumber)
+	if err != nil || len(events) != 3 {
+		t.Errorf("history mismatch: have %d events, %v, want 3", len(events), err)
+	}
+}
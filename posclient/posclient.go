@@ -0,0 +1,283 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+// Package posclient provides a client for the pos and privacy RPC APIs of
+// Wanchain, the way ethclient does for the eth API.
+package posclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+// Client defines typed wrappers for the pos and privacy RPC APIs.
+type Client struct {
+	c *rpc.Client
+}
+
+// Dial connects a client to the given URL.
+func Dial(rawurl string) (*Client, error) {
+	c, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+// NewClient creates a client that uses the given RPC client.
+func NewClient(c *rpc.Client) *Client {
+	return &Client{c}
+}
+
+// Close closes the underlying RPC connection.
+func (pc *Client) Close() {
+	pc.c.Close()
+}
+
+// Schedule
+
+// EpochID returns the current epoch.
+func (pc *Client) EpochID(ctx context.Context) (uint64, error) {
+	var epochID uint64
+	err := pc.c.CallContext(ctx, &epochID, "pos_getEpochID")
+	return epochID, err
+}
+
+// SlotID returns the current slot of the current epoch.
+func (pc *Client) SlotID(ctx context.Context) (uint64, error) {
+	var slotID uint64
+	err := pc.c.CallContext(ctx, &slotID, "pos_getSlotID")
+	return slotID, err
+}
+
+// SlotCount returns the number of slots of an epoch.
+func (pc *Client) SlotCount(ctx context.Context) (uint64, error) {
+	var count uint64
+	err := pc.c.CallContext(ctx, &count, "pos_getSlotCount")
+	return count, err
+}
+
+// SlotTime returns the time span of a slot in seconds.
+func (pc *Client) SlotTime(ctx context.Context) (uint64, error) {
+	var slotTime uint64
+	err := pc.c.CallContext(ctx, &slotTime, "pos_getSlotTime")
+	return slotTime, err
+}
+
+// EpochIDByTime returns the epoch of the given unix time.
+func (pc *Client) EpochIDByTime(ctx context.Context, time uint64) (uint64, error) {
+	var epochID uint64
+	err := pc.c.CallContext(ctx, &epochID, "pos_getEpochIDByTime", time)
+	return epochID, err
+}
+
+// TimeByEpochID returns the unix time an epoch starts at.
+func (pc *Client) TimeByEpochID(ctx context.Context, epochID uint64) (uint64, error) {
+	var time uint64
+	err := pc.c.CallContext(ctx, &time, "pos_getTimeByEpochID", epochID)
+	return time, err
+}
+
+// EpochIDByBlockNumber returns the epoch of the given block.
+func (pc *Client) EpochIDByBlockNumber(ctx context.Context, number uint64) (uint64, error) {
+	var epochID uint64
+	err := pc.c.CallContext(ctx, &epochID, "pos_getEpochIdByBlockNumber", number)
+	return epochID, err
+}
+
+// MaxStableBlockNumber returns the highest block which can no longer be reorged.
+func (pc *Client) MaxStableBlockNumber(ctx context.Context) (uint64, error) {
+	var number uint64
+	err := pc.c.CallContext(ctx, &number, "pos_getMaxStableBlkNumber")
+	return number, err
+}
+
+// PosInfo returns the first epoch and block of the pos stage.
+func (pc *Client) PosInfo(ctx context.Context) (*PosInfoJson, error) {
+	var info PosInfoJson
+	if err := pc.c.CallContext(ctx, &info, "pos_getPosInfo"); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Leaders
+
+// EpochLeaders returns the addresses of the epoch leaders of an epoch.
+func (pc *Client) EpochLeaders(ctx context.Context, epochID uint64) ([]common.Address, error) {
+	var leaders []common.Address
+	err := pc.c.CallContext(ctx, &leaders, "pos_getEpochLeadersAddrByEpochID", epochID)
+	return leaders, err
+}
+
+// RandomProposers returns the addresses of the random proposers of an epoch.
+func (pc *Client) RandomProposers(ctx context.Context, epochID uint64) ([]common.Address, error) {
+	var proposers []common.Address
+	err := pc.c.CallContext(ctx, &proposers, "pos_getRandomProposersAddrByEpochID", epochID)
+	return proposers, err
+}
+
+// LeaderGroup returns the white listed leader group of an epoch.
+func (pc *Client) LeaderGroup(ctx context.Context, epochID uint64) ([]LeaderJson, error) {
+	var leaders []LeaderJson
+	err := pc.c.CallContext(ctx, &leaders, "pos_getLeaderGroupByEpochID", epochID)
+	return leaders, err
+}
+
+// SlotLeader returns the secp256k1 public key of the leader of a slot.
+func (pc *Client) SlotLeader(ctx context.Context, epochID, slotID uint64) ([]byte, error) {
+	var result string
+	if err := pc.c.CallContext(ctx, &result, "pos_getSlotLeaderByEpochIDAndSlotID", epochID, slotID); err != nil {
+		return nil, err
+	}
+	// The node reports its failures in place of the key
+	pk, err := hexutil.Decode("0x" + result)
+	if err != nil {
+		return nil, errors.New(result)
+	}
+	return pk, nil
+}
+
+// Random returns the random number of an epoch at the given block. If number
+// is nil, the latest known block is used.
+func (pc *Client) Random(ctx context.Context, epochID uint64, number *big.Int) (*big.Int, error) {
+	blockNr := int64(-1)
+	if number != nil {
+		blockNr = number.Int64()
+	}
+	var random hexutil.Big
+	err := pc.c.CallContext(ctx, &random, "pos_getRandom", epochID, blockNr)
+	return (*big.Int)(&random), err
+}
+
+// EpochBlockCount returns the number of blocks sealed in an epoch.
+func (pc *Client) EpochBlockCount(ctx context.Context, epochID uint64) (uint64, error) {
+	var count uint64
+	err := pc.c.CallContext(ctx, &count, "pos_getEpochBlkCnt", epochID)
+	return count, err
+}
+
+// Stakers
+
+// StakerInfo returns the validators registered at the given block.
+func (pc *Client) StakerInfo(ctx context.Context, number uint64) ([]*StakerJson, error) {
+	var stakers []*StakerJson
+	err := pc.c.CallContext(ctx, &stakers, "pos_getStakerInfo", number)
+	return stakers, err
+}
+
+// EpochStakerInfo returns the election probability of a validator in an epoch.
+func (pc *Client) EpochStakerInfo(ctx context.Context, epochID uint64, validator common.Address) (*ApiStakerInfo, error) {
+	var info ApiStakerInfo
+	if err := pc.c.CallContext(ctx, &info, "pos_getEpochStakerInfo", epochID, validator); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// EpochStakerInfoAll returns the election probability of every validator in
+// an epoch.
+func (pc *Client) EpochStakerInfoAll(ctx context.Context, epochID uint64) ([]ApiStakerInfo, error) {
+	var infos []ApiStakerInfo
+	err := pc.c.CallContext(ctx, &infos, "pos_getEpochStakerInfoAll", epochID)
+	return infos, err
+}
+
+// EpochStakeOut returns the stake returned at the end of an epoch.
+func (pc *Client) EpochStakeOut(ctx context.Context, epochID uint64) ([]RefundInfo, error) {
+	var refunds []RefundInfo
+	err := pc.c.CallContext(ctx, &refunds, "pos_getEpochStakeOut", epochID)
+	return refunds, err
+}
+
+// Incentives
+
+// EpochIncentivePayDetail returns the incentive paid to every validator and
+// delegator for an epoch.
+func (pc *Client) EpochIncentivePayDetail(ctx context.Context, epochID uint64) ([]ValidatorInfo, error) {
+	var infos []ValidatorInfo
+	err := pc.c.CallContext(ctx, &infos, "pos_getEpochIncentivePayDetail", epochID)
+	return infos, err
+}
+
+// EpochIncentiveBlockNumber returns the block the incentive of an epoch was
+// paid in.
+func (pc *Client) EpochIncentiveBlockNumber(ctx context.Context, epochID uint64) (uint64, error) {
+	var number uint64
+	err := pc.c.CallContext(ctx, &number, "pos_getEpochIncentiveBlockNumber", epochID)
+	return number, err
+}
+
+// EpochIncentive returns the incentive paid for an epoch.
+func (pc *Client) EpochIncentive(ctx context.Context, epochID uint64) (*big.Int, error) {
+	return pc.getAmount(ctx, "pos_getEpochIncentive", epochID)
+}
+
+// TotalIncentive returns the incentive paid since the start of the pos stage.
+func (pc *Client) TotalIncentive(ctx context.Context) (*big.Int, error) {
+	return pc.getAmount(ctx, "pos_getTotalIncentive")
+}
+
+// getAmount calls a method reporting an amount as a decimal string.
+func (pc *Client) getAmount(ctx context.Context, method string, args ...interface{}) (*big.Int, error) {
+	var result string
+	if err := pc.c.CallContext(ctx, &result, method, args...); err != nil {
+		return nil, err
+	}
+	amount, ok := new(big.Int).SetString(result, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s result %q", method, result)
+	}
+	return amount, nil
+}
+
+// Activity returns the activity of the leaders of an epoch.
+func (pc *Client) Activity(ctx context.Context, epochID uint64) (*Activity, error) {
+	var activity *Activity
+	err := pc.c.CallContext(ctx, &activity, "pos_getActivity", epochID)
+	return activity, err
+}
+
+// SlotActivity returns the activity of the slot leaders of an epoch.
+func (pc *Client) SlotActivity(ctx context.Context, epochID uint64) (*SlotActivity, error) {
+	var activity *SlotActivity
+	err := pc.c.CallContext(ctx, &activity, "pos_getSlotActivity", epochID)
+	return activity, err
+}
+
+// ValidatorActivity returns the activity of the epoch leaders and random
+// proposers of an epoch.
+func (pc *Client) ValidatorActivity(ctx context.Context, epochID uint64) (*ValidatorActivity, error) {
+	var activity *ValidatorActivity
+	err := pc.c.CallContext(ctx, &activity, "pos_getValidatorActivity", epochID)
+	return activity, err
+}
+
+// EpRnpActivity returns the activity of the epoch leaders and random proposers
+// of an epoch.
+func (pc *Client) EpRnpActivity(ctx context.Context, epochID uint64) (*EpRnpActivity, error) {
+	var activity *EpRnpActivity
+	err := pc.c.CallContext(ctx, &activity, "pos_getEpRnpActivity", epochID)
+	return activity, err
+}
+
+// Epoch summaries
+
+// EpochSummary returns the summary of a final epoch.
+func (pc *Client) EpochSummary(ctx context.Context, epochID uint64) (*EpochSummary, error) {
+	var summary *EpochSummary
+	err := pc.c.CallContext(ctx, &summary, "pos_getEpochSummary", epochID)
+	return summary, err
+}
+
+// SubscribeEpochSummary subscribes to the summary of every epoch once it is
+// final.
+func (pc *Client) SubscribeEpochSummary(ctx context.Context, ch chan<- *EpochSummary) (ethereum.Subscription, error) {
+	return pc.c.Subscribe(ctx, "pos", ch, "epochSummary")
+}
@@ -0,0 +1,204 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package posclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/common/math"
+	"github.com/wanchain/go-wanchain/pos/posapi"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+var (
+	testValidator = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	testDelegator = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	testOTA       = hexutil.MustDecode("0x03" + string(bytes.Repeat([]byte("ab"), 32)) + "02" + string(bytes.Repeat([]byte("cd"), 32)))
+)
+
+// PosService serves the pos namespace with the result types of posapi.
+type PosService struct{}
+
+func (PosService) GetEpochID() uint64 { return 18100 }
+
+func (PosService) GetSlotLeaderByEpochIDAndSlotID(epochID, slotID uint64) string {
+	if slotID > 0 {
+		return "slot leader not found"
+	}
+	return "04abcd"
+}
+
+func (PosService) GetRandom(epochID uint64, blockNr int64) (*big.Int, error) {
+	if blockNr != -1 {
+		return nil, errors.New("no random number exists")
+	}
+	return big.NewInt(int64(epochID)), nil
+}
+
+func (PosService) GetEpochStakerInfoAll(epochID uint64) ([]posapi.ApiStakerInfo, error) {
+	return []posapi.ApiStakerInfo{{
+		Addr: testValidator,
+		Infors: []posapi.ApiClientProbability{
+			{Addr: testValidator, Probability: (*math.HexOrDecimal256)(big.NewInt(300))},
+			{Addr: testDelegator, Probability: (*math.HexOrDecimal256)(big.NewInt(200))},
+		},
+		FeeRate:          1500,
+		TotalProbability: (*math.HexOrDecimal256)(big.NewInt(500)),
+	}}, nil
+}
+
+func (PosService) GetEpochIncentivePayDetail(epochID uint64) ([]posapi.ValidatorInfo, error) {
+	return []posapi.ValidatorInfo{{
+		Address:       testValidator,
+		WalletAddress: testValidator,
+		Incentive:     (*math.HexOrDecimal256)(big.NewInt(1000)),
+		Type:          "validator",
+		Delegators: []posapi.DelegatorInfo{
+			{Address: testDelegator, Incentive: (*math.HexOrDecimal256)(big.NewInt(10)), Type: "delegator"},
+		},
+	}}, nil
+}
+
+func (PosService) GetEpochIncentive(epochID uint64) (string, error) {
+	return "123456789012345678901234567890", nil
+}
+
+func (PosService) GetTotalIncentive() (string, error) {
+	return "Not POS stage.", nil
+}
+
+func (PosService) GetActivity(epochID uint64) (*posapi.Activity, error) {
+	return &posapi.Activity{
+		EpLeader:    []common.Address{testValidator},
+		EpActivity:  []int{1},
+		RpLeader:    []common.Address{testDelegator},
+		RpActivity:  []int{0},
+		SltLeader:   []common.Address{testValidator},
+		SlBlocks:    []int{120},
+		SlActivity:  0.5,
+		SlCtrlCount: 3,
+	}, nil
+}
+
+// WanService serves the privacy methods of the wan namespace.
+type WanService struct{}
+
+func (WanService) GetOTAMixSet(ctx context.Context, otaAddr string, setLen int) ([]string, error) {
+	set := make([]string, setLen)
+	for i := range set {
+		set[i] = otaAddr
+	}
+	return set, nil
+}
+
+func (WanService) GetOTABalance(ctx context.Context, otaWAddr string, blockNr rpc.BlockNumber) (*big.Int, error) {
+	if otaWAddr != hexutil.Encode(testOTA) || blockNr != rpc.LatestBlockNumber {
+		return nil, errors.New("unexpected arguments")
+	}
+	return big.NewInt(1e18), nil
+}
+
+func newTestClient(t *testing.T) *Client {
+	server := rpc.NewServer()
+	if err := server.RegisterName("pos", new(PosService)); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterName("wan", new(WanService)); err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(rpc.DialInProc(server))
+}
+
+func TestSchedule(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	epochID, err := client.EpochID(ctx)
+	if err != nil || epochID != 18100 {
+		t.Fatalf("epoch id mismatch: have %d, %v, want 18100", epochID, err)
+	}
+	pk, err := client.SlotLeader(ctx, epochID, 0)
+	if err != nil || !bytes.Equal(pk, []byte{0x04, 0xab, 0xcd}) {
+		t.Fatalf("slot leader mismatch: have %x, %v", pk, err)
+	}
+	if _, err := client.SlotLeader(ctx, epochID, 1); err == nil || err.Error() != "slot leader not found" {
+		t.Fatalf("slot leader error mismatch: have %v", err)
+	}
+	random, err := client.Random(ctx, epochID, nil)
+	if err != nil || random.Uint64() != epochID {
+		t.Fatalf("random mismatch: have %v, %v, want %d", random, err, epochID)
+	}
+	if _, err := client.Random(ctx, epochID, big.NewInt(5)); err == nil {
+		t.Fatalf("random of a missing block succeeded")
+	}
+}
+
+func TestStakersAndIncentives(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	stakers, err := client.EpochStakerInfoAll(ctx, 18100)
+	if err != nil {
+		t.Fatalf("failed to get stakers: %v", err)
+	}
+	want, _ := PosService{}.GetEpochStakerInfoAll(18100)
+	if len(stakers) != 1 || stakers[0].Addr != want[0].Addr || stakers[0].FeeRate != want[0].FeeRate ||
+		len(stakers[0].Infors) != 2 || (*big.Int)(stakers[0].TotalProbability).Cmp((*big.Int)(want[0].TotalProbability)) != 0 {
+		t.Errorf("stakers mismatch: have %+v, want %+v", stakers, want)
+	}
+
+	pays, err := client.EpochIncentivePayDetail(ctx, 18100)
+	if err != nil {
+		t.Fatalf("failed to get incentive pay detail: %v", err)
+	}
+	if len(pays) != 1 || pays[0].Type != "validator" || (*big.Int)(pays[0].Incentive).Int64() != 1000 ||
+		len(pays[0].Delegators) != 1 || pays[0].Delegators[0].Address != testDelegator {
+		t.Errorf("incentive pay detail mismatch: have %+v", pays)
+	}
+
+	incentive, err := client.EpochIncentive(ctx, 18100)
+	if err != nil || incentive.String() != "123456789012345678901234567890" {
+		t.Errorf("epoch incentive mismatch: have %v, %v", incentive, err)
+	}
+	if _, err := client.TotalIncentive(ctx); err == nil {
+		t.Errorf("non numeric total incentive accepted")
+	}
+
+	activity, err := client.Activity(ctx, 18100)
+	if err != nil {
+		t.Fatalf("failed to get activity: %v", err)
+	}
+	wantActivity, _ := PosService{}.GetActivity(18100)
+	if !reflect.DeepEqual(*activity, Activity(*wantActivity)) {
+		t.Errorf("activity mismatch: have %+v, want %+v", activity, wantActivity)
+	}
+}
+
+func TestPrivacy(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	balance, err := client.OTABalance(ctx, testOTA, nil)
+	if err != nil || balance.Cmp(big.NewInt(1e18)) != 0 {
+		t.Fatalf("ota balance mismatch: have %v, %v", balance, err)
+	}
+	set, err := client.OTAMixSet(ctx, testOTA, 3)
+	if err != nil || len(set) != 3 {
+		t.Fatalf("mix set mismatch: have %d, %v, want 3", len(set), err)
+	}
+	for i, ota := range set {
+		if !bytes.Equal(ota, testOTA) {
+			t.Errorf("mix set ota %d mismatch: have %x, want %x", i, ota, testOTA)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package posclient
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+)
+
+// WanAddress returns the wan address of an account managed by the node, the
+// public key pair privacy transactions are sent to.
+func (pc *Client) WanAddress(ctx context.Context, account common.Address) ([]byte, error) {
+	var wanAddr hexutil.Bytes
+	err := pc.c.CallContext(ctx, &wanAddr, "wan_getWanAddress", account)
+	return wanAddr, err
+}
+
+// GenerateOneTimeAddress returns a fresh one-time address of a wan address.
+func (pc *Client) GenerateOneTimeAddress(ctx context.Context, wanAddr []byte) ([]byte, error) {
+	var ota hexutil.Bytes
+	err := pc.c.CallContext(ctx, &ota, "wan_generateOneTimeAddress", hexutil.Bytes(wanAddr))
+	return ota, err
+}
+
+// OTABalance returns the balance of a one-time address at the given block. If
+// number is nil, the latest known block is used.
+func (pc *Client) OTABalance(ctx context.Context, ota []byte, number *big.Int) (*big.Int, error) {
+	var balance hexutil.Big
+	err := pc.c.CallContext(ctx, &balance, "wan_getOTABalance", hexutil.Bytes(ota), toBlockNumArg(number))
+	return (*big.Int)(&balance), err
+}
+
+// AccountOTABalance returns the unspent balance of the one-time addresses of
+// the accounts managed by the node at the given block. If number is nil, the
+// latest known block is used.
+func (pc *Client) AccountOTABalance(ctx context.Context, number *big.Int) (*big.Int, error) {
+	var balance hexutil.Big
+	err := pc.c.CallContext(ctx, &balance, "personal_getOTABalance", toBlockNumArg(number))
+	return (*big.Int)(&balance), err
+}
+
+// OTAMixSet returns size one-time addresses of the same balance as ota, to
+// hide the spent one in the ring signature of a refund.
+func (pc *Client) OTAMixSet(ctx context.Context, ota []byte, size int) ([][]byte, error) {
+	var set []hexutil.Bytes
+	if err := pc.c.CallContext(ctx, &set, "wan_getOTAMixSet", hexutil.Bytes(ota), size); err != nil {
+		return nil, err
+	}
+	mixSet := make([][]byte, len(set))
+	for i, ota := range set {
+		mixSet[i] = ota
+	}
+	return mixSet, nil
+}
+
+// OTAUsed reports whether the key image of a one-time address was spent.
+func (pc *Client) OTAUsed(ctx context.Context, image []byte) (bool, error) {
+	var used bool
+	err := pc.c.CallContext(ctx, &used, "wan_checkOTAUsed", hexutil.Bytes(image))
+	return used, err
+}
+
+// SendPrivacyCxtTransaction sends a privacy transaction signed by the node with
+// the private key of the one-time address it spends.
+func (pc *Client) SendPrivacyCxtTransaction(ctx context.Context, args TxArgs, otaPrivateKey string) (common.Hash, error) {
+	var hash common.Hash
+	err := pc.c.CallContext(ctx, &hash, "personal_sendPrivacyCxtTransaction", args, otaPrivateKey)
+	return hash, err
+}
+
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(number)
+}
@@ -0,0 +1,163 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package posclient
+
+import (
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/common/math"
+)
+
+// The result types below mirror the ones of pos/posapi, so they decode the
+// responses of the pos namespace without pulling the node into the client.
+
+// ValidatorActivity is the activity of the epoch leaders and random proposers.
+type ValidatorActivity struct {
+	EpLeader   []common.Address `json:"epLeader"`
+	EpActivity []int            `json:"epActivity"`
+	RpLeader   []common.Address `json:"rpLeader"`
+	RpActivity []int            `json:"rpActivity"`
+}
+
+// SlotActivity is the activity of the slot leaders.
+type SlotActivity struct {
+	SltLeader   []common.Address `json:"sltLeader"`
+	SlBlocks    []int            `json:"slBlocks"`
+	SlActivity  float64          `json:"slActivity"`
+	SlCtrlCount int              `json:"slCtrlCount"`
+}
+
+// Activity is the activity of the epoch leaders, random proposers and slot
+// leaders of an epoch.
+type Activity struct {
+	EpLeader    []common.Address `json:"epLeader"`
+	EpActivity  []int            `json:"epActivity"`
+	RpLeader    []common.Address `json:"rpLeader"`
+	RpActivity  []int            `json:"rpActivity"`
+	SltLeader   []common.Address `json:"sltLeader"`
+	SlBlocks    []int            `json:"slBlocks"`
+	SlActivity  float64          `json:"slActivity"`
+	SlCtrlCount int              `json:"slCtrlCount"`
+}
+
+// EpRnpActivity is the activity of the epoch leaders and random proposers.
+type EpRnpActivity struct {
+	EpLeader   []common.Address `json:"epLeader"`
+	EpActivity []int            `json:"epActivity"`
+	RpLeader   []common.Address `json:"rpLeader"`
+	RpActivity []int            `json:"rpActivity"`
+}
+
+// ValidatorInfo is the incentive paid to a validator and its delegators.
+type ValidatorInfo struct {
+	Address       common.Address        `json:"address"`
+	WalletAddress common.Address        `json:"stakeInFromAddr"`
+	Incentive     *math.HexOrDecimal256 `json:"incentive"`
+	Type          string                `json:"type"`
+	Delegators    []DelegatorInfo       `json:"delegators"`
+}
+
+// DelegatorInfo is the incentive paid to a delegator.
+type DelegatorInfo struct {
+	Address   common.Address        `json:"address"`
+	Incentive *math.HexOrDecimal256 `json:"incentive"`
+	Type      string                `json:"type"`
+}
+
+// ApiClientProbability is the election probability of a staker.
+type ApiClientProbability struct {
+	Addr        common.Address
+	Probability *math.HexOrDecimal256
+}
+
+// ApiStakerInfo is the election probability of a validator and its clients in
+// an epoch.
+type ApiStakerInfo struct {
+	Addr             common.Address
+	Infors           []ApiClientProbability
+	FeeRate          uint64
+	TotalProbability *math.HexOrDecimal256
+}
+
+// ClientInfo is a delegator of a validator.
+type ClientInfo struct {
+	Address     common.Address        `json:"address"`
+	Amount      *math.HexOrDecimal256 `json:"amount"`
+	StakeAmount *math.HexOrDecimal256 `json:"votingPower"`
+	QuitEpoch   uint64                `json:"quitEpoch"`
+}
+
+// PartnerInfo is a partner of a validator.
+type PartnerInfo struct {
+	Address      common.Address        `json:"address"`
+	Amount       *math.HexOrDecimal256 `json:"amount"`
+	StakeAmount  *math.HexOrDecimal256 `json:"votingPower"`
+	Renewal      bool                  `json:"renewal"`
+	LockEpochs   uint64                `json:"lockEpochs"`
+	StakingEpoch uint64                `json:"stakingEpoch"`
+}
+
+// StakerJson is a validator registered in the staking contract.
+type StakerJson struct {
+	Address   common.Address `json:"address"`
+	PubSec256 string         `json:"pubSec256"`
+	PubBn256  string         `json:"pubBn256"`
+
+	Amount         *math.HexOrDecimal256 `json:"amount"`
+	StakeAmount    *math.HexOrDecimal256 `json:"votingPower"`
+	LockEpochs     uint64                `json:"lockEpochs"`
+	NextLockEpochs uint64                `json:"nextLockEpochs"`
+	From           common.Address        `json:"from"`
+
+	StakingEpoch uint64        `json:"stakingEpoch"`
+	FeeRate      uint64        `json:"feeRate"`
+	Clients      []ClientInfo  `json:"clients"`
+	Partners     []PartnerInfo `json:"partners"`
+
+	MaxFeeRate          uint64 `json:"maxFeeRate"`
+	FeeRateChangedEpoch uint64 `json:"feeRateChangedEpoch"`
+}
+
+// RefundInfo is the stake returned to an address.
+type RefundInfo struct {
+	Addr   common.Address        `json:"address"`
+	Amount *math.HexOrDecimal256 `json:"amount"`
+}
+
+// PosInfoJson is where the pos stage of the chain starts.
+type PosInfoJson struct {
+	FirstEpochId     uint64 `json:"firstEpochId"`
+	FirstBlockNumber uint64 `json:"firstBlockNumber"`
+}
+
+// LeaderJson is a member of the leader group of an epoch.
+type LeaderJson struct {
+	Type      uint8          `json:"type"`
+	SecAddr   common.Address `json:"secAddr"`
+	PubSec256 string         `json:"pubSec256"`
+	PubBn256  string         `json:"pubBn256"`
+}
+
+// EpochSummary is the summary of a final epoch.
+type EpochSummary struct {
+	EpochID              uint64           `json:"epochId"`
+	EpochLeaders         []common.Address `json:"epochLeaders"`
+	RandomProposers      []common.Address `json:"randomProposers"`
+	Random               *hexutil.Big     `json:"random"`
+	IncentiveBlockNumber uint64           `json:"incentiveBlockNumber"`
+	TotalIncentive       string           `json:"totalIncentive"`
+	IncentivePay         []ValidatorInfo  `json:"incentivePay"`
+	Activity             *Activity        `json:"activity"`
+	ActivityUnavailable  bool             `json:"activityUnavailable,omitempty"`
+}
+
+// TxArgs are the arguments of a privacy transaction sent through the node.
+type TxArgs struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      *hexutil.Big    `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     hexutil.Bytes   `json:"data"`
+	Nonce    *hexutil.Uint64 `json:"nonce"`
+}
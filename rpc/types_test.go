@@ -46,6 +46,8 @@ func TestBlockNumberJSONUnmarshal(t *testing.T) {
 		14: {`someString`, true, BlockNumber(0)},
 		15: {`""`, true, BlockNumber(0)},
 		16: {``, true, BlockNumber(0)},
+		17: {`"stable"`, false, StableBlockNumber},
+		18: {`"finalized"`, false, StableBlockNumber},
 	}
 
 	for i, test := range tests {
@@ -55,6 +55,11 @@ var Forks = map[string]*params.ChainConfig{
 		//DAOForkBlock:   big.NewInt(0),
 		ByzantiumBlock: big.NewInt(0),
 	},
+	"Istanbul": &params.ChainConfig{
+		ChainId:        big.NewInt(1),
+		ByzantiumBlock: big.NewInt(0),
+		IstanbulBlock:  big.NewInt(0),
+	},
 //
 //	"FrontierToHomesteadAt5": &params.ChainConfig{
 //		ChainId:        big.NewInt(1),
@@ -0,0 +1,264 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package trie
+
+import (
+	"sync"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/log"
+)
+
+// LeafCallback returns the hashes of the stored blobs a trie leaf refers to,
+// e.g. the storage trie root and the code of an account.
+type LeafCallback func(leaf []byte) []common.Hash
+
+// NodeCache is a reference counted in-memory store of trie nodes sitting in
+// front of a persistent database. Tries committed into the cache keep their
+// nodes in memory until the root referencing them is either dereferenced,
+// dropping the nodes no other root refers to, or committed to the database.
+//
+// Blobs keyed by anything but a hash, e.g. the preimages of the secure trie,
+// are written through to the database.
+type NodeCache struct {
+	diskdb ethdb.Database
+	onleaf LeafCallback
+
+	lock  sync.RWMutex
+	nodes map[common.Hash]*cachedNode
+	size  common.StorageSize // Storage size of the cached nodes
+
+	gcnodes uint64             // Nodes garbage collected since the last commit
+	gcsize  common.StorageSize // Storage garbage collected since the last commit
+}
+
+// cachedNode is a trie node held by a NodeCache.
+type cachedNode struct {
+	blob     []byte
+	parents  int                 // Number of live nodes and roots referencing this one
+	children map[common.Hash]int // Cached nodes this one references
+}
+
+// NewNodeCache creates a trie node cache on top of the given database. The
+// leaf callback, if any, is used to reference the blobs trie leaves refer to.
+func NewNodeCache(diskdb ethdb.Database, onleaf LeafCallback) *NodeCache {
+	return &NodeCache{
+		diskdb: diskdb,
+		onleaf: onleaf,
+		nodes:  make(map[common.Hash]*cachedNode),
+	}
+}
+
+// DiskDB returns the persistent database beneath the cache.
+func (c *NodeCache) DiskDB() ethdb.Database {
+	return c.diskdb
+}
+
+// Put implements DatabaseWriter, caching a trie node and referencing the
+// cached nodes it refers to. Nodes are stored after their children, so the
+// children of a node are cached, or persisted, by the time it is put.
+func (c *NodeCache) Put(key, value []byte) error {
+	if len(key) != common.HashLength {
+		return c.diskdb.Put(key, value)
+	}
+	hash := common.BytesToHash(key)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.nodes[hash]; ok {
+		return nil
+	}
+	entry := &cachedNode{blob: common.CopyBytes(value)}
+	// Blobs that are not trie nodes, i.e. contract code, refer to nothing
+	if n, err := decodeNode(key, value, 0); err == nil {
+		c.reference(entry, n)
+	}
+	c.nodes[hash] = entry
+	c.size += common.StorageSize(common.HashLength + len(value))
+	return nil
+}
+
+// reference adds a reference from entry to the cached nodes referred to by the
+// given node, walking into the nodes embedded in it.
+func (c *NodeCache) reference(entry *cachedNode, n node) {
+	switch n := n.(type) {
+	case *shortNode:
+		c.reference(entry, n.Val)
+	case *fullNode:
+		for _, child := range n.Children {
+			c.reference(entry, child)
+		}
+	case hashNode:
+		c.addChild(entry, common.BytesToHash(n))
+	case valueNode:
+		if c.onleaf != nil {
+			for _, hash := range c.onleaf(n) {
+				c.addChild(entry, hash)
+			}
+		}
+	}
+}
+
+func (c *NodeCache) addChild(entry *cachedNode, hash common.Hash) {
+	child, ok := c.nodes[hash]
+	if !ok {
+		// Not cached, the child is in the database already
+		return
+	}
+	if entry.children == nil {
+		entry.children = make(map[common.Hash]int)
+	}
+	entry.children[hash]++
+	child.parents++
+}
+
+// Get implements DatabaseReader, serving cached nodes from memory and
+// anything else from the database.
+func (c *NodeCache) Get(key []byte) ([]byte, error) {
+	if len(key) == common.HashLength {
+		c.lock.RLock()
+		entry, ok := c.nodes[common.BytesToHash(key)]
+		c.lock.RUnlock()
+
+		if ok {
+			return entry.blob, nil
+		}
+	}
+	return c.diskdb.Get(key)
+}
+
+// Has implements DatabaseReader.
+func (c *NodeCache) Has(key []byte) (bool, error) {
+	if len(key) == common.HashLength {
+		c.lock.RLock()
+		_, ok := c.nodes[common.BytesToHash(key)]
+		c.lock.RUnlock()
+
+		if ok {
+			return true, nil
+		}
+	}
+	return c.diskdb.Has(key)
+}
+
+// Reference adds an external reference to a cached root, keeping the trie
+// below it in memory until it is dereferenced.
+func (c *NodeCache) Reference(root common.Hash) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if entry, ok := c.nodes[root]; ok {
+		entry.parents++
+	}
+}
+
+// Dereference removes an external reference from a cached root, dropping the
+// nodes below it that are no longer referenced.
+func (c *NodeCache) Dereference(root common.Hash) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	nodes, size, start := len(c.nodes), c.size, time.Now()
+	c.dereference(root)
+
+	c.gcnodes += uint64(nodes - len(c.nodes))
+	c.gcsize += size - c.size
+
+	log.Debug("Dereferenced trie from memory", "nodes", nodes-len(c.nodes), "size", size-c.size, "time", time.Since(start),
+		"gcnodes", c.gcnodes, "gcsize", c.gcsize, "livenodes", len(c.nodes), "livesize", c.size)
+}
+
+func (c *NodeCache) dereference(hash common.Hash) {
+	entry, ok := c.nodes[hash]
+	if !ok {
+		return
+	}
+	if entry.parents > 0 {
+		entry.parents--
+	}
+	if entry.parents == 0 {
+		for child, count := range entry.children {
+			for i := 0; i < count; i++ {
+				c.dereference(child)
+			}
+		}
+		delete(c.nodes, hash)
+		c.size -= common.StorageSize(common.HashLength + len(entry.blob))
+	}
+}
+
+// Commit writes the cached trie below the given root to the database and
+// drops it from memory. Other cached roots sharing nodes with it read them
+// from the database afterwards.
+func (c *NodeCache) Commit(root common.Hash) error {
+	start := time.Now()
+	batch := c.diskdb.NewBatch()
+
+	// The nodes stay cached while being written, so readers always find them
+	c.lock.RLock()
+	nodes, size := len(c.nodes), c.size
+	err := c.commit(root, batch, make(map[common.Hash]struct{}))
+	c.lock.RUnlock()
+
+	if err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	c.lock.Lock()
+	c.uncache(root)
+	log.Info("Persisted trie from memory", "nodes", nodes-len(c.nodes), "size", size-c.size, "time", time.Since(start),
+		"gcnodes", c.gcnodes, "gcsize", c.gcsize, "livenodes", len(c.nodes), "livesize", c.size)
+	c.gcnodes, c.gcsize = 0, 0
+	c.lock.Unlock()
+
+	return nil
+}
+
+// commit writes the cached nodes below a hash into the batch, children first.
+func (c *NodeCache) commit(hash common.Hash, batch ethdb.Batch, done map[common.Hash]struct{}) error {
+	entry, ok := c.nodes[hash]
+	if !ok {
+		return nil
+	}
+	if _, ok := done[hash]; ok {
+		return nil
+	}
+	for child := range entry.children {
+		if err := c.commit(child, batch, done); err != nil {
+			return err
+		}
+	}
+	if err := batch.Put(hash[:], entry.blob); err != nil {
+		return err
+	}
+	done[hash] = struct{}{}
+	return nil
+}
+
+// uncache drops a persisted node and its cached children from memory. The
+// parents still cached keep their counts, their children are then skipped.
+func (c *NodeCache) uncache(hash common.Hash) {
+	entry, ok := c.nodes[hash]
+	if !ok {
+		return
+	}
+	delete(c.nodes, hash)
+	c.size -= common.StorageSize(common.HashLength + len(entry.blob))
+
+	for child := range entry.children {
+		c.uncache(child)
+	}
+}
+
+// Size returns the number and the storage size of the cached nodes.
+func (c *NodeCache) Size() (int, common.StorageSize) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return len(c.nodes), c.size
+}
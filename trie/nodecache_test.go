@@ -0,0 +1,117 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package trie
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/ethdb"
+)
+
+// fillTrie inserts the given number of keys, prefixed to make the values of
+// distinct tries differ, and commits the trie into the cache.
+func fillTrie(t *testing.T, trie *Trie, cache *NodeCache, prefix string, n int) common.Hash {
+	for i := 0; i < n; i++ {
+		key := []byte(fmt.Sprintf("key-%04d", i))
+		trie.Update(key, []byte(fmt.Sprintf("%s-value-%04d-padded-beyond-a-hash", prefix, i)))
+	}
+	root, err := trie.CommitTo(cache)
+	if err != nil {
+		t.Fatalf("failed to commit trie: %v", err)
+	}
+	return root
+}
+
+func checkTrie(t *testing.T, root common.Hash, db Database, prefix string, n int) {
+	trie, err := New(root, db)
+	if err != nil {
+		t.Fatalf("failed to open trie %x: %v", root, err)
+	}
+	for i := 0; i < n; i++ {
+		want := fmt.Sprintf("%s-value-%04d-padded-beyond-a-hash", prefix, i)
+		if have, err := trie.TryGet([]byte(fmt.Sprintf("key-%04d", i))); err != nil || string(have) != want {
+			t.Fatalf("trie %x value %d mismatch: have %q (%v), want %q", root, i, have, err, want)
+		}
+	}
+}
+
+func TestNodeCacheDereference(t *testing.T) {
+	diskdb, _ := ethdb.NewMemDatabase()
+	cache := NewNodeCache(diskdb, nil)
+
+	trie, _ := New(common.Hash{}, cache)
+	first := fillTrie(t, trie, cache, "first", 100)
+	cache.Reference(first)
+
+	// Rewrite half the keys, sharing the other half of the nodes
+	second := fillTrie(t, trie, cache, "second", 50)
+	cache.Reference(second)
+
+	if len(diskdb.Keys()) != 0 {
+		t.Fatalf("cached nodes written to disk: %d", len(diskdb.Keys()))
+	}
+	live, _ := cache.Size()
+	cache.Dereference(first)
+	if nodes, _ := cache.Size(); nodes >= live {
+		t.Errorf("dereferenced nodes not dropped: have %d nodes, had %d", nodes, live)
+	}
+	if _, err := New(first, cache); err == nil {
+		t.Errorf("dereferenced root still cached")
+	}
+	checkTrie(t, second, cache, "second", 50)
+
+	cache.Dereference(second)
+	if nodes, size := cache.Size(); nodes != 0 || size != 0 {
+		t.Errorf("cache not empty: %d nodes, %v", nodes, size)
+	}
+}
+
+func TestNodeCacheCommit(t *testing.T) {
+	diskdb, _ := ethdb.NewMemDatabase()
+	cache := NewNodeCache(diskdb, nil)
+
+	trie, _ := New(common.Hash{}, cache)
+	first := fillTrie(t, trie, cache, "first", 100)
+	cache.Reference(first)
+	second := fillTrie(t, trie, cache, "second", 50)
+	cache.Reference(second)
+
+	if err := cache.Commit(second); err != nil {
+		t.Fatalf("failed to commit trie: %v", err)
+	}
+	checkTrie(t, second, diskdb, "second", 50)
+
+	// The first trie reads its shared nodes from disk, and drops its own
+	checkTrie(t, first, cache, "first", 100)
+	cache.Dereference(first)
+	if nodes, _ := cache.Size(); nodes != 0 {
+		t.Errorf("cache not empty: %d nodes", nodes)
+	}
+	checkTrie(t, second, cache, "second", 50)
+}
+
+func TestNodeCacheLeafReferences(t *testing.T) {
+	diskdb, _ := ethdb.NewMemDatabase()
+	blob := []byte("a blob referenced by the leaves, longer than a hash")
+	blobHash := crypto.Keccak256Hash(blob)
+
+	cache := NewNodeCache(diskdb, func(leaf []byte) []common.Hash {
+		return []common.Hash{blobHash}
+	})
+	cache.Put(blobHash[:], blob)
+
+	trie, _ := New(common.Hash{}, cache)
+	root := fillTrie(t, trie, cache, "leaf", 10)
+	cache.Reference(root)
+
+	if have, err := cache.Get(blobHash[:]); err != nil || string(have) != string(blob) {
+		t.Fatalf("referenced blob missing: %v", err)
+	}
+	cache.Dereference(root)
+	if _, err := cache.Get(blobHash[:]); err == nil {
+		t.Errorf("blob of dereferenced leaves still cached")
+	}
+}